@@ -8,17 +8,29 @@ create table activation_attempts
     error_message varchar(255)                       null comment '激活失败时的错误信息',
     request_data  text                               null comment '激活请求的JSON数据',
     response_data text                               null comment '激活响应的JSON数据',
+    ip            varchar(64)                        null comment '客户端IP，经过信任的代理时取 X-Forwarded-For',
+    user_agent    varchar(255)                       null comment '客户端 User-Agent',
+    seid          varchar(255)                       null comment '激活请求中的设备SEID',
+    app_id        varchar(32)                        null comment '激活码所属的应用',
+    country       varchar(8)                         null comment 'IP所在国家代码',
+    city          varchar(128)                       null comment 'IP所在城市',
     created_at    datetime default CURRENT_TIMESTAMP not null comment '记录创建时间'
 );
 
 create table app
 (
-    id          varchar(32)                         not null
+    id            varchar(32)                            not null
         primary key,
-    name        varchar(255)                        not null,
-    card_length int                                 not null,
-    card_prefix varchar(255)                        not null,
-    created_at  timestamp default CURRENT_TIMESTAMP not null
+    name          varchar(255)                           not null,
+    card_length   int                                    not null,
+    card_prefix   varchar(255)                           not null,
+    status        varchar(16)  default 'active'          not null comment '状态: active-启用, disabled-停用',
+    time_types    json                                   null comment '生成激活码时可选的时间类型，为空表示不限制',
+    max_duration  int          default 0                 not null comment '单个激活码的最大有效时长（分钟），0 表示不限制',
+    device_policy varchar(64)  default ''                not null comment '设备绑定策略',
+    contact_url   varchar(512) default ''                not null comment '客服或支持页面的链接',
+    archived_at   timestamp                              null comment '归档时间',
+    created_at    timestamp    default CURRENT_TIMESTAMP not null
 );
 
 create table card
@@ -40,7 +52,8 @@ create table card
     app_id     varchar(36)   null comment 'App唯一标识符(UUID)',
     minutes    int default 0 not null comment '有效分钟数',
     locked_at  datetime      null comment '锁定时间',
-    time_type  varchar(50)   not null comment '激活码时间类型'
+    time_type  varchar(50)   not null comment '激活码时间类型',
+    batch_id   varchar(36)   null comment '批次ID，同一次批量生成的激活码相同'
 );
 
 create table user
@@ -50,7 +63,7 @@ create table user
     username     varchar(255)                        not null,
     password     varchar(255)                        not null,
     status       int       default 1                 null,
-    ancestry     varchar(255)                        null,
+    ancestry     varchar(1024)                       null,
     total_cnt    int       default 0                 null,
     used_cnt     int       default 0                 null,
     noused_cnt   int       default 0                 null,
@@ -81,3 +94,444 @@ create table user_config
         unique (user_id, config_key)
 )
     charset = latin1;
+
+create table role
+(
+    id          varchar(32)                         not null
+        primary key,
+    name        varchar(64)                         not null comment '角色名称',
+    description varchar(255)                        null comment '角色描述',
+    permissions json                                null comment '角色拥有的权限',
+    creator_id  varchar(32)                         not null comment '创建者ID',
+    created_at  timestamp default CURRENT_TIMESTAMP not null,
+    updated_at  timestamp default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP,
+    constraint name
+        unique (name)
+);
+
+-- ancestry 为以 / 分隔的祖先ID物化路径，通过前缀匹配查询整棵子树
+-- 完整路径超过索引长度限制，只索引前 255 个字符，前缀匹配仍然可以使用该索引
+create index idx_user_ancestry
+    on user (ancestry(255));
+
+create table quota_ledger
+(
+    id         int auto_increment
+        primary key,
+    user_id    varchar(255)                       not null comment '额度所属用户ID',
+    amount     int                                not null comment '变动数量，正数为增加，负数为扣减',
+    type       varchar(32)                        not null comment '流水类型: credit, transfer_in, transfer_out, debit, refund',
+    reason     varchar(255)                       null comment '变动原因',
+    actor_id   varchar(255)                       null comment '操作人ID',
+    related_id varchar(255)                       null comment '关联对象ID',
+    created_at datetime default CURRENT_TIMESTAMP not null
+);
+
+create index idx_quota_ledger_user
+    on quota_ledger (user_id, created_at);
+
+-- 从 max_cnt 迁移到额度流水：剩余额度 = max_cnt - 已生成且未退还的激活码数量
+insert into quota_ledger (user_id, amount, type, reason)
+select u.id,
+       u.max_cnt - (select count(*)
+                    from card c
+                    where c.user_id = u.id
+                      and not (c.status = 4 and c.used_at is null)),
+       'credit',
+       '迁移初始额度'
+from user u;
+
+create table user_app_grant
+(
+    id           varchar(32)                         not null
+        primary key,
+    user_id      varchar(255)                        not null comment '用户ID',
+    app_id       varchar(255)                        not null comment '应用ID',
+    quota        int       default -1                not null comment '该应用最多可生成的激活码数量，-1 表示不限制',
+    time_types   json                                null comment '允许的时间类型，为空表示不限制',
+    max_duration int       default 0                 not null comment '单个激活码的最大有效时长（分钟），0 表示不限制',
+    creator_id   varchar(255)                        not null comment '授权人ID',
+    created_at   timestamp default CURRENT_TIMESTAMP not null,
+    updated_at   timestamp default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP,
+    constraint user_app
+        unique (user_id, app_id)
+);
+
+create table api_key
+(
+    id           varchar(32)                         not null
+        primary key,
+    user_id      varchar(255)                        not null comment '所属用户ID，可以是普通用户或服务账号',
+    name         varchar(64)                         not null comment '名称',
+    prefix       varchar(16)                         not null comment '明文保存的查找前缀',
+    key_hash     char(64)                            not null comment '完整 Key 的 SHA-256',
+    permissions  json                                null comment 'Key 拥有的权限，是所属用户权限的子集',
+    expires_at   timestamp                           null comment '过期时间，为空表示永不过期',
+    last_used_at timestamp                           null comment '最近使用时间',
+    revoked_at   timestamp                           null comment '吊销时间',
+    creator_id   varchar(255)                        not null comment '创建者ID',
+    created_at   timestamp default CURRENT_TIMESTAMP not null,
+    constraint prefix
+        unique (prefix)
+);
+
+create index idx_api_key_user
+    on api_key (user_id);
+
+-- 管理 API Key 需要 API_KEY_MANAGE 权限，已经创建过 Key 的用户保留该权限
+update user u
+set u.permissions = json_array_append(coalesce(u.permissions, json_array()), '$', 'API_KEY_MANAGE')
+where u.id in (select k.creator_id from api_key k)
+  and not json_contains(coalesce(u.permissions, json_array()), '"API_KEY_MANAGE"');
+
+create table user_identity
+(
+    id            varchar(32)                         not null
+        primary key,
+    issuer        varchar(255)                        not null comment '身份提供方',
+    subject       varchar(255)                        not null comment '身份提供方中的用户标识(sub)',
+    user_id       varchar(255)                        not null comment '本地用户ID',
+    created_at    timestamp default CURRENT_TIMESTAMP not null,
+    last_login_at timestamp default CURRENT_TIMESTAMP not null,
+    constraint issuer_subject
+        unique (issuer, subject)
+);
+
+create table user_session
+(
+    id           varchar(32)                         not null
+        primary key,
+    user_id      varchar(255)                        not null comment '所属用户ID',
+    ip           varchar(64)                         not null comment '登录时的IP',
+    user_agent   varchar(512)                        not null comment '登录时的 User-Agent',
+    expires_at   timestamp                           not null comment '过期时间，与 token 的过期时间一致',
+    last_seen_at timestamp                           not null comment '最近活跃时间',
+    revoked_at   timestamp                           null comment '吊销时间',
+    created_at   timestamp default CURRENT_TIMESTAMP not null
+);
+
+create index idx_user_session_user
+    on user_session (user_id);
+
+create table audit_log
+(
+    id          bigint auto_increment
+        primary key,
+    actor_id    varchar(255)                        not null comment '操作人ID',
+    actor_name  varchar(255)                        not null comment '操作人用户名',
+    action      varchar(64)                         not null comment '操作，例如 card.update',
+    target_type varchar(32)                         not null comment '对象类型',
+    target_id   varchar(255)                        not null comment '对象ID，激活码为激活码值',
+    before_data json                                null comment '修改前发生变化的字段',
+    after_data  json                                null comment '修改后发生变化的字段',
+    ip          varchar(64)                         not null comment '操作人IP',
+    request_id  varchar(64)                         not null comment '请求ID',
+    created_at  timestamp default CURRENT_TIMESTAMP not null
+);
+
+create index idx_audit_log_target
+    on audit_log (target_type, target_id);
+
+create index idx_audit_log_actor
+    on audit_log (actor_id, created_at);
+
+create index idx_audit_log_created_at
+    on audit_log (created_at);
+
+-- 审计日志只追加，禁止修改和删除
+create trigger audit_log_no_update
+    before update
+    on audit_log
+    for each row signal sqlstate '45000' set message_text = 'audit_log is append-only';
+
+create trigger audit_log_no_delete
+    before delete
+    on audit_log
+    for each row signal sqlstate '45000' set message_text = 'audit_log is append-only';
+
+create index idx_activation_attempts_card_value
+    on activation_attempts (card_value);
+
+create index idx_activation_attempts_ip
+    on activation_attempts (ip);
+
+create index idx_activation_attempts_seid
+    on activation_attempts (seid);
+
+create index idx_activation_attempts_app_id
+    on activation_attempts (app_id, created_at);
+
+create index idx_activation_attempts_country
+    on activation_attempts (country, created_at);
+
+create table card_check
+(
+    id         int unsigned auto_increment
+        primary key,
+    card_value varchar(255)                       not null comment '激活码值',
+    available  tinyint(1)                         not null comment '检查结果 (1表示可用，0表示不可用)',
+    ip         varchar(64)                        null comment '请求方IP',
+    created_at datetime default CURRENT_TIMESTAMP not null comment '检查时间'
+)
+    comment '公开接口的激活码可用性检查记录';
+
+create index idx_card_check_card_value
+    on card_check (card_value);
+
+create table abuse_flag
+(
+    id           bigint auto_increment
+        primary key,
+    rule         varchar(64)                         not null comment '触发的规则',
+    signal_name  varchar(32)                         not null comment '规则统计的指标',
+    subject_type varchar(16)                         not null comment '对象类型: card, seid, ip',
+    subject      varchar(255)                        not null comment '激活码值、SEID 或 IP',
+    count        int                                 not null comment '触发时窗口内的统计值',
+    score        int                                 not null comment '触发时对象的总分',
+    actions      varchar(64)  default ''             not null comment '已执行的动作，逗号分隔',
+    detail       varchar(255) default ''             not null comment '触发事件的说明',
+    status       varchar(16)  default 'open'         not null comment '审核状态: open, confirmed, dismissed',
+    reviewer_id  varchar(32)                         null comment '审核人',
+    review_note  varchar(255)                        null comment '审核备注',
+    reviewed_at  timestamp                           null comment '审核时间',
+    created_at   timestamp    default CURRENT_TIMESTAMP not null
+)
+    comment '滥用检测产生的标记';
+
+create index idx_abuse_flag_subject
+    on abuse_flag (rule, subject_type, subject, status);
+
+create index idx_abuse_flag_status
+    on abuse_flag (status, created_at);
+
+create table ip_block
+(
+    ip         varchar(64)                         not null
+        primary key,
+    reason     varchar(255) default ''             not null comment '封禁原因，规则名或管理员备注',
+    expires_at timestamp                           not null comment '到期时间',
+    created_at timestamp    default CURRENT_TIMESTAMP not null
+)
+    comment '被滥用检测封禁的IP';
+
+create index idx_activation_attempts_ip_success
+    on activation_attempts (ip, success, created_at);
+
+create index idx_card_check_ip
+    on card_check (ip, available, created_at);
+
+-- 批次上线前生成的激活码按创建人和创建时间划分批次，取 md5 得到 32 位的ID，不超过 batch_id 的长度
+update card
+set batch_id = md5(concat(user_id, '-', date_format(created_at, '%Y%m%d%H%i%s')))
+where batch_id is null;
+
+create index idx_card_used_at
+    on card (used_at);
+
+create index idx_card_expired_at
+    on card (expired_at);
+
+create index idx_card_batch_id
+    on card (batch_id);
+
+create table card_stat
+(
+    user_id varchar(36) not null comment '激活码所有者',
+    app_id  varchar(36) not null comment '应用ID，没有应用的激活码为空字符串',
+    day     date        not null comment '激活码的生成日期',
+    status  int         not null comment '激活码当前状态',
+    count   int         not null comment '激活码数量',
+    primary key (user_id, app_id, day, status)
+)
+    comment '激活码统计，激活码变化时在同一个事务中增量更新，可以用 -rebuild-stats 重建';
+
+create index idx_card_stat_app
+    on card_stat (app_id, day);
+
+-- 统计上线前的激活码，和 -rebuild-stats 相同
+insert into card_stat (user_id, app_id, day, status, count)
+select user_id, coalesce(app_id, ''), date(created_at), status, count(*)
+from card
+group by user_id, coalesce(app_id, ''), date(created_at), status;
+
+create table webhook_subscription
+(
+    id         varchar(36)                          not null
+        primary key,
+    app_id     varchar(36)                          not null comment '应用ID，只接收该应用激活码的事件',
+    url        varchar(512)                         not null comment '接收地址',
+    secret     varchar(128)                         not null comment '签名密钥',
+    events     varchar(255)                         not null comment '订阅的事件类型，逗号分隔',
+    enabled    tinyint(1) default 1                 not null comment '是否启用',
+    creator_id varchar(32)                          not null comment '创建人',
+    created_at timestamp  default CURRENT_TIMESTAMP not null,
+    updated_at timestamp  default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP
+)
+    comment '应用的 webhook 订阅';
+
+create index idx_webhook_subscription_app
+    on webhook_subscription (app_id, enabled);
+
+create table webhook_delivery
+(
+    id               bigint auto_increment
+        primary key,
+    subscription_id  varchar(36)                           not null comment '订阅ID',
+    event_id         varchar(128)                          not null comment '事件ID，同一个订阅的同一个事件只投递一次',
+    event_type       varchar(32)                           not null comment '事件类型',
+    payload          text                                  not null comment '请求体',
+    status           varchar(16)                           not null comment '投递状态: pending, succeeded, dead',
+    attempts         int          default 0                not null comment '已尝试次数',
+    next_attempt_at  timestamp                             not null comment '下次尝试时间',
+    last_status_code int          default 0                not null comment '最后一次尝试时接收方返回的状态码',
+    last_error       varchar(255) default ''               not null comment '最后一次尝试的错误信息',
+    delivered_at     timestamp                             null comment '投递成功的时间',
+    created_at       timestamp    default CURRENT_TIMESTAMP not null,
+    updated_at       timestamp    default CURRENT_TIMESTAMP not null,
+    constraint uk_webhook_delivery_event
+        unique (subscription_id, event_id)
+)
+    comment 'webhook 发件箱，事件和激活码的变化在同一个事务中写入';
+
+create index idx_webhook_delivery_due
+    on webhook_delivery (status, next_attempt_at);
+
+create table event
+(
+    id             bigint auto_increment
+        primary key,
+    user_id        varchar(255)  default ''                not null comment '事件涉及的用户',
+    remind_user_id varchar(255)                            not null comment '接收通知的用户',
+    type           varchar(32)                             not null comment '通知类型: low_stock, low_quota, batch_expiring, abuse_flagged',
+    name           varchar(255)                            not null comment '事件名称',
+    detail         varchar(1024) default ''                not null comment '事件详细描述',
+    params         json                                    null comment '站外通知模板的参数',
+    dedup_key      varchar(255)  default ''                not null comment '冷却期内同一个用户相同 key 的通知只产生一次',
+    is_read        tinyint(1)    default 0                 not null comment '是否已读',
+    read_at        timestamp                               null comment '已读时间',
+    created_at     timestamp     default CURRENT_TIMESTAMP not null
+)
+    comment '站内通知';
+
+create index idx_event_remind
+    on event (remind_user_id, is_read, id);
+
+create index idx_event_dedup
+    on event (remind_user_id, dedup_key, created_at);
+
+create table notification_template
+(
+    type       varchar(32)                         not null comment '通知类型',
+    locale     varchar(16)                         not null comment '语言: zh-CN, en-US',
+    subject    varchar(255)                        not null comment '标题模板',
+    body       text                                not null comment '正文模板',
+    updater_id varchar(255)                        not null comment '最后修改人',
+    updated_at timestamp default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP,
+    primary key (type, locale)
+)
+    comment '修改过的站外通知模板，没有修改的使用代码中的默认模板';
+
+create table card_export_job
+(
+    id          varchar(36)                            not null
+        primary key,
+    user_id     varchar(255)                           not null comment '创建任务的用户，只有该用户可以下载',
+    format      varchar(8)                             not null comment '文件格式: csv, xlsx',
+    args        text                                   not null comment '导出参数',
+    status      varchar(16)                            not null comment '任务状态: pending, running, succeeded, failed, expired',
+    file_name   varchar(255) default ''                not null comment '下载时使用的文件名',
+    file_path   varchar(512) default ''                not null comment '文件在服务器上的路径',
+    row_count   int          default 0                 not null comment '导出的激活码数量',
+    error       varchar(255) default ''                not null comment '失败原因',
+    created_at  timestamp    default CURRENT_TIMESTAMP not null,
+    started_at  timestamp                              null comment '开始时间',
+    finished_at timestamp                              null comment '完成时间',
+    expires_at  timestamp                              null comment '文件删除时间'
+)
+    comment '激活码后台导出任务';
+
+create index idx_card_export_job_user
+    on card_export_job (user_id, created_at);
+
+create index idx_card_export_job_expires
+    on card_export_job (status, expires_at);
+
+create table app_version
+(
+    id          varchar(36)                             not null
+        primary key,
+    app_id      varchar(36)                             not null comment '应用ID',
+    channel     varchar(32)                             not null comment '发布渠道，例如 stable、beta',
+    version     varchar(32)                             not null comment '版本号',
+    min_version varchar(32)   default ''                not null comment '发布该版本后渠道的最低支持版本，为空表示不限制',
+    notes       varchar(1024) default ''                not null comment '版本说明',
+    creator_id  varchar(36)                             not null comment '创建人',
+    created_at  timestamp     default CURRENT_TIMESTAMP not null,
+    constraint app_channel_version
+        unique (app_id, channel, version)
+)
+    comment '应用发布的客户端版本';
+
+create table device_version
+(
+    app_id       varchar(36)  not null comment '应用ID',
+    seid         varchar(255) not null comment '设备SEID',
+    channel      varchar(32)  not null comment '发布渠道',
+    version      varchar(32)  not null comment '最近一次上报的版本号',
+    last_seen_at timestamp    not null comment '最近一次上报的时间',
+    primary key (app_id, seid)
+)
+    comment '设备最近一次上报的客户端版本';
+
+create index idx_device_version_seen
+    on device_version (app_id, last_seen_at);
+
+create table remote_config_key
+(
+    id            varchar(36)                            not null
+        primary key,
+    app_id        varchar(36)                            not null comment '应用ID',
+    config_key    varchar(128)                           not null comment '配置项名称',
+    value_type    varchar(16)                            not null comment '值类型: string, int, float, bool, json',
+    default_value text                                   not null comment '默认值，JSON 格式，为空表示没有默认值',
+    description   varchar(255) default ''                not null comment '说明',
+    created_at    timestamp    default CURRENT_TIMESTAMP not null,
+    updated_at    timestamp    default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP,
+    constraint app_config_key
+        unique (app_id, config_key)
+)
+    comment '下发给客户端的远程配置项';
+
+create table remote_config_value
+(
+    id          varchar(36)                            not null
+        primary key,
+    key_id      varchar(36)                            not null comment '配置项ID',
+    app_id      varchar(36)                            not null comment '应用ID',
+    scope       varchar(16)                            not null comment '作用范围: app-应用, tier-权益等级, card-激活码',
+    scope_value varchar(255) default ''                not null comment '权益等级或激活码的值，作用范围为 app 时为空',
+    value       text                                   not null comment '配置值，JSON 格式',
+    version     int                                    not null comment '版本号，每次修改加一',
+    updater_id  varchar(36)                            not null comment '最后修改人',
+    updated_at  timestamp    default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP,
+    constraint key_scope_value
+        unique (key_id, scope, scope_value)
+)
+    comment '远程配置项在各个作用范围上的值';
+
+create index idx_remote_config_value_app
+    on remote_config_value (app_id, scope, scope_value);
+
+create table remote_config_history
+(
+    id         bigint auto_increment
+        primary key,
+    value_id   varchar(36)                         not null comment '配置值ID',
+    version    int                                 not null comment '版本号',
+    value      text                                not null comment '该版本的配置值',
+    updater_id varchar(36)                         not null comment '修改人',
+    created_at timestamp default CURRENT_TIMESTAMP not null,
+    constraint value_version
+        unique (value_id, version)
+)
+    comment '远程配置值的历史版本，用于回滚';
@@ -4,7 +4,9 @@ import (
 	"crypto/rsa"
 	"time"
 
+	"configuration-management/pkg/geoip"
 	"configuration-management/pkg/logger"
+	"configuration-management/pkg/ratelimit"
 	"configuration-management/pkg/setting"
 
 	"github.com/patrickmn/go-cache"
@@ -13,12 +15,23 @@ import (
 
 // 读取配置信息，然后保存到全局变量中
 var (
-	ServerSetting     *setting.ServerSettingS
-	AppSetting        *setting.AppSettingS
-	DatabaseSetting   *setting.DatabaseSettingS
-	Logger            *logger.Logger
-	DBEngine          *gorm.DB
-	InvalidTokenCache *cache.Cache
+	ServerSetting       *setting.ServerSettingS
+	AppSetting          *setting.AppSettingS
+	DatabaseSetting     *setting.DatabaseSettingS
+	OIDCSetting         *setting.OIDCSettingS
+	GeoIPSetting        *setting.GeoIPSettingS
+	AbuseSetting        *setting.AbuseSettingS
+	RateLimitSetting    *setting.RateLimitSettingS
+	WebhookSetting      *setting.WebhookSettingS
+	NotificationSetting *setting.NotificationSettingS
+	ExportSetting       *setting.ExportSettingS
+	CardSheetSetting    *setting.CardSheetSettingS
+	RedeemSetting       *setting.RedeemSettingS
+	RateLimiter         *ratelimit.Limiter
+	GeoLocator          geoip.Locator
+	Logger              *logger.Logger
+	DBEngine            *gorm.DB
+	InvalidTokenCache   *cache.Cache
 
 	PrivateKey *rsa.PrivateKey
 	PublicKey  *rsa.PublicKey
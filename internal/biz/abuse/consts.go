@@ -0,0 +1,71 @@
+package abuse
+
+import (
+	"time"
+
+	"configuration-management/pkg/setting"
+)
+
+// 被统计的对象类型
+const (
+	SubjectCard = "card"
+	SubjectSEID = "seid"
+	SubjectIP   = "ip"
+)
+
+// 规则统计的指标
+const (
+	SignalSEIDsPerCard      = "seids_per_card"       // 窗口内尝试激活同一个激活码的设备数
+	SignalCardsPerSEID      = "cards_per_seid"       // 窗口内同一个设备尝试激活的激活码数
+	SignalInvalidCardsPerIP = "invalid_cards_per_ip" // 窗口内同一个IP激活失败或检查为不可用的激活码数
+	SignalChecksPerIP       = "checks_per_ip"        // 窗口内同一个IP检查可用性的次数
+	SignalChecksPerCard     = "checks_per_card"      // 窗口内同一个激活码被检查可用性的次数
+)
+
+// 触发规则后的动作
+const (
+	ActionLock    = "lock"     // 锁定事件中的激活码
+	ActionBlockIP = "block_ip" // 封禁事件中的IP
+	ActionNotify  = "notify"   // 通知管理员
+	ActionFlag    = "flag"     // 只标记，等待管理员审核
+)
+
+// 标记的审核状态
+const (
+	FlagStatusOpen      = "open"      // 待审核
+	FlagStatusConfirmed = "confirmed" // 确认为滥用
+	FlagStatusDismissed = "dismissed" // 误报
+)
+
+const (
+	// defaultBlockDuration 规则没有配置封禁时长时使用
+	defaultBlockDuration = 24 * time.Hour
+	// maxFlagDetailLength 标记说明的最大长度
+	maxFlagDetailLength = 255
+	// ipBlockCacheTTL IP封禁状态的缓存时间
+	ipBlockCacheTTL          = time.Minute
+	ipBlockCacheCleanupCycle = 5 * time.Minute
+)
+
+// 事件类型
+const (
+	EventActivation = "activation"
+	EventCheck      = "check"
+)
+
+// signalSubjects 指标对应的统计对象
+var signalSubjects = map[string]string{
+	SignalSEIDsPerCard:      SubjectCard,
+	SignalCardsPerSEID:      SubjectSEID,
+	SignalInvalidCardsPerIP: SubjectIP,
+	SignalChecksPerIP:       SubjectIP,
+	SignalChecksPerCard:     SubjectCard,
+}
+
+// DefaultRules 没有配置规则时使用
+var DefaultRules = []setting.AbuseRule{
+	{Name: "card-shared-by-devices", Signal: SignalSEIDsPerCard, Window: 24 * time.Hour, Threshold: 3, Score: 50, Actions: []string{ActionLock, ActionNotify}},
+	{Name: "device-probing-cards", Signal: SignalCardsPerSEID, Window: time.Hour, Threshold: 10, Score: 30, Actions: []string{ActionFlag}},
+	{Name: "ip-enumerating-cards", Signal: SignalInvalidCardsPerIP, Window: time.Hour, Threshold: 20, Score: 80, Actions: []string{ActionBlockIP, ActionNotify}, BlockDuration: 24 * time.Hour},
+	{Name: "ip-check-burst", Signal: SignalChecksPerIP, Window: time.Minute, Threshold: 60, Score: 20, Actions: []string{ActionBlockIP}, BlockDuration: time.Hour},
+}
@@ -0,0 +1,101 @@
+package abuse
+
+import (
+	"time"
+
+	"configuration-management/pkg/setting"
+)
+
+// Event 公开接口上发生的一次激活或可用性检查
+type Event struct {
+	Type      string // activation, check
+	CardValue string
+	SEID      string
+	IP        string
+	Success   bool // 激活是否成功或检查时是否可用
+}
+
+func (e Event) subject(subjectType string) string {
+	switch subjectType {
+	case SubjectCard:
+		return e.CardValue
+	case SubjectSEID:
+		return e.SEID
+	case SubjectIP:
+		return e.IP
+	}
+	return ""
+}
+
+// Trigger 一条被触发的规则
+type Trigger struct {
+	Rule        setting.AbuseRule
+	SubjectType string
+	Subject     string
+	Count       int // 窗口内的统计值
+	Score       int // 对象在本次评估中触发的所有规则的总分
+}
+
+// signalEvents 指标只在相关的事件上计算，避免每次请求都执行所有统计
+var signalEvents = map[string][]string{
+	SignalSEIDsPerCard:      {EventActivation},
+	SignalCardsPerSEID:      {EventActivation},
+	SignalInvalidCardsPerIP: {EventActivation, EventCheck},
+	SignalChecksPerIP:       {EventCheck},
+	SignalChecksPerCard:     {EventCheck},
+}
+
+// evaluate 对事件涉及的激活码、设备和IP计算所有规则，返回达到阈值的规则
+func evaluate(rules []setting.AbuseRule, event Event, counter Counter, now time.Time) ([]Trigger, error) {
+	triggers := make([]Trigger, 0)
+	scores := make(map[string]int)
+	for _, rule := range rules {
+		if !contains(signalEvents[rule.Signal], event.Type) {
+			continue
+		}
+		subjectType := signalSubjects[rule.Signal]
+		subject := event.subject(subjectType)
+		if subject == "" {
+			continue
+		}
+
+		count, err := countSignal(counter, rule.Signal, subject, now.Add(-rule.Window))
+		if err != nil {
+			return nil, err
+		}
+		if count < rule.Threshold {
+			continue
+		}
+		scores[subjectType+":"+subject] += rule.Score
+		triggers = append(triggers, Trigger{Rule: rule, SubjectType: subjectType, Subject: subject, Count: count})
+	}
+	for i := range triggers {
+		triggers[i].Score = scores[triggers[i].SubjectType+":"+triggers[i].Subject]
+	}
+	return triggers, nil
+}
+
+func countSignal(counter Counter, signal string, subject string, since time.Time) (int, error) {
+	switch signal {
+	case SignalSEIDsPerCard:
+		return counter.CountSEIDsByCard(subject, since)
+	case SignalCardsPerSEID:
+		return counter.CountCardsBySEID(subject, since)
+	case SignalInvalidCardsPerIP:
+		return counter.CountInvalidCardsByIP(subject, since)
+	case SignalChecksPerIP:
+		return counter.CountChecksByIP(subject, since)
+	case SignalChecksPerCard:
+		return counter.CountChecksByCard(subject, since)
+	}
+	return 0, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
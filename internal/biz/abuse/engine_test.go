@@ -0,0 +1,94 @@
+package abuse
+
+import (
+	"testing"
+	"time"
+
+	"configuration-management/pkg/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCounter struct {
+	seidsByCard   int
+	cardsBySEID   int
+	invalidByIP   int
+	checksByIP    int
+	checksByCard  int
+	lastSince     time.Time
+	countedSignal []string
+}
+
+func (f *fakeCounter) record(signal string, since time.Time) {
+	f.countedSignal = append(f.countedSignal, signal)
+	f.lastSince = since
+}
+
+func (f *fakeCounter) CountSEIDsByCard(_ string, since time.Time) (int, error) {
+	f.record(SignalSEIDsPerCard, since)
+	return f.seidsByCard, nil
+}
+
+func (f *fakeCounter) CountCardsBySEID(_ string, since time.Time) (int, error) {
+	f.record(SignalCardsPerSEID, since)
+	return f.cardsBySEID, nil
+}
+
+func (f *fakeCounter) CountInvalidCardsByIP(_ string, since time.Time) (int, error) {
+	f.record(SignalInvalidCardsPerIP, since)
+	return f.invalidByIP, nil
+}
+
+func (f *fakeCounter) CountChecksByIP(_ string, since time.Time) (int, error) {
+	f.record(SignalChecksPerIP, since)
+	return f.checksByIP, nil
+}
+
+func (f *fakeCounter) CountChecksByCard(_ string, since time.Time) (int, error) {
+	f.record(SignalChecksPerCard, since)
+	return f.checksByCard, nil
+}
+
+func TestEvaluateActivation(t *testing.T) {
+	counter := &fakeCounter{seidsByCard: 3, cardsBySEID: 2, invalidByIP: 25}
+	event := Event{Type: EventActivation, CardValue: "card", SEID: "seid", IP: "1.1.1.1"}
+
+	triggers, err := evaluate(DefaultRules, event, counter, time.Now())
+	assert.NoError(t, err)
+	// 检查次数的规则不在激活事件上计算
+	assert.Equal(t, []string{SignalSEIDsPerCard, SignalCardsPerSEID, SignalInvalidCardsPerIP}, counter.countedSignal)
+	if assert.Len(t, triggers, 2) {
+		assert.Equal(t, "card-shared-by-devices", triggers[0].Rule.Name)
+		assert.Equal(t, SubjectCard, triggers[0].SubjectType)
+		assert.Equal(t, "card", triggers[0].Subject)
+		assert.Equal(t, "ip-enumerating-cards", triggers[1].Rule.Name)
+		assert.Equal(t, "1.1.1.1", triggers[1].Subject)
+	}
+}
+
+func TestEvaluateScoresPerSubject(t *testing.T) {
+	rules := []setting.AbuseRule{
+		{Name: "a", Signal: SignalChecksPerIP, Window: time.Minute, Threshold: 10, Score: 20},
+		{Name: "b", Signal: SignalInvalidCardsPerIP, Window: time.Hour, Threshold: 5, Score: 30},
+		{Name: "c", Signal: SignalChecksPerCard, Window: time.Hour, Threshold: 5, Score: 7},
+	}
+	counter := &fakeCounter{checksByIP: 10, invalidByIP: 5, checksByCard: 6}
+	now := time.Now()
+	triggers, err := evaluate(rules, Event{Type: EventCheck, CardValue: "card", IP: "2.2.2.2"}, counter, now)
+	assert.NoError(t, err)
+	if assert.Len(t, triggers, 3) {
+		// 同一个IP触发的规则分数累加，激活码单独计分
+		assert.Equal(t, 50, triggers[0].Score)
+		assert.Equal(t, 50, triggers[1].Score)
+		assert.Equal(t, 7, triggers[2].Score)
+	}
+	assert.Equal(t, now.Add(-time.Hour), counter.lastSince)
+}
+
+func TestEvaluateSkipsMissingSubject(t *testing.T) {
+	counter := &fakeCounter{cardsBySEID: 100}
+	triggers, err := evaluate(DefaultRules, Event{Type: EventActivation, CardValue: "card"}, counter, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, triggers)
+	assert.NotContains(t, counter.countedSignal, SignalCardsPerSEID)
+}
@@ -0,0 +1,37 @@
+package abuse
+
+import "time"
+
+// Flag 规则触发后产生的标记，由管理员审核
+type Flag struct {
+	ID          int64      `json:"id"`
+	Rule        string     `json:"rule"`                             // 触发的规则
+	Signal      string     `json:"signal" gorm:"column:signal_name"` // 规则统计的指标，SIGNAL 是 MySQL 的保留字
+	SubjectType string     `json:"subject_type"`                     // 对象类型: card, seid, ip
+	Subject     string     `json:"subject"`                          // 激活码值、SEID 或 IP
+	Count       int        `json:"count"`                            // 触发时窗口内的统计值
+	Score       int        `json:"score"`                            // 触发时对象的总分
+	Actions     string     `json:"actions"`                          // 已执行的动作，逗号分隔
+	Detail      string     `json:"detail"`                           // 触发事件的说明
+	Status      string     `json:"status"`                           // open, confirmed, dismissed
+	ReviewerID  string     `json:"reviewer_id"`                      // 审核人
+	ReviewNote  string     `json:"review_note"`                      // 审核备注
+	ReviewedAt  *time.Time `json:"reviewed_at"`                      // 审核时间
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (f *Flag) TableName() string {
+	return "abuse_flag"
+}
+
+// IPBlock 被封禁的IP，到期后自动失效
+type IPBlock struct {
+	IP        string    `json:"ip" gorm:"primaryKey"`
+	Reason    string    `json:"reason"`     // 封禁原因，规则名或管理员备注
+	ExpiresAt time.Time `json:"expires_at"` // 到期时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (b *IPBlock) TableName() string {
+	return "ip_block"
+}
@@ -0,0 +1,43 @@
+package abuse
+
+import "time"
+
+type QueryFlagsArgs struct {
+	Status      string `json:"status"`
+	Rule        string `json:"rule"`
+	SubjectType string `json:"subject_type"`
+	Subject     string `json:"subject"`
+	Page        int    `json:"page"`
+	Limit       int    `json:"limit"`
+}
+
+type QueryFlagsResult struct {
+	List  []Flag
+	Total int
+}
+
+// Counter 统计规则需要的指标，数据来自激活记录和可用性检查记录
+type Counter interface {
+	CountSEIDsByCard(cardValue string, since time.Time) (int, error)
+	CountCardsBySEID(seid string, since time.Time) (int, error)
+	CountInvalidCardsByIP(ip string, since time.Time) (int, error)
+	CountChecksByIP(ip string, since time.Time) (int, error)
+	CountChecksByCard(cardValue string, since time.Time) (int, error)
+}
+
+type Repository interface {
+	Counter
+
+	CreateFlag(flag *Flag) error
+	// HasOpenFlag 同一个规则和对象已经有待审核的标记时不再重复标记
+	HasOpenFlag(rule string, subjectType string, subject string) (bool, error)
+	GetFlagByID(id int64) (Flag, error)
+	UpdateFlag(flag Flag) error
+	QueryFlags(args QueryFlagsArgs) (QueryFlagsResult, error)
+
+	// SaveIPBlock 封禁IP，已经封禁时更新到期时间和原因
+	SaveIPBlock(block IPBlock) error
+	GetActiveIPBlock(ip string) (IPBlock, bool, error)
+	GetActiveIPBlocks() ([]IPBlock, error)
+	DeleteIPBlock(ip string) error
+}
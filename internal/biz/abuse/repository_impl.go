@@ -0,0 +1,183 @@
+package abuse
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE abuse_flag (
+    id           BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    rule         VARCHAR(64)  NOT NULL,             -- 触发的规则
+    signal_name  VARCHAR(32)  NOT NULL,             -- 统计的指标
+    subject_type VARCHAR(16)  NOT NULL,             -- 对象类型
+    subject      VARCHAR(255) NOT NULL,             -- 对象
+    count        INT          NOT NULL,             -- 窗口内的统计值
+    score        INT          NOT NULL,             -- 对象的总分
+    actions      VARCHAR(64)  NOT NULL DEFAULT '',  -- 已执行的动作
+    detail       VARCHAR(255) NOT NULL DEFAULT '',  -- 说明
+    status       VARCHAR(16)  NOT NULL DEFAULT 'open',
+    reviewer_id  VARCHAR(32)  NULL,
+    review_note  VARCHAR(255) NULL,
+    reviewed_at  TIMESTAMP    NULL,
+    created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE ip_block (
+    ip         VARCHAR(64)  NOT NULL PRIMARY KEY,
+    reason     VARCHAR(255) NOT NULL DEFAULT '', -- 封禁原因
+    expires_at TIMESTAMP    NOT NULL,            -- 到期时间
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) count(sql string, values ...any) (int, error) {
+	var count int64
+	if err := r.db.Raw(sql, values...).Scan(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *repository) CountSEIDsByCard(cardValue string, since time.Time) (int, error) {
+	return r.count("SELECT COUNT(DISTINCT seid) FROM activation_attempts WHERE card_value = ? AND seid <> '' AND created_at >= ?",
+		cardValue, since)
+}
+
+func (r *repository) CountCardsBySEID(seid string, since time.Time) (int, error) {
+	return r.count("SELECT COUNT(DISTINCT card_value) FROM activation_attempts WHERE seid = ? AND created_at >= ?",
+		seid, since)
+}
+
+func (r *repository) CountInvalidCardsByIP(ip string, since time.Time) (int, error) {
+	return r.count(`SELECT COUNT(DISTINCT card_value) FROM (
+    SELECT card_value FROM activation_attempts WHERE ip = ? AND success = 0 AND created_at >= ?
+    UNION
+    SELECT card_value FROM card_check WHERE ip = ? AND available = 0 AND created_at >= ?
+) t`, ip, since, ip, since)
+}
+
+func (r *repository) CountChecksByIP(ip string, since time.Time) (int, error) {
+	return r.count("SELECT COUNT(*) FROM card_check WHERE ip = ? AND created_at >= ?", ip, since)
+}
+
+func (r *repository) CountChecksByCard(cardValue string, since time.Time) (int, error) {
+	return r.count("SELECT COUNT(*) FROM card_check WHERE card_value = ? AND created_at >= ?", cardValue, since)
+}
+
+func (r *repository) CreateFlag(flag *Flag) error {
+	if err := r.db.Create(flag).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"flag": flag,
+		}).Error("创建滥用标记失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) HasOpenFlag(rule string, subjectType string, subject string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&Flag{}).Where("rule = ? AND subject_type = ? AND subject = ? AND status = ?",
+		rule, subjectType, subject, FlagStatusOpen).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *repository) GetFlagByID(id int64) (Flag, error) {
+	var flag Flag
+	if err := r.db.Where("id = ?", id).First(&flag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Flag{}, errcode.NotFound
+		}
+		return Flag{}, err
+	}
+	return flag, nil
+}
+
+func (r *repository) UpdateFlag(flag Flag) error {
+	return r.db.Model(&Flag{}).Where("id = ?", flag.ID).Updates(map[string]any{
+		"status":      flag.Status,
+		"reviewer_id": flag.ReviewerID,
+		"review_note": flag.ReviewNote,
+		"reviewed_at": flag.ReviewedAt,
+	}).Error
+}
+
+func (r *repository) QueryFlags(args QueryFlagsArgs) (QueryFlagsResult, error) {
+	db := r.db.Model(&Flag{})
+	if args.Status != "" {
+		db = db.Where("status = ?", args.Status)
+	}
+	if args.Rule != "" {
+		db = db.Where("rule = ?", args.Rule)
+	}
+	if args.SubjectType != "" {
+		db = db.Where("subject_type = ?", args.SubjectType)
+	}
+	if args.Subject != "" {
+		db = db.Where("subject = ?", args.Subject)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryFlagsResult{}, err
+	}
+	flags := make([]Flag, 0)
+	if err := db.Order("id desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&flags).Error; err != nil {
+		return QueryFlagsResult{}, err
+	}
+	return QueryFlagsResult{List: flags, Total: int(total)}, nil
+}
+
+func (r *repository) SaveIPBlock(block IPBlock) error {
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ip"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "expires_at"}),
+	}).Create(&block).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"block": block,
+		}).Error("封禁IP失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) GetActiveIPBlock(ip string) (IPBlock, bool, error) {
+	var block IPBlock
+	if err := r.db.Where("ip = ? AND expires_at > ?", ip, time.Now()).First(&block).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return IPBlock{}, false, nil
+		}
+		return IPBlock{}, false, err
+	}
+	return block, true, nil
+}
+
+func (r *repository) GetActiveIPBlocks() ([]IPBlock, error) {
+	blocks := make([]IPBlock, 0)
+	if err := r.db.Where("expires_at > ?", time.Now()).Order("created_at desc").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (r *repository) DeleteIPBlock(ip string) error {
+	return r.db.Where("ip = ?", ip).Delete(&IPBlock{}).Error
+}
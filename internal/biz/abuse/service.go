@@ -0,0 +1,34 @@
+package abuse
+
+import "configuration-management/pkg/app"
+
+// Verdict 一次评估的结果
+type Verdict struct {
+	Flagged  bool     `json:"flagged"`  // 事件涉及的对象有待审核的标记
+	Locked   bool     `json:"locked"`   // 激活码已被自动锁定
+	Blocked  bool     `json:"blocked"`  // IP已被封禁
+	Triggers []string `json:"triggers"` // 本次触发的规则
+}
+
+type ReviewFlagArgs struct {
+	ID     int64     `json:"id"`
+	Status string    `json:"status"` // confirmed, dismissed
+	Note   string    `json:"note"`
+	Actor  app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+// Notifier 规则动作为 notify 时通知管理员
+type Notifier interface {
+	NotifyFlag(flag Flag) error
+}
+
+type Service interface {
+	// Evaluate 在激活或检查记录写入后调用，根据规则标记、锁定激活码或封禁IP
+	Evaluate(event Event) (Verdict, error)
+	// IsIPBlocked 检查IP是否在封禁期内，结果缓存一分钟
+	IsIPBlocked(ip string) (bool, error)
+	QueryFlags(args QueryFlagsArgs) (QueryFlagsResult, error)
+	ReviewFlag(args ReviewFlagArgs) error
+	GetActiveIPBlocks() ([]IPBlock, error)
+	UnblockIP(ip string, actor app.Actor) error
+}
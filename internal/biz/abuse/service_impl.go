@@ -0,0 +1,269 @@
+package abuse
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/setting"
+
+	"github.com/patrickmn/go-cache"
+	"gorm.io/gorm"
+)
+
+var (
+	initializing sync.Once
+	ipBlockCache *cache.Cache // IP是否被封禁
+
+	notifierMu sync.RWMutex
+	notifier   Notifier = logNotifier{}
+)
+
+// systemActor 自动执行的动作在审计日志中的操作人
+var systemActor = app.Actor{Name: "abuse-detector"}
+
+// SetNotifier 替换管理员通知的实现，默认只写日志
+func SetNotifier(n Notifier) {
+	notifierMu.Lock()
+	defer notifierMu.Unlock()
+	notifier = n
+}
+
+func getNotifier() Notifier {
+	notifierMu.RLock()
+	defer notifierMu.RUnlock()
+	return notifier
+}
+
+type logNotifier struct{}
+
+func (logNotifier) NotifyFlag(flag Flag) error {
+	global.Logger.WithFields(logger.Fields{
+		"flag": flag,
+	}).Warning("检测到疑似滥用")
+	return nil
+}
+
+type service struct {
+	db          *gorm.DB
+	repo        Repository
+	cardService card.Service
+	enabled     bool
+	rules       []setting.AbuseRule
+}
+
+func NewService() Service {
+	initializing.Do(func() {
+		ipBlockCache = cache.New(ipBlockCacheTTL, ipBlockCacheCleanupCycle)
+	})
+	s := &service{
+		db:          global.DBEngine,
+		repo:        NewRepository(global.DBEngine),
+		cardService: card.NewService(),
+		rules:       DefaultRules,
+	}
+	if cfg := global.AbuseSetting; cfg != nil {
+		s.enabled = cfg.Enabled
+		if len(cfg.Rules) > 0 {
+			s.rules = cfg.Rules
+		}
+	}
+	return s
+}
+
+func (s *service) Evaluate(event Event) (Verdict, error) {
+	verdict := Verdict{Triggers: make([]string, 0)}
+	if !s.enabled {
+		return verdict, nil
+	}
+
+	triggers, err := evaluate(s.rules, event, s.repo, time.Now())
+	if err != nil {
+		return verdict, err
+	}
+	for _, trigger := range triggers {
+		verdict.Flagged = true
+		verdict.Triggers = append(verdict.Triggers, trigger.Rule.Name)
+
+		// 已经有待审核的标记时不重复执行动作
+		exists, err := s.repo.HasOpenFlag(trigger.Rule.Name, trigger.SubjectType, trigger.Subject)
+		if err != nil {
+			return verdict, err
+		}
+		if exists {
+			continue
+		}
+
+		flag := Flag{
+			Rule:        trigger.Rule.Name,
+			Signal:      trigger.Rule.Signal,
+			SubjectType: trigger.SubjectType,
+			Subject:     trigger.Subject,
+			Count:       trigger.Count,
+			Score:       trigger.Score,
+			Detail:      describe(event),
+			Status:      FlagStatusOpen,
+		}
+		actions := make([]string, 0, len(trigger.Rule.Actions))
+		for _, action := range trigger.Rule.Actions {
+			switch action {
+			case ActionLock:
+				if event.CardValue == "" {
+					continue
+				}
+				if err := s.lockCard(event); err != nil {
+					return verdict, err
+				}
+				verdict.Locked = true
+			case ActionBlockIP:
+				if event.IP == "" {
+					continue
+				}
+				if err := s.blockIP(event.IP, trigger.Rule); err != nil {
+					return verdict, err
+				}
+				verdict.Blocked = true
+			}
+			actions = append(actions, action)
+		}
+		flag.Actions = strings.Join(actions, ",")
+		if err := s.repo.CreateFlag(&flag); err != nil {
+			return verdict, err
+		}
+
+		if contains(actions, ActionNotify) {
+			// 通知失败不影响标记
+			if err := getNotifier().NotifyFlag(flag); err != nil {
+				global.Logger.WithFields(logger.Fields{
+					"flag": flag,
+				}).Error("通知管理员失败", err)
+			}
+		}
+	}
+	return verdict, nil
+}
+
+// lockCard 锁定事件中的激活码，只锁定未使用和已使用的激活码
+func (s *service) lockCard(event Event) error {
+	current, err := s.cardService.GetCardByValue(event.CardValue)
+	if err != nil {
+		// 不存在的激活码不需要锁定
+		if errors.Is(err, errcode.NotFound) {
+			return nil
+		}
+		return err
+	}
+	if current.Status != card.StatusUnused && current.Status != card.StatusUsed {
+		return nil
+	}
+	actor := systemActor
+	actor.IP = event.IP
+	return s.cardService.BatchUpdateStatus(card.BatchUpdateStatusArgs{
+		Actor:  actor,
+		Values: []string{event.CardValue},
+		Status: card.StatusLocked,
+	})
+}
+
+func (s *service) blockIP(ip string, rule setting.AbuseRule) error {
+	duration := rule.BlockDuration
+	if duration <= 0 {
+		duration = defaultBlockDuration
+	}
+	if err := s.repo.SaveIPBlock(IPBlock{
+		IP:        ip,
+		Reason:    rule.Name,
+		ExpiresAt: time.Now().Add(duration),
+	}); err != nil {
+		return err
+	}
+	ipBlockCache.Set(ip, true, cache.DefaultExpiration)
+	return nil
+}
+
+func (s *service) IsIPBlocked(ip string) (bool, error) {
+	if blocked, ok := ipBlockCache.Get(ip); ok {
+		return blocked.(bool), nil
+	}
+	_, blocked, err := s.repo.GetActiveIPBlock(ip)
+	if err != nil {
+		return false, err
+	}
+	ipBlockCache.Set(ip, blocked, cache.DefaultExpiration)
+	return blocked, nil
+}
+
+func (s *service) QueryFlags(args QueryFlagsArgs) (QueryFlagsResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QueryFlags(args)
+}
+
+func (s *service) ReviewFlag(args ReviewFlagArgs) error {
+	if args.Status != FlagStatusConfirmed && args.Status != FlagStatusDismissed {
+		return errcode.InvalidParams.WithDetails("status 只能为 confirmed 或 dismissed")
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := repo.GetFlagByID(args.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		after := before
+		after.Status = args.Status
+		after.ReviewerID = args.Actor.ID
+		after.ReviewNote = args.Note
+		after.ReviewedAt = &now
+		if err := repo.UpdateFlag(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionAbuseFlagReview, audit.TargetAbuseFlag, strconv.FormatInt(before.ID, 10), before, after)
+	})
+}
+
+func (s *service) GetActiveIPBlocks() ([]IPBlock, error) {
+	return s.repo.GetActiveIPBlocks()
+}
+
+func (s *service) UnblockIP(ip string, actor app.Actor) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, blocked, err := repo.GetActiveIPBlock(ip)
+		if err != nil {
+			return err
+		}
+		if !blocked {
+			return errcode.NotFound.WithDetails("IP没有被封禁")
+		}
+		if err := repo.DeleteIPBlock(ip); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionIPBlockDelete, audit.TargetIPBlock, ip, before, nil)
+	})
+	if err != nil {
+		return err
+	}
+	ipBlockCache.Delete(ip)
+	return nil
+}
+
+func describe(event Event) string {
+	detail := event.Type + " card=" + event.CardValue + " seid=" + event.SEID + " ip=" + event.IP
+	if len(detail) > maxFlagDetailLength {
+		return detail[:maxFlagDetailLength]
+	}
+	return detail
+}
@@ -10,6 +10,12 @@ type ActivationAttempt struct {
 	ErrorMessage string    `gorm:"type:varchar(255)" json:"error_message" structs:"error_message"`
 	RequestData  string    `gorm:"type:json" json:"request_data" structs:"request_data"`
 	ResponseData string    `gorm:"type:json" json:"response_data" structs:"response_data"`
+	IP           string    `gorm:"type:varchar(64)" json:"ip" structs:"ip"`                  // 客户端IP，经过信任的代理时取 X-Forwarded-For
+	UserAgent    string    `gorm:"type:varchar(255)" json:"user_agent" structs:"user_agent"` // 客户端 User-Agent
+	SEID         string    `gorm:"column:seid;type:varchar(255)" json:"seid" structs:"seid"` // 激活请求中的设备SEID
+	AppID        string    `gorm:"type:varchar(32)" json:"app_id" structs:"app_id"`          // 激活码所属的应用
+	Country      string    `gorm:"type:varchar(8)" json:"country" structs:"country"`         // IP所在国家代码，未配置地理位置库时为空
+	City         string    `gorm:"type:varchar(128)" json:"city" structs:"city"`             // IP所在城市
 	CreatedAt    time.Time `gorm:"type:datetime;not null;default:current_timestamp" json:"created_at" structs:"created_at"`
 }
 
@@ -1,5 +1,24 @@
 package activationattempt
 
+import "configuration-management/internal/biz/common"
+
+type QueryActivationAttemptsArgs struct {
+	CardValue          string           `json:"card_value"`
+	IP                 string           `json:"ip"`
+	SEID               string           `json:"seid"`
+	AppID              string           `json:"app_id"`
+	Country            string           `json:"country"`
+	Success            *bool            `json:"success"` // 为空时不限制
+	CreatedAtDateRange common.TimeRange `json:"created_at_date_range"`
+	Page               int              `json:"page"`
+	Limit              int              `json:"limit"`
+}
+
+type QueryActivationAttemptsResult struct {
+	List  []ActivationAttempt
+	Total int
+}
+
 type Repository interface {
 	CreateActivationAttempt(attempt *ActivationAttempt) error
 	GetActivationAttemptByID(id uint) (*ActivationAttempt, error)
@@ -7,4 +26,6 @@ type Repository interface {
 	DeleteActivationAttemptByID(id uint) error
 	GetActivationAttemptCountByCardValue(cardID string) (int64, error)
 	GetActivationAttemptCountByCardValueInHour(cardValue string) (int64, error)
+	GetActivationAttemptsByCardValue(cardValue string) ([]ActivationAttempt, error)
+	QueryActivationAttempts(args QueryActivationAttemptsArgs) (QueryActivationAttemptsResult, error)
 }
@@ -59,3 +59,57 @@ func (r *repository) GetActivationAttemptCountByCardValueInHour(cardValue string
 	}
 	return count, nil
 }
+
+// GetActivationAttemptsByCardValue 获取激活码所有的激活记录，按时间顺序
+func (r *repository) GetActivationAttemptsByCardValue(cardValue string) ([]ActivationAttempt, error) {
+	attempts := make([]ActivationAttempt, 0)
+	err := r.db.Model(&ActivationAttempt{}).Where("card_value = ?", cardValue).Order("id asc").Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *repository) filter(args QueryActivationAttemptsArgs) *gorm.DB {
+	db := r.db.Model(&ActivationAttempt{})
+	if args.CardValue != "" {
+		db = db.Where("card_value = ?", args.CardValue)
+	}
+	if args.IP != "" {
+		db = db.Where("ip = ?", args.IP)
+	}
+	if args.SEID != "" {
+		db = db.Where("seid = ?", args.SEID)
+	}
+	if args.AppID != "" {
+		db = db.Where("app_id = ?", args.AppID)
+	}
+	if args.Country != "" {
+		db = db.Where("country = ?", args.Country)
+	}
+	if args.Success != nil {
+		db = db.Where("success = ?", *args.Success)
+	}
+	if !args.CreatedAtDateRange.StartTime.IsZero() {
+		db = db.Where("created_at >= ?", args.CreatedAtDateRange.StartTime.Format(time.DateTime))
+	}
+	if !args.CreatedAtDateRange.EndTime.IsZero() {
+		db = db.Where("created_at <= ?", args.CreatedAtDateRange.EndTime.Format(time.DateTime))
+	}
+	return db
+}
+
+// QueryActivationAttempts 分页查询激活记录，按时间倒序
+func (r *repository) QueryActivationAttempts(args QueryActivationAttemptsArgs) (QueryActivationAttemptsResult, error) {
+	var total int64
+	if err := r.filter(args).Count(&total).Error; err != nil {
+		return QueryActivationAttemptsResult{}, err
+	}
+
+	attempts := make([]ActivationAttempt, 0)
+	if err := r.filter(args).Order("id desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).
+		Find(&attempts).Error; err != nil {
+		return QueryActivationAttemptsResult{}, err
+	}
+	return QueryActivationAttemptsResult{List: attempts, Total: int(total)}, nil
+}
@@ -1,7 +1,9 @@
 package activationattempt
 
 type Service interface {
+	// CreateActivationAttempt 记录一次激活，配置了地理位置库时根据IP补充国家和城市
 	CreateActivationAttempt(attempt *ActivationAttempt) error
 	GetActivationAttemptCountByCardValue(cardValue string) (int64, error)
 	GetActivationAttemptCountByCardValueInHour(cardValue string) (int64, error)
+	QueryActivationAttempts(args QueryActivationAttemptsArgs) (QueryActivationAttemptsResult, error)
 }
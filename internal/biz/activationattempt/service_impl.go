@@ -1,16 +1,39 @@
 package activationattempt
 
-import "configuration-management/global"
+import (
+	"net"
+
+	"configuration-management/global"
+	"configuration-management/pkg/geoip"
+	"configuration-management/pkg/logger"
+)
 
 type service struct {
 	Repository Repository
+	Locator    geoip.Locator
 }
 
 func NewService() Service {
-	return &service{Repository: NewRepository(global.DBEngine)}
+	locator := global.GeoLocator
+	if locator == nil {
+		locator = geoip.NopLocator{}
+	}
+	return &service{Repository: NewRepository(global.DBEngine), Locator: locator}
 }
 
 func (s *service) CreateActivationAttempt(attempt *ActivationAttempt) error {
+	attempt.UserAgent = truncate(attempt.UserAgent, 255)
+	if attempt.IP != "" && attempt.Country == "" {
+		// 查询地理位置失败不影响记录
+		if location, err := s.Locator.Lookup(net.ParseIP(attempt.IP)); err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"ip": attempt.IP,
+			}).Error("查询IP地理位置失败", err)
+		} else {
+			attempt.Country = location.Country
+			attempt.City = location.City
+		}
+	}
 	return s.Repository.CreateActivationAttempt(attempt)
 }
 
@@ -21,3 +44,20 @@ func (s *service) GetActivationAttemptCountByCardValue(cardValue string) (int64,
 func (s *service) GetActivationAttemptCountByCardValueInHour(cardValue string) (int64, error) {
 	return s.Repository.GetActivationAttemptCountByCardValueInHour(cardValue)
 }
+
+func (s *service) QueryActivationAttempts(args QueryActivationAttemptsArgs) (QueryActivationAttemptsResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.Repository.QueryActivationAttempts(args)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
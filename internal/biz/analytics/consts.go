@@ -0,0 +1,48 @@
+package analytics
+
+import "time"
+
+// 时间序列的粒度
+const (
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketMonth = "month"
+)
+
+// 分组维度
+const (
+	GroupNone     = ""
+	GroupApp      = "app"
+	GroupReseller = "reseller" // 激活码的所有者
+	GroupTimeType = "time_type"
+	GroupBatch    = "batch"
+)
+
+const (
+	// defaultRange 没有指定时间范围时统计最近 30 天
+	defaultRange = 30 * 24 * time.Hour
+	// maxHourRange 按小时统计时最长的时间范围
+	maxHourRange = 31 * 24 * time.Hour
+)
+
+// bucketFormats 粒度对应的 MySQL DATE_FORMAT 格式
+var bucketFormats = map[string]string{
+	BucketHour:  "%Y-%m-%d %H:00",
+	BucketDay:   "%Y-%m-%d",
+	BucketMonth: "%Y-%m",
+}
+
+// groupColumn 分组的 key 和用于展示的 label
+type groupColumn struct {
+	Key   string
+	Label string
+}
+
+// groupColumns 分组维度对应的 card 表字段
+var groupColumns = map[string]groupColumn{
+	GroupNone:     {Key: "''", Label: "''"},
+	GroupApp:      {Key: "COALESCE(app_id, '')", Label: "COALESCE(app_id, '')"},
+	GroupReseller: {Key: "user_id", Label: "COALESCE(user_name, '')"},
+	GroupTimeType: {Key: "time_type", Label: "time_type"},
+	GroupBatch:    {Key: "COALESCE(batch_id, '')", Label: "COALESCE(remark, '')"},
+}
@@ -0,0 +1,29 @@
+package analytics
+
+// SeriesPoint 时间序列中一个时间段、一个分组的激活数量
+type SeriesPoint struct {
+	Bucket     string `json:"bucket"`      // 时间段，例如 2024-01-02
+	GroupKey   string `json:"group_key"`   // 分组的值，不分组时为空
+	GroupLabel string `json:"group_label"` // 分组的名称
+	Count      int    `json:"count"`       // 激活数量
+}
+
+// ConversionRow 生成到激活的转化情况
+type ConversionRow struct {
+	GroupKey             string  `json:"group_key"`
+	GroupLabel           string  `json:"group_label"`
+	Generated            int     `json:"generated"`              // 生成数量，不包括未使用就删除的激活码
+	Activated            int     `json:"activated"`              // 已激活数量
+	ConversionRate       float64 `json:"conversion_rate"`        // 激活数量 / 生成数量
+	AvgActivationSeconds float64 `json:"avg_activation_seconds"` // 从生成到激活的平均秒数
+}
+
+// ChurnRow 到期后未续费的情况，续费指同一个设备在同一个应用上激活了新的激活码
+type ChurnRow struct {
+	GroupKey   string  `json:"group_key"`
+	GroupLabel string  `json:"group_label"`
+	Expired    int     `json:"expired"`    // 到期数量
+	Renewed    int     `json:"renewed"`    // 续费数量
+	Churned    int     `json:"churned"`    // 流失数量
+	ChurnRate  float64 `json:"churn_rate"` // 流失数量 / 到期数量
+}
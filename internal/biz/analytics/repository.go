@@ -0,0 +1,19 @@
+package analytics
+
+import "configuration-management/internal/biz/common"
+
+// Scope 调用者能查看的激活码范围
+type Scope struct {
+	UserId      string   // 为空时不限制
+	SubtreePath string   // 不为空时包含所有下级用户的激活码
+	AppIDs      []string // 为空时不限制
+}
+
+type Repository interface {
+	// ActivationSeries 按激活时间分段统计激活数量
+	ActivationSeries(scope Scope, bucket string, group string, usedAt common.TimeRange) ([]SeriesPoint, error)
+	// Conversion 统计生成时间在范围内的激活码的转化情况
+	Conversion(scope Scope, group string, createdAt common.TimeRange) ([]ConversionRow, error)
+	// Churn 统计到期时间在范围内的激活码的续费情况
+	Churn(scope Scope, group string, expiredAt common.TimeRange) ([]ChurnRow, error)
+}
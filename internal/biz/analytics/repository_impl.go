@@ -0,0 +1,75 @@
+package analytics
+
+import (
+	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/common"
+
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// scoped 限定为调用者能查看的激活码，所有统计都只查询 card 表
+func (r *repository) scoped(scope Scope) *gorm.DB {
+	db := r.db.Table("card c")
+	if scope.UserId != "" {
+		db = card.ScopeByOwner(r.db, db, scope.UserId, scope.SubtreePath)
+	}
+	if len(scope.AppIDs) > 0 {
+		db = db.Where("app_id IN (?)", scope.AppIDs)
+	}
+	return db
+}
+
+func (r *repository) ActivationSeries(scope Scope, bucket string, group string, usedAt common.TimeRange) ([]SeriesPoint, error) {
+	column := groupColumns[group]
+	points := make([]SeriesPoint, 0)
+	err := r.scoped(scope).
+		Select("DATE_FORMAT(used_at, ?) AS bucket, "+column.Key+" AS group_key, MAX("+column.Label+") AS group_label, COUNT(*) AS count",
+			bucketFormats[bucket]).
+		Where("used_at IS NOT NULL AND used_at >= ? AND used_at < ?", usedAt.StartTime, usedAt.EndTime).
+		Group("bucket, group_key").
+		Order("bucket, group_key").
+		Scan(&points).Error
+	return points, err
+}
+
+func (r *repository) Conversion(scope Scope, group string, createdAt common.TimeRange) ([]ConversionRow, error) {
+	column := groupColumns[group]
+	rows := make([]ConversionRow, 0)
+	err := r.scoped(scope).
+		Select(column.Key+" AS group_key, MAX("+column.Label+") AS group_label, "+
+			"COUNT(*) AS generated, "+
+			"SUM(CASE WHEN used_at IS NOT NULL THEN 1 ELSE 0 END) AS activated, "+
+			"COALESCE(AVG(CASE WHEN used_at IS NOT NULL THEN TIMESTAMPDIFF(SECOND, created_at, used_at) END), 0) AS avg_activation_seconds").
+		Where("created_at >= ? AND created_at < ?", createdAt.StartTime, createdAt.EndTime).
+		// 未使用就删除的激活码已经退还额度，不算作生成
+		Where("NOT (status = ? AND used_at IS NULL)", card.StatusDeleted).
+		Group("group_key").
+		Order("group_key").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *repository) Churn(scope Scope, group string, expiredAt common.TimeRange) ([]ChurnRow, error) {
+	column := groupColumns[group]
+	rows := make([]ChurnRow, 0)
+	err := r.scoped(scope).
+		Select(column.Key+" AS group_key, MAX("+column.Label+") AS group_label, "+
+			"COUNT(*) AS expired, "+
+			"SUM(CASE WHEN EXISTS ("+
+			"SELECT 1 FROM card n WHERE n.seid = c.seid AND n.app_id = c.app_id AND n.id <> c.id AND n.used_at > c.used_at"+
+			") THEN 1 ELSE 0 END) AS renewed").
+		Where("seid IS NOT NULL AND seid <> '' AND used_at IS NOT NULL").
+		Where("expired_at >= ? AND expired_at < ?", expiredAt.StartTime, expiredAt.EndTime).
+		Group("group_key").
+		Order("group_key").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,28 @@
+package analytics
+
+import "configuration-management/internal/biz/common"
+
+type ActivationSeriesArgs struct {
+	Scope  Scope            `json:"-"`
+	Bucket string           `json:"bucket"`   // hour, day, month
+	Group  string           `json:"group_by"` // 为空时不分组
+	Range  common.TimeRange `json:"range"`    // 激活时间范围，默认最近 30 天
+}
+
+type ConversionArgs struct {
+	Scope Scope            `json:"-"`
+	Group string           `json:"group_by"`
+	Range common.TimeRange `json:"range"` // 生成时间范围，默认最近 30 天
+}
+
+type ChurnArgs struct {
+	Scope Scope            `json:"-"`
+	Group string           `json:"group_by"`
+	Range common.TimeRange `json:"range"` // 到期时间范围，默认最近 30 天，结束时间不会晚于当前时间
+}
+
+type Service interface {
+	ActivationSeries(args ActivationSeriesArgs) ([]SeriesPoint, error)
+	Conversion(args ConversionArgs) ([]ConversionRow, error)
+	Churn(args ChurnArgs) ([]ChurnRow, error)
+}
@@ -0,0 +1,145 @@
+package analytics
+
+import (
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/common"
+	"configuration-management/pkg/errcode"
+)
+
+type service struct {
+	repo    Repository
+	appRepo apps.Repository
+}
+
+func NewService() Service {
+	return &service{
+		repo:    NewRepository(global.DBEngine),
+		appRepo: apps.NewRepository(global.DBEngine),
+	}
+}
+
+func (s *service) ActivationSeries(args ActivationSeriesArgs) ([]SeriesPoint, error) {
+	if _, ok := bucketFormats[args.Bucket]; !ok {
+		return nil, errcode.InvalidParams.WithDetails("不支持的 bucket: " + args.Bucket)
+	}
+	if err := checkGroup(args.Group); err != nil {
+		return nil, err
+	}
+	timeRange := normalizeRange(args.Range, time.Now())
+	if args.Bucket == BucketHour && timeRange.EndTime.Sub(timeRange.StartTime) > maxHourRange {
+		return nil, errcode.InvalidParams.WithDetails("按小时统计时时间范围不能超过 31 天")
+	}
+
+	points, err := s.repo.ActivationSeries(args.Scope, args.Bucket, args.Group, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	if args.Group == GroupApp {
+		names, err := s.appNames()
+		if err != nil {
+			return nil, err
+		}
+		for i := range points {
+			points[i].GroupLabel = names[points[i].GroupKey]
+		}
+	}
+	return points, nil
+}
+
+func (s *service) Conversion(args ConversionArgs) ([]ConversionRow, error) {
+	if err := checkGroup(args.Group); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.Conversion(args.Scope, args.Group, normalizeRange(args.Range, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.groupNames(args.Group)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if names != nil {
+			rows[i].GroupLabel = names[rows[i].GroupKey]
+		}
+		rows[i].ConversionRate = rate(rows[i].Activated, rows[i].Generated)
+	}
+	return rows, nil
+}
+
+func (s *service) Churn(args ChurnArgs) ([]ChurnRow, error) {
+	if err := checkGroup(args.Group); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	timeRange := normalizeRange(args.Range, now)
+	// 还没有到期的激活码不统计
+	if timeRange.EndTime.After(now) {
+		timeRange.EndTime = now
+	}
+	rows, err := s.repo.Churn(args.Scope, args.Group, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.groupNames(args.Group)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if names != nil {
+			rows[i].GroupLabel = names[rows[i].GroupKey]
+		}
+		rows[i].Churned = rows[i].Expired - rows[i].Renewed
+		rows[i].ChurnRate = rate(rows[i].Churned, rows[i].Expired)
+	}
+	return rows, nil
+}
+
+// groupNames 按应用分组时返回应用ID到名称的映射，其他分组返回 nil
+func (s *service) groupNames(group string) (map[string]string, error) {
+	if group != GroupApp {
+		return nil, nil
+	}
+	return s.appNames()
+}
+
+func (s *service) appNames() (map[string]string, error) {
+	options, err := s.appRepo.QueryAppOptions()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(options))
+	for _, option := range options {
+		names[option.ID] = option.Name
+	}
+	return names, nil
+}
+
+func checkGroup(group string) error {
+	if _, ok := groupColumns[group]; !ok {
+		return errcode.InvalidParams.WithDetails("不支持的 group_by: " + group)
+	}
+	return nil
+}
+
+// normalizeRange 补全时间范围，没有开始时间时从结束时间往前 30 天，没有结束时间时到当前时间
+func normalizeRange(timeRange common.TimeRange, now time.Time) common.TimeRange {
+	if timeRange.EndTime.IsZero() {
+		timeRange.EndTime = now
+	}
+	if timeRange.StartTime.IsZero() {
+		timeRange.StartTime = timeRange.EndTime.Add(-defaultRange)
+	}
+	return timeRange
+}
+
+// rate 保留四位小数的比例，分母为 0 时返回 0
+func rate(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator*10000/denominator) / 10000
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"configuration-management/internal/biz/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRepository struct {
+	churn     []ChurnRow
+	churnArgs common.TimeRange
+}
+
+func (f *fakeRepository) ActivationSeries(Scope, string, string, common.TimeRange) ([]SeriesPoint, error) {
+	return []SeriesPoint{}, nil
+}
+
+func (f *fakeRepository) Conversion(Scope, string, common.TimeRange) ([]ConversionRow, error) {
+	return []ConversionRow{{Generated: 3, Activated: 1}}, nil
+}
+
+func (f *fakeRepository) Churn(_ Scope, _ string, expiredAt common.TimeRange) ([]ChurnRow, error) {
+	f.churnArgs = expiredAt
+	return f.churn, nil
+}
+
+func TestNormalizeRange(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	r := normalizeRange(common.TimeRange{}, now)
+	assert.Equal(t, now, r.EndTime)
+	assert.Equal(t, now.Add(-defaultRange), r.StartTime)
+
+	start := now.Add(-time.Hour)
+	r = normalizeRange(common.TimeRange{StartTime: start}, now)
+	assert.Equal(t, start, r.StartTime)
+	assert.Equal(t, now, r.EndTime)
+}
+
+func TestActivationSeriesValidation(t *testing.T) {
+	s := &service{repo: &fakeRepository{}}
+
+	_, err := s.ActivationSeries(ActivationSeriesArgs{Bucket: "week"})
+	assert.Error(t, err)
+
+	_, err = s.ActivationSeries(ActivationSeriesArgs{Bucket: BucketDay, Group: "country"})
+	assert.Error(t, err)
+
+	end := time.Now()
+	_, err = s.ActivationSeries(ActivationSeriesArgs{
+		Bucket: BucketHour,
+		Range:  common.TimeRange{StartTime: end.Add(-60 * 24 * time.Hour), EndTime: end},
+	})
+	assert.Error(t, err)
+
+	_, err = s.ActivationSeries(ActivationSeriesArgs{Bucket: BucketDay, Group: GroupTimeType})
+	assert.NoError(t, err)
+}
+
+func TestConversionAndChurnRates(t *testing.T) {
+	repo := &fakeRepository{churn: []ChurnRow{{Expired: 4, Renewed: 1}, {Expired: 0}}}
+	s := &service{repo: repo}
+
+	conversion, err := s.Conversion(ConversionArgs{Group: GroupBatch})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.3333, conversion[0].ConversionRate)
+
+	future := time.Now().Add(24 * time.Hour)
+	churn, err := s.Churn(ChurnArgs{Range: common.TimeRange{EndTime: future}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, churn[0].Churned)
+	assert.Equal(t, 0.75, churn[0].ChurnRate)
+	assert.Equal(t, float64(0), churn[1].ChurnRate)
+	// 结束时间不会晚于当前时间
+	assert.True(t, repo.churnArgs.EndTime.Before(future))
+}
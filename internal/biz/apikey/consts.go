@@ -0,0 +1,13 @@
+package apikey
+
+import "time"
+
+const (
+	// KeyPrefix 所有 API Key 的前缀，便于在日志和代码中识别
+	KeyPrefix = "ak_"
+	// LookupLength 明文保存的查找前缀长度
+	LookupLength = 8
+
+	// lastUsedInterval 最近使用时间的更新间隔，避免每个请求都写数据库
+	lastUsedInterval = time.Minute
+)
@@ -0,0 +1,101 @@
+package apikey
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DBStruct struct {
+	ID          string          `json:"id"`                           // 唯一标识符(UUID)
+	UserID      string          `json:"user_id"`                      // 所属用户ID，可以是普通用户或服务账号
+	Name        string          `json:"name"`                         // 名称
+	Prefix      string          `json:"prefix"`                       // 明文保存的查找前缀
+	KeyHash     string          `json:"key_hash"`                     // 完整 Key 的 SHA-256
+	Permissions json.RawMessage `json:"permissions" gorm:"type:json"` // Key 拥有的权限，是所属用户权限的子集
+	ExpiresAt   *time.Time      `json:"expires_at"`                   // 过期时间，为空表示永不过期
+	LastUsedAt  *time.Time      `json:"last_used_at"`                 // 最近使用时间
+	RevokedAt   *time.Time      `json:"revoked_at"`                   // 吊销时间
+	CreatorID   string          `json:"creator_id"`                   // 创建者ID
+	CreatedAt   time.Time       `json:"created_at"`                   // 创建时间
+}
+
+func (s *DBStruct) TableName() string {
+	return "api_key"
+}
+
+func (s *DBStruct) ToModel() (APIKey, error) {
+	key := APIKey{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		Name:       s.Name,
+		Prefix:     s.Prefix,
+		KeyHash:    s.KeyHash,
+		ExpiresAt:  s.ExpiresAt,
+		LastUsedAt: s.LastUsedAt,
+		RevokedAt:  s.RevokedAt,
+		CreatorID:  s.CreatorID,
+		CreatedAt:  s.CreatedAt,
+	}
+	if len(s.Permissions) > 0 {
+		if err := json.Unmarshal(s.Permissions, &key.Permissions); err != nil {
+			return APIKey{}, err
+		}
+	}
+	return key, nil
+}
+
+func BatchToModel(dbStructs []DBStruct) ([]APIKey, error) {
+	keys := make([]APIKey, 0, len(dbStructs))
+	for _, dbStruct := range dbStructs {
+		key, err := dbStruct.ToModel()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// APIKey 用于机器访问私有接口的长期凭证，只保存哈希
+type APIKey struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatorID   string     `json:"creator_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) ToDBStruct() DBStruct {
+	permissions := k.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	permissionsJson, _ := json.Marshal(permissions)
+	return DBStruct{
+		ID:          k.ID,
+		UserID:      k.UserID,
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		KeyHash:     k.KeyHash,
+		Permissions: permissionsJson,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		RevokedAt:   k.RevokedAt,
+		CreatorID:   k.CreatorID,
+		CreatedAt:   k.CreatedAt,
+	}
+}
+
+// IsActive 未吊销且未过期
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
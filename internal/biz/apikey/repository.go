@@ -0,0 +1,12 @@
+package apikey
+
+import "time"
+
+type Repository interface {
+	GetAPIKeyByID(id string) (APIKey, error)
+	GetAPIKeyByPrefix(prefix string) (APIKey, error)
+	QueryAPIKeys(args QueryAPIKeysArgs) (QueryAPIKeysResult, error)
+	CreateAPIKey(key APIKey) error
+	RevokeAPIKey(id string, revokedAt time.Time) error
+	UpdateLastUsedAt(id string, lastUsedAt time.Time) error
+}
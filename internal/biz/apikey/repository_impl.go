@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+/*
+表结构如下：
+CREATE TABLE api_key (
+    id           VARCHAR(32)  NOT NULL PRIMARY KEY, -- 唯一标识符
+    user_id      VARCHAR(32)  NOT NULL,             -- 所属用户ID
+    name         VARCHAR(64)  NOT NULL,             -- 名称
+    prefix       VARCHAR(16)  NOT NULL UNIQUE,      -- 明文保存的查找前缀
+    key_hash     CHAR(64)     NOT NULL,             -- 完整 Key 的 SHA-256
+    permissions  JSON         NULL,                 -- Key 拥有的权限
+    expires_at   TIMESTAMP    NULL,                 -- 过期时间
+    last_used_at TIMESTAMP    NULL,                 -- 最近使用时间
+    revoked_at   TIMESTAMP    NULL,                 -- 吊销时间
+    creator_id   VARCHAR(32)  NOT NULL,             -- 创建者ID
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAPIKeyByID(id string) (APIKey, error) {
+	var dbStruct DBStruct
+	if err := r.db.Table(dbStruct.TableName()).Where("id = ?", id).First(&dbStruct).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return APIKey{}, errcode.NotFound
+		}
+		return APIKey{}, err
+	}
+	return dbStruct.ToModel()
+}
+
+func (r *repository) GetAPIKeyByPrefix(prefix string) (APIKey, error) {
+	var dbStruct DBStruct
+	if err := r.db.Table(dbStruct.TableName()).Where("prefix = ?", prefix).First(&dbStruct).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return APIKey{}, errcode.NotFound
+		}
+		return APIKey{}, err
+	}
+	return dbStruct.ToModel()
+}
+
+func (r *repository) QueryAPIKeys(args QueryAPIKeysArgs) (QueryAPIKeysResult, error) {
+	db := r.db.Table((&DBStruct{}).TableName()).Where("user_id = ?", args.UserID)
+	if !args.IncludeRevoked {
+		db = db.Where("revoked_at IS NULL")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryAPIKeysResult{}, err
+	}
+
+	var dbStructs = make([]DBStruct, 0)
+	if err := db.Order("created_at desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).
+		Find(&dbStructs).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Error("查询 API Key 列表失败", err)
+		return QueryAPIKeysResult{}, err
+	}
+	keys, err := BatchToModel(dbStructs)
+	if err != nil {
+		return QueryAPIKeysResult{}, err
+	}
+	return QueryAPIKeysResult{
+		Total: int(total),
+		List:  keys,
+	}, nil
+}
+
+func (r *repository) CreateAPIKey(key APIKey) error {
+	dbStruct := key.ToDBStruct()
+	if err := r.db.Table(dbStruct.TableName()).Create(&dbStruct).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":      key.ID,
+			"user_id": key.UserID,
+		}).Error("创建 API Key 失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) RevokeAPIKey(id string, revokedAt time.Time) error {
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("吊销 API Key 失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) UpdateLastUsedAt(id string, lastUsedAt time.Time) error {
+	return r.db.Table((&DBStruct{}).TableName()).Where("id = ?", id).
+		Update("last_used_at", lastUsedAt).Error
+}
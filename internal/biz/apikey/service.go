@@ -0,0 +1,45 @@
+package apikey
+
+import (
+	"time"
+
+	"configuration-management/internal/biz/user"
+)
+
+type QueryAPIKeysArgs struct {
+	ViewerID       string `json:"viewer_id"`
+	UserID         string `json:"user_id"` // 为空时查询自己的 Key
+	ViaAPIKey      bool   `json:"via_api_key"`
+	IncludeRevoked bool   `json:"include_revoked"`
+	Page           int    `json:"page"`
+	Limit          int    `json:"limit"`
+}
+
+type QueryAPIKeysResult struct {
+	List  []APIKey
+	Total int
+}
+
+type CreateAPIKeyArgs struct {
+	CreatorID   string     `json:"creator_id"`
+	UserID      string     `json:"user_id"` // 为空时为自己创建
+	Name        string     `json:"name"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	ViaAPIKey   bool       `json:"via_api_key"` // 调用方是否通过 API Key 鉴权
+}
+
+type RevokeAPIKeyArgs struct {
+	OperatorID string `json:"operator_id"`
+	ID         string `json:"id"`
+	ViaAPIKey  bool   `json:"via_api_key"`
+}
+
+type Service interface {
+	QueryAPIKeys(args QueryAPIKeysArgs) (QueryAPIKeysResult, error)
+	// CreateAPIKey 返回创建的 Key 和完整的明文 Key，明文只在创建时返回一次
+	CreateAPIKey(args CreateAPIKeyArgs) (APIKey, string, error)
+	RevokeAPIKey(args RevokeAPIKeyArgs) error
+	// Authenticate 校验明文 Key，返回 Key 和所属用户
+	Authenticate(plaintext string) (APIKey, user.User, error)
+}
@@ -0,0 +1,211 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/permissions"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/utils"
+)
+
+var errManageViaAPIKey = errcode.NoPermission.WithDetails("不能使用 API Key 管理 API Key，请登录后操作")
+
+type service struct {
+	repo        Repository
+	userService user.Service
+}
+
+func NewService() Service {
+	return &service{
+		repo:        NewRepository(global.DBEngine),
+		userService: user.NewService(),
+	}
+}
+
+func (s *service) QueryAPIKeys(args QueryAPIKeysArgs) (QueryAPIKeysResult, error) {
+	if args.ViaAPIKey {
+		return QueryAPIKeysResult{}, errManageViaAPIKey
+	}
+	if args.UserID == "" {
+		args.UserID = args.ViewerID
+	}
+	if _, err := s.getOwner(args.ViewerID, args.UserID); err != nil {
+		return QueryAPIKeysResult{}, err
+	}
+
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	}
+	return s.repo.QueryAPIKeys(args)
+}
+
+func (s *service) CreateAPIKey(args CreateAPIKeyArgs) (APIKey, string, error) {
+	// 不允许用 Key 创建 Key，否则权限范围小的 Key 可以创建拥有用户全部权限的 Key
+	if args.ViaAPIKey {
+		return APIKey{}, "", errManageViaAPIKey
+	}
+	if args.UserID == "" {
+		args.UserID = args.CreatorID
+	}
+	if args.ExpiresAt != nil && args.ExpiresAt.Before(time.Now()) {
+		return APIKey{}, "", errcode.InvalidParams.WithDetails("过期时间不能早于当前时间")
+	}
+	owner, err := s.getOwner(args.CreatorID, args.UserID)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	// Key 的权限只能是所属用户权限的子集
+	ownerPermissions, err := s.userService.GetUserPermissions(owner.ID)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	for _, p := range args.Permissions {
+		if !permissions.Contains(ownerPermissions, p) {
+			return APIKey{}, "", errcode.NoPermission.WithDetails("用户没有该权限: " + p)
+		}
+	}
+
+	prefix, err := randomHex(LookupLength / 2)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	plaintext := KeyPrefix + prefix + "_" + secret
+
+	key := APIKey{
+		ID:          utils.GenerateUUID(),
+		UserID:      owner.ID,
+		Name:        args.Name,
+		Prefix:      prefix,
+		KeyHash:     hashKey(plaintext),
+		Permissions: permissions.Merge(args.Permissions),
+		ExpiresAt:   args.ExpiresAt,
+		CreatorID:   args.CreatorID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.CreateAPIKey(key); err != nil {
+		return APIKey{}, "", err
+	}
+	return key, plaintext, nil
+}
+
+func (s *service) RevokeAPIKey(args RevokeAPIKeyArgs) error {
+	if args.ViaAPIKey {
+		return errManageViaAPIKey
+	}
+	key, err := s.repo.GetAPIKeyByID(args.ID)
+	if err != nil {
+		if errors.Is(err, errcode.NotFound) {
+			return errcode.NotFound.WithDetails("API Key 不存在")
+		}
+		return err
+	}
+	if _, err := s.getOwner(args.OperatorID, key.UserID); err != nil {
+		return err
+	}
+	return s.repo.RevokeAPIKey(key.ID, time.Now())
+}
+
+func (s *service) Authenticate(plaintext string) (APIKey, user.User, error) {
+	// 格式: ak_<prefix>_<secret>
+	parts := strings.SplitN(strings.TrimPrefix(plaintext, KeyPrefix), "_", 2)
+	if !strings.HasPrefix(plaintext, KeyPrefix) || len(parts) != 2 || len(parts[0]) != LookupLength {
+		return APIKey{}, user.User{}, errcode.UnauthorizedTokenError
+	}
+
+	key, err := s.repo.GetAPIKeyByPrefix(parts[0])
+	if err != nil {
+		if errors.Is(err, errcode.NotFound) {
+			return APIKey{}, user.User{}, errcode.UnauthorizedTokenError
+		}
+		return APIKey{}, user.User{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashKey(plaintext))) != 1 {
+		return APIKey{}, user.User{}, errcode.UnauthorizedTokenError
+	}
+	now := time.Now()
+	if !key.IsActive(now) {
+		return APIKey{}, user.User{}, errcode.UnauthorizedTokenTimeout
+	}
+
+	owner, err := s.userService.GetUserByID(key.UserID)
+	if err != nil {
+		return APIKey{}, user.User{}, err
+	}
+	if owner.Status != user.StatusNormal {
+		return APIKey{}, user.User{}, errcode.UnauthorizedTokenError.WithDetails("用户已经被停封")
+	}
+
+	// 最近使用时间不需要很精确，失败也不影响本次请求
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedInterval {
+		if err := s.repo.UpdateLastUsedAt(key.ID, now); err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"id": key.ID,
+			}).Error("更新 API Key 最近使用时间失败", err)
+		}
+	}
+	return key, owner, nil
+}
+
+// getOwner 检查 operator 是否可以管理 userID 的 Key：自己、root 或者上级
+func (s *service) getOwner(operatorID string, userID string) (user.User, error) {
+	owner, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return user.User{}, errcode.NotFound.WithDetails("用户不存在")
+	}
+	if operatorID == userID {
+		return owner, nil
+	}
+	operator, err := s.userService.GetUserByID(operatorID)
+	if err != nil {
+		return user.User{}, err
+	}
+	if !operator.IsRoot() && !operator.IsAncestorOf(owner) {
+		global.Logger.WithFields(logger.Fields{
+			"operator_id": operatorID,
+			"user_id":     userID,
+		}).Info("[getOwner] 没有权限")
+		return user.User{}, errcode.NoPermission
+	}
+	return owner, nil
+}
+
+// KeyRoles 通过 API Key 访问时使用的角色，去掉 root，root 用户的 Key 也只能使用 Key 自己的权限
+func KeyRoles(owner user.User) []string {
+	roles := make([]string, 0, len(owner.Roles))
+	for _, role := range owner.Roles {
+		if role != user.RoleRoot {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func hashKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
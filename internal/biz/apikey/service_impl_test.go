@@ -0,0 +1,177 @@
+package apikey
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/permissions"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+)
+
+type fakeRepository struct {
+	keys []APIKey
+}
+
+func (f *fakeRepository) GetAPIKeyByID(id string) (APIKey, error) {
+	for _, k := range f.keys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return APIKey{}, errcode.NotFound
+}
+
+func (f *fakeRepository) GetAPIKeyByPrefix(prefix string) (APIKey, error) {
+	for _, k := range f.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return APIKey{}, errcode.NotFound
+}
+
+func (f *fakeRepository) QueryAPIKeys(args QueryAPIKeysArgs) (QueryAPIKeysResult, error) {
+	return QueryAPIKeysResult{List: f.keys, Total: len(f.keys)}, nil
+}
+
+func (f *fakeRepository) CreateAPIKey(key APIKey) error {
+	f.keys = append(f.keys, key)
+	return nil
+}
+
+func (f *fakeRepository) RevokeAPIKey(id string, revokedAt time.Time) error {
+	return nil
+}
+
+func (f *fakeRepository) UpdateLastUsedAt(id string, lastUsedAt time.Time) error {
+	return nil
+}
+
+// fakeUserService 只实现 API Key 用到的方法
+type fakeUserService struct {
+	user.Service
+	users       map[string]user.User
+	permissions map[string]user.Permissions
+}
+
+func (f *fakeUserService) GetUserByID(id string) (user.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return user.User{}, errcode.NotFound
+	}
+	return u, nil
+}
+
+func (f *fakeUserService) GetUserPermissions(id string) (user.Permissions, error) {
+	return f.permissions[id], nil
+}
+
+func newTestService() (*service, *fakeRepository) {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+	repo := &fakeRepository{}
+	return &service{
+		repo: repo,
+		userService: &fakeUserService{
+			users: map[string]user.User{
+				"root":   {ID: "root", Status: user.StatusNormal, Roles: user.Roles{user.RoleRoot, "admin"}},
+				"seller": {ID: "seller", Status: user.StatusNormal, Ancestry: "root", Roles: user.Roles{"admin"}},
+			},
+			permissions: map[string]user.Permissions{
+				"root":   permissions.RootPermissions,
+				"seller": {permissions.QUERY, permissions.CREATE, permissions.API_KEY_MANAGE},
+			},
+		},
+	}, repo
+}
+
+func assertNoPermission(t *testing.T, err error) {
+	t.Helper()
+	var e *errcode.Error
+	if !errors.As(err, &e) || e.Code() != errcode.NoPermission.Code() {
+		t.Fatalf("expected no permission error, got %v", err)
+	}
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	s, repo := newTestService()
+
+	key, plaintext, err := s.CreateAPIKey(CreateAPIKeyArgs{
+		CreatorID:   "seller",
+		Name:        "ci",
+		Permissions: []string{permissions.QUERY, permissions.QUERY},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key.Permissions) != 1 || key.UserID != "seller" || len(repo.keys) != 1 {
+		t.Fatalf("unexpected key: %+v", key)
+	}
+	if _, owner, err := s.Authenticate(plaintext); err != nil || owner.ID != "seller" {
+		t.Fatalf("authenticate failed: %v", err)
+	}
+
+	// 不能超出所属用户的权限
+	_, _, err = s.CreateAPIKey(CreateAPIKeyArgs{
+		CreatorID:   "seller",
+		Name:        "escalate",
+		Permissions: []string{permissions.DELETE},
+	})
+	assertNoPermission(t, err)
+}
+
+func TestManageAPIKeyViaAPIKey(t *testing.T) {
+	s, repo := newTestService()
+	repo.keys = append(repo.keys, APIKey{ID: "k1", UserID: "seller"})
+
+	// 权限范围小的 Key 不能用来创建拥有用户全部权限的 Key
+	_, _, err := s.CreateAPIKey(CreateAPIKeyArgs{
+		CreatorID:   "seller",
+		Name:        "escalate",
+		Permissions: []string{permissions.QUERY, permissions.CREATE},
+		ViaAPIKey:   true,
+	})
+	assertNoPermission(t, err)
+	if len(repo.keys) != 1 {
+		t.Fatalf("key should not be created")
+	}
+
+	assertNoPermission(t, s.RevokeAPIKey(RevokeAPIKeyArgs{OperatorID: "seller", ID: "k1", ViaAPIKey: true}))
+	_, err = s.QueryAPIKeys(QueryAPIKeysArgs{ViewerID: "seller", ViaAPIKey: true})
+	assertNoPermission(t, err)
+
+	if err := s.RevokeAPIKey(RevokeAPIKeyArgs{OperatorID: "seller", ID: "k1"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManageOtherUsersKeys(t *testing.T) {
+	s, repo := newTestService()
+	repo.keys = append(repo.keys, APIKey{ID: "k1", UserID: "root"})
+
+	// 下级不能管理上级的 Key
+	assertNoPermission(t, s.RevokeAPIKey(RevokeAPIKeyArgs{OperatorID: "seller", ID: "k1"}))
+	_, _, err := s.CreateAPIKey(CreateAPIKeyArgs{CreatorID: "seller", UserID: "root", Name: "x"})
+	assertNoPermission(t, err)
+
+	// 上级可以为下级创建
+	if _, _, err := s.CreateAPIKey(CreateAPIKeyArgs{CreatorID: "root", UserID: "seller", Name: "x", Permissions: []string{permissions.QUERY}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyRoles(t *testing.T) {
+	roles := KeyRoles(user.User{Roles: user.Roles{"admin", user.RoleRoot, "operator"}})
+	if len(roles) != 2 || roles[0] != "admin" || roles[1] != "operator" {
+		t.Fatalf("unexpected roles: %v", roles)
+	}
+	for _, role := range roles {
+		if role == user.RoleRoot {
+			t.Fatalf("api key callers must not get the root role")
+		}
+	}
+}
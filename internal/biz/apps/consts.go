@@ -0,0 +1,18 @@
+package apps
+
+// 应用的状态，停用的应用的激活码不能激活和检查
+const (
+	StatusActive   = "active"
+	StatusDisabled = "disabled"
+)
+
+// 激活码的状态，和 card 包中的一致，card 依赖 apps 所以不能直接引用
+const (
+	cardStatusUnused = 1
+	cardStatusUsed   = 2
+)
+
+// IsStatus 检查是否为有效的应用状态
+func IsStatus(status string) bool {
+	return status == StatusActive || status == StatusDisabled
+}
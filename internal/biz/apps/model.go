@@ -1,19 +1,131 @@
 package apps
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-type App struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	CardLength int       `json:"card_length"`
-	CardPrefix string    `json:"card_prefix"`
-	CreatedAt  time.Time `json:"created_at"`
+type DBStruct struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	CardLength   int             `json:"card_length"`
+	CardPrefix   string          `json:"card_prefix"`
+	Status       string          `json:"status"`                      // 状态: active-启用, disabled-停用
+	TimeTypes    json.RawMessage `json:"time_types" gorm:"type:json"` // 生成激活码时可选的时间类型，为空表示不限制
+	MaxDuration  int             `json:"max_duration"`                // 单个激活码的最大有效时长（分钟），0 表示不限制
+	DevicePolicy string          `json:"device_policy"`               // 设备绑定策略
+	ContactURL   string          `json:"contact_url"`                 // 客服或支持页面的链接
+	ArchivedAt   *time.Time      `json:"archived_at"`                 // 归档时间，为空表示未归档
+	CreatedAt    time.Time       `json:"created_at"`
 }
 
-func (a *App) TableName() string {
+func (s *DBStruct) TableName() string {
 	return "app"
 }
 
+func (s *DBStruct) ToModel() (App, error) {
+	app := App{
+		ID:           s.ID,
+		Name:         s.Name,
+		CardLength:   s.CardLength,
+		CardPrefix:   s.CardPrefix,
+		Status:       s.Status,
+		MaxDuration:  s.MaxDuration,
+		DevicePolicy: s.DevicePolicy,
+		ContactURL:   s.ContactURL,
+		ArchivedAt:   s.ArchivedAt,
+		CreatedAt:    s.CreatedAt,
+	}
+	if app.Status == "" {
+		app.Status = StatusActive
+	}
+	if len(s.TimeTypes) > 0 {
+		if err := json.Unmarshal(s.TimeTypes, &app.TimeTypes); err != nil {
+			return App{}, err
+		}
+	}
+	return app, nil
+}
+
+func BatchToModel(dbStructs []DBStruct) ([]App, error) {
+	apps := make([]App, 0, len(dbStructs))
+	for _, dbStruct := range dbStructs {
+		app, err := dbStruct.ToModel()
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+type App struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	CardLength   int        `json:"card_length"`
+	CardPrefix   string     `json:"card_prefix"`
+	Status       string     `json:"status"`
+	TimeTypes    []string   `json:"time_types"`
+	MaxDuration  int        `json:"max_duration"`
+	DevicePolicy string     `json:"device_policy"`
+	ContactURL   string     `json:"contact_url"`
+	ArchivedAt   *time.Time `json:"archived_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (a *App) ToDBStruct() DBStruct {
+	timeTypes := a.TimeTypes
+	if timeTypes == nil {
+		timeTypes = []string{}
+	}
+	timeTypesJson, _ := json.Marshal(timeTypes)
+	status := a.Status
+	if status == "" {
+		status = StatusActive
+	}
+	return DBStruct{
+		ID:           a.ID,
+		Name:         a.Name,
+		CardLength:   a.CardLength,
+		CardPrefix:   a.CardPrefix,
+		Status:       status,
+		TimeTypes:    timeTypesJson,
+		MaxDuration:  a.MaxDuration,
+		DevicePolicy: a.DevicePolicy,
+		ContactURL:   a.ContactURL,
+		ArchivedAt:   a.ArchivedAt,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+// Disabled 应用是否已停用
+func (a *App) Disabled() bool {
+	return a.Status == StatusDisabled
+}
+
+// Archived 应用是否已归档
+func (a *App) Archived() bool {
+	return a.ArchivedAt != nil
+}
+
+// AllowTimeType 检查应用是否允许该时间类型
+func (a *App) AllowTimeType(timeType string) bool {
+	if len(a.TimeTypes) == 0 {
+		return true
+	}
+	for _, t := range a.TimeTypes {
+		if t == timeType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowDuration 检查有效时长（分钟）是否不超过应用的最大有效时长
+func (a *App) AllowDuration(minutes int) bool {
+	return a.MaxDuration <= 0 || minutes <= a.MaxDuration
+}
+
 func (a *App) ToOption() AppOption {
 	return AppOption{
 		ID:   a.ID,
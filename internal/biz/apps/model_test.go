@@ -0,0 +1,66 @@
+package apps
+
+import (
+	"errors"
+	"testing"
+
+	"configuration-management/pkg/errcode"
+)
+
+func TestAppDBStructRoundTrip(t *testing.T) {
+	app := App{ID: "a1", Name: "应用", TimeTypes: []string{"daily", "monthly"}, MaxDuration: 60 * 24 * 31}
+	dbStruct := app.ToDBStruct()
+	if dbStruct.Status != StatusActive {
+		t.Fatalf("status = %q, want %q", dbStruct.Status, StatusActive)
+	}
+	got, err := dbStruct.ToModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.TimeTypes) != 2 || got.TimeTypes[1] != "monthly" || got.Disabled() || got.Archived() {
+		t.Fatalf("got %+v", got)
+	}
+
+	// 旧数据没有状态和时间类型
+	got, err = (&DBStruct{ID: "a2"}).ToModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusActive || !got.AllowTimeType("yearly") || !got.AllowDuration(1<<30) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestAppAllow(t *testing.T) {
+	app := App{TimeTypes: []string{"daily"}, MaxDuration: 120}
+	if !app.AllowTimeType("daily") || app.AllowTimeType("yearly") || app.AllowTimeType("") {
+		t.Fatal("unexpected time type result")
+	}
+	if !app.AllowDuration(120) || app.AllowDuration(121) {
+		t.Fatal("unexpected duration result")
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	cases := []struct {
+		args UpdateAppSettingsArgs
+		ok   bool
+	}{
+		{UpdateAppSettingsArgs{Status: StatusActive}, true},
+		{UpdateAppSettingsArgs{Status: StatusDisabled, ContactURL: "https://example.com/support"}, true},
+		{UpdateAppSettingsArgs{Status: "paused"}, false},
+		{UpdateAppSettingsArgs{Status: StatusActive, MaxDuration: -1}, false},
+		{UpdateAppSettingsArgs{Status: StatusActive, ContactURL: "javascript:alert(1)"}, false},
+		{UpdateAppSettingsArgs{Status: StatusActive, ContactURL: "https://"}, false},
+	}
+	for i, c := range cases {
+		err := validateSettings(c.args)
+		if c.ok != (err == nil) {
+			t.Fatalf("case %d: err = %v", i, err)
+		}
+		var e *errcode.Error
+		if err != nil && (!errors.As(err, &e) || e.Code() != errcode.InvalidParams.Code()) {
+			t.Fatalf("case %d: err = %v, want InvalidParams", i, err)
+		}
+	}
+}
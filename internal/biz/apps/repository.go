@@ -1,5 +1,7 @@
 package apps
 
+import "time"
+
 type Repository interface {
 	QueryAppList(args QueryAppListArgs) (QueryAppListResult, error)
 	CreateApp(app App) error
@@ -7,4 +9,9 @@ type Repository interface {
 	DeleteApp(id string) error
 	QueryAppOptions() ([]AppOption, error)
 	GetAppByIDs(ids []string) ([]App, error)
+	// LockApp 使用 SELECT ... FOR UPDATE 锁定应用行，需要在事务中调用，应用不存在时返回 errcode.NotFound
+	// 删除应用和生成激活码都先锁定应用，删除前的检查和生成激活码不会交错
+	LockApp(id string) error
+	// CountLiveCards 统计应用下还能使用的激活码：未使用的，以及已激活且未过期的
+	CountLiveCards(id string, now time.Time) (int64, error)
 }
@@ -1,23 +1,44 @@
 package apps
 
 import (
-	"configuration-management/global"
+	"time"
+
+	"configuration-management/pkg/errcode"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+/*
+create table app
+(
+    id            varchar(32)                            not null
+        primary key,
+    name          varchar(255)                           not null,
+    card_length   int                                    not null,
+    card_prefix   varchar(255)                           not null,
+    status        varchar(16)  default 'active'          not null comment '状态: active-启用, disabled-停用',
+    time_types    json                                   null comment '生成激活码时可选的时间类型，为空表示不限制',
+    max_duration  int          default 0                 not null comment '单个激活码的最大有效时长（分钟），0 表示不限制',
+    device_policy varchar(64)  default ''                not null comment '设备绑定策略',
+    contact_url   varchar(512) default ''                not null comment '客服或支持页面的链接',
+    archived_at   timestamp                              null comment '归档时间',
+    created_at    timestamp    default CURRENT_TIMESTAMP not null
+);
+*/
+
 type repositoryImpl struct {
 	db *gorm.DB
 }
 
-func NewRepository() Repository {
+func NewRepository(db *gorm.DB) Repository {
 	return &repositoryImpl{
-		db: global.DBEngine,
+		db: db,
 	}
 }
 
 func (r *repositoryImpl) QueryAppList(args QueryAppListArgs) (QueryAppListResult, error) {
-	db := r.db.Table((&App{}).TableName())
+	db := r.db.Table((&DBStruct{}).TableName())
 	if args.ID != "" {
 		db.Where("id = ?", args.ID)
 	}
@@ -25,6 +46,16 @@ func (r *repositoryImpl) QueryAppList(args QueryAppListArgs) (QueryAppListResult
 		// 模糊搜索
 		db.Where("name like ?", "%"+args.Name+"%")
 	}
+	if args.Status != "" {
+		db.Where("status = ?", args.Status)
+	}
+	if args.Archived != nil {
+		if *args.Archived {
+			db.Where("archived_at is not null")
+		} else {
+			db.Where("archived_at is null")
+		}
+	}
 
 	// 获取数量
 	var total int64
@@ -41,8 +72,12 @@ func (r *repositoryImpl) QueryAppList(args QueryAppListArgs) (QueryAppListResult
 
 	// order by created_at desc
 	db.Order("created_at desc")
-	var apps []App
-	if err := db.Find(&apps).Error; err != nil {
+	var dbStructs []DBStruct
+	if err := db.Find(&dbStructs).Error; err != nil {
+		return QueryAppListResult{}, err
+	}
+	apps, err := BatchToModel(dbStructs)
+	if err != nil {
 		return QueryAppListResult{}, err
 	}
 	return QueryAppListResult{
@@ -51,14 +86,14 @@ func (r *repositoryImpl) QueryAppList(args QueryAppListArgs) (QueryAppListResult
 	}, nil
 }
 
-// QueryAppOptions 获取 app options, [{id:xxx, name:xxx}]
+// QueryAppOptions 获取 app options, [{id:xxx, name:xxx}]，不包括已归档的应用
 func (r *repositoryImpl) QueryAppOptions() ([]AppOption, error) {
-	var apps []App
-	if err := r.db.Table((&App{}).TableName()).Find(&apps).Error; err != nil {
+	var dbStructs []DBStruct
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("archived_at is null").Find(&dbStructs).Error; err != nil {
 		return nil, err
 	}
 	var appOptions []AppOption
-	for _, app := range apps {
+	for _, app := range dbStructs {
 		appOptions = append(appOptions, AppOption{
 			ID:   app.ID,
 			Name: app.Name,
@@ -68,30 +103,56 @@ func (r *repositoryImpl) QueryAppOptions() ([]AppOption, error) {
 }
 
 func (r *repositoryImpl) CreateApp(app App) error {
-	if err := r.db.Create(&app).Error; err != nil {
+	dbStruct := app.ToDBStruct()
+	if err := r.db.Create(&dbStruct).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (r *repositoryImpl) UpdateApp(app App) error {
-	if err := r.db.Save(&app).Error; err != nil {
+	dbStruct := app.ToDBStruct()
+	if err := r.db.Save(&dbStruct).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (r *repositoryImpl) DeleteApp(id string) error {
-	if err := r.db.Delete(&App{}, id).Error; err != nil {
+	if err := r.db.Where("id = ?", id).Delete(&DBStruct{}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (r *repositoryImpl) GetAppByIDs(ids []string) ([]App, error) {
-	var apps []App
-	if err := r.db.Table((&App{}).TableName()).Where("id in ?", ids).Find(&apps).Error; err != nil {
+	var dbStructs []DBStruct
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("id in ?", ids).Find(&dbStructs).Error; err != nil {
 		return nil, err
 	}
-	return apps, nil
+	return BatchToModel(dbStructs)
+}
+
+func (r *repositoryImpl) LockApp(id string) error {
+	var lockedID string
+	if err := r.db.Table((&DBStruct{}).TableName()).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", id).Scan(&lockedID).Error; err != nil {
+		return err
+	}
+	if lockedID == "" {
+		return errcode.NotFound.WithDetails("应用不存在")
+	}
+	return nil
+}
+
+func (r *repositoryImpl) CountLiveCards(id string, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Table("card").
+		Where("app_id = ?", id).
+		Where("(status = ? or (status = ? and (expired_at is null or expired_at > ?)))", cardStatusUnused, cardStatusUsed, now).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
 }
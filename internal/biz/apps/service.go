@@ -1,10 +1,14 @@
 package apps
 
+import "configuration-management/pkg/app"
+
 type QueryAppListArgs struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Page  int    `json:"page"`
-	Limit int    `json:"limit"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Archived *bool  `json:"archived"` // 为空时不按归档状态过滤
+	Page     int    `json:"page"`
+	Limit    int    `json:"limit"`
 }
 
 type QueryAppListResult struct {
@@ -13,16 +17,28 @@ type QueryAppListResult struct {
 }
 
 type CreateAppArgs struct {
-	Name       string `json:"name"`
-	CardLength int    `json:"card_length"`
-	CardPrefix string `json:"card_prefix"`
+	Name       string    `json:"name"`
+	CardLength int       `json:"card_length"`
+	CardPrefix string    `json:"card_prefix"`
+	Actor      app.Actor `json:"-"` // 操作人，写入审计日志
 }
 
 type UpdateAppArgs struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	CardLength int    `json:"card_length"`
-	CardPrefix string `json:"card_prefix"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	CardLength int       `json:"card_length"`
+	CardPrefix string    `json:"card_prefix"`
+	Actor      app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type UpdateAppSettingsArgs struct {
+	ID           string    `json:"id"`
+	Status       string    `json:"status"`
+	TimeTypes    []string  `json:"time_types"`
+	MaxDuration  int       `json:"max_duration"`
+	DevicePolicy string    `json:"device_policy"`
+	ContactURL   string    `json:"contact_url"`
+	Actor        app.Actor `json:"-"` // 操作人，写入审计日志
 }
 
 type AppOption struct {
@@ -34,7 +50,13 @@ type Service interface {
 	QueryAppList(args QueryAppListArgs) (QueryAppListResult, error)
 	CreateApp(args CreateAppArgs) error
 	UpdateApp(args UpdateAppArgs) error
-	DeleteApp(id string) error
+	UpdateAppSettings(args UpdateAppSettingsArgs) error
+	// ArchiveApp 归档或取消归档，归档的应用不能再生成激活码，已有的激活码不受影响
+	ArchiveApp(id string, archived bool, actor app.Actor) error
+	// DeleteApp 删除应用，应用下还有未使用或未过期的激活码时不能删除
+	DeleteApp(id string, actor app.Actor) error
+	// CheckAppEnabled 应用停用时返回 errcode.AppDisabled，id 为空或应用不存在时不限制
+	CheckAppEnabled(id string) error
 	QueryAppOptions() ([]AppOption, error)
 	GetAppByIDs(ids []string) ([]App, error)
 }
@@ -1,21 +1,29 @@
 package apps
 
 import (
+	"fmt"
+	"net/url"
 	"time"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
 	"configuration-management/utils"
+
+	"gorm.io/gorm"
 )
 
 type serviceImpl struct {
+	db   *gorm.DB
 	repo Repository
 }
 
 func NewService() Service {
 	return &serviceImpl{
-		repo: NewRepository(),
+		db:   global.DBEngine,
+		repo: NewRepository(global.DBEngine),
 	}
 }
 
@@ -44,12 +52,19 @@ func (s *serviceImpl) CreateApp(args CreateAppArgs) error {
 		return errcode.DuplicateKey
 	}
 
-	return s.repo.CreateApp(App{
+	newApp := App{
 		ID:         utils.GenerateUUID(),
 		Name:       args.Name,
 		CardLength: args.CardLength,
 		CardPrefix: args.CardPrefix,
+		Status:     StatusActive,
 		CreatedAt:  time.Now(),
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).CreateApp(newApp); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionAppCreate, audit.TargetApp, newApp.ID, nil, newApp)
 	})
 }
 
@@ -87,15 +102,134 @@ func (s *serviceImpl) UpdateApp(args UpdateAppArgs) error {
 		return errcode.NotFound
 	}
 
-	app := result.List[0]
-	app.Name = args.Name
-	app.CardPrefix = args.CardPrefix
-	app.CardLength = args.CardLength
-	return s.repo.UpdateApp(app)
+	before := result.List[0]
+	after := before
+	after.Name = args.Name
+	after.CardPrefix = args.CardPrefix
+	after.CardLength = args.CardLength
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).UpdateApp(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionAppUpdate, audit.TargetApp, after.ID, before, after)
+	})
 }
 
-func (s *serviceImpl) DeleteApp(id string) error {
-	return s.repo.DeleteApp(id)
+func (s *serviceImpl) UpdateAppSettings(args UpdateAppSettingsArgs) error {
+	if err := validateSettings(args); err != nil {
+		return err
+	}
+	before, err := s.getApp(args.ID)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Status = args.Status
+	after.TimeTypes = args.TimeTypes
+	after.MaxDuration = args.MaxDuration
+	after.DevicePolicy = args.DevicePolicy
+	after.ContactURL = args.ContactURL
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).UpdateApp(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionAppSettings, audit.TargetApp, after.ID, before, after)
+	})
+}
+
+func (s *serviceImpl) ArchiveApp(id string, archived bool, actor app.Actor) error {
+	before, err := s.getApp(id)
+	if err != nil {
+		return err
+	}
+	if before.Archived() == archived {
+		return nil
+	}
+
+	after := before
+	action := audit.ActionAppUnarchive
+	after.ArchivedAt = nil
+	if archived {
+		now := time.Now()
+		after.ArchivedAt = &now
+		action = audit.ActionAppArchive
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).UpdateApp(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, action, audit.TargetApp, id, before, after)
+	})
+}
+
+func (s *serviceImpl) DeleteApp(id string, actor app.Actor) error {
+	before, err := s.getApp(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		// 锁定应用后再检查，生成激活码时也会先锁定应用，检查之后不会再生成新的激活码
+		if err := repo.LockApp(id); err != nil {
+			return err
+		}
+		count, err := repo.CountLiveCards(id, time.Now())
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errcode.AppHasLiveCards.WithDetails(fmt.Sprintf("还有 %d 个激活码可以使用，请先归档应用", count))
+		}
+		if err := repo.DeleteApp(id); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionAppDelete, audit.TargetApp, id, before, nil)
+	})
+}
+
+func (s *serviceImpl) CheckAppEnabled(id string) error {
+	if id == "" {
+		return nil
+	}
+	list, err := s.repo.GetAppByIDs([]string{id})
+	if err != nil {
+		return err
+	}
+	if len(list) > 0 && list[0].Disabled() {
+		return errcode.AppDisabled
+	}
+	return nil
+}
+
+// getApp 查询应用，不存在时返回 errcode.NotFound
+func (s *serviceImpl) getApp(id string) (App, error) {
+	result, err := s.repo.QueryAppList(QueryAppListArgs{ID: id})
+	if err != nil {
+		return App{}, err
+	}
+	if result.Total < 1 {
+		return App{}, errcode.NotFound.WithDetails("应用不存在")
+	}
+	return result.List[0], nil
+}
+
+// validateSettings 校验应用设置，时间类型由调用方校验
+func validateSettings(args UpdateAppSettingsArgs) error {
+	if !IsStatus(args.Status) {
+		return errcode.InvalidParams.WithDetails("无效的状态: " + args.Status)
+	}
+	if args.MaxDuration < 0 {
+		return errcode.InvalidParams.WithDetails("最大有效时长不能小于 0")
+	}
+	if args.ContactURL != "" {
+		u, err := url.Parse(args.ContactURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errcode.InvalidParams.WithDetails("联系链接必须是 http 或 https 地址")
+		}
+	}
+	return nil
 }
 
 func (s *serviceImpl) QueryAppOptions() ([]AppOption, error) {
@@ -0,0 +1,22 @@
+package appversion
+
+import "time"
+
+// DefaultChannel 客户端没有上报发布渠道时使用的渠道
+const DefaultChannel = "stable"
+
+// UpdateRequired 客户端版本低于最低支持版本时，签名响应中的结果
+const UpdateRequired = "update_required"
+
+const (
+	// maxVersionLength 版本号和渠道名的最大长度
+	maxVersionLength = 32
+	// maxNotesLength 版本说明的最大长度
+	maxNotesLength = 1024
+	// policyCacheTTL 版本策略的缓存时间，新增或删除版本时会清除缓存
+	policyCacheTTL = time.Minute
+	// defaultActiveDays 统计活跃设备时默认的天数
+	defaultActiveDays = 30
+	// maxActiveDays 统计活跃设备时最多的天数
+	maxActiveDays = 365
+)
@@ -0,0 +1,55 @@
+package appversion
+
+import "time"
+
+// AppVersion 应用在某个发布渠道上发布的客户端版本
+type AppVersion struct {
+	ID         string    `json:"id"`
+	AppID      string    `json:"app_id"`
+	Channel    string    `json:"channel"`     // 发布渠道，例如 stable、beta
+	Version    string    `json:"version"`     // 版本号，例如 1.2.3
+	MinVersion string    `json:"min_version"` // 发布该版本后渠道的最低支持版本，为空表示不限制
+	Notes      string    `json:"notes"`       // 版本说明
+	CreatorID  string    `json:"creator_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (v *AppVersion) TableName() string {
+	return "app_version"
+}
+
+// DeviceVersion 设备最近一次上报的客户端版本
+type DeviceVersion struct {
+	AppID      string    `json:"app_id"`
+	SEID       string    `json:"seid" gorm:"column:seid"`
+	Channel    string    `json:"channel"`
+	Version    string    `json:"version"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+func (d *DeviceVersion) TableName() string {
+	return "device_version"
+}
+
+// Policy 应用在某个渠道上的版本策略，由渠道中版本号最高的版本决定
+type Policy struct {
+	Channel       string `json:"channel"`
+	LatestVersion string `json:"latest_version"`
+	MinVersion    string `json:"min_version"`
+}
+
+// Verdict 客户端版本的检查结果
+type Verdict struct {
+	UpdateRequired bool   `json:"update_required"`
+	Channel        string `json:"channel"`
+	MinVersion     string `json:"min_version"`
+	LatestVersion  string `json:"latest_version"`
+}
+
+// VersionCount 某个版本的活跃设备数量
+type VersionCount struct {
+	Channel   string `json:"channel"`
+	Version   string `json:"version"`
+	Devices   int64  `json:"devices"`
+	Supported bool   `json:"supported"` // 是否不低于渠道当前的最低支持版本
+}
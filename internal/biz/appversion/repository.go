@@ -0,0 +1,29 @@
+package appversion
+
+import "time"
+
+type QueryVersionsArgs struct {
+	AppID   string `json:"app_id"`
+	Channel string `json:"channel"`
+	Page    int    `json:"page"`
+	Limit   int    `json:"limit"`
+}
+
+type QueryVersionsResult struct {
+	List  []AppVersion
+	Total int
+}
+
+type Repository interface {
+	CreateVersion(v AppVersion) error
+	GetVersionByID(id string) (AppVersion, error)
+	DeleteVersion(id string) error
+	QueryVersions(args QueryVersionsArgs) (QueryVersionsResult, error)
+	// GetChannelVersions 获取应用在某个渠道上的所有版本
+	GetChannelVersions(appID string, channel string) ([]AppVersion, error)
+
+	// SaveDevice 新增或者覆盖设备上报的版本
+	SaveDevice(device DeviceVersion) error
+	// CountDevices 按渠道和版本统计 since 之后上报过的设备数量，channel 为空时统计所有渠道
+	CountDevices(appID string, channel string, since time.Time) ([]VersionCount, error)
+}
@@ -0,0 +1,112 @@
+package appversion
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/pkg/errcode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE app_version (
+    id          VARCHAR(36)   NOT NULL PRIMARY KEY,
+    app_id      VARCHAR(36)   NOT NULL,             -- 应用ID
+    channel     VARCHAR(32)   NOT NULL,             -- 发布渠道
+    version     VARCHAR(32)   NOT NULL,             -- 版本号
+    min_version VARCHAR(32)   NOT NULL DEFAULT '',  -- 发布该版本后渠道的最低支持版本
+    notes       VARCHAR(1024) NOT NULL DEFAULT '',  -- 版本说明
+    creator_id  VARCHAR(36)   NOT NULL,             -- 创建人
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (app_id, channel, version)
+);
+
+CREATE TABLE device_version (
+    app_id       VARCHAR(36)  NOT NULL,  -- 应用ID
+    seid         VARCHAR(255) NOT NULL,  -- 设备SEID
+    channel      VARCHAR(32)  NOT NULL,  -- 发布渠道
+    version      VARCHAR(32)  NOT NULL,  -- 最近一次上报的版本号
+    last_seen_at TIMESTAMP    NOT NULL,  -- 最近一次上报的时间
+    PRIMARY KEY (app_id, seid),
+    INDEX idx_device_version_seen (app_id, last_seen_at)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateVersion(v AppVersion) error {
+	return r.db.Create(&v).Error
+}
+
+func (r *repository) GetVersionByID(id string) (AppVersion, error) {
+	var v AppVersion
+	if err := r.db.Where("id = ?", id).First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return AppVersion{}, errcode.NotFound.WithDetails("版本不存在")
+		}
+		return AppVersion{}, err
+	}
+	return v, nil
+}
+
+func (r *repository) DeleteVersion(id string) error {
+	return r.db.Where("id = ?", id).Delete(&AppVersion{}).Error
+}
+
+func (r *repository) QueryVersions(args QueryVersionsArgs) (QueryVersionsResult, error) {
+	db := r.db.Model(&AppVersion{})
+	if args.AppID != "" {
+		db = db.Where("app_id = ?", args.AppID)
+	}
+	if args.Channel != "" {
+		db = db.Where("channel = ?", args.Channel)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryVersionsResult{}, err
+	}
+	versions := make([]AppVersion, 0)
+	if err := db.Order("created_at desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&versions).Error; err != nil {
+		return QueryVersionsResult{}, err
+	}
+	return QueryVersionsResult{List: versions, Total: int(total)}, nil
+}
+
+func (r *repository) GetChannelVersions(appID string, channel string) ([]AppVersion, error) {
+	versions := make([]AppVersion, 0)
+	if err := r.db.Where("app_id = ? AND channel = ?", appID, channel).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *repository) SaveDevice(device DeviceVersion) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "app_id"}, {Name: "seid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channel", "version", "last_seen_at"}),
+	}).Create(&device).Error
+}
+
+func (r *repository) CountDevices(appID string, channel string, since time.Time) ([]VersionCount, error) {
+	db := r.db.Model(&DeviceVersion{}).
+		Select("channel, version, count(*) as devices").
+		Where("app_id = ? AND last_seen_at >= ?", appID, since)
+	if channel != "" {
+		db = db.Where("channel = ?", channel)
+	}
+	counts := make([]VersionCount, 0)
+	if err := db.Group("channel, version").Order("channel, devices desc").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,42 @@
+package appversion
+
+import (
+	"time"
+
+	"configuration-management/pkg/app"
+)
+
+type CreateVersionArgs struct {
+	AppID      string    `json:"app_id"`
+	Channel    string    `json:"channel"`
+	Version    string    `json:"version"`
+	MinVersion string    `json:"min_version"`
+	Notes      string    `json:"notes"`
+	Actor      app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type DeviceBreakdownArgs struct {
+	AppID   string `json:"app_id"`
+	Channel string `json:"channel"` // 为空时统计所有渠道
+	Days    int    `json:"days"`    // 统计最近多少天上报过的设备
+}
+
+type DeviceBreakdownResult struct {
+	Since    time.Time      `json:"since"`
+	Total    int64          `json:"total"`
+	Versions []VersionCount `json:"versions"`
+	Policies []Policy       `json:"policies"` // 统计中出现的渠道当前的版本策略
+}
+
+type Service interface {
+	QueryVersions(args QueryVersionsArgs) (QueryVersionsResult, error)
+	CreateVersion(args CreateVersionArgs) (AppVersion, error)
+	DeleteVersion(id string, actor app.Actor) error
+
+	// Evaluate 检查客户端版本是否低于应用在该渠道上的最低支持版本
+	Evaluate(appID string, channel string, clientVersion string) (Verdict, error)
+	// RecordDevice 记录设备上报的版本，用于统计活跃设备的版本分布
+	RecordDevice(device DeviceVersion) error
+	// DeviceBreakdown 按渠道和版本统计活跃设备
+	DeviceBreakdown(args DeviceBreakdownArgs) (DeviceBreakdownResult, error)
+}
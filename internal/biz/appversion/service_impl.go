@@ -0,0 +1,203 @@
+package appversion
+
+import (
+	"fmt"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/utils"
+
+	"github.com/patrickmn/go-cache"
+	"gorm.io/gorm"
+)
+
+// policyCache 每次激活和检查都要读取版本策略，按 应用ID/渠道 缓存
+var policyCache = cache.New(policyCacheTTL, policyCacheTTL)
+
+type service struct {
+	db      *gorm.DB
+	repo    Repository
+	appRepo apps.Repository
+}
+
+func NewService() Service {
+	return &service{
+		db:      global.DBEngine,
+		repo:    NewRepository(global.DBEngine),
+		appRepo: apps.NewRepository(global.DBEngine),
+	}
+}
+
+func (s *service) QueryVersions(args QueryVersionsArgs) (QueryVersionsResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QueryVersions(args)
+}
+
+func (s *service) CreateVersion(args CreateVersionArgs) (AppVersion, error) {
+	args.Channel = NormalizeChannel(args.Channel)
+	if err := validateVersion(args); err != nil {
+		return AppVersion{}, err
+	}
+	result, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{ID: args.AppID})
+	if err != nil {
+		return AppVersion{}, err
+	}
+	if result.Total == 0 {
+		return AppVersion{}, errcode.NotFound.WithDetails("应用不存在")
+	}
+	versions, err := s.repo.GetChannelVersions(args.AppID, args.Channel)
+	if err != nil {
+		return AppVersion{}, err
+	}
+	for _, v := range versions {
+		if c, err := CompareVersions(v.Version, args.Version); err == nil && c == 0 {
+			return AppVersion{}, errcode.DuplicateKey.WithDetails("版本已经存在: " + v.Version)
+		}
+	}
+
+	v := AppVersion{
+		ID:         utils.GenerateUUID(),
+		AppID:      args.AppID,
+		Channel:    args.Channel,
+		Version:    args.Version,
+		MinVersion: args.MinVersion,
+		Notes:      args.Notes,
+		CreatorID:  args.Actor.ID,
+		CreatedAt:  time.Now(),
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).CreateVersion(v); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionAppVersionCreate, audit.TargetAppVersion, v.ID, nil, v)
+	})
+	if err != nil {
+		return AppVersion{}, err
+	}
+	policyCache.Delete(policyKey(v.AppID, v.Channel))
+	return v, nil
+}
+
+func (s *service) DeleteVersion(id string, actor app.Actor) error {
+	v, err := s.repo.GetVersionByID(id)
+	if err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).DeleteVersion(id); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionAppVersionDelete, audit.TargetAppVersion, id, v, nil)
+	})
+	if err != nil {
+		return err
+	}
+	policyCache.Delete(policyKey(v.AppID, v.Channel))
+	return nil
+}
+
+func (s *service) Evaluate(appID string, channel string, clientVersion string) (Verdict, error) {
+	policy, err := s.getPolicy(appID, NormalizeChannel(channel))
+	if err != nil {
+		return Verdict{}, err
+	}
+	return evaluate(policy, clientVersion), nil
+}
+
+func (s *service) RecordDevice(device DeviceVersion) error {
+	if device.AppID == "" || device.SEID == "" || device.Version == "" {
+		return nil
+	}
+	device.Channel = NormalizeChannel(device.Channel)
+	if device.LastSeenAt.IsZero() {
+		device.LastSeenAt = time.Now()
+	}
+	return s.repo.SaveDevice(device)
+}
+
+func (s *service) DeviceBreakdown(args DeviceBreakdownArgs) (DeviceBreakdownResult, error) {
+	if args.Days <= 0 {
+		args.Days = defaultActiveDays
+	}
+	if args.Days > maxActiveDays {
+		return DeviceBreakdownResult{}, errcode.InvalidParams.WithDetails(fmt.Sprintf("最多统计 %d 天", maxActiveDays))
+	}
+	if args.Channel != "" {
+		args.Channel = NormalizeChannel(args.Channel)
+	}
+	since := time.Now().AddDate(0, 0, -args.Days)
+	counts, err := s.repo.CountDevices(args.AppID, args.Channel, since)
+	if err != nil {
+		return DeviceBreakdownResult{}, err
+	}
+
+	result := DeviceBreakdownResult{Since: since, Versions: counts, Policies: []Policy{}}
+	policies := make(map[string]Policy)
+	for i, count := range counts {
+		policy, ok := policies[count.Channel]
+		if !ok {
+			if policy, err = s.getPolicy(args.AppID, count.Channel); err != nil {
+				return DeviceBreakdownResult{}, err
+			}
+			policies[count.Channel] = policy
+			result.Policies = append(result.Policies, policy)
+		}
+		result.Versions[i].Supported = !evaluate(policy, count.Version).UpdateRequired
+		result.Total += count.Devices
+	}
+	return result, nil
+}
+
+// getPolicy 获取应用在某个渠道上的版本策略，渠道没有任何版本时不限制
+func (s *service) getPolicy(appID string, channel string) (Policy, error) {
+	key := policyKey(appID, channel)
+	if cached, ok := policyCache.Get(key); ok {
+		return cached.(Policy), nil
+	}
+	versions, err := s.repo.GetChannelVersions(appID, channel)
+	if err != nil {
+		return Policy{}, err
+	}
+	policy := buildPolicy(channel, versions)
+	policyCache.SetDefault(key, policy)
+	return policy, nil
+}
+
+func policyKey(appID string, channel string) string {
+	return appID + "/" + channel
+}
+
+// validateVersion 校验要发布的版本，最低支持版本不能高于该版本
+func validateVersion(args CreateVersionArgs) error {
+	if args.AppID == "" {
+		return errcode.InvalidParams.WithDetails("缺少应用ID")
+	}
+	if !IsChannel(args.Channel) {
+		return errcode.InvalidParams.WithDetails("无效的渠道: " + args.Channel)
+	}
+	if !IsVersion(args.Version) {
+		return errcode.InvalidParams.WithDetails("无效的版本号: " + args.Version)
+	}
+	if len(args.Notes) > maxNotesLength {
+		return errcode.InvalidParams.WithDetails(fmt.Sprintf("版本说明不能超过 %d 个字符", maxNotesLength))
+	}
+	if args.MinVersion == "" {
+		return nil
+	}
+	if !IsVersion(args.MinVersion) {
+		return errcode.InvalidParams.WithDetails("无效的最低支持版本: " + args.MinVersion)
+	}
+	if c, _ := CompareVersions(args.MinVersion, args.Version); c > 0 {
+		return errcode.InvalidParams.WithDetails("最低支持版本不能高于发布的版本")
+	}
+	return nil
+}
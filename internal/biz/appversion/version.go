@@ -0,0 +1,145 @@
+package appversion
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var channelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// version 解析后的版本号，例如 v1.2.3-beta.1 为 [1 2 3] 和 beta.1
+type version struct {
+	parts      []int
+	prerelease string
+}
+
+// parseVersion 解析点分隔的数字版本号，最多 4 段，允许 v 前缀和 - 之后的预发布标识，忽略 + 之后的构建信息
+func parseVersion(s string) (version, error) {
+	raw := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v version
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s, v.prerelease = s[:i], s[i+1:]
+		if v.prerelease == "" {
+			return version{}, fmt.Errorf("无效的版本号: %s", raw)
+		}
+	}
+	segments := strings.Split(s, ".")
+	if s == "" || len(segments) > 4 {
+		return version{}, fmt.Errorf("无效的版本号: %s", raw)
+	}
+	for _, seg := range segments {
+		n, err := strconv.Atoi(seg)
+		if err != nil || n < 0 {
+			return version{}, fmt.Errorf("无效的版本号: %s", raw)
+		}
+		v.parts = append(v.parts, n)
+	}
+	return v, nil
+}
+
+// compare 比较版本号，缺少的段按 0 处理，相同版本号的预发布版本低于正式版本
+func (v version) compare(other version) int {
+	for i := 0; i < len(v.parts) || i < len(other.parts); i++ {
+		a, b := 0, 0
+		if i < len(v.parts) {
+			a = v.parts[i]
+		}
+		if i < len(other.parts) {
+			b = other.parts[i]
+		}
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == other.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case other.prerelease == "":
+		return -1
+	case v.prerelease < other.prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// CompareVersions 比较两个版本号，a < b 返回 -1，a == b 返回 0，a > b 返回 1
+func CompareVersions(a string, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.compare(vb), nil
+}
+
+// IsVersion 检查是否为有效的版本号
+func IsVersion(s string) bool {
+	if len(s) > maxVersionLength {
+		return false
+	}
+	_, err := parseVersion(s)
+	return err == nil
+}
+
+// IsChannel 检查是否为有效的渠道名：小写字母、数字、下划线和减号
+func IsChannel(s string) bool {
+	return len(s) <= maxVersionLength && channelPattern.MatchString(s)
+}
+
+// NormalizeChannel 渠道为空时使用默认渠道
+func NormalizeChannel(channel string) string {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" {
+		return DefaultChannel
+	}
+	return channel
+}
+
+// buildPolicy 渠道中版本号最高的版本决定最新版本和最低支持版本，无效的版本号会被忽略
+func buildPolicy(channel string, versions []AppVersion) Policy {
+	policy := Policy{Channel: channel}
+	var latest version
+	for _, v := range versions {
+		parsed, err := parseVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		if policy.LatestVersion == "" || parsed.compare(latest) > 0 {
+			latest = parsed
+			policy.LatestVersion = v.Version
+			policy.MinVersion = v.MinVersion
+		}
+	}
+	return policy
+}
+
+// evaluate 检查客户端版本是否低于最低支持版本
+// 没有上报版本的旧客户端不检查，上报了无法解析的版本号时要求更新
+func evaluate(policy Policy, clientVersion string) Verdict {
+	verdict := Verdict{
+		Channel:       policy.Channel,
+		MinVersion:    policy.MinVersion,
+		LatestVersion: policy.LatestVersion,
+	}
+	if clientVersion == "" || policy.MinVersion == "" {
+		return verdict
+	}
+	c, err := CompareVersions(clientVersion, policy.MinVersion)
+	verdict.UpdateRequired = err != nil || c < 0
+	return verdict
+}
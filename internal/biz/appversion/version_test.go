@@ -0,0 +1,94 @@
+package appversion
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0-beta.1", "2.0.0", -1},
+		{"2.0.0-beta.2", "2.0.0-beta.1", 1},
+		{"1.0.0+build.7", "1.0.0", 0},
+	}
+	for _, c := range cases {
+		got, err := CompareVersions(c.a, c.b)
+		if err != nil {
+			t.Fatalf("%s vs %s: %v", c.a, c.b, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s vs %s = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+
+	for _, s := range []string{"", "v", "1..2", "1.2.3.4.5", "1.x", "1.0-", "-1.0"} {
+		if IsVersion(s) {
+			t.Fatalf("IsVersion(%q) = true", s)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	versions := []AppVersion{
+		{Version: "1.0.0"},
+		{Version: "1.10.0", MinVersion: "1.5.0"},
+		{Version: "1.9.0", MinVersion: "1.8.0"},
+		{Version: "bad", MinVersion: "9.0.0"},
+	}
+	policy := buildPolicy("stable", versions)
+	if policy.LatestVersion != "1.10.0" || policy.MinVersion != "1.5.0" {
+		t.Fatalf("policy = %+v", policy)
+	}
+
+	cases := []struct {
+		version string
+		update  bool
+	}{
+		{"", false}, // 旧客户端不上报版本
+		{"1.4.9", true},
+		{"1.5.0", false},
+		{"1.5.0-rc.1", true},
+		{"2.0.0", false},
+		{"garbage", true},
+	}
+	for _, c := range cases {
+		verdict := evaluate(policy, c.version)
+		if verdict.UpdateRequired != c.update {
+			t.Fatalf("evaluate(%q) = %+v, want update %v", c.version, verdict, c.update)
+		}
+		if verdict.LatestVersion != "1.10.0" || verdict.Channel != "stable" {
+			t.Fatalf("verdict = %+v", verdict)
+		}
+	}
+
+	// 渠道没有版本时不限制
+	if evaluate(buildPolicy("beta", nil), "0.0.1").UpdateRequired {
+		t.Fatal("empty policy should not require update")
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	cases := []struct {
+		args CreateVersionArgs
+		ok   bool
+	}{
+		{CreateVersionArgs{AppID: "a1", Channel: "stable", Version: "1.2.0", MinVersion: "1.0.0"}, true},
+		{CreateVersionArgs{AppID: "a1", Channel: "beta", Version: "2.0.0-beta.1"}, true},
+		{CreateVersionArgs{AppID: "a1", Channel: "stable", Version: "1.2.0", MinVersion: "1.3.0"}, false},
+		{CreateVersionArgs{AppID: "a1", Channel: "Stable!", Version: "1.2.0"}, false},
+		{CreateVersionArgs{AppID: "a1", Channel: "stable", Version: "latest"}, false},
+		{CreateVersionArgs{Channel: "stable", Version: "1.2.0"}, false},
+	}
+	for i, c := range cases {
+		if err := validateVersion(c.args); c.ok != (err == nil) {
+			t.Fatalf("case %d: err = %v", i, err)
+		}
+	}
+	if NormalizeChannel(" Beta ") != "beta" || NormalizeChannel("") != DefaultChannel {
+		t.Fatal("unexpected channel normalization")
+	}
+}
@@ -0,0 +1,91 @@
+package audit
+
+// 审计对象的类型
+const (
+	TargetCard       = "card"
+	TargetUser       = "user"
+	TargetApp        = "app"
+	TargetAppVersion = "app_version"
+	TargetUserConfig = "user_config"
+
+	TargetRemoteConfigKey   = "remote_config_key"
+	TargetRemoteConfigValue = "remote_config_value"
+
+	TargetAbuseFlag = "abuse_flag"
+	TargetIPBlock   = "ip_block"
+
+	TargetWebhook         = "webhook"
+	TargetWebhookDelivery = "webhook_delivery"
+
+	TargetNotificationTemplate = "notification_template"
+)
+
+// 审计的操作，格式为 <对象>.<动作>
+const (
+	ActionCardCreate       = "card.create"
+	ActionCardUpdate       = "card.update"
+	ActionCardUpdateStatus = "card.update_status"
+	ActionCardDelete       = "card.delete"
+	ActionCardSetExpiredAt = "card.set_expired_at"
+	ActionCardImport       = "card.import"
+	ActionCardActivate     = "card.activate"
+	ActionCardTransfer     = "card.transfer"
+
+	ActionUserCreate         = "user.create"
+	ActionUserUpdate         = "user.update"
+	ActionUserDelete         = "user.delete"
+	ActionUserDisable        = "user.disable"
+	ActionUserResetPassword  = "user.reset_password"
+	ActionUserChangePassword = "user.change_password"
+	ActionUserUpdateProfile  = "user.update_profile"
+	ActionUserSetRoles       = "user.set_roles"
+	ActionUserGrantQuota     = "user.grant_quota"
+	ActionUserSetAppGrant    = "user.set_app_grant"
+	ActionUserDeleteAppGrant = "user.delete_app_grant"
+
+	ActionAppCreate    = "app.create"
+	ActionAppUpdate    = "app.update"
+	ActionAppDelete    = "app.delete"
+	ActionAppSettings  = "app.update_settings"
+	ActionAppArchive   = "app.archive"
+	ActionAppUnarchive = "app.unarchive"
+
+	ActionAppVersionCreate = "app_version.create"
+	ActionAppVersionDelete = "app_version.delete"
+
+	ActionUserConfigCreate = "user_config.create"
+	ActionUserConfigUpdate = "user_config.update"
+	ActionUserConfigDelete = "user_config.delete"
+
+	ActionRemoteConfigKeyCreate     = "remote_config_key.create"
+	ActionRemoteConfigKeyUpdate     = "remote_config_key.update"
+	ActionRemoteConfigKeyDelete     = "remote_config_key.delete"
+	ActionRemoteConfigValueSave     = "remote_config_value.save"
+	ActionRemoteConfigValueDelete   = "remote_config_value.delete"
+	ActionRemoteConfigValueRollback = "remote_config_value.rollback"
+
+	ActionAbuseFlagReview = "abuse_flag.review"
+	ActionIPBlockDelete   = "ip_block.delete"
+
+	ActionWebhookCreate       = "webhook.create"
+	ActionWebhookUpdate       = "webhook.update"
+	ActionWebhookRotateSecret = "webhook.rotate_secret"
+	ActionWebhookDelete       = "webhook.delete"
+	ActionWebhookRedeliver    = "webhook_delivery.redeliver"
+
+	ActionNotificationTemplateUpdate = "notification_template.update"
+	ActionNotificationTemplateDelete = "notification_template.delete"
+)
+
+const (
+	// redacted 敏感字段在审计日志中的替代值
+	redacted = "******"
+	// exportBatchSize 导出时每次从数据库读取的条数
+	exportBatchSize = 500
+)
+
+// sensitiveFields 不能明文写入审计日志的字段，只记录是否发生了变化
+var sensitiveFields = map[string]struct{}{
+	"password": {},
+	"key_hash": {},
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Diff 比较修改前后的对象，只返回发生变化的字段
+// before 为 nil 表示创建，after 为 nil 表示删除，此时返回另一侧的全部字段
+func Diff(before any, after any) (json.RawMessage, json.RawMessage, error) {
+	beforeMap, err := toMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterMap, err := toMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changedBefore := make(map[string]any)
+	changedAfter := make(map[string]any)
+	for key, value := range beforeMap {
+		if afterValue, ok := afterMap[key]; afterMap != nil && ok && reflect.DeepEqual(value, afterValue) {
+			continue
+		}
+		changedBefore[key] = redact(key, value)
+	}
+	for key, value := range afterMap {
+		if beforeValue, ok := beforeMap[key]; beforeMap != nil && ok && reflect.DeepEqual(value, beforeValue) {
+			continue
+		}
+		changedAfter[key] = redact(key, value)
+	}
+
+	return marshal(beforeMap, changedBefore), marshal(afterMap, changedAfter), nil
+}
+
+// toMap 通过 json 把对象转换为 map，对象的 json tag 就是审计日志中的字段名
+func toMap(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func redact(key string, value any) any {
+	if _, ok := sensitiveFields[key]; ok {
+		return redacted
+	}
+	return value
+}
+
+// marshal 对象不存在时写入 null，存在时写入发生变化的字段
+func marshal(original map[string]any, changed map[string]any) json.RawMessage {
+	if original == nil {
+		return nil
+	}
+	data, _ := json.Marshal(changed)
+	return data
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID       string   `json:"id"`
+	Status   int      `json:"status"`
+	Password string   `json:"password"`
+	Apps     []string `json:"apps"`
+}
+
+func decode(t *testing.T, data json.RawMessage) map[string]any {
+	if data == nil {
+		return nil
+	}
+	m := make(map[string]any)
+	assert.NoError(t, json.Unmarshal(data, &m))
+	return m
+}
+
+func TestDiffUpdate(t *testing.T) {
+	before := testUser{ID: "1", Status: 1, Password: "old", Apps: []string{"a"}}
+	after := testUser{ID: "1", Status: -1, Password: "new", Apps: []string{"a"}}
+
+	b, a, err := Diff(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"status": float64(1), "password": redacted}, decode(t, b))
+	assert.Equal(t, map[string]any{"status": float64(-1), "password": redacted}, decode(t, a))
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	u := &testUser{ID: "1", Status: 1, Password: "secret"}
+
+	b, a, err := Diff(nil, u)
+	assert.NoError(t, err)
+	assert.Nil(t, b)
+	assert.Equal(t, "1", decode(t, a)["id"])
+	assert.Equal(t, redacted, decode(t, a)["password"])
+
+	var nilUser *testUser
+	b, a, err = Diff(u, nilUser)
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+	assert.Equal(t, float64(1), decode(t, b)["status"])
+}
+
+func TestDiffNoChange(t *testing.T) {
+	u := testUser{ID: "1", Apps: []string{"a", "b"}}
+	b, a, err := Diff(u, u)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(b))
+	assert.Equal(t, "{}", string(a))
+}
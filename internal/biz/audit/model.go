@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entry 一条审计日志，只追加不修改
+type Entry struct {
+	ID         int64           `json:"id"`                                         // 自增ID，同时表示写入顺序
+	ActorID    string          `json:"actor_id"`                                   // 操作人ID
+	ActorName  string          `json:"actor_name"`                                 // 操作人用户名
+	Action     string          `json:"action"`                                     // 操作，例如 card.update
+	TargetType string          `json:"target_type"`                                // 对象类型
+	TargetID   string          `json:"target_id"`                                  // 对象ID
+	Before     json.RawMessage `json:"before" gorm:"column:before_data;type:json"` // 修改前发生变化的字段
+	After      json.RawMessage `json:"after" gorm:"column:after_data;type:json"`   // 修改后发生变化的字段
+	IP         string          `json:"ip"`                                         // 操作人IP
+	RequestID  string          `json:"request_id"`                                 // 请求ID
+	CreatedAt  time.Time       `json:"created_at"`                                 // 创建时间
+}
+
+func (e *Entry) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"configuration-management/internal/biz/common"
+	"configuration-management/pkg/app"
+)
+
+type QueryEntriesArgs struct {
+	ActorID            string           `json:"actor_id"`
+	Action             string           `json:"action"`
+	TargetType         string           `json:"target_type"`
+	TargetID           string           `json:"target_id"`
+	RequestID          string           `json:"request_id"`
+	CreatedAtDateRange common.TimeRange `json:"created_at_date_range"`
+	Page               int              `json:"page"`
+	Limit              int              `json:"limit"`
+}
+
+type QueryEntriesResult struct {
+	List  []Entry
+	Total int
+}
+
+// Repository 审计日志只提供写入和查询，没有修改和删除
+type Repository interface {
+	CreateEntries(entries []Entry) error
+	// Record 生成并写入一条审计日志，before 和 after 只保留发生变化的字段
+	Record(actor app.Actor, action string, targetType string, targetID string, before any, after any) error
+	QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error)
+	// IterateEntries 按ID顺序分批读取符合条件的审计日志，用于导出
+	IterateEntries(args QueryEntriesArgs, batchSize int, fn func(entries []Entry) error) error
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+/*
+表结构如下：
+CREATE TABLE audit_log (
+    id          BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY, -- 自增ID
+    actor_id    VARCHAR(32)  NOT NULL,             -- 操作人ID
+    actor_name  VARCHAR(255) NOT NULL,             -- 操作人用户名
+    action      VARCHAR(64)  NOT NULL,             -- 操作
+    target_type VARCHAR(32)  NOT NULL,             -- 对象类型
+    target_id   VARCHAR(255) NOT NULL,             -- 对象ID
+    before_data JSON         NULL,                 -- 修改前发生变化的字段
+    after_data  JSON         NULL,                 -- 修改后发生变化的字段
+    ip          VARCHAR(64)  NOT NULL,             -- 操作人IP
+    request_id  VARCHAR(64)  NOT NULL,             -- 请求ID
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateEntries(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := r.db.Table((&Entry{}).TableName()).CreateInBatches(entries, 500).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"action": entries[0].Action,
+			"count":  len(entries),
+		}).Error("写入审计日志失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Record(actor app.Actor, action string, targetType string, targetID string, before any, after any) error {
+	entry, err := NewEntry(actor, action, targetType, targetID, before, after)
+	if err != nil {
+		return err
+	}
+	return r.CreateEntries([]Entry{entry})
+}
+
+func (r *repository) filter(args QueryEntriesArgs) *gorm.DB {
+	db := r.db.Table((&Entry{}).TableName())
+	if args.ActorID != "" {
+		db = db.Where("actor_id = ?", args.ActorID)
+	}
+	if args.Action != "" {
+		db = db.Where("action = ?", args.Action)
+	}
+	if args.TargetType != "" {
+		db = db.Where("target_type = ?", args.TargetType)
+	}
+	if args.TargetID != "" {
+		db = db.Where("target_id = ?", args.TargetID)
+	}
+	if args.RequestID != "" {
+		db = db.Where("request_id = ?", args.RequestID)
+	}
+	if !args.CreatedAtDateRange.StartTime.IsZero() {
+		db = db.Where("created_at >= ?", args.CreatedAtDateRange.StartTime.Format("2006-01-02 15:04:05"))
+	}
+	if !args.CreatedAtDateRange.EndTime.IsZero() {
+		db = db.Where("created_at <= ?", args.CreatedAtDateRange.EndTime.Format("2006-01-02 15:04:05"))
+	}
+	return db
+}
+
+func (r *repository) QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error) {
+	var total int64
+	if err := r.filter(args).Count(&total).Error; err != nil {
+		return QueryEntriesResult{}, err
+	}
+
+	entries := make([]Entry, 0)
+	if err := r.filter(args).Order("id desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).
+		Find(&entries).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Error("查询审计日志失败", err)
+		return QueryEntriesResult{}, err
+	}
+	return QueryEntriesResult{List: entries, Total: int(total)}, nil
+}
+
+func (r *repository) IterateEntries(args QueryEntriesArgs, batchSize int, fn func(entries []Entry) error) error {
+	var lastID int64
+	for {
+		entries := make([]Entry, 0, batchSize)
+		if err := r.filter(args).Where("id > ?", lastID).Order("id asc").Limit(batchSize).
+			Find(&entries).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := fn(entries); err != nil {
+			return err
+		}
+		if len(entries) < batchSize {
+			return nil
+		}
+		lastID = entries[len(entries)-1].ID
+	}
+}
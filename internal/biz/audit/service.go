@@ -0,0 +1,7 @@
+package audit
+
+type Service interface {
+	QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error)
+	// ExportEntries 按写入顺序分批回调所有符合条件的审计日志，不分页
+	ExportEntries(args QueryEntriesArgs, fn func(entries []Entry) error) error
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+
+	"gorm.io/gorm"
+)
+
+type service struct {
+	repo Repository
+}
+
+func NewService() Service {
+	return &service{
+		repo: NewRepository(global.DBEngine),
+	}
+}
+
+func (s *service) QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QueryEntries(args)
+}
+
+func (s *service) ExportEntries(args QueryEntriesArgs, fn func(entries []Entry) error) error {
+	return s.repo.IterateEntries(args, exportBatchSize, fn)
+}
+
+// NewEntry 生成一条审计日志，before 和 after 只保留发生变化的字段
+func NewEntry(actor app.Actor, action string, targetType string, targetID string, before any, after any) (Entry, error) {
+	changedBefore, changedAfter, err := Diff(before, after)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     changedBefore,
+		After:      changedAfter,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// Record 写入一条审计日志，tx 必须是被记录的修改所在的事务，修改回滚时审计日志一起回滚
+func Record(tx *gorm.DB, actor app.Actor, action string, targetType string, targetID string, before any, after any) error {
+	return NewRepository(tx).Record(actor, action, targetType, targetID, before, after)
+}
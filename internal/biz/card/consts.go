@@ -12,3 +12,16 @@ const (
 	MonthlyTime = "monthly"
 	YearlyTime  = "yearly"
 )
+
+// TimeTypes 所有的时间类型
+var TimeTypes = []string{HourlyTime, DailyTime, WeeklyTime, MonthlyTime, YearlyTime}
+
+// IsTimeType 检查是否为有效的时间类型
+func IsTimeType(timeType string) bool {
+	for _, t := range TimeTypes {
+		if t == timeType {
+			return true
+		}
+	}
+	return false
+}
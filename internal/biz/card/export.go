@@ -0,0 +1,129 @@
+package card
+
+import (
+	"strconv"
+	"time"
+
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/spreadsheet"
+)
+
+// 导出文件表头支持的语言
+const (
+	ExportLocaleZhCN = "zh-CN"
+	ExportLocaleEnUS = "en-US"
+)
+
+// exportColumn 导出文件中的一列
+type exportColumn struct {
+	Key     string
+	Headers map[string]string // 各个语言的表头
+	Value   func(card Card, appNames map[string]string) string
+}
+
+var exportColumns = []exportColumn{
+	{"id", map[string]string{ExportLocaleZhCN: "ID", ExportLocaleEnUS: "ID"}, func(card Card, _ map[string]string) string { return card.ID }},
+	{"value", map[string]string{ExportLocaleZhCN: "激活码", ExportLocaleEnUS: "Code"}, func(card Card, _ map[string]string) string { return card.Value }},
+	{"app_id", map[string]string{ExportLocaleZhCN: "应用ID", ExportLocaleEnUS: "App ID"}, func(card Card, _ map[string]string) string { return card.AppID }},
+	{"app_name", map[string]string{ExportLocaleZhCN: "应用", ExportLocaleEnUS: "App"}, func(card Card, appNames map[string]string) string { return appNames[card.AppID] }},
+	{"status", map[string]string{ExportLocaleZhCN: "状态", ExportLocaleEnUS: "Status"}, nil},
+	{"user_name", map[string]string{ExportLocaleZhCN: "创建人", ExportLocaleEnUS: "Owner"}, func(card Card, _ map[string]string) string { return card.UserName }},
+	{"time_type", map[string]string{ExportLocaleZhCN: "时间类型", ExportLocaleEnUS: "Time type"}, func(card Card, _ map[string]string) string { return card.TimeType }},
+	{"days", map[string]string{ExportLocaleZhCN: "天数", ExportLocaleEnUS: "Days"}, func(card Card, _ map[string]string) string { return strconv.Itoa(card.Days) }},
+	{"hours", map[string]string{ExportLocaleZhCN: "小时数", ExportLocaleEnUS: "Hours"}, func(card Card, _ map[string]string) string { return strconv.Itoa(card.Hours) }},
+	{"minutes", map[string]string{ExportLocaleZhCN: "分钟数", ExportLocaleEnUS: "Minutes"}, func(card Card, _ map[string]string) string { return strconv.Itoa(card.Minutes) }},
+	{"seid", map[string]string{ExportLocaleZhCN: "设备SEID", ExportLocaleEnUS: "Device SEID"}, func(card Card, _ map[string]string) string { return card.SEID }},
+	{"used_at", map[string]string{ExportLocaleZhCN: "使用时间", ExportLocaleEnUS: "Used at"}, func(card Card, _ map[string]string) string { return formatTime(card.UsedAt) }},
+	{"expired_at", map[string]string{ExportLocaleZhCN: "过期时间", ExportLocaleEnUS: "Expires at"}, func(card Card, _ map[string]string) string { return formatTime(card.ExpiredAt) }},
+	{"locked_at", map[string]string{ExportLocaleZhCN: "锁定时间", ExportLocaleEnUS: "Locked at"}, func(card Card, _ map[string]string) string { return formatTime(card.LockedAt) }},
+	{"remark", map[string]string{ExportLocaleZhCN: "备注", ExportLocaleEnUS: "Remark"}, func(card Card, _ map[string]string) string { return card.Remark }},
+	{"batch_id", map[string]string{ExportLocaleZhCN: "批次", ExportLocaleEnUS: "Batch"}, func(card Card, _ map[string]string) string { return card.BatchID }},
+	{"created_at", map[string]string{ExportLocaleZhCN: "生成时间", ExportLocaleEnUS: "Created at"}, func(card Card, _ map[string]string) string { return formatTime(card.CreatedAt) }},
+}
+
+// DefaultExportColumns 没有选择列时导出的列
+var DefaultExportColumns = []string{"value", "app_name", "status", "time_type", "days", "hours", "minutes", "seid", "used_at", "expired_at", "remark", "created_at"}
+
+var statusLabels = map[string]map[int]string{
+	ExportLocaleZhCN: {StatusUnused: "未使用", StatusUsed: "已使用", StatusLocked: "已锁定", StatusDeleted: "已删除"},
+	ExportLocaleEnUS: {StatusUnused: "Unused", StatusUsed: "Used", StatusLocked: "Locked", StatusDeleted: "Deleted"},
+}
+
+// CheckExportArgs 检查导出的格式、列和语言，用于在后台任务开始前提前返回参数错误
+func CheckExportArgs(args ExportCardsArgs) error {
+	if args.Format != spreadsheet.FormatCSV && args.Format != spreadsheet.FormatXLSX {
+		return errcode.InvalidParams.WithDetails("不支持的文件格式 " + args.Format)
+	}
+	_, err := newExportLayout(args.Columns, args.Locale)
+	return err
+}
+
+// exportLayout 选择的列和语言
+type exportLayout struct {
+	locale  string
+	columns []exportColumn
+}
+
+// newExportLayout 检查选择的列和语言，列按选择的顺序输出
+func newExportLayout(keys []string, locale string) (exportLayout, error) {
+	if locale == "" {
+		locale = ExportLocaleZhCN
+	}
+	if _, ok := statusLabels[locale]; !ok {
+		return exportLayout{}, errcode.InvalidParams.WithDetails("不支持的语言 " + locale)
+	}
+	if len(keys) == 0 {
+		keys = DefaultExportColumns
+	}
+
+	layout := exportLayout{locale: locale, columns: make([]exportColumn, 0, len(keys))}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		column, ok := findExportColumn(key)
+		if !ok {
+			return exportLayout{}, errcode.InvalidParams.WithDetails("不支持导出的列 " + key)
+		}
+		layout.columns = append(layout.columns, column)
+	}
+	return layout, nil
+}
+
+func findExportColumn(key string) (exportColumn, bool) {
+	for _, column := range exportColumns {
+		if column.Key == key {
+			return column, true
+		}
+	}
+	return exportColumn{}, false
+}
+
+func (l exportLayout) header() []string {
+	header := make([]string, len(l.columns))
+	for i, column := range l.columns {
+		header[i] = column.Headers[l.locale]
+	}
+	return header
+}
+
+func (l exportLayout) row(card Card, appNames map[string]string) []string {
+	row := make([]string, len(l.columns))
+	for i, column := range l.columns {
+		if column.Key == "status" {
+			row[i] = statusLabels[l.locale][card.Status]
+			continue
+		}
+		row[i] = column.Value(card, appNames)
+	}
+	return row
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,56 @@
+package card
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"configuration-management/pkg/errcode"
+)
+
+func TestExportLayout(t *testing.T) {
+	layout, err := newExportLayout([]string{"value", "status", "app_name", "value", "used_at"}, ExportLocaleEnUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 重复的列只输出一次
+	if header := layout.header(); !reflect.DeepEqual(header, []string{"Code", "Status", "App", "Used at"}) {
+		t.Fatalf("unexpected header: %v", header)
+	}
+
+	usedAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.Local)
+	row := layout.row(Card{Value: "ABC", AppID: "a1", Status: StatusUsed, UsedAt: &usedAt}, map[string]string{"a1": "App One"})
+	if !reflect.DeepEqual(row, []string{"ABC", "Used", "App One", "2024-05-01 08:30:00"}) {
+		t.Fatalf("unexpected row: %v", row)
+	}
+
+	// 默认使用中文表头和默认的列
+	layout, err = newExportLayout(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layout.columns) != len(DefaultExportColumns) || layout.header()[0] != "激活码" {
+		t.Fatalf("unexpected default layout: %v", layout.header())
+	}
+	if row := layout.row(Card{Status: StatusUnused}, nil); row[2] != "未使用" || row[8] != "" {
+		t.Fatalf("unexpected default row: %v", row)
+	}
+}
+
+func TestCheckExportArgs(t *testing.T) {
+	cases := []ExportCardsArgs{
+		{Format: "pdf"},
+		{Format: "csv", Columns: []string{"password"}},
+		{Format: "xlsx", Locale: "ja-JP"},
+	}
+	for _, args := range cases {
+		var e *errcode.Error
+		if err := CheckExportArgs(args); !errors.As(err, &e) || e.Code() != errcode.InvalidParams.Code() {
+			t.Fatalf("expected invalid params for %+v, got %v", args, err)
+		}
+	}
+	if err := CheckExportArgs(ExportCardsArgs{Format: "xlsx", Columns: []string{"id", "batch_id"}, Locale: ExportLocaleZhCN}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,137 @@
+package card
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"configuration-management/internal/biz/activationattempt"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/cardcheck"
+)
+
+// 激活码时间线中的事件类型
+const (
+	HistoryCreated           = "created"
+	HistoryActivationAttempt = "activation_attempt"
+	HistoryAvailabilityCheck = "availability_check"
+	HistoryStatusChanged     = "status_changed"
+	HistoryExpiryChanged     = "expiry_changed"
+	HistoryDeviceRebound     = "device_rebound"
+	HistoryUpdated           = "updated"
+	HistoryDeleted           = "deleted"
+)
+
+// HistoryActor 事件的操作人，公开接口产生的事件没有操作人
+type HistoryActor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// HistoryEvent 激活码时间线中的一个事件
+type HistoryEvent struct {
+	Time    time.Time       `json:"time"`              // 发生时间
+	Type    string          `json:"type"`              // 事件类型
+	Actor   *HistoryActor   `json:"actor,omitempty"`   // 操作人
+	IP      string          `json:"ip,omitempty"`      // 请求方IP
+	Success *bool           `json:"success,omitempty"` // 激活是否成功、检查时是否可用
+	Detail  string          `json:"detail,omitempty"`  // 说明，例如激活失败的原因
+	Before  json.RawMessage `json:"before,omitempty"`  // 修改前发生变化的字段
+	After   json.RawMessage `json:"after,omitempty"`   // 修改后发生变化的字段
+}
+
+// buildHistory 将审计日志、激活记录和可用性检查记录合并为按时间排序的事件列表
+// 审计日志上线前的数据没有对应的记录，使用激活码上的时间字段补齐
+func buildHistory(card *Card, entries []audit.Entry, attempts []activationattempt.ActivationAttempt, checks []cardcheck.CardCheck) []HistoryEvent {
+	events := make([]HistoryEvent, 0, len(entries)+len(attempts)+len(checks)+1)
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		eventType := auditEventType(entry)
+		seen[eventType] = true
+		events = append(events, HistoryEvent{
+			Time:   entry.CreatedAt,
+			Type:   eventType,
+			Actor:  &HistoryActor{ID: entry.ActorID, Name: entry.ActorName},
+			IP:     entry.IP,
+			Before: entry.Before,
+			After:  entry.After,
+		})
+	}
+
+	activated := false
+	for _, attempt := range attempts {
+		success := attempt.Success
+		activated = activated || success
+		events = append(events, HistoryEvent{
+			Time:    attempt.CreatedAt,
+			Type:    HistoryActivationAttempt,
+			IP:      attempt.IP,
+			Success: &success,
+			Detail:  attempt.ErrorMessage,
+		})
+	}
+
+	for _, check := range checks {
+		available := check.Available
+		events = append(events, HistoryEvent{
+			Time:    check.CreatedAt,
+			Type:    HistoryAvailabilityCheck,
+			IP:      check.IP,
+			Success: &available,
+		})
+	}
+
+	if card != nil {
+		if !seen[HistoryCreated] && card.CreatedAt != nil {
+			events = append(events, HistoryEvent{Time: *card.CreatedAt, Type: HistoryCreated, Detail: "由 " + card.UserName + " 创建"})
+		}
+		if !activated && card.UsedAt != nil {
+			success := true
+			events = append(events, HistoryEvent{Time: *card.UsedAt, Type: HistoryActivationAttempt, Success: &success, Detail: "设备 " + card.SEID})
+		}
+		if !seen[HistoryStatusChanged] && card.LockedAt != nil {
+			events = append(events, HistoryEvent{Time: *card.LockedAt, Type: HistoryStatusChanged, Detail: "已锁定"})
+		}
+		if !seen[HistoryDeleted] && card.DeletedAt != nil {
+			events = append(events, HistoryEvent{Time: *card.DeletedAt, Type: HistoryDeleted})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+// auditEventType 根据审计日志的操作和变化的字段确定事件类型
+func auditEventType(entry audit.Entry) string {
+	switch entry.Action {
+	case audit.ActionCardCreate, audit.ActionCardImport:
+		return HistoryCreated
+	case audit.ActionCardDelete:
+		return HistoryDeleted
+	case audit.ActionCardSetExpiredAt:
+		return HistoryExpiryChanged
+	}
+
+	var before, after map[string]any
+	_ = json.Unmarshal(entry.Before, &before)
+	_ = json.Unmarshal(entry.After, &after)
+	// 原来绑定了设备，修改后换成了其他设备
+	if seid, ok := after["seid"]; ok {
+		if old, _ := before["seid"].(string); old != "" && seid != nil && seid != "" {
+			return HistoryDeviceRebound
+		}
+	}
+	if _, ok := after["status"]; ok {
+		return HistoryStatusChanged
+	}
+	if _, ok := after["expired_at"]; ok && len(after) == 1 {
+		return HistoryExpiryChanged
+	}
+	if entry.Action == audit.ActionCardUpdateStatus {
+		return HistoryStatusChanged
+	}
+	return HistoryUpdated
+}
@@ -0,0 +1,75 @@
+package card
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"configuration-management/internal/biz/activationattempt"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/cardcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEventType(t *testing.T) {
+	cases := []struct {
+		action string
+		before string
+		after  string
+		want   string
+	}{
+		{audit.ActionCardCreate, `null`, `{"value":"x"}`, HistoryCreated},
+		{audit.ActionCardSetExpiredAt, `{"expired_at":null}`, `{"expired_at":"2026-01-01T00:00:00Z"}`, HistoryExpiryChanged},
+		{audit.ActionCardUpdate, `{"seid":"a"}`, `{"seid":"b"}`, HistoryDeviceRebound},
+		{audit.ActionCardUpdate, `{"seid":""}`, `{"seid":"b","status":2}`, HistoryStatusChanged},
+		{audit.ActionCardUpdate, `{"expired_at":null}`, `{"expired_at":"2026-01-01T00:00:00Z"}`, HistoryExpiryChanged},
+		{audit.ActionCardUpdate, `{"remark":"a"}`, `{"remark":"b"}`, HistoryUpdated},
+		{audit.ActionCardUpdateStatus, `{"status":1}`, `{"status":3}`, HistoryStatusChanged},
+		{audit.ActionCardDelete, `{"status":1}`, `{"status":4}`, HistoryDeleted},
+		{audit.ActionCardActivate, `{"seid":"","status":1}`, `{"seid":"b","status":2}`, HistoryStatusChanged},
+		{audit.ActionCardTransfer, `{"user_id":"a","user_name":"a"}`, `{"user_id":"b","user_name":"b"}`, HistoryUpdated},
+	}
+	for _, c := range cases {
+		got := auditEventType(audit.Entry{
+			Action: c.action,
+			Before: json.RawMessage(c.before),
+			After:  json.RawMessage(c.after),
+		})
+		assert.Equal(t, c.want, got, c.action+" "+c.after)
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	createdAt, usedAt, lockedAt := at(0), at(10), at(20)
+	card := &Card{Value: "x", UserName: "root", CreatedAt: &createdAt, UsedAt: &usedAt, LockedAt: &lockedAt}
+
+	entries := []audit.Entry{
+		{Action: audit.ActionCardUpdateStatus, ActorID: "u1", ActorName: "root", CreatedAt: at(20), After: json.RawMessage(`{"status":3}`)},
+	}
+	attempts := []activationattempt.ActivationAttempt{
+		{CardValue: "x", Success: false, ErrorMessage: "激活码已锁定", IP: "1.1.1.1", CreatedAt: at(30)},
+	}
+	checks := []cardcheck.CardCheck{
+		{CardValue: "x", Available: true, IP: "2.2.2.2", CreatedAt: at(5)},
+	}
+
+	events := buildHistory(card, entries, attempts, checks)
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	// 创建和激活没有对应的记录，由激活码上的时间补齐；锁定已有审计日志，不再重复
+	assert.Equal(t, []string{
+		HistoryCreated,
+		HistoryAvailabilityCheck,
+		HistoryActivationAttempt,
+		HistoryStatusChanged,
+		HistoryActivationAttempt,
+	}, types)
+	assert.Equal(t, "u1", events[3].Actor.ID)
+	assert.False(t, *events[4].Success)
+	assert.Equal(t, "1.1.1.1", events[4].IP)
+}
@@ -0,0 +1,306 @@
+package card
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/pkg/errcode"
+	"configuration-management/utils"
+)
+
+const (
+	// ImportMaxRows 一次最多导入的激活码数量
+	ImportMaxRows = 10000
+	// importChunkSize 每个事务提交的激活码数量
+	importChunkSize = 500
+)
+
+// importColumns 导入文件中可以识别的列
+var importColumns = []string{"value", "status", "days", "hours", "minutes", "time_type", "seid", "used_at", "remark"}
+
+// importTimeLayouts used_at 支持的时间格式，没有时区时按服务器时区解析
+var importTimeLayouts = []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02"}
+
+// ParseImportCSV 读取导入文件，第一行为表头
+// 表头可以是列名，也可以是导出文件的中英文表头，因此导出的文件可以直接导入
+func ParseImportCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errcode.InvalidParams.WithDetails("导入文件为空")
+	}
+	if err != nil {
+		return nil, errcode.InvalidParams.WithDetails("导入文件格式错误: " + err.Error())
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\xEF\xBB\xBF")
+		}
+		if key, ok := importColumnKey(name); ok {
+			if _, dup := index[key]; !dup {
+				index[key] = i
+			}
+		}
+	}
+	if _, ok := index["value"]; !ok {
+		return nil, errcode.InvalidParams.WithDetails("导入文件缺少激活码列 value")
+	}
+
+	rows := make([]ImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errcode.InvalidParams.WithDetails("导入文件格式错误: " + err.Error())
+		}
+		if len(rows) >= ImportMaxRows {
+			return nil, errcode.InvalidParams.WithDetails(fmt.Sprintf("一次最多导入 %d 个激活码", ImportMaxRows))
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseImportRecord(record, index, line))
+	}
+	return rows, nil
+}
+
+// importColumnKey 根据表头找到对应的列，不区分大小写
+func importColumnKey(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, key := range importColumns {
+		if strings.EqualFold(name, key) {
+			return key, true
+		}
+		column, _ := findExportColumn(key)
+		for _, header := range column.Headers {
+			if strings.EqualFold(name, header) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseImportRecord 把一行记录转换为 ImportRow，无法解析的字段记录在 err 中，校验时作为该行的错误返回
+func parseImportRecord(record []string, index map[string]int, line int) ImportRow {
+	field := func(key string) string {
+		i, ok := index[key]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(key string) (int, error) {
+		if field(key) == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(field(key))
+		if err != nil {
+			return 0, fmt.Errorf("%s 不是整数: %s", key, field(key))
+		}
+		return n, nil
+	}
+
+	row := ImportRow{
+		Line:     line,
+		Value:    field("value"),
+		TimeType: field("time_type"),
+		SEID:     field("seid"),
+		UsedAt:   field("used_at"),
+		Remark:   field("remark"),
+	}
+	var err error
+	if row.Status, err = parseImportStatus(field("status")); err != nil {
+		row.err = err.Error()
+		return row
+	}
+	if row.Days, err = number("days"); err != nil {
+		row.err = err.Error()
+		return row
+	}
+	if row.Hours, err = number("hours"); err != nil {
+		row.err = err.Error()
+		return row
+	}
+	if row.Minutes, err = number("minutes"); err != nil {
+		row.err = err.Error()
+	}
+	return row
+}
+
+// parseImportStatus 状态可以是数字，也可以是导出文件中的中英文状态名称
+func parseImportStatus(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if status, err := strconv.Atoi(s); err == nil {
+		return status, nil
+	}
+	for _, labels := range statusLabels {
+		for status, label := range labels {
+			if strings.EqualFold(s, label) {
+				return status, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("不支持的状态: %s", s)
+}
+
+// parseImportTime 解析使用时间，空字符串返回 nil
+func parseImportTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("无法解析使用时间: %s", s)
+}
+
+// buildImportCards 逐行校验要导入的激活码并生成记录，返回通过校验的激活码和每行的错误
+// existing 为数据库中已经存在的激活码，g 为 nil 时只检查应用设置中的时间类型和时长
+func buildImportCards(args ImportCardsArgs, app apps.App, g *grant.Grant, existing map[string]bool, batchID string, now time.Time) ([]Card, []ImportRowError) {
+	cards := make([]Card, 0, len(args.Rows))
+	rowErrors := make([]ImportRowError, 0)
+	seen := make(map[string]int, len(args.Rows))
+
+	for i, row := range args.Rows {
+		line := row.Line
+		if line == 0 {
+			line = i + 1
+		}
+		value := strings.TrimSpace(row.Value)
+		fail := func(message string) {
+			rowErrors = append(rowErrors, ImportRowError{Line: line, Value: value, Message: message})
+		}
+
+		if row.err != "" {
+			fail(row.err)
+			continue
+		}
+		if value == "" {
+			fail("激活码为空")
+			continue
+		}
+		if !utils.IsActivationKeyOfApp(value, app.CardPrefix, app.CardLength) {
+			fail(fmt.Sprintf("激活码不符合应用的格式: 前缀 %q，长度 %d 的大写字母或数字", app.CardPrefix, app.CardLength))
+			continue
+		}
+		if first, ok := seen[value]; ok {
+			fail(fmt.Sprintf("与第 %d 行重复", first))
+			continue
+		}
+		seen[value] = line
+		if existing[value] {
+			fail("激活码已存在")
+			continue
+		}
+
+		status := row.Status
+		if status == 0 {
+			status = StatusUnused
+		}
+		if status != StatusUnused && status != StatusUsed && status != StatusLocked {
+			fail(fmt.Sprintf("不支持的状态: %d", row.Status))
+			continue
+		}
+
+		// 没有填写时长时使用请求中的默认值
+		days, hours, minutes := row.Days, row.Hours, row.Minutes
+		if days == 0 && hours == 0 && minutes == 0 {
+			days, hours, minutes = args.Days, args.Hours, args.Minutes
+		}
+		if days < 0 || hours < 0 || minutes < 0 || DurationMinutes(days, hours, minutes) == 0 {
+			fail("有效时长必须大于 0")
+			continue
+		}
+		timeType := row.TimeType
+		if timeType == "" {
+			timeType = args.TimeType
+		}
+		if timeType == "" && len(app.TimeTypes) > 0 {
+			timeType = app.TimeTypes[0]
+		}
+		if timeType != "" && !IsTimeType(timeType) {
+			fail("不支持的时间类型: " + timeType)
+			continue
+		}
+		if !app.AllowTimeType(timeType) {
+			fail("应用不支持的时间类型: " + timeType)
+			continue
+		}
+		if !app.AllowDuration(DurationMinutes(days, hours, minutes)) {
+			fail("超过应用的最大有效时长")
+			continue
+		}
+		if g != nil && !g.AllowTimeType(timeType) {
+			fail("不允许的时间类型: " + timeType)
+			continue
+		}
+		if g != nil && !g.AllowDuration(DurationMinutes(days, hours, minutes)) {
+			fail("超过最大有效时长")
+			continue
+		}
+
+		usedAt, err := parseImportTime(strings.TrimSpace(row.UsedAt))
+		if err != nil {
+			fail(err.Error())
+			continue
+		}
+		seid := strings.TrimSpace(row.SEID)
+		if status == StatusUsed && usedAt == nil {
+			fail("已使用的激活码需要填写使用时间")
+			continue
+		}
+		if status == StatusUnused && (usedAt != nil || seid != "") {
+			fail("未使用的激活码不能有使用时间和设备")
+			continue
+		}
+
+		remark := row.Remark
+		if remark == "" {
+			remark = args.Remark
+		}
+		card := Card{
+			ID:        utils.GenerateUUID(),
+			AppID:     args.AppID,
+			Status:    status,
+			UserID:    args.UserID,
+			UserName:  args.UserName,
+			Days:      days,
+			Hours:     hours,
+			Minutes:   minutes,
+			TimeType:  timeType,
+			Value:     value,
+			SEID:      seid,
+			Remark:    remark,
+			BatchID:   batchID,
+			CreatedAt: &now,
+		}
+		// 使用过的激活码按使用时间计算过期时间，和激活时一致
+		if usedAt != nil {
+			expiredAt := usedAt.AddDate(0, 0, days).Add(time.Minute * time.Duration(minutes)).Add(time.Hour * time.Duration(hours))
+			card.Used = true
+			card.UsedAt = usedAt
+			card.ExpiredAt = &expiredAt
+		}
+		if status == StatusLocked {
+			card.LockedAt = &now
+		}
+		cards = append(cards, card)
+	}
+	return cards, rowErrors
+}
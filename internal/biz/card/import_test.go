@@ -0,0 +1,95 @@
+package card
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/grant"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	// 导出文件的英文表头，带 BOM
+	data := "\xEF\xBB\xBFCode,Status,Days,Used at,Device SEID,extra\n" +
+		"AB0001,Used,30,2024-05-01 08:30:00,SE1,x\n" +
+		"AB0002,,,,\n" +
+		"AB0003,2,abc,,\n"
+	rows, err := ParseImportCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if r := rows[0]; r.Line != 2 || r.Value != "AB0001" || r.Status != StatusUsed || r.Days != 30 || r.SEID != "SE1" || r.UsedAt != "2024-05-01 08:30:00" {
+		t.Fatalf("unexpected first row: %+v", r)
+	}
+	if r := rows[1]; r.Status != 0 || r.Days != 0 || r.err != "" {
+		t.Fatalf("unexpected second row: %+v", r)
+	}
+	if rows[2].err == "" {
+		t.Fatalf("expected parse error in third row")
+	}
+
+	if _, err := ParseImportCSV(strings.NewReader("status,days\n1,2\n")); err == nil {
+		t.Fatal("expected error without value column")
+	}
+}
+
+func TestBuildImportCards(t *testing.T) {
+	app := apps.App{ID: "a1", CardPrefix: "AB", CardLength: 4}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	args := ImportCardsArgs{
+		UserID:   "u1",
+		AppID:    "a1",
+		Days:     7,
+		TimeType: DailyTime,
+		Rows: []ImportRow{
+			{Value: "AB0001"},
+			{Value: "AB0002", Status: StatusUsed, Days: 1, UsedAt: "2024-05-01 08:00:00", SEID: "SE1"},
+			{Value: "ab0003"},
+			{Value: "AB0001"},
+			{Value: "AB0004"},
+			{Value: "AB0005", Status: StatusUsed},
+			{Value: "AB0006", SEID: "SE2"},
+			{Value: "AB0007", Status: StatusDeleted},
+			{Value: "AB0008", TimeType: "forever"},
+		},
+	}
+	cards, rowErrors := buildImportCards(args, app, nil, map[string]bool{"AB0004": true}, "b1", now)
+
+	if len(cards) != 2 {
+		t.Fatalf("unexpected cards: %+v", cards)
+	}
+	if c := cards[0]; c.Status != StatusUnused || c.Days != 7 || c.TimeType != DailyTime || c.BatchID != "b1" || c.UsedAt != nil {
+		t.Fatalf("unexpected unused card: %+v", c)
+	}
+	if c := cards[1]; !c.Used || c.ExpiredAt == nil || !c.ExpiredAt.Equal(time.Date(2024, 5, 2, 8, 0, 0, 0, time.Local)) {
+		t.Fatalf("unexpected used card: %+v", c)
+	}
+
+	lines := make([]int, 0, len(rowErrors))
+	for _, e := range rowErrors {
+		lines = append(lines, e.Line)
+	}
+	want := []int{3, 4, 5, 6, 7, 8, 9}
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected errors: %+v", rowErrors)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("unexpected errors: %+v", rowErrors)
+		}
+	}
+	if rowErrors[1].Message != "与第 1 行重复" {
+		t.Fatalf("unexpected duplicate message: %s", rowErrors[1].Message)
+	}
+
+	// 授权限制时间类型
+	g := &grant.Grant{TimeTypes: []string{HourlyTime}}
+	_, rowErrors = buildImportCards(ImportCardsArgs{Days: 1, TimeType: DailyTime, Rows: []ImportRow{{Value: "AB0001"}}}, app, g, nil, "b1", now)
+	if len(rowErrors) != 1 {
+		t.Fatalf("expected grant error, got %+v", rowErrors)
+	}
+}
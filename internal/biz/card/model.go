@@ -24,6 +24,7 @@ type Card struct {
 	UsedAt    *time.Time `json:"used_at" gorm:"default:NULL type:timestamp"`              // 使用时间
 	LockedAt  *time.Time `json:"locked_at" gorm:"default:NULL type:timestamp"`            // 锁定时间
 	Remark    string     `json:"remark"`                                                  // 备注信息
+	BatchID   string     `json:"batch_id" gorm:"default:NULL"`                            // 批次ID，同一次批量生成的激活码相同
 	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"default:NULL type:timestamp"` // 删除时间
 	CreatedAt *time.Time `json:"created_at"`                                              // 生成时间
 }
@@ -47,10 +48,16 @@ type CardView struct {
 	UsedAt    string `json:"used_at"`              // 使用时间
 	LockedAt  string `json:"locked_at"`            // 锁定时间
 	Remark    string `json:"remark"`               // 备注信息
+	BatchID   string `json:"batch_id"`             // 批次ID
 	DeletedAt string `json:"deleted_at,omitempty"` // 删除时间
 	CreatedAt string `json:"created_at"`           // 生成时间
 }
 
+// DurationMinutes 有效时长，单位为分钟
+func DurationMinutes(days, hours, minutes int) int {
+	return days*24*60 + hours*60 + minutes
+}
+
 // TableName 指定 Card 结构体对应的表名
 func (card *Card) TableName() string {
 	return "card"
@@ -101,6 +108,7 @@ func (card *Card) ToView(appOptions []apps.AppOption) CardView {
 		UsedAt:    usedAt,
 		LockedAt:  locakedAt,
 		Remark:    card.Remark,
+		BatchID:   card.BatchID,
 		DeletedAt: deletedAt,
 		CreatedAt: createdAt,
 	}
@@ -0,0 +1,70 @@
+package card
+
+import "sort"
+
+// holdsQuota 激活码是否占用生成时扣减的额度，只有已删除且从未使用的激活码退还了额度
+// 生成时的应用额度统计也使用同样的规则
+func holdsQuota(status int, neverUsed bool) bool {
+	return !(status == StatusDeleted && neverUsed)
+}
+
+// quotaGroup 按用户（或应用）、状态和是否使用过统计的激活码数量
+type quotaGroup struct {
+	UserId    string
+	AppId     string // 转移激活码时按应用统计
+	Status    int
+	NeverUsed bool
+	Count     int
+}
+
+// quotaDelta 一个用户的额度变化，正数为退还，负数为重新扣减
+type quotaDelta struct {
+	UserId string
+	Amount int
+}
+
+// quotaDeltas 计算激活码的状态都变为 newStatus 后每个用户的额度变化，按用户ID排序，保证锁定用户的顺序一致
+func quotaDeltas(groups []quotaGroup, newStatus int) []quotaDelta {
+	amounts := make(map[string]int)
+	for _, g := range groups {
+		held, holds := holdsQuota(g.Status, g.NeverUsed), holdsQuota(newStatus, g.NeverUsed)
+		switch {
+		case held && !holds:
+			amounts[g.UserId] += g.Count
+		case !held && holds:
+			amounts[g.UserId] -= g.Count
+		}
+	}
+
+	deltas := make([]quotaDelta, 0, len(amounts))
+	for userId, amount := range amounts {
+		if amount != 0 {
+			deltas = append(deltas, quotaDelta{UserId: userId, Amount: amount})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].UserId < deltas[j].UserId })
+	return deltas
+}
+
+// refundableCount 以后删除时会退还额度的激活码数量，即占用额度且从未使用的激活码
+// 转移激活码时这部分额度随激活码一起转移，否则接收方删除激活码时会凭空得到额度
+func refundableCount(groups []quotaGroup) int {
+	count := 0
+	for _, g := range groups {
+		if holdsQuota(g.Status, g.NeverUsed) && g.NeverUsed {
+			count += g.Count
+		}
+	}
+	return count
+}
+
+// heldByApp 每个应用占用额度的激活码数量，和生成激活码时统计应用额度的规则相同
+func heldByApp(groups []quotaGroup) map[string]int {
+	held := make(map[string]int)
+	for _, g := range groups {
+		if holdsQuota(g.Status, g.NeverUsed) {
+			held[g.AppId] += g.Count
+		}
+	}
+	return held
+}
@@ -0,0 +1,144 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuotaDeltas(t *testing.T) {
+	cases := []struct {
+		name      string
+		groups    []quotaGroup
+		newStatus int
+		want      []quotaDelta
+	}{
+		{
+			name:      "删除未使用的激活码退还额度",
+			groups:    []quotaGroup{{UserId: "u1", Status: StatusUnused, NeverUsed: true, Count: 3}},
+			newStatus: StatusDeleted,
+			want:      []quotaDelta{{UserId: "u1", Amount: 3}},
+		},
+		{
+			name:      "删除从未使用的锁定激活码退还额度",
+			groups:    []quotaGroup{{UserId: "u1", Status: StatusLocked, NeverUsed: true, Count: 2}},
+			newStatus: StatusDeleted,
+			want:      []quotaDelta{{UserId: "u1", Amount: 2}},
+		},
+		{
+			name: "删除使用过的激活码不退还额度",
+			groups: []quotaGroup{
+				{UserId: "u1", Status: StatusUsed, NeverUsed: false, Count: 2},
+				{UserId: "u1", Status: StatusLocked, NeverUsed: false, Count: 1},
+			},
+			newStatus: StatusDeleted,
+			want:      []quotaDelta{},
+		},
+		{
+			name:      "恢复退还过额度的激活码重新扣减",
+			groups:    []quotaGroup{{UserId: "u1", Status: StatusDeleted, NeverUsed: true, Count: 2}},
+			newStatus: StatusLocked,
+			want:      []quotaDelta{{UserId: "u1", Amount: -2}},
+		},
+		{
+			name:      "恢复使用过的激活码不扣减",
+			groups:    []quotaGroup{{UserId: "u1", Status: StatusDeleted, NeverUsed: false, Count: 2}},
+			newStatus: StatusUsed,
+			want:      []quotaDelta{},
+		},
+		{
+			name: "未删除的激活码之间变化不影响额度",
+			groups: []quotaGroup{
+				{UserId: "u1", Status: StatusUnused, NeverUsed: true, Count: 2},
+				{UserId: "u1", Status: StatusUsed, NeverUsed: false, Count: 1},
+			},
+			newStatus: StatusLocked,
+			want:      []quotaDelta{},
+		},
+		{
+			name: "按用户汇总并排序",
+			groups: []quotaGroup{
+				{UserId: "u2", Status: StatusUnused, NeverUsed: true, Count: 1},
+				{UserId: "u1", Status: StatusLocked, NeverUsed: true, Count: 1},
+				{UserId: "u1", Status: StatusUnused, NeverUsed: true, Count: 2},
+				{UserId: "u1", Status: StatusDeleted, NeverUsed: true, Count: 4},
+			},
+			newStatus: StatusDeleted,
+			want:      []quotaDelta{{UserId: "u1", Amount: 3}, {UserId: "u2", Amount: 1}},
+		},
+	}
+	for _, c := range cases {
+		if got := quotaDeltas(c.groups, c.newStatus); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: quotaDeltas() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+// TestQuotaDeltasRoundTrip 锁定后删除再恢复的激活码，退还和重新扣减的额度应当抵消
+func TestQuotaDeltasRoundTrip(t *testing.T) {
+	for _, status := range []int{StatusUnused, StatusLocked, StatusUsed} {
+		for _, neverUsed := range []bool{true, false} {
+			if status == StatusUsed && neverUsed {
+				continue
+			}
+			balance := 0
+			for _, d := range quotaDeltas([]quotaGroup{{UserId: "u1", Status: status, NeverUsed: neverUsed, Count: 1}}, StatusDeleted) {
+				balance += d.Amount
+			}
+			for _, d := range quotaDeltas([]quotaGroup{{UserId: "u1", Status: StatusDeleted, NeverUsed: neverUsed, Count: 1}}, status) {
+				balance += d.Amount
+			}
+			if balance != 0 {
+				t.Errorf("status %d neverUsed %v: balance after delete and restore = %d, want 0", status, neverUsed, balance)
+			}
+		}
+	}
+}
+
+func TestRefundableCount(t *testing.T) {
+	groups := []quotaGroup{
+		{AppId: "a1", Status: StatusUnused, NeverUsed: true, Count: 3},
+		{AppId: "a1", Status: StatusLocked, NeverUsed: true, Count: 2},
+		{AppId: "a1", Status: StatusLocked, NeverUsed: false, Count: 4},
+		{AppId: "a2", Status: StatusUsed, NeverUsed: false, Count: 5},
+		{AppId: "a2", Status: StatusDeleted, NeverUsed: true, Count: 6},
+		{AppId: "a2", Status: StatusDeleted, NeverUsed: false, Count: 7},
+	}
+	if got := refundableCount(groups); got != 5 {
+		t.Fatalf("refundableCount() = %d, want 5", got)
+	}
+}
+
+// TestTransferThenDeleteLockedCard 转移从未使用的锁定激活码后再删除，双方的额度都应当平衡
+func TestTransferThenDeleteLockedCard(t *testing.T) {
+	locked := []quotaGroup{{UserId: "from", Status: StatusLocked, NeverUsed: true, Count: 1}}
+	// 生成时 from 扣减了 1
+	balances := map[string]int{"from": -1}
+
+	refundable := refundableCount(locked)
+	balances["from"] += refundable
+	balances["to"] -= refundable
+
+	locked[0].UserId = "to"
+	for _, d := range quotaDeltas(locked, StatusDeleted) {
+		balances[d.UserId] += d.Amount
+	}
+	if balances["from"] != 0 || balances["to"] != 0 {
+		t.Fatalf("unexpected balances after transfer and delete: %v", balances)
+	}
+}
+
+// TestHeldByApp 转移时计入接收方应用额度的激活码，和生成时的统计规则相同
+func TestHeldByApp(t *testing.T) {
+	groups := []quotaGroup{
+		{AppId: "a1", Status: StatusUnused, NeverUsed: true, Count: 3},
+		{AppId: "a1", Status: StatusLocked, NeverUsed: true, Count: 2},
+		{AppId: "a1", Status: StatusDeleted, NeverUsed: true, Count: 6},
+		{AppId: "a2", Status: StatusUsed, NeverUsed: false, Count: 5},
+		{AppId: "a2", Status: StatusDeleted, NeverUsed: false, Count: 1},
+		{AppId: "a3", Status: StatusDeleted, NeverUsed: true, Count: 4},
+	}
+	want := map[string]int{"a1": 5, "a2": 6}
+	if got := heldByApp(groups); !reflect.DeepEqual(got, want) {
+		t.Fatalf("heldByApp() = %v, want %v", got, want)
+	}
+}
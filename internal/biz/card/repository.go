@@ -1,18 +1,33 @@
 package card
 
+import (
+	"time"
+
+	"configuration-management/pkg/app"
+)
+
 type Repository interface {
 	GetCardByID(id string) (Card, error)
 	GetCardByValue(value string) (Card, error)
 	GetCardsByUserId(userId string) ([]Card, error)
 	GetCards(args GetCardsArgs) (GetCardsResult, error)
-	DeleteCardByValue(value string, userId string) error
-	DeleteCardsByValues(values []string, userId string) error
+	// EachCard 使用游标逐行读取符合条件的激活码，按生成时间倒序
+	EachCard(args GetCardsArgs, fn func(card Card) error) error
+	DeleteCardByValue(value string, userId string, operatorId string) error
+	DeleteCardsByValues(values []string, userId string, operatorId string) error
 	CreateCard(card Card) (Card, error)
-	CreateCards(cards []Card) ([]Card, error)
+	CreateCards(cards []Card, appQuota int) ([]Card, error)
+	// GetExistingValues 返回 values 中已经存在的激活码，包括已删除的激活码
+	GetExistingValues(values []string) ([]string, error)
 	UpdateCard(card Card) error
+	UpdateCardStatus(card Card, operatorId string) error
 	DeleteCard(card Card) error
 	GetCardCountByUserIds(userIds []string) (map[string]CardCountByUser, error)
 	GetCardTotalCountByUserId(userId string) (int64, error)
 	BatchUpdateStatus(args BatchUpdateStatusArgs) error
-	GetCardCountByUserIdAndStatus(userId string) (map[int]int, error)
+	GetCardCountByUserIdAndStatus(userId string, subtreePath string) (map[int]int, error)
+	LockUnusedCardsByUserIds(userIds []string, actor app.Actor) (int64, error)
+	TransferCards(fromUserId string, toUserId string, toUserName string, actor app.Actor) (int64, error)
+	// GetExpiredCards 查询 (since, until] 内到期的已使用激活码
+	GetExpiredCards(since time.Time, until time.Time) ([]Card, error)
 }
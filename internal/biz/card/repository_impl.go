@@ -2,8 +2,14 @@ package card
 
 import (
 	"errors"
+	"time"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/cardstat"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/quota"
+	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
 
@@ -75,12 +81,11 @@ func (r *repository) GetCardsByUserId(userId string) ([]Card, error) {
 	return cards, nil
 }
 
-func (r *repository) GetCards(args GetCardsArgs) (GetCardsResult, error) {
-	var cards []Card
-	// 动态的构建查询条件
+// filterCards 根据查询条件动态的构建查询
+func (r *repository) filterCards(args GetCardsArgs) *gorm.DB {
 	db := r.db.Table((&Card{}).TableName())
 	if args.UserId != "" {
-		db = db.Where("user_id = ?", args.UserId)
+		db = r.scopeByOwner(db, args.UserId, args.SubtreePath)
 	}
 	if len(args.Values) > 0 {
 		db = db.Where("value IN (?)", args.Values)
@@ -104,6 +109,9 @@ func (r *repository) GetCards(args GetCardsArgs) (GetCardsResult, error) {
 	if args.SEID != "" {
 		db = db.Where("seid = ?", args.SEID)
 	}
+	if args.BatchID != "" {
+		db = db.Where("batch_id = ?", args.BatchID)
+	}
 	if !args.CreatedAtDateRange.StartTime.IsZero() {
 		db = db.Where("created_at >= ?", args.CreatedAtDateRange.StartTime.Format("2006-01-02 15:04:05"))
 	}
@@ -116,6 +124,31 @@ func (r *repository) GetCards(args GetCardsArgs) (GetCardsResult, error) {
 	if !args.UsedAtDateRange.EndTime.IsZero() {
 		db = db.Where("used_at <= ?", args.UsedAtDateRange.EndTime.Format("2006-01-02 15:04:05"))
 	}
+	return db
+}
+
+// EachCard 使用游标逐行读取符合条件的激活码，不会一次加载到内存中，fn 返回错误时停止
+func (r *repository) EachCard(args GetCardsArgs, fn func(card Card) error) error {
+	rows, err := r.filterCards(args).Order("created_at DESC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var card Card
+		if err := r.db.ScanRows(rows, &card); err != nil {
+			return err
+		}
+		if err := fn(card); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *repository) GetCards(args GetCardsArgs) (GetCardsResult, error) {
+	var cards []Card
+	db := r.filterCards(args)
 
 	// 打印 SQL 语句
 	db = db.Debug()
@@ -157,12 +190,23 @@ func (r *repository) GetCards(args GetCardsArgs) (GetCardsResult, error) {
 	}, nil
 }
 
-func (r *repository) DeleteCardByValue(value string, userId string) error {
-	db := r.db.Table((&Card{}).TableName()).Where("value = ?", value)
-	if userId != "" {
-		db = db.Where("user_id = ?", userId)
+func (r *repository) DeleteCardByValue(value string, userId string, operatorId string) error {
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Table((&Card{}).TableName()).Where("value = ?", value)
+		if userId != "" {
+			db = db.Where("user_id = ?", userId)
+		}
+		return db
 	}
-	if err := db.Unscoped().Delete(&Card{}).Error; err != nil {
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := settleQuota(tx, scope, StatusDeleted, operatorId); err != nil {
+			return err
+		}
+		if err := moveStats(tx, scope, nil); err != nil {
+			return err
+		}
+		return scope(tx).Unscoped().Delete(&Card{}).Error
+	}); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			global.Logger.WithFields(logger.Fields{
 				"value":  value,
@@ -187,7 +231,12 @@ func (r *repository) CreateCard(card Card) (Card, error) {
 	}
 
 	// 创建记录
-	if err := r.db.Create(&card).Error; err != nil {
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&card).Error; err != nil {
+			return err
+		}
+		return cardstat.NewRepository(tx).Apply(statsOf([]Card{card}))
+	}); err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"card": card,
 		}).Error("创建时记录失败", err)
@@ -197,10 +246,47 @@ func (r *repository) CreateCard(card Card) (Card, error) {
 	return card, nil
 }
 
-// CreateCards 根据数量批量创建 Card 记录
-func (r *repository) CreateCards(cards []Card) ([]Card, error) {
+// CreateCards 根据数量批量创建 Card 记录，并在同一个事务中扣减创建者的额度
+// appQuota 为创建者在该应用上最多可生成的激活码数量，grant.QuotaUnlimited 表示不限制
+func (r *repository) CreateCards(cards []Card, appQuota int) ([]Card, error) {
+	if len(cards) == 0 {
+		return cards, nil
+	}
+
 	// gorm 的批量创建
-	if err := r.db.Create(cards).Error; err != nil {
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定用户后检查余额，防止并发创建时超出额度
+		if err := quota.NewRepository(tx).Consume(quota.Entry{
+			UserID:    cards[0].UserID,
+			Amount:    -len(cards),
+			Type:      quota.TypeDebit,
+			Reason:    "生成激活码",
+			ActorID:   cards[0].UserID,
+			RelatedID: cards[0].AppID,
+		}); err != nil {
+			return err
+		}
+		// 用户行已经被锁定，这里的统计不会和并发的创建冲突
+		if appQuota != grant.QuotaUnlimited {
+			count, err := countHeldCards(tx, cards[0].UserID, cards[0].AppID)
+			if err != nil {
+				return err
+			}
+			if count+int64(len(cards)) > int64(appQuota) {
+				global.Logger.WithFields(logger.Fields{
+					"user_id":   cards[0].UserID,
+					"app_id":    cards[0].AppID,
+					"count":     count,
+					"app_quota": appQuota,
+				}).Info("应用额度不足")
+				return errcode.NoPermission.WithDetails("应用额度不足")
+			}
+		}
+		if err := tx.Create(cards).Error; err != nil {
+			return err
+		}
+		return cardstat.NewRepository(tx).Apply(statsOf(cards))
+	}); err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"cards": cards,
 		}).Error("创建时记录失败", err)
@@ -211,12 +297,40 @@ func (r *repository) CreateCards(cards []Card) ([]Card, error) {
 	return cards, nil
 }
 
+// GetExistingValues 分批查询已经存在的激活码，避免 IN 中的参数过多
+func (r *repository) GetExistingValues(values []string) ([]string, error) {
+	const batchSize = 1000
+	existing := make([]string, 0)
+	for start := 0; start < len(values); start += batchSize {
+		end := start + batchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		var batch []string
+		if err := r.db.Table((&Card{}).TableName()).
+			Where("value IN (?)", values[start:end]).
+			Pluck("value", &batch).Error; err != nil {
+			return nil, err
+		}
+		existing = append(existing, batch...)
+	}
+	return existing, nil
+}
+
 func (r *repository) UpdateCard(card Card) error {
 	cardMap := structs.Map(card)
 	if card.ExpiredAt != nil && !card.ExpiredAt.IsZero() {
 		cardMap["expired_at"] = card.ExpiredAt.Format("2006-01-02 15:04:05")
 	}
-	if err := r.db.Model(&card).Where("id = ?", card.ID).Updates(cardMap).Error; err != nil {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Table((&Card{}).TableName()).Where("id = ?", card.ID)
+	}
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := moveStats(tx, scope, withStatus(card.Status)); err != nil {
+			return err
+		}
+		return tx.Model(&card).Where("id = ?", card.ID).Updates(cardMap).Error
+	}); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			global.Logger.WithFields(logger.Fields{
 				"card": card,
@@ -229,8 +343,16 @@ func (r *repository) UpdateCard(card Card) error {
 }
 
 func (r *repository) DeleteCard(card Card) error {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Table((&Card{}).TableName()).Where("id = ?", card.ID)
+	}
 	// 根据 ID 进行删除
-	if err := r.db.Table((&Card{}).TableName()).Delete(&Card{}, card.ID).Error; err != nil {
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := moveStats(tx, scope, nil); err != nil {
+			return err
+		}
+		return tx.Table((&Card{}).TableName()).Delete(&Card{}, card.ID).Error
+	}); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil
 		}
@@ -239,13 +361,24 @@ func (r *repository) DeleteCard(card Card) error {
 	return nil
 }
 
-func (r *repository) DeleteCardsByValues(values []string, userId string) error {
-	db := r.db.Table((&Card{}).TableName()).Where("value IN (?)", values)
-	if userId != "" {
-		db = db.Where("user_id = ?", userId)
+func (r *repository) DeleteCardsByValues(values []string, userId string, operatorId string) error {
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Table((&Card{}).TableName()).Where("value IN (?)", values)
+		if userId != "" {
+			db = db.Where("user_id = ?", userId)
+		}
+		return db
 	}
 	// 根据 ID 进行删除，硬删除
-	if err := db.Unscoped().Delete(&Card{}).Error; err != nil {
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := settleQuota(tx, scope, StatusDeleted, operatorId); err != nil {
+			return err
+		}
+		if err := moveStats(tx, scope, nil); err != nil {
+			return err
+		}
+		return scope(tx).Unscoped().Delete(&Card{}).Error
+	}); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			global.Logger.WithFields(logger.Fields{
 				"values": values,
@@ -266,11 +399,11 @@ type CardCountByUser struct {
 	Deleted int64  `json:"deleted"`
 }
 
-// GetCardCountByUserIds 根据 user_ids 查询每个人的激活码总数以及各个状态的激活码数量
+// GetCardCountByUserIds 根据 user_ids 查询每个人的激活码总数以及各个状态的激活码数量，从 card_stat 统计表读取
 func (r *repository) GetCardCountByUserIds(userIds []string) (map[string]CardCountByUser, error) {
 	// 查询总数
 	var cardCountByUsers []CardCountByUser
-	if err := r.db.Table((&Card{}).TableName()).Select("user_id, COUNT(*) AS total, SUM(IF(status = 1, 1, 0)) AS unused, SUM(IF(status = 2, 1, 0)) AS used, SUM(IF(status = 3, 1, 0)) AS locked, SUM(IF(status = 4, 1, 0)) AS deleted").Where("user_id IN (?)", userIds).Group("user_id").Find(&cardCountByUsers).Error; err != nil {
+	if err := r.db.Table((&cardstat.Stat{}).TableName()).Select("user_id, SUM(count) AS total, SUM(IF(status = 1, count, 0)) AS unused, SUM(IF(status = 2, count, 0)) AS used, SUM(IF(status = 3, count, 0)) AS locked, SUM(IF(status = 4, count, 0)) AS deleted").Where("user_id IN (?)", userIds).Group("user_id").Find(&cardCountByUsers).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			global.Logger.WithFields(logger.Fields{
 				"user_ids": userIds,
@@ -305,7 +438,40 @@ func (r *repository) GetCardTotalCountByUserId(userId string) (int64, error) {
 
 // BatchUpdateStatus 批量跟新状态
 func (r *repository) BatchUpdateStatus(args BatchUpdateStatusArgs) error {
-	db := r.db.Table((&Card{}).TableName()).Where("value IN (?)", args.Values)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		scope := func(db *gorm.DB) *gorm.DB {
+			db = db.Table((&Card{}).TableName()).Where("value IN (?)", args.Values)
+			if args.UserId != "" {
+				db = db.Where("user_id = ?", args.UserId)
+			}
+			return db
+		}
+		// 有不属于授权应用的激活码时拒绝整批更新
+		if len(args.AppIDs) > 0 {
+			var count int64
+			if err := scope(tx).Where("app_id NOT IN (?)", args.AppIDs).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				global.Logger.WithFields(logger.Fields{
+					"args":  args,
+					"count": count,
+				}).Info("激活码不属于有权限的应用")
+				return errcode.NoPermission.WithDetails("激活码不属于有权限的应用")
+			}
+		}
+		if err := settleQuota(tx, scope, args.Status, args.Actor.ID); err != nil {
+			return err
+		}
+		if err := moveStats(tx, scope, withStatus(args.Status)); err != nil {
+			return err
+		}
+		return batchUpdateStatus(tx, args)
+	})
+}
+
+func batchUpdateStatus(tx *gorm.DB, args BatchUpdateStatusArgs) error {
+	db := tx.Table((&Card{}).TableName()).Where("value IN (?)", args.Values)
 
 	// 如果是更新为未使用，则重置使用时间、过期时间、IMEI、SEID
 	// 更新时需要更新时间
@@ -362,22 +528,22 @@ func (r *repository) BatchUpdateStatus(args BatchUpdateStatusArgs) error {
 	return nil
 }
 
-// GetCardCountByUserIdAndStatus 统计某个用户的已使用、未使用、已锁定、已删除的激活码数量
-func (r *repository) GetCardCountByUserIdAndStatus(userId string) (map[int]int, error) {
+// GetCardCountByUserIdAndStatus 统计某个用户（以及 subtreePath 下所有下级）的已使用、未使用、已锁定、已删除的激活码数量
+func (r *repository) GetCardCountByUserIdAndStatus(userId string, subtreePath string) (map[int]int, error) {
 	// SELECT
 	//    status,
-	//    COUNT(*) as status_count
+	//    SUM(count) as count
 	//FROM
-	//    card
+	//    card_stat
 	//GROUP BY
 	//    status;
 	var cardCountByStatus []struct {
 		Status int `json:"status"`
 		Count  int `json:"count"`
 	}
-	db := r.db.Table((&Card{}).TableName()).Select("status, COUNT(*) AS count")
+	db := r.db.Table((&cardstat.Stat{}).TableName()).Select("status, SUM(count) AS count")
 	if userId != "" {
-		db = db.Where("user_id = ?", userId)
+		db = r.scopeByOwner(db, userId, subtreePath)
 	}
 
 	if err := db.Group("status").Find(&cardCountByStatus).Error; err != nil {
@@ -396,3 +562,287 @@ func (r *repository) GetCardCountByUserIdAndStatus(userId string) (map[int]int,
 	}
 	return cardCountByStatusMap, nil
 }
+
+// scopeByOwner 限定查询某个用户的激活码，subtreePath 不为空时包含该用户所有下级的激活码
+// 下级通过 user 表 ancestry 字段的物化路径前缀查找
+func (r *repository) scopeByOwner(db *gorm.DB, userId string, subtreePath string) *gorm.DB {
+	return ScopeByOwner(r.db, db, userId, subtreePath)
+}
+
+// ScopeByOwner 限定为 userId 以及 subtreePath 下所有下级用户的激活码，conn 用于构造子查询
+func ScopeByOwner(conn *gorm.DB, db *gorm.DB, userId string, subtreePath string) *gorm.DB {
+	if subtreePath == "" {
+		return db.Where("user_id = ?", userId)
+	}
+	subUsers := conn.Table("user").Select("id").
+		Where("ancestry = ? OR ancestry LIKE ?", subtreePath, subtreePath+"/%")
+	return db.Where("(user_id = ? OR user_id IN (?))", userId, subUsers)
+}
+
+// UpdateCardStatus 更新激活码（包括状态），状态变化时在同一个事务中结算额度
+func (r *repository) UpdateCardStatus(card Card, operatorId string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		scope := func(db *gorm.DB) *gorm.DB {
+			return db.Table((&Card{}).TableName()).Where("id = ?", card.ID)
+		}
+		if err := settleQuota(tx, scope, card.Status, operatorId); err != nil {
+			return err
+		}
+		return NewRepository(tx).UpdateCard(card)
+	})
+}
+
+// settleQuota 激活码状态变化前结算额度，scope 用于限定受影响的激活码
+// 从未使用的激活码被删除时退还额度，不论删除前是未使用还是已锁定；退还过额度的激活码恢复时重新扣减额度
+func settleQuota(tx *gorm.DB, scope func(db *gorm.DB) *gorm.DB, newStatus int, operatorId string) error {
+	var groups []quotaGroup
+	if err := scope(tx).Select("user_id, status, used_at IS NULL AS never_used, COUNT(*) AS count").
+		Group("user_id, status, never_used").Find(&groups).Error; err != nil {
+		return err
+	}
+
+	quotaRepo := quota.NewRepository(tx)
+	refunds := make([]quota.Entry, 0)
+	for _, d := range quotaDeltas(groups, newStatus) {
+		if d.Amount > 0 {
+			refunds = append(refunds, quota.Entry{
+				UserID:  d.UserId,
+				Amount:  d.Amount,
+				Type:    quota.TypeRefund,
+				Reason:  "删除未使用的激活码",
+				ActorID: operatorId,
+			})
+			continue
+		}
+		if err := quotaRepo.Consume(quota.Entry{
+			UserID:  d.UserId,
+			Amount:  d.Amount,
+			Type:    quota.TypeDebit,
+			Reason:  "恢复已删除的激活码",
+			ActorID: operatorId,
+		}); err != nil {
+			return err
+		}
+	}
+	return quotaRepo.CreateEntries(refunds)
+}
+
+// LockUnusedCardsByUserIds 把这些用户所有未使用的激活码锁定，返回锁定的数量，每个激活码写入一条审计日志
+func (r *repository) LockUnusedCardsByUserIds(userIds []string, actor app.Actor) (int64, error) {
+	if len(userIds) == 0 {
+		return 0, nil
+	}
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Table((&Card{}).TableName()).Where("user_id IN (?) AND status = ?", userIds, StatusUnused)
+	}
+	var locked int64
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		var before []Card
+		if err := scope(tx).Find(&before).Error; err != nil {
+			return err
+		}
+		if err := moveStats(tx, scope, withStatus(StatusLocked)); err != nil {
+			return err
+		}
+		result := tx.Exec(`UPDATE card SET status = ?, locked_at = NOW() WHERE user_id IN (?) AND status = ?`,
+			StatusLocked, userIds, StatusUnused)
+		if result.Error != nil {
+			return result.Error
+		}
+		locked = result.RowsAffected
+
+		now := time.Now()
+		after := make([]Card, 0, len(before))
+		for _, c := range before {
+			c.Status = StatusLocked
+			c.LockedAt = &now
+			after = append(after, c)
+		}
+		return recordCardChanges(tx, actor, audit.ActionCardUpdateStatus, before, after)
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_ids": userIds,
+		}).Error("锁定未使用的激活码失败", err)
+		return 0, err
+	}
+	return locked, nil
+}
+
+// TransferCards 把 fromUserId 的所有激活码转移给 toUserId，每个激活码写入一条审计日志
+// 从未使用且没有删除的激活码（包括已锁定的）连同额度一起转移：退还 fromUserId 的额度，并扣减 toUserId 的额度
+// 接收方在某个应用上有授权时，转移后的激活码数量不能超过授权的额度
+// 需要传入事务，激活码、额度和审计日志一起提交或回滚
+func (r *repository) TransferCards(fromUserId string, toUserId string, toUserName string, actor app.Actor) (int64, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Table((&Card{}).TableName()).Where("user_id = ?", fromUserId)
+	}
+	var groups []quotaGroup
+	if err := scope(r.db).Select("app_id, status, used_at IS NULL AS never_used, COUNT(*) AS count").
+		Group("app_id, status, never_used").Find(&groups).Error; err != nil {
+		return 0, err
+	}
+	if refundable := refundableCount(groups); refundable > 0 {
+		quotaRepo := quota.NewRepository(r.db)
+		if err := quotaRepo.CreateEntries([]quota.Entry{{
+			UserID:    fromUserId,
+			Amount:    refundable,
+			Type:      quota.TypeRefund,
+			Reason:    "转移从未使用的激活码",
+			ActorID:   actor.ID,
+			RelatedID: toUserId,
+		}}); err != nil {
+			return 0, err
+		}
+		if err := quotaRepo.Consume(quota.Entry{
+			UserID:    toUserId,
+			Amount:    -refundable,
+			Type:      quota.TypeDebit,
+			Reason:    "接收转移的激活码",
+			ActorID:   actor.ID,
+			RelatedID: fromUserId,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := checkTransferAppQuotas(r.db, toUserId, heldByApp(groups)); err != nil {
+		return 0, err
+	}
+
+	if err := moveStats(r.db, scope, func(stat *cardstat.Stat) {
+		stat.UserID = toUserId
+	}); err != nil {
+		return 0, err
+	}
+
+	var before []Card
+	if err := scope(r.db).Find(&before).Error; err != nil {
+		return 0, err
+	}
+	result := scope(r.db).Updates(map[string]interface{}{"user_id": toUserId, "user_name": toUserName})
+	if result.Error != nil {
+		global.Logger.WithFields(logger.Fields{
+			"from_user_id": fromUserId,
+			"to_user_id":   toUserId,
+		}).Error("转移激活码失败", result.Error)
+		return 0, result.Error
+	}
+
+	after := make([]Card, 0, len(before))
+	for _, c := range before {
+		c.UserID = toUserId
+		c.UserName = toUserName
+		after = append(after, c)
+	}
+	if err := recordCardChanges(r.db, actor, audit.ActionCardTransfer, before, after); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected, nil
+}
+
+// countHeldCards 用户在该应用上占用额度的激活码数量，已删除且从未使用的激活码退还了额度，不计入
+func countHeldCards(tx *gorm.DB, userId string, appId string) (int64, error) {
+	var count int64
+	err := tx.Table((&Card{}).TableName()).
+		Where("user_id = ? AND app_id = ?", userId, appId).
+		Where("NOT (status = ? AND used_at IS NULL)", StatusDeleted).
+		Count(&count).Error
+	return count, err
+}
+
+// checkTransferAppQuotas 接收转移的激活码后，用户在每个应用上的激活码数量不能超过授权的额度
+// held 为每个应用要转移过来的占用额度的激活码数量，和 CreateCards 的检查相同
+func checkTransferAppQuotas(tx *gorm.DB, userId string, held map[string]int) error {
+	grantRepo := grant.NewRepository(tx)
+	for appId, n := range held {
+		g, err := grantRepo.GetGrant(userId, appId)
+		if errors.Is(err, errcode.NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if g.Quota == grant.QuotaUnlimited {
+			continue
+		}
+		count, err := countHeldCards(tx, userId, appId)
+		if err != nil {
+			return err
+		}
+		if count+int64(n) > int64(g.Quota) {
+			global.Logger.WithFields(logger.Fields{
+				"user_id":   userId,
+				"app_id":    appId,
+				"count":     count,
+				"transfer":  n,
+				"app_quota": g.Quota,
+			}).Info("接收方的应用额度不足")
+			return errcode.NoPermission.WithDetails("接收方在应用 " + appId + " 上的额度不足")
+		}
+	}
+	return nil
+}
+
+// GetExpiredCards 查询 (since, until] 内到期的已使用激活码
+func (r *repository) GetExpiredCards(since time.Time, until time.Time) ([]Card, error) {
+	cards := make([]Card, 0)
+	if err := r.db.Table((&Card{}).TableName()).
+		Where("status = ? AND expired_at > ? AND expired_at <= ?", StatusUsed, since, until).
+		Find(&cards).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"since": since,
+			"until": until,
+		}).Error("查询到期的激活码失败", err)
+		return nil, err
+	}
+	return cards, nil
+}
+
+// moveStats 激活码变化前更新统计，scope 用于限定受影响的激活码
+// move 修改激活码所在的统计行，为 nil 时表示激活码被删除
+func moveStats(tx *gorm.DB, scope func(db *gorm.DB) *gorm.DB, move func(stat *cardstat.Stat)) error {
+	var rows []cardstat.Stat
+	if err := scope(tx).
+		Select("user_id, COALESCE(app_id, '') AS app_id, DATE(created_at) AS day, status, COUNT(*) AS count").
+		Group("user_id, COALESCE(app_id, ''), DATE(created_at), status").
+		Find(&rows).Error; err != nil {
+		return err
+	}
+
+	deltas := make([]cardstat.Stat, 0, 2*len(rows))
+	for _, row := range rows {
+		removed := row
+		removed.Count = -row.Count
+		deltas = append(deltas, removed)
+		if move != nil {
+			move(&row)
+			deltas = append(deltas, row)
+		}
+	}
+	return cardstat.NewRepository(tx).Apply(deltas)
+}
+
+// withStatus 把激活码移到 status 对应的统计行
+func withStatus(status int) func(stat *cardstat.Stat) {
+	return func(stat *cardstat.Stat) {
+		stat.Status = status
+	}
+}
+
+// statsOf 新创建的激活码对应的统计增量
+func statsOf(cards []Card) []cardstat.Stat {
+	deltas := make([]cardstat.Stat, 0, len(cards))
+	for _, card := range cards {
+		createdAt := time.Now()
+		if card.CreatedAt != nil {
+			createdAt = *card.CreatedAt
+		}
+		deltas = append(deltas, cardstat.Stat{
+			UserID: card.UserID,
+			AppID:  card.AppID,
+			Day:    cardstat.Day(createdAt),
+			Status: card.Status,
+			Count:  1,
+		})
+	}
+	return deltas
+}
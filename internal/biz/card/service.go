@@ -1,21 +1,25 @@
 package card
 
 import (
+	"io"
 	"time"
 
 	"configuration-management/internal/biz/common"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/setting"
 )
 
 type CreateCardArgs struct {
 	UserID string `json:"user_id"` // 用户ID，关联到用户表中的id字段
 	IMEI   string `json:"imei"`    // 使用的设备IMEI
 	Days   int    `json:"days"`    // 有效天数
+
+	Actor app.Actor `json:"-"` // 操作人，写入审计日志
 }
 
 type CreateCardsArgs struct {
 	UserID   string `json:"user_id"`   // 用户ID，关联到用户表中的id字段
 	UserName string `json:"user_name"` // 用户ID，关联到用户表中的id字段
-	MaxCnt   int    `json:"max_cnt"`   // 最大生成数量
 	Minutes  int    `json:"minutes"`   // 有效分钟数
 	Hours    int    `json:"hours"`     // 有效小时数
 	Days     int    `json:"days"`      // 有效天数
@@ -23,25 +27,80 @@ type CreateCardsArgs struct {
 	Remark   string `json:"remark"`    // 备注信息
 	Count    int    `json:"count"`     // 生成数量
 	AppID    string `json:"app_id"`    // 应用ID
+
+	CheckGrant bool      `json:"check_grant"` // 是否检查应用授权，root 不检查
+	Apps       []string  `json:"apps"`        // 创建者有权限的应用
+	Actor      app.Actor `json:"-"`           // 操作人，写入审计日志
+}
+
+// ImportRow 导入的一行激活码，状态和时长为空时使用默认值
+type ImportRow struct {
+	Line     int    `json:"-"`         // 在导入文件中的行号，JSON 导入时为序号
+	Value    string `json:"value"`     // 激活码值
+	Status   int    `json:"status"`    // 状态: 1-未使用, 2-已使用, 3-已锁定，为空时为未使用
+	Minutes  int    `json:"minutes"`   // 有效分钟数
+	Hours    int    `json:"hours"`     // 有效小时数
+	Days     int    `json:"days"`      // 有效天数
+	TimeType string `json:"time_type"` // 时间类型
+	SEID     string `json:"seid"`      // 使用的设备SEID
+	UsedAt   string `json:"used_at"`   // 使用时间，已使用的激活码必填
+	Remark   string `json:"remark"`    // 备注信息
+
+	err string // 解析导入文件时的错误
+}
+
+type ImportCardsArgs struct {
+	UserID   string      `json:"user_id"`   // 导入人，导入的激活码属于该用户
+	UserName string      `json:"user_name"` // 导入人的用户名
+	AppID    string      `json:"app_id"`    // 应用ID
+	Rows     []ImportRow `json:"rows"`      // 要导入的激活码
+	DryRun   bool        `json:"dry_run"`   // 只校验不导入
+	Minutes  int         `json:"minutes"`   // 默认有效分钟数
+	Hours    int         `json:"hours"`     // 默认有效小时数
+	Days     int         `json:"days"`      // 默认有效天数
+	TimeType string      `json:"time_type"` // 默认时间类型
+	Remark   string      `json:"remark"`    // 默认备注信息
+
+	CheckGrant bool      `json:"check_grant"` // 是否检查应用授权，root 不检查
+	Apps       []string  `json:"apps"`        // 导入人有权限的应用
+	Actor      app.Actor `json:"-"`           // 操作人，写入审计日志
+}
+
+// ImportRowError 没有通过校验的一行
+type ImportRowError struct {
+	Line    int    `json:"line"`    // 行号
+	Value   string `json:"value"`   // 激活码值
+	Message string `json:"message"` // 错误原因
+}
+
+type ImportCardsResult struct {
+	Total    int              `json:"total"`    // 总行数
+	Valid    int              `json:"valid"`    // 通过校验的行数
+	Imported int              `json:"imported"` // 已导入的数量
+	DryRun   bool             `json:"dry_run"`  // 是否只校验
+	BatchID  string           `json:"batch_id"` // 导入的激活码的批次ID
+	Errors   []ImportRowError `json:"errors"`   // 没有通过校验的行
 }
 
 type UpdateCardArgs struct {
-	UserId        string `json:"user_id"`         // 用户ID，关联到用户表中的id字段
-	CurrentCard   Card   `json:"current_card"`    // 当前卡信息
-	ID            string `json:"id"`              // 激活码唯一标识符(UUID)
-	Status        int    `json:"status"`          // 状态: 0-未使用, 1-已使用, 2-已锁定, 3-已删除
-	Minutes       int    `json:"minutes"`         // 有效分钟数
-	Hours         int    `json:"hours"`           // 有效小时数
-	Days          int    `json:"days"`            // 有效天数
-	TimeType      string `json:"time_type"`       // 时间类型
-	IMEI          string `json:"imei"`            // 使用的设备IMEI
-	SEID          string `json:"seid"`            // 使用的设备SEID
-	Remark        string `json:"remark"`          // 备注信息
-	KeepExpiredAt bool   `json:"keep_expired_at"` // 是否保留过期时间
+	UserId        string    `json:"user_id"`         // 用户ID，关联到用户表中的id字段
+	Actor         app.Actor `json:"-"`               // 操作人，写入审计日志
+	CurrentCard   Card      `json:"current_card"`    // 当前卡信息
+	ID            string    `json:"id"`              // 激活码唯一标识符(UUID)
+	Status        int       `json:"status"`          // 状态: 0-未使用, 1-已使用, 2-已锁定, 3-已删除
+	Minutes       int       `json:"minutes"`         // 有效分钟数
+	Hours         int       `json:"hours"`           // 有效小时数
+	Days          int       `json:"days"`            // 有效天数
+	TimeType      string    `json:"time_type"`       // 时间类型
+	IMEI          string    `json:"imei"`            // 使用的设备IMEI
+	SEID          string    `json:"seid"`            // 使用的设备SEID
+	Remark        string    `json:"remark"`          // 备注信息
+	KeepExpiredAt bool      `json:"keep_expired_at"` // 是否保留过期时间
 }
 
 type GetCardsArgs struct {
 	UserId             string           `json:"user_id"`               // 用户ID，关联到用户表中的id字段
+	SubtreePath        string           `json:"subtree_path"`          // 不为空时同时查询该路径下所有下级用户的激活码
 	Values             []string         `json:"values"`                // 激活码值
 	Status             []int            `json:"status"`                // 状态: 0-未使用, 1-已使用, 2-已锁定, 3-已删除
 	Remark             string           `json:"remark"`                // 备注信息
@@ -50,6 +109,7 @@ type GetCardsArgs struct {
 	TimeType           string           `json:"time_type"`             // 时间类型
 	UserName           string           `json:"user_name"`             // 用户ID，关联到用户表中的id字段
 	SEID               string           `json:"seid"`                  // 使用的设备SEID
+	BatchID            string           `json:"batch_id"`              // 批次ID
 	CreatedAtDateRange common.TimeRange `json:"created_at_date_range"` // 创建时间范围
 	UsedAtDateRange    common.TimeRange `json:"used_at_date_range"`    // 使用时间范围
 	Page               int              `json:"page"`                  // 页码
@@ -62,16 +122,19 @@ type GetCardsResult struct {
 }
 
 type BatchUpdateStatusArgs struct {
-	UserId string   `json:"user_id"`                              // 用户ID，关联到用户表中的id字段
-	Values []string `json:"values"`                               // 激活码值
-	Status int      `json:"status"`                               // 状态: 0-未使用, 2-已锁定, 3-已删除
-	IMEI   string   `json:"imei"`                                 // 使用的设备IMEI
-	SEID   string   `json:"seid" gorm:"default:NULL;Column:seid"` // 使用的设备SEID
+	UserId string    `json:"user_id"`                              // 用户ID，关联到用户表中的id字段
+	Actor  app.Actor `json:"-"`                                    // 操作人，写入审计日志
+	Values []string  `json:"values"`                               // 激活码值
+	Status int       `json:"status"`                               // 状态: 0-未使用, 2-已锁定, 3-已删除
+	IMEI   string    `json:"imei"`                                 // 使用的设备IMEI
+	SEID   string    `json:"seid" gorm:"default:NULL;Column:seid"` // 使用的设备SEID
+	AppIDs []string  `json:"app_ids"`                              // 不为空时只能更新这些应用的激活码
 }
 
 type ActivateCardArgs struct {
-	Value string `json:"value"` // 激活码值
-	SEID  string `json:"seid"`  // 使用的设备SEID
+	Value string    `json:"value"` // 激活码值
+	SEID  string    `json:"seid"`  // 使用的设备SEID
+	Actor app.Actor `json:"-"`     // 发起激活的请求方，写入审计日志
 }
 
 type CheckCardStatusArgs struct {
@@ -83,27 +146,71 @@ type SetCardExpiredAtArgs struct {
 	Value        string    `json:"value"`          // 激活码值
 	UserId       string    `json:"user_id"`        // 用户ID，关联到用户表中的id字段
 	NewExpiredAt time.Time `json:"new_expired_at"` // 新的过期时间
+	Actor        app.Actor `json:"-"`              // 操作人，写入审计日志
 }
 
 type CheckAvailabilityArgs struct {
 	CardValue string `json:"card_value"` // 激活码值
 }
 
+type GetCardHistoryArgs struct {
+	Value       string   `json:"value"`        // 激活码值
+	UserId      string   `json:"user_id"`      // 查询者ID，为空时不限制
+	SubtreePath string   `json:"subtree_path"` // 查询者的下级路径
+	AppIDs      []string `json:"app_ids"`      // 查询者有权限的应用，为空时不限制
+}
+
+// RenderCardSheetArgs 打印激活码卡片，Filter 中的分页参数不生效
+type RenderCardSheetArgs struct {
+	Filter GetCardsArgs `json:"filter"` // 查询条件
+	Layout string       `json:"layout"` // 版式名称，为空时使用默认版式
+}
+
+type GetCardQRCodeArgs struct {
+	Value       string   `json:"value"`        // 激活码值
+	Size        int      `json:"size"`         // 图片边长，单位为像素
+	UserId      string   `json:"user_id"`      // 查询者ID，为空时不限制
+	SubtreePath string   `json:"subtree_path"` // 查询者的下级路径
+	AppIDs      []string `json:"app_ids"`      // 查询者有权限的应用，为空时不限制
+}
+
+// ExportCardsArgs 导出激活码，Filter 中的分页参数不生效
+type ExportCardsArgs struct {
+	Filter  GetCardsArgs `json:"filter"`  // 查询条件
+	Format  string       `json:"format"`  // 文件格式: csv, xlsx
+	Columns []string     `json:"columns"` // 导出的列，为空时使用 DefaultExportColumns
+	Locale  string       `json:"locale"`  // 表头和状态使用的语言: zh-CN, en-US
+}
+
 type Service interface {
 	GetCardByID(id string) (Card, error)
 	GetCardByValue(value string) (Card, error)
 	GetCardsByUserId(userId string) ([]Card, error)
 	GetCards(args GetCardsArgs) (GetCardsResult, error)
-	DeleteCardByValue(value string, userId string) error
+	// ExportCards 逐行写入导出文件，返回导出的激活码数量
+	ExportCards(args ExportCardsArgs, w io.Writer) (int, error)
+	// RenderCardSheet 把激活码按版式排版为可打印的 PDF，返回激活码数量
+	RenderCardSheet(args RenderCardSheetArgs, w io.Writer) (int, error)
+	// GetCardQRCode 生成激活码兑换链接的二维码 PNG 图片
+	GetCardQRCode(args GetCardQRCodeArgs) ([]byte, error)
+	// GetSheetLayouts 返回可用的打印版式，第一个为默认版式
+	GetSheetLayouts() []setting.CardSheetLayout
+	DeleteCardByValue(value string, userId string, actor app.Actor) error
 	CreateCard(args CreateCardArgs) (Card, error)
 	CreateCards(args CreateCardsArgs) ([]Card, error)
+	// ImportCards 导入外部生成的激活码，有任何一行没有通过校验时不导入，通过校验后分批提交
+	ImportCards(args ImportCardsArgs) (ImportCardsResult, error)
 	UpdateCard(args UpdateCardArgs) error
-	DeleteCard(card Card) error
-	DeleteCardsByValues(values []string, userId string) error
+	DeleteCard(card Card, actor app.Actor) error
+	DeleteCardsByValues(values []string, userId string, actor app.Actor) error
 	BatchUpdateStatus(args BatchUpdateStatusArgs) error
-	GetCardCountByUserIdAndStatus(userId string) (map[int]int, error)
+	GetCardCountByUserIdAndStatus(userId string, subtreePath string) (map[int]int, error)
+	// PublishExpiredCards 为 (since, until] 内到期的已使用激活码产生 webhook 到期事件
+	PublishExpiredCards(since time.Time, until time.Time) (int, error)
 	CheckCardStatus(args CheckCardStatusArgs) (bool, error)
 	ActivateCard(args ActivateCardArgs) (Card, error)
 	SetCardExpiredAt(args SetCardExpiredAtArgs) error
 	CheckAvailability(args CheckAvailabilityArgs) (bool, error)
+	// GetCardHistory 获取激活码从创建开始按时间排序的所有事件
+	GetCardHistory(args GetCardHistoryArgs) ([]HistoryEvent, error)
 }
@@ -2,16 +2,29 @@ package card
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"time"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/activationattempt"
 	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/cardcheck"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/webhook"
+	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
+	"configuration-management/pkg/setting"
+	"configuration-management/pkg/spreadsheet"
 	"configuration-management/utils"
 
 	"github.com/patrickmn/go-cache"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
 )
 
 var (
@@ -23,9 +36,25 @@ var (
 	checkCardStatusCacheTTL = time.Minute * 5 // 检查激活码状态缓存过期时间(5分钟内更新一次)
 )
 
+// cardStatus 检查激活码状态的缓存，应用的状态不缓存，停用后立即生效
+type cardStatus struct {
+	available bool
+	appID     string
+}
+
 type service struct {
-	repo    Repository
-	appRepo apps.Repository
+	db          *gorm.DB
+	repo        Repository
+	appRepo     apps.Repository
+	appService  apps.Service
+	grantRepo   grant.Repository
+	auditRepo   audit.Repository
+	attemptRepo activationattempt.Repository
+	checkRepo   cardcheck.Repository
+
+	redeemURL     string                    // 二维码中的兑换链接模板
+	maxSheetCards int                       // 一次最多打印的激活码数量
+	sheetLayouts  []setting.CardSheetLayout // 默认版式和配置中的版式
 }
 
 func NewService() Service {
@@ -33,9 +62,30 @@ func NewService() Service {
 		activateCache = *cache.New(activateCacheTTL, activateCacheTTL)
 		checkCardStatusCache = *cache.New(checkCardStatusCacheTTL, checkCardStatusCacheTTL)
 	})
+	redeemURL, maxSheetCards := DefaultRedeemURL, defaultMaxSheetCards
+	var layouts []setting.CardSheetLayout
+	if cfg := global.CardSheetSetting; cfg != nil {
+		if cfg.RedeemURL != "" {
+			redeemURL = cfg.RedeemURL
+		}
+		if cfg.MaxCards > 0 {
+			maxSheetCards = cfg.MaxCards
+		}
+		layouts = cfg.Layouts
+	}
 	return &service{
-		repo:    NewRepository(global.DBEngine),
-		appRepo: apps.NewRepository(),
+		db:          global.DBEngine,
+		repo:        NewRepository(global.DBEngine),
+		appRepo:     apps.NewRepository(global.DBEngine),
+		appService:  apps.NewService(),
+		grantRepo:   grant.NewRepository(global.DBEngine),
+		auditRepo:   audit.NewRepository(global.DBEngine),
+		attemptRepo: activationattempt.NewRepository(global.DBEngine),
+		checkRepo:   cardcheck.NewRepository(global.DBEngine),
+
+		redeemURL:     redeemURL,
+		maxSheetCards: maxSheetCards,
+		sheetLayouts:  mergeSheetLayouts(layouts),
 	}
 }
 
@@ -55,8 +105,143 @@ func (s *service) GetCards(args GetCardsArgs) (GetCardsResult, error) {
 	return s.repo.GetCards(args)
 }
 
-func (s *service) DeleteCardByValue(value string, userId string) error {
-	return s.repo.DeleteCardByValue(value, userId)
+func (s *service) ExportCards(args ExportCardsArgs, w io.Writer) (int, error) {
+	if err := CheckExportArgs(args); err != nil {
+		return 0, err
+	}
+	layout, err := newExportLayout(args.Columns, args.Locale)
+	if err != nil {
+		return 0, err
+	}
+	writer, err := spreadsheet.New(args.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	appNames, err := s.getAppNames()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writer.Write(layout.header()); err != nil {
+		return 0, err
+	}
+	count := 0
+	if err := s.repo.EachCard(args.Filter, func(card Card) error {
+		count++
+		return writer.Write(layout.row(card, appNames))
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"args":  args,
+			"count": count,
+		}).Error("导出激活码失败", err)
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+func (s *service) RenderCardSheet(args RenderCardSheetArgs, w io.Writer) (int, error) {
+	layout, err := findSheetLayout(s.sheetLayouts, args.Layout)
+	if err != nil {
+		return 0, err
+	}
+	renderer, err := newSheetRenderer(layout, s.redeemURL)
+	if err != nil {
+		return 0, err
+	}
+	appNames, err := s.getAppNames()
+	if err != nil {
+		return 0, err
+	}
+
+	// 整个文件生成后才写入，超过数量上限时不会输出不完整的文件
+	count := 0
+	if err := s.repo.EachCard(args.Filter, func(card Card) error {
+		count++
+		if count > s.maxSheetCards {
+			return errcode.InvalidParams.WithDetails(fmt.Sprintf("一次最多打印 %d 个激活码", s.maxSheetCards))
+		}
+		return renderer.Add(newSheetCard(card, appNames[card.AppID]))
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"args":  args,
+			"count": count,
+		}).Error("打印激活码失败", err)
+		return 0, err
+	}
+	if count == 0 {
+		return 0, errcode.CardNotFound
+	}
+	if _, err := renderer.WriteTo(w); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+func (s *service) GetCardQRCode(args GetCardQRCodeArgs) ([]byte, error) {
+	if args.Size < QRCodeMinSize || args.Size > QRCodeMaxSize {
+		return nil, errcode.InvalidParams.WithDetails(fmt.Sprintf("图片边长需要在 %d 到 %d 之间", QRCodeMinSize, QRCodeMaxSize))
+	}
+	// 非 root 只能查看自己和下级的激活码
+	result, err := s.repo.GetCards(GetCardsArgs{
+		UserId:      args.UserId,
+		SubtreePath: args.SubtreePath,
+		Values:      []string{args.Value},
+		AppIDs:      args.AppIDs,
+		Page:        1,
+		Limit:       1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.List) == 0 {
+		return nil, errcode.CardNotFound
+	}
+	card := result.List[0]
+
+	appNames, err := s.getAppNames()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := parseSheetTemplate("redeem_url", s.redeemURL)
+	if err != nil {
+		return nil, err
+	}
+	link, err := executeSheetTemplate(tmpl, newSheetCard(card, appNames[card.AppID]))
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(link, qrcode.Medium, args.Size)
+}
+
+func (s *service) GetSheetLayouts() []setting.CardSheetLayout {
+	return s.sheetLayouts
+}
+
+// getAppNames 返回应用ID对应的名称
+func (s *service) getAppNames() (map[string]string, error) {
+	appOptions, err := s.appRepo.QueryAppOptions()
+	if err != nil {
+		return nil, err
+	}
+	appNames := make(map[string]string, len(appOptions))
+	for _, option := range appOptions {
+		appNames[option.ID] = option.Name
+	}
+	return appNames, nil
+}
+
+func (s *service) DeleteCardByValue(value string, userId string, actor app.Actor) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := getCardsByValues(repo, []string{value}, userId)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteCardByValue(value, userId, actor.ID); err != nil {
+			return err
+		}
+		return recordCardChanges(tx, actor, audit.ActionCardDelete, before, nil)
+	})
 }
 
 func (s *service) CreateCard(args CreateCardArgs) (Card, error) {
@@ -70,13 +255,24 @@ func (s *service) CreateCard(args CreateCardArgs) (Card, error) {
 		CreatedAt: &now,
 	}
 
-	newCard, err := s.repo.CreateCard(card)
-	if err != nil {
-		if errors.Is(err, errcode.DuplicateKey) {
+	var newCard Card
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		repo := NewRepository(tx)
+		newCard, err = repo.CreateCard(card)
+		if err != nil {
+			if !errors.Is(err, errcode.DuplicateKey) {
+				return err
+			}
 			// 重复则重新生成Value
 			card.Value = utils.GenerateActivationKey()
-			return s.repo.CreateCard(card)
+			if newCard, err = repo.CreateCard(card); err != nil {
+				return err
+			}
 		}
+		return recordCardChanges(tx, args.Actor, audit.ActionCardCreate, nil, []Card{newCard})
+	})
+	if err != nil {
 		return Card{}, err
 	}
 
@@ -84,57 +280,40 @@ func (s *service) CreateCard(args CreateCardArgs) (Card, error) {
 }
 
 func (s *service) CreateCards(args CreateCardsArgs) ([]Card, error) {
-	// 校验AppID
-	result, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{
-		ID: args.AppID,
-	})
+	app, g, err := s.getCreateScope(args.AppID, args.UserID, args.CheckGrant, args.Apps)
 	if err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"args": args,
-		}).Error("查询应用列表失败", err)
+		}).Info("检查应用和授权失败", err)
 		return []Card{}, err
 	}
-	if result.Total == 0 {
-		global.Logger.WithFields(logger.Fields{
-			"args": args,
-		}).Error("应用不存在")
-		return []Card{}, errcode.NotFound.WithDetails("应用不存在")
-	}
 
-	// 获取 App 信息
-	appList, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{
-		ID: args.AppID,
-	})
-	if err != nil {
-		global.Logger.WithFields(logger.Fields{
-			"args": args,
-		}).Error("查询应用列表失败", err)
-		return []Card{}, err
+	// 检查应用设置，没有指定时间类型时使用应用的第一个时间类型
+	if args.TimeType == "" && len(app.TimeTypes) > 0 {
+		args.TimeType = app.TimeTypes[0]
 	}
-	if appList.Total == 0 {
-		global.Logger.WithFields(logger.Fields{
-			"args": args,
-		}).Error("应用不存在")
-		return []Card{}, errcode.NotFound.WithDetails("应用不存在")
+	if !app.AllowTimeType(args.TimeType) {
+		return []Card{}, errcode.InvalidParams.WithDetails("应用不支持的时间类型: " + args.TimeType)
 	}
-	app := appList.List[0]
-
-	// 检查用户最大创建数量
-	totalCnt, err := s.repo.GetCardTotalCountByUserId(args.UserID)
-	if err != nil {
-		global.Logger.WithFields(logger.Fields{
-			"args": args,
-		}).Error("查询用户激活码数量失败", err)
-		return []Card{}, err
+	if !app.AllowDuration(DurationMinutes(args.Days, args.Hours, args.Minutes)) {
+		return []Card{}, errcode.InvalidParams.WithDetails("超过应用的最大有效时长")
 	}
-	if totalCnt+int64(args.Count) > int64(args.MaxCnt) {
-		global.Logger.WithFields(logger.Fields{
-			"args": args,
-		}).Error("用户激活码数量超限")
-		return []Card{}, errcode.NoPermission.WithDetails("用户激活码数量超限")
+
+	// 检查应用授权
+	appQuota := grant.QuotaUnlimited
+	if g != nil {
+		if !g.AllowTimeType(args.TimeType) {
+			return []Card{}, errcode.NoPermission.WithDetails("不允许的时间类型: " + args.TimeType)
+		}
+		if !g.AllowDuration(DurationMinutes(args.Days, args.Hours, args.Minutes)) {
+			return []Card{}, errcode.NoPermission.WithDetails("超过最大有效时长")
+		}
+		appQuota = g.Quota
 	}
 
+	// 额度在创建时的同一个事务中检查和扣减
 	now := time.Now()
+	batchID := utils.GenerateUUID()
 	var cards []Card
 	for i := 0; i < args.Count; i++ {
 		cards = append(cards, Card{
@@ -149,11 +328,23 @@ func (s *service) CreateCards(args CreateCardsArgs) ([]Card, error) {
 			Days:      args.Days,
 			Value:     utils.GenerateActivationKeyByApp(app.CardPrefix, app.CardLength),
 			Remark:    args.Remark,
+			BatchID:   batchID,
 			CreatedAt: &now,
 		})
 	}
 
-	newCards, err := s.repo.CreateCards(cards)
+	var newCards []Card
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定应用，避免和删除应用交错
+		if err := apps.NewRepository(tx).LockApp(args.AppID); err != nil {
+			return err
+		}
+		var err error
+		if newCards, err = NewRepository(tx).CreateCards(cards, appQuota); err != nil {
+			return err
+		}
+		return recordCardChanges(tx, args.Actor, audit.ActionCardCreate, nil, newCards)
+	})
 	if err != nil {
 		return []Card{}, err
 	}
@@ -161,6 +352,125 @@ func (s *service) CreateCards(args CreateCardsArgs) ([]Card, error) {
 	return newCards, nil
 }
 
+// getCreateScope 获取要生成激活码的应用，checkGrant 时检查创建者是否有该应用的权限
+// 返回的授权为 nil 表示不限制时间类型、时长和额度
+func (s *service) getCreateScope(appID string, userID string, checkGrant bool, scope []string) (apps.App, *grant.Grant, error) {
+	appList, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{
+		ID: appID,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id": appID,
+		}).Error("查询应用列表失败", err)
+		return apps.App{}, nil, err
+	}
+	if appList.Total == 0 {
+		return apps.App{}, nil, errcode.NotFound.WithDetails("应用不存在")
+	}
+	app := appList.List[0]
+	if app.Archived() {
+		return apps.App{}, nil, errcode.AppArchived
+	}
+	if !checkGrant {
+		return app, nil, nil
+	}
+
+	hasApp := false
+	for _, id := range scope {
+		if id == appID {
+			hasApp = true
+			break
+		}
+	}
+	if !hasApp {
+		return apps.App{}, nil, errcode.NoPermission.WithDetails("没有该应用的权限")
+	}
+	g, err := s.grantRepo.GetGrant(userID, appID)
+	if errors.Is(err, errcode.NotFound) {
+		return app, nil, nil
+	}
+	if err != nil {
+		return apps.App{}, nil, err
+	}
+	return app, &g, nil
+}
+
+func (s *service) ImportCards(args ImportCardsArgs) (ImportCardsResult, error) {
+	if len(args.Rows) == 0 {
+		return ImportCardsResult{}, errcode.InvalidParams.WithDetails("没有要导入的激活码")
+	}
+	if len(args.Rows) > ImportMaxRows {
+		return ImportCardsResult{}, errcode.InvalidParams.WithDetails(fmt.Sprintf("一次最多导入 %d 个激活码", ImportMaxRows))
+	}
+	app, g, err := s.getCreateScope(args.AppID, args.UserID, args.CheckGrant, args.Apps)
+	if err != nil {
+		return ImportCardsResult{}, err
+	}
+
+	values := make([]string, 0, len(args.Rows))
+	for _, row := range args.Rows {
+		if value := strings.TrimSpace(row.Value); value != "" {
+			values = append(values, value)
+		}
+	}
+	existingValues, err := s.repo.GetExistingValues(values)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id": args.AppID,
+		}).Error("查询已存在的激活码失败", err)
+		return ImportCardsResult{}, err
+	}
+	existing := make(map[string]bool, len(existingValues))
+	for _, value := range existingValues {
+		existing[value] = true
+	}
+
+	batchID := utils.GenerateUUID()
+	cards, rowErrors := buildImportCards(args, app, g, existing, batchID, time.Now())
+	result := ImportCardsResult{
+		Total:  len(args.Rows),
+		Valid:  len(cards),
+		DryRun: args.DryRun,
+		Errors: rowErrors,
+	}
+	if args.DryRun || len(rowErrors) > 0 {
+		return result, nil
+	}
+
+	// 分批提交，每批单独扣减额度，失败时已经提交的批次保留
+	appQuota := grant.QuotaUnlimited
+	if g != nil {
+		appQuota = g.Quota
+	}
+	result.BatchID = batchID
+	for start := 0; start < len(cards); start += importChunkSize {
+		end := start + importChunkSize
+		if end > len(cards) {
+			end = len(cards)
+		}
+		chunk := cards[start:end]
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := apps.NewRepository(tx).LockApp(args.AppID); err != nil {
+				return err
+			}
+			if _, err := NewRepository(tx).CreateCards(chunk, appQuota); err != nil {
+				return err
+			}
+			return recordCardChanges(tx, args.Actor, audit.ActionCardImport, nil, chunk)
+		})
+		if err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"app_id":   args.AppID,
+				"batch_id": batchID,
+				"imported": result.Imported,
+			}).Error("导入激活码失败", err)
+			return result, err
+		}
+		result.Imported += len(chunk)
+	}
+	return result, nil
+}
+
 func (s *service) UpdateCard(args UpdateCardArgs) error {
 	// 非 root 用户
 	if args.UserId != "" {
@@ -173,6 +483,8 @@ func (s *service) UpdateCard(args UpdateCardArgs) error {
 		}
 	}
 
+	before := args.CurrentCard
+
 	// 更新字段
 	args.CurrentCard.Status = args.Status
 	args.CurrentCard.SEID = args.SEID
@@ -182,10 +494,20 @@ func (s *service) UpdateCard(args UpdateCardArgs) error {
 	args.CurrentCard.TimeType = args.TimeType
 	args.CurrentCard.Remark = args.Remark
 
-	return s.repo.UpdateCard(args.CurrentCard)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		if err := repo.UpdateCardStatus(args.CurrentCard, args.Actor.ID); err != nil {
+			return err
+		}
+		after, err := getCardsByValues(repo, []string{before.Value}, "")
+		if err != nil {
+			return err
+		}
+		return recordCardChanges(tx, args.Actor, audit.ActionCardUpdate, []Card{before}, after)
+	})
 }
 
-func (s *service) DeleteCard(card Card) error {
+func (s *service) DeleteCard(card Card, actor app.Actor) error {
 	// 将状态更新为已删除
 	c, err := s.repo.GetCardByID(card.ID)
 	if err != nil {
@@ -195,21 +517,76 @@ func (s *service) DeleteCard(card Card) error {
 		return err
 	}
 
+	before := c
 	c.Status = StatusDeleted
 
-	return s.repo.UpdateCard(c)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		if err := repo.UpdateCardStatus(c, actor.ID); err != nil {
+			return err
+		}
+		after, err := getCardsByValues(repo, []string{c.Value}, "")
+		if err != nil {
+			return err
+		}
+		return recordCardChanges(tx, actor, audit.ActionCardDelete, []Card{before}, after)
+	})
 }
 
-func (s *service) DeleteCardsByValues(values []string, userId string) error {
-	return s.repo.DeleteCardsByValues(values, userId)
+func (s *service) DeleteCardsByValues(values []string, userId string, actor app.Actor) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := getCardsByValues(repo, values, userId)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteCardsByValues(values, userId, actor.ID); err != nil {
+			return err
+		}
+		after, err := getCardsByValues(repo, values, userId)
+		if err != nil {
+			return err
+		}
+		return recordCardChanges(tx, actor, audit.ActionCardDelete, before, after)
+	})
 }
 
 func (s *service) BatchUpdateStatus(args BatchUpdateStatusArgs) error {
-	return s.repo.BatchUpdateStatus(args)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := getCardsByValues(repo, args.Values, args.UserId)
+		if err != nil {
+			return err
+		}
+		if err := repo.BatchUpdateStatus(args); err != nil {
+			return err
+		}
+		after, err := getCardsByValues(repo, args.Values, args.UserId)
+		if err != nil {
+			return err
+		}
+		return recordCardChanges(tx, args.Actor, audit.ActionCardUpdateStatus, before, after)
+	})
+}
+
+// PublishExpiredCards 为 (since, until] 内到期的已使用激活码产生到期事件，返回激活码数量
+func (s *service) PublishExpiredCards(since time.Time, until time.Time) (int, error) {
+	cards, err := s.repo.GetExpiredCards(since, until)
+	if err != nil {
+		return 0, err
+	}
+	events := make([]webhook.Event, 0, len(cards))
+	for _, c := range cards {
+		events = append(events, expiredEvent(c))
+	}
+	if err := webhook.NewRepository(s.db).Enqueue(events); err != nil {
+		return 0, err
+	}
+	return len(cards), nil
 }
 
-func (s *service) GetCardCountByUserIdAndStatus(userId string) (map[int]int, error) {
-	return s.repo.GetCardCountByUserIdAndStatus(userId)
+func (s *service) GetCardCountByUserIdAndStatus(userId string, subtreePath string) (map[int]int, error) {
+	return s.repo.GetCardCountByUserIdAndStatus(userId, subtreePath)
 }
 
 // ActivateCard 激活激活码
@@ -220,7 +597,11 @@ func (s *service) ActivateCard(args ActivateCardArgs) (Card, error) {
 		global.Logger.WithFields(logger.Fields{
 			"args": args,
 		}).Error("激活码被重复激活")
-		return c.(Card), nil
+		card := c.(Card)
+		if err := s.appService.CheckAppEnabled(card.AppID); err != nil {
+			return card, err
+		}
+		return card, nil
 	}
 
 	// 校验激活码是否存在
@@ -237,6 +618,9 @@ func (s *service) ActivateCard(args ActivateCardArgs) (Card, error) {
 		}).Error("激活码不存在")
 		return Card{}, errcode.NotFound.WithDetails("激活码不存在")
 	}
+	if err := s.appService.CheckAppEnabled(card.AppID); err != nil {
+		return card, err
+	}
 
 	// 激活条件: 未使用且未过期且未绑定设备
 
@@ -265,6 +649,7 @@ func (s *service) ActivateCard(args ActivateCardArgs) (Card, error) {
 	}
 
 	// 更新激活码状态
+	before := card
 	card.Status = StatusUsed
 	card.SEID = args.SEID
 	card.Used = true
@@ -274,7 +659,12 @@ func (s *service) ActivateCard(args ActivateCardArgs) (Card, error) {
 	expiredAt := now.AddDate(0, 0, card.Days).Add(time.Minute * time.Duration(card.Minutes)).Add(time.Hour * time.Duration(card.Hours))
 	card.ExpiredAt = &expiredAt
 
-	err = s.repo.UpdateCard(card)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).UpdateCard(card); err != nil {
+			return err
+		}
+		return recordCardChanges(tx, args.Actor, audit.ActionCardActivate, []Card{before}, []Card{card})
+	})
 	if err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"args": args,
@@ -294,7 +684,11 @@ func (s *service) CheckCardStatus(args CheckCardStatusArgs) (bool, error) {
 	result, ok := checkCardStatusCache.Get(args.Value)
 	if ok {
 		println("命中缓存")
-		return result.(bool), nil
+		status := result.(cardStatus)
+		if err := s.appService.CheckAppEnabled(status.appID); err != nil {
+			return false, err
+		}
+		return status.available, nil
 	}
 
 	// 校验激活码是否存在
@@ -304,7 +698,7 @@ func (s *service) CheckCardStatus(args CheckCardStatusArgs) (bool, error) {
 			"value": args.Value,
 		}).Error("查询激活码失败", err)
 		if errors.Is(err, errcode.NotFound) {
-			checkCardStatusCache.SetDefault(args.Value, false)
+			checkCardStatusCache.SetDefault(args.Value, cardStatus{})
 			return false, nil
 		}
 		return false, err
@@ -313,10 +707,14 @@ func (s *service) CheckCardStatus(args CheckCardStatusArgs) (bool, error) {
 		global.Logger.WithFields(logger.Fields{
 			"value": args.Value,
 		}).Error("激活码不存在")
-		checkCardStatusCache.SetDefault(args.Value, false)
+		checkCardStatusCache.SetDefault(args.Value, cardStatus{})
 		return false, nil
 	}
 
+	if err := s.appService.CheckAppEnabled(card.AppID); err != nil {
+		return false, err
+	}
+
 	// 校验激活码状态
 	if card.Status != StatusUsed {
 		global.Logger.WithFields(logger.Fields{
@@ -343,7 +741,7 @@ func (s *service) CheckCardStatus(args CheckCardStatusArgs) (bool, error) {
 	}
 
 	// 设置缓存
-	checkCardStatusCache.SetDefault(args.Value, true)
+	checkCardStatusCache.SetDefault(args.Value, cardStatus{available: true, appID: card.AppID})
 
 	// 状态为已激活且未过期且设备匹配
 	return true, nil
@@ -385,9 +783,15 @@ func (s *service) SetCardExpiredAt(args SetCardExpiredAtArgs) error {
 	}
 
 	// 更新激活码状态
+	before := card
 	card.ExpiredAt = &args.NewExpiredAt
 
-	return s.repo.UpdateCard(card)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).UpdateCard(card); err != nil {
+			return err
+		}
+		return recordCardChanges(tx, args.Actor, audit.ActionCardSetExpiredAt, []Card{before}, []Card{card})
+	})
 }
 
 // CheckAvailability 检查激活码是否可用
@@ -410,6 +814,10 @@ func (s *service) CheckAvailability(args CheckAvailabilityArgs) (bool, error) {
 		return false, nil
 	}
 
+	if err := s.appService.CheckAppEnabled(card.AppID); err != nil {
+		return false, err
+	}
+
 	// 校验激活码状态
 	if card.Status != StatusUsed {
 		global.Logger.WithFields(logger.Fields{
@@ -429,3 +837,115 @@ func (s *service) CheckAvailability(args CheckAvailabilityArgs) (bool, error) {
 	// 状态为已激活且未过期且设备匹配
 	return true, nil
 }
+
+// getCardsByValues 查询 values 对应的激活码，userId 不为空时只查询该用户的
+func getCardsByValues(repo Repository, values []string, userId string) ([]Card, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result, err := repo.GetCards(GetCardsArgs{
+		UserId: userId,
+		Values: values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.List, nil
+}
+
+// recordCardChanges 对比修改前后的激活码，为每个发生变化的激活码写入一条审计日志
+// before 为空表示创建，after 中不存在的激活码表示已经被删除
+func recordCardChanges(tx *gorm.DB, actor app.Actor, action string, before []Card, after []Card) error {
+	entries := make([]audit.Entry, 0, len(before)+len(after))
+	afterByID := make(map[string]Card, len(after))
+	for _, c := range after {
+		afterByID[c.ID] = c
+	}
+
+	for _, b := range before {
+		var a any
+		if c, ok := afterByID[b.ID]; ok {
+			a = c
+			delete(afterByID, b.ID)
+		}
+		entry, err := audit.NewEntry(actor, action, audit.TargetCard, b.Value, b, a)
+		if err != nil {
+			return err
+		}
+		// 没有任何变化的激活码不记录
+		if a != nil && string(entry.After) == "{}" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	for _, c := range after {
+		if _, ok := afterByID[c.ID]; !ok {
+			continue
+		}
+		entry, err := audit.NewEntry(actor, action, audit.TargetCard, c.Value, nil, c)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := audit.NewRepository(tx).CreateEntries(entries); err != nil {
+		return err
+	}
+	return publishCardEvents(tx, before, after)
+}
+
+func (s *service) GetCardHistory(args GetCardHistoryArgs) ([]HistoryEvent, error) {
+	var current *Card
+	if args.UserId != "" || len(args.AppIDs) > 0 {
+		// 非 root 只能查看自己和下级的激活码
+		result, err := s.repo.GetCards(GetCardsArgs{
+			UserId:      args.UserId,
+			SubtreePath: args.SubtreePath,
+			Values:      []string{args.Value},
+			AppIDs:      args.AppIDs,
+			Page:        1,
+			Limit:       1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.List) == 0 {
+			return nil, errcode.CardNotFound
+		}
+		current = &result.List[0]
+	} else {
+		card, err := s.repo.GetCardByValue(args.Value)
+		if err != nil && !errors.Is(err, errcode.NotFound) {
+			return nil, err
+		}
+		// 已经物理删除的激活码仍然可以通过审计日志查看历史
+		if err == nil {
+			current = &card
+		}
+	}
+
+	entries := make([]audit.Entry, 0)
+	if err := s.auditRepo.IterateEntries(audit.QueryEntriesArgs{
+		TargetType: audit.TargetCard,
+		TargetID:   args.Value,
+	}, 500, func(batch []audit.Entry) error {
+		entries = append(entries, batch...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if current == nil && len(entries) == 0 {
+		return nil, errcode.CardNotFound
+	}
+
+	attempts, err := s.attemptRepo.GetActivationAttemptsByCardValue(args.Value)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.checkRepo.GetCardChecksByCardValue(args.Value)
+	if err != nil {
+		return nil, err
+	}
+	return buildHistory(current, entries, attempts, checks), nil
+}
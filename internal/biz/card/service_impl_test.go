@@ -0,0 +1,166 @@
+package card
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"testing"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+)
+
+type fakeAppRepository struct {
+	apps.Repository
+	apps map[string]apps.App
+}
+
+func (f *fakeAppRepository) QueryAppList(args apps.QueryAppListArgs) (apps.QueryAppListResult, error) {
+	a, ok := f.apps[args.ID]
+	if !ok {
+		return apps.QueryAppListResult{List: []apps.App{}}, nil
+	}
+	return apps.QueryAppListResult{List: []apps.App{a}, Total: 1}, nil
+}
+
+func (f *fakeAppRepository) QueryAppOptions() ([]apps.AppOption, error) {
+	options := make([]apps.AppOption, 0, len(f.apps))
+	for _, a := range f.apps {
+		options = append(options, apps.AppOption{ID: a.ID, Name: a.Name})
+	}
+	return options, nil
+}
+
+// fakeRepository 只实现导出用到的方法
+type fakeRepository struct {
+	Repository
+	cards []Card
+}
+
+func (f *fakeRepository) EachCard(_ GetCardsArgs, fn func(card Card) error) error {
+	for _, c := range f.cards {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeGrantRepository struct {
+	grant.Repository
+	grants map[string]grant.Grant
+}
+
+func (f *fakeGrantRepository) GetGrant(userID string, appID string) (grant.Grant, error) {
+	g, ok := f.grants[userID+"/"+appID]
+	if !ok {
+		return grant.Grant{}, errcode.NotFound
+	}
+	return g, nil
+}
+
+func newGrantTestService() *service {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+	return &service{
+		appRepo: &fakeAppRepository{apps: map[string]apps.App{
+			"a1": {ID: "a1", Status: apps.StatusActive},
+			"a2": {ID: "a2", Status: apps.StatusActive},
+		}},
+		grantRepo: &fakeGrantRepository{grants: map[string]grant.Grant{
+			"seller/a1": {UserID: "seller", AppID: "a1", Quota: 100, TimeTypes: []string{DailyTime}, MaxDuration: 7 * 24 * 60},
+		}},
+	}
+}
+
+func assertErrCode(t *testing.T, err error, want *errcode.Error) {
+	t.Helper()
+	var e *errcode.Error
+	if !errors.As(err, &e) || e.Code() != want.Code() {
+		t.Fatalf("expected error code %d, got %v", want.Code(), err)
+	}
+}
+
+func TestGetCreateScope(t *testing.T) {
+	s := newGrantTestService()
+
+	_, g, err := s.getCreateScope("a1", "seller", true, []string{"a1"})
+	if err != nil || g == nil || g.Quota != 100 {
+		t.Fatalf("expected seller grant, got %+v, %v", g, err)
+	}
+	// 没有授权记录时不限制
+	_, g, err = s.getCreateScope("a2", "seller", true, []string{"a1", "a2"})
+	if err != nil || g != nil {
+		t.Fatalf("expected no grant, got %+v, %v", g, err)
+	}
+	// root 不检查授权
+	_, g, err = s.getCreateScope("a1", "root", false, nil)
+	if err != nil || g != nil {
+		t.Fatalf("expected unrestricted scope, got %+v, %v", g, err)
+	}
+
+	// 没有应用的权限
+	_, _, err = s.getCreateScope("a2", "seller", true, []string{"a1"})
+	assertErrCode(t, err, errcode.NoPermission)
+	_, _, err = s.getCreateScope("missing", "seller", true, []string{"missing"})
+	assertErrCode(t, err, errcode.NotFound)
+}
+
+func TestCreateCardsRejectsGrantViolations(t *testing.T) {
+	s := newGrantTestService()
+	base := CreateCardsArgs{UserID: "seller", AppID: "a1", Count: 1, CheckGrant: true, Apps: []string{"a1"}}
+
+	args := base
+	args.TimeType = MonthlyTime
+	args.Days = 1
+	_, err := s.CreateCards(args)
+	assertErrCode(t, err, errcode.NoPermission)
+
+	args = base
+	args.TimeType = DailyTime
+	args.Days = 8
+	_, err = s.CreateCards(args)
+	assertErrCode(t, err, errcode.NoPermission)
+
+	args = base
+	args.AppID = "a2"
+	args.TimeType = DailyTime
+	_, err = s.CreateCards(args)
+	assertErrCode(t, err, errcode.NoPermission)
+}
+
+func TestExportCardsEscapesFormulas(t *testing.T) {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+	s := &service{
+		appRepo: &fakeAppRepository{apps: map[string]apps.App{"a1": {ID: "a1", Name: "@App"}}},
+		repo: &fakeRepository{cards: []Card{
+			{Value: "=HYPERLINK(\"http://x\")", AppID: "a1", Status: StatusUnused, Remark: "+1"},
+			{Value: "ABC", AppID: "a1", Status: StatusUnused, Remark: "-2"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	count, err := s.ExportCards(ExportCardsArgs{Format: "csv", Columns: []string{"value", "app_name", "remark"}, Locale: ExportLocaleEnUS}, &buf)
+	if err != nil || count != 2 {
+		t.Fatalf("ExportCards() = %d, %v", count, err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(buf.Bytes(), []byte("\xEF\xBB\xBF")))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Code", "App", "Remark"},
+		{"'=HYPERLINK(\"http://x\")", "'@App", "'+1"},
+		{"ABC", "'@App", "'-2"},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Fatalf("record[%d][%d] = %q, want %q", i, j, records[i][j], want[i][j])
+			}
+		}
+	}
+}
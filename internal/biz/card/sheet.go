@@ -0,0 +1,252 @@
+package card
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/pdf"
+	"configuration-management/pkg/setting"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// DefaultRedeemURL 没有配置兑换链接时二维码中的内容
+	DefaultRedeemURL = "https://example.com/public/v1/redeem?code={{.Value}}"
+	// defaultMaxSheetCards 一次最多打印的激活码数量
+	defaultMaxSheetCards = 2000
+	// sheetPadding 卡片内容到边缘的距离，单位为毫米
+	sheetPadding = 3.0
+	// QRCodeMinSize QRCodeMaxSize 二维码图片的边长范围，单位为像素
+	QRCodeMinSize = 64
+	QRCodeMaxSize = 1024
+)
+
+// defaultSheetFooter 默认在卡片底部显示有效时长
+const defaultSheetFooter = "{{if .Days}}{{.Days}}天{{end}}{{if .Hours}}{{.Hours}}小时{{end}}{{if .Minutes}}{{.Minutes}}分钟{{end}}"
+
+// DefaultSheetLayouts 内置的版式，第一个为默认版式
+var DefaultSheetLayouts = []setting.CardSheetLayout{
+	// 常见的 A4 不干胶标签纸，每张 70x37 毫米
+	{Name: "a4-3x8", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 8, CardWidth: 70, CardHeight: 37, MarginTop: 0.5, QRSize: 31, FontSize: 9, Footer: defaultSheetFooter},
+	// 信用卡大小的刮刮卡，每页一张
+	{Name: "card-85x54", PageWidth: 85.6, PageHeight: 54, Columns: 1, Rows: 1, CardWidth: 85.6, CardHeight: 54, QRSize: 42, FontSize: 11, Footer: defaultSheetFooter},
+}
+
+// sheetCard 版式和兑换链接模板中可以使用的字段
+type sheetCard struct {
+	Value    string
+	AppID    string
+	AppName  string
+	Days     int
+	Hours    int
+	Minutes  int
+	TimeType string
+	BatchID  string
+	Remark   string
+}
+
+func newSheetCard(card Card, appName string) sheetCard {
+	return sheetCard{
+		Value:    card.Value,
+		AppID:    card.AppID,
+		AppName:  appName,
+		Days:     card.Days,
+		Hours:    card.Hours,
+		Minutes:  card.Minutes,
+		TimeType: card.TimeType,
+		BatchID:  card.BatchID,
+		Remark:   card.Remark,
+	}
+}
+
+// mergeSheetLayouts 合并默认版式和配置中的版式，同名时使用配置中的版式
+func mergeSheetLayouts(layouts []setting.CardSheetLayout) []setting.CardSheetLayout {
+	merged := make([]setting.CardSheetLayout, 0, len(DefaultSheetLayouts)+len(layouts))
+	for _, layout := range DefaultSheetLayouts {
+		if configured, ok := lookupSheetLayout(layouts, layout.Name); ok {
+			layout = configured
+		}
+		merged = append(merged, layout)
+	}
+	for _, layout := range layouts {
+		if _, ok := lookupSheetLayout(DefaultSheetLayouts, layout.Name); !ok {
+			merged = append(merged, layout)
+		}
+	}
+	return merged
+}
+
+// findSheetLayout 按名称查找版式，name 为空时使用第一个版式
+func findSheetLayout(layouts []setting.CardSheetLayout, name string) (setting.CardSheetLayout, error) {
+	if name == "" && len(layouts) > 0 {
+		return layouts[0], nil
+	}
+	if layout, ok := lookupSheetLayout(layouts, name); ok {
+		return layout, nil
+	}
+	return setting.CardSheetLayout{}, errcode.InvalidParams.WithDetails("不存在的版式 " + name)
+}
+
+func lookupSheetLayout(layouts []setting.CardSheetLayout, name string) (setting.CardSheetLayout, bool) {
+	for _, layout := range layouts {
+		if layout.Name == name {
+			return layout, true
+		}
+	}
+	return setting.CardSheetLayout{}, false
+}
+
+func checkSheetLayout(layout setting.CardSheetLayout) error {
+	if layout.Columns <= 0 || layout.Rows <= 0 || layout.CardWidth <= 0 || layout.CardHeight <= 0 ||
+		layout.PageWidth <= 0 || layout.PageHeight <= 0 || layout.FontSize <= 0 {
+		return errcode.InvalidParams.WithDetails("版式 " + layout.Name + " 的尺寸无效")
+	}
+	return nil
+}
+
+// sheetRenderer 把激活码逐个排版到 PDF 中，每页排满后换页
+type sheetRenderer struct {
+	layout    setting.CardSheetLayout
+	redeemURL *template.Template
+	title     *template.Template
+	footer    *template.Template
+	doc       *pdf.Document
+	count     int
+}
+
+func newSheetRenderer(layout setting.CardSheetLayout, redeemURL string) (*sheetRenderer, error) {
+	if err := checkSheetLayout(layout); err != nil {
+		return nil, err
+	}
+	if layout.Title == "" {
+		layout.Title = "{{.AppName}}"
+	}
+	r := &sheetRenderer{
+		layout: layout,
+		doc:    pdf.New(layout.PageWidth*pdf.MM, layout.PageHeight*pdf.MM),
+	}
+	var err error
+	if r.redeemURL, err = parseSheetTemplate("redeem_url", redeemURL); err != nil {
+		return nil, err
+	}
+	if r.title, err = parseSheetTemplate("title", layout.Title); err != nil {
+		return nil, err
+	}
+	if r.footer, err = parseSheetTemplate("footer", layout.Footer); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func parseSheetTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, errcode.InvalidParams.WithDetails(fmt.Sprintf("模板 %s 格式错误: %s", name, err))
+	}
+	return tmpl, nil
+}
+
+func executeSheetTemplate(tmpl *template.Template, data sheetCard) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", errcode.InvalidParams.WithDetails(fmt.Sprintf("模板 %s 执行失败: %s", tmpl.Name(), err))
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Add 在下一个位置绘制一张卡片：左侧为二维码，右侧为标题、激活码和底部文字
+func (r *sheetRenderer) Add(data sheetCard) error {
+	l := r.layout
+	perPage := l.Columns * l.Rows
+	index := r.count % perPage
+	if index == 0 {
+		r.doc.AddPage()
+	}
+	r.count++
+
+	x := l.MarginLeft + float64(index%l.Columns)*(l.CardWidth+l.GapX)
+	y := l.MarginTop + float64(index/l.Columns)*(l.CardHeight+l.GapY)
+	if l.CutLines {
+		r.doc.StrokeRect(x*pdf.MM, y*pdf.MM, l.CardWidth*pdf.MM, l.CardHeight*pdf.MM, true)
+	}
+
+	link, err := executeSheetTemplate(r.redeemURL, data)
+	if err != nil {
+		return err
+	}
+	qrSize := l.QRSize
+	if limit := l.CardHeight - 2*sheetPadding; qrSize > limit {
+		qrSize = limit
+	}
+	textX := x + sheetPadding
+	if qrSize > 0 {
+		qr, err := qrcode.New(link, qrcode.Medium)
+		if err != nil {
+			return err
+		}
+		drawQRCode(r.doc, qr.Bitmap(), (x+sheetPadding)*pdf.MM, (y+(l.CardHeight-qrSize)/2)*pdf.MM, qrSize*pdf.MM)
+		textX += qrSize + sheetPadding
+	}
+	textWidth := (x + l.CardWidth - sheetPadding - textX) * pdf.MM
+	if textWidth <= 0 {
+		return nil
+	}
+
+	title, err := executeSheetTemplate(r.title, data)
+	if err != nil {
+		return err
+	}
+	footer, err := executeSheetTemplate(r.footer, data)
+	if err != nil {
+		return err
+	}
+	top, bottom := (y+sheetPadding)*pdf.MM, (y+l.CardHeight-sheetPadding)*pdf.MM
+	if title != "" {
+		size := fitFontSize(title, l.FontSize, textWidth)
+		r.doc.Text(textX*pdf.MM, top+size, size, title)
+	}
+	size := fitFontSize(data.Value, l.FontSize*1.2, textWidth)
+	r.doc.Text(textX*pdf.MM, (top+bottom)/2+size/3, size, data.Value)
+	if footer != "" {
+		size := fitFontSize(footer, l.FontSize*0.8, textWidth)
+		r.doc.Text(textX*pdf.MM, bottom, size, footer)
+	}
+	return nil
+}
+
+func (r *sheetRenderer) WriteTo(w io.Writer) (int64, error) {
+	return r.doc.WriteTo(w)
+}
+
+// fitFontSize 文字超出宽度时缩小字号
+func fitFontSize(s string, size float64, width float64) float64 {
+	if w := pdf.TextWidth(s, size); w > width {
+		return size * width / w
+	}
+	return size
+}
+
+// drawQRCode 以矢量矩形绘制二维码，同一行连续的黑色模块合并为一个矩形
+func drawQRCode(doc *pdf.Document, bitmap [][]bool, x, y, size float64) {
+	if len(bitmap) == 0 {
+		return
+	}
+	module := size / float64(len(bitmap))
+	for row, line := range bitmap {
+		for col := 0; col < len(line); {
+			if !line[col] {
+				col++
+				continue
+			}
+			start := col
+			for col < len(line) && line[col] {
+				col++
+			}
+			doc.FillRect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, module)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package card
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"configuration-management/pkg/setting"
+)
+
+func TestSheetLayouts(t *testing.T) {
+	layouts := mergeSheetLayouts([]setting.CardSheetLayout{
+		{Name: "card-85x54", Columns: 1, Rows: 1},
+		{Name: "custom", Columns: 2, Rows: 2},
+	})
+	if len(layouts) != 3 || layouts[0].Name != "a4-3x8" || layouts[1].PageWidth != 0 || layouts[2].Name != "custom" {
+		t.Fatalf("unexpected layouts: %+v", layouts)
+	}
+	if layout, err := findSheetLayout(layouts, ""); err != nil || layout.Name != "a4-3x8" {
+		t.Fatalf("unexpected default layout: %+v, %v", layout, err)
+	}
+	if _, err := findSheetLayout(layouts, "missing"); err == nil {
+		t.Fatal("expected error for missing layout")
+	}
+}
+
+func TestSheetRenderer(t *testing.T) {
+	layout := DefaultSheetLayouts[0]
+	layout.Title = "{{.AppName}} {{.TimeType}}"
+	layout.CutLines = true
+	renderer, err := newSheetRenderer(layout, "https://example.com/redeem?code={{.Value}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每页 24 张，25 张需要两页
+	for i := 0; i < 25; i++ {
+		if err := renderer.Add(newSheetCard(Card{Value: "AB0001", AppID: "a1", Days: 30, TimeType: DailyTime}, "应用")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := renderer.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+	if c := strings.Count(data, "/Type /Page "); c != 2 {
+		t.Fatalf("expected 2 pages, got %d", c)
+	}
+	if !strings.Contains(data, "(AB0001) Tj") || !strings.Contains(data, "re f") {
+		t.Fatal("missing card value or qrcode")
+	}
+	// 默认的底部文字为有效时长：30天
+	if !strings.Contains(data, "<003300305929> Tj") {
+		t.Fatal("missing footer")
+	}
+
+	if _, err := newSheetRenderer(layout, "{{.Value"); err == nil {
+		t.Fatal("expected template error")
+	}
+}
@@ -0,0 +1,113 @@
+package card
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"configuration-management/internal/biz/webhook"
+	"configuration-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// webhookCard 生命周期事件中的激活码信息
+type webhookCard struct {
+	Value     string     `json:"value"`
+	AppID     string     `json:"app_id"`
+	Status    int        `json:"status"`
+	UserID    string     `json:"user_id"`
+	TimeType  string     `json:"time_type"`
+	SEID      string     `json:"seid"`
+	UsedAt    *time.Time `json:"used_at"`
+	ExpiredAt *time.Time `json:"expired_at"`
+	LockedAt  *time.Time `json:"locked_at"`
+	BatchID   string     `json:"batch_id"`
+}
+
+func toWebhookCard(card Card) webhookCard {
+	return webhookCard{
+		Value:     card.Value,
+		AppID:     card.AppID,
+		Status:    card.Status,
+		UserID:    card.UserID,
+		TimeType:  card.TimeType,
+		SEID:      card.SEID,
+		UsedAt:    card.UsedAt,
+		ExpiredAt: card.ExpiredAt,
+		LockedAt:  card.LockedAt,
+		BatchID:   card.BatchID,
+	}
+}
+
+// cardEvents 根据修改前后的激活码产生生命周期事件，after 中不存在的激活码视为被删除
+func cardEvents(before []Card, after []Card, now time.Time) []webhook.Event {
+	afterByID := make(map[string]Card, len(after))
+	for _, c := range after {
+		afterByID[c.ID] = c
+	}
+
+	events := make([]webhook.Event, 0)
+	for _, b := range before {
+		a, ok := afterByID[b.ID]
+		eventType := ""
+		switch {
+		case !ok:
+			eventType, a = webhook.EventCardDeleted, b
+		case a.Status == b.Status:
+			continue
+		case a.Status == StatusUsed:
+			eventType = webhook.EventCardActivated
+		case a.Status == StatusLocked:
+			eventType = webhook.EventCardLocked
+		case a.Status == StatusDeleted:
+			eventType = webhook.EventCardDeleted
+		default:
+			continue
+		}
+		events = append(events, webhook.Event{
+			ID:         utils.GenerateUUID(),
+			Type:       eventType,
+			AppID:      a.AppID,
+			OccurredAt: now,
+			Data:       toWebhookCard(a),
+		})
+	}
+	return events
+}
+
+// publishCardEvents 把激活码的生命周期事件写入 webhook 发件箱，tx 为激活码变化所在的事务
+func publishCardEvents(tx *gorm.DB, before []Card, after []Card) error {
+	return webhook.NewRepository(tx).Enqueue(cardEvents(before, after, time.Now()))
+}
+
+// expiredEvent 到期事件的ID由激活码和到期时间决定，重复扫描不会重复投递，续期后再次到期会产生新的事件
+func expiredEvent(card Card) webhook.Event {
+	return webhook.Event{
+		ID:         fmt.Sprintf("%s:%s:%d", webhook.EventCardExpired, card.ID, card.ExpiredAt.Unix()),
+		Type:       webhook.EventCardExpired,
+		AppID:      card.AppID,
+		OccurredAt: *card.ExpiredAt,
+		Data:       toWebhookCard(card),
+	}
+}
+
+// NewExpiryProducer 每轮投递前扫描新到期的激活码并产生到期事件
+// 启动后第一次扫描最近 window 内到期的激活码，覆盖服务停止期间到期的激活码
+func NewExpiryProducer(service Service, window time.Duration) webhook.Producer {
+	var mu sync.Mutex
+	var last time.Time
+	return func(now time.Time) error {
+		mu.Lock()
+		defer mu.Unlock()
+		since := last
+		if since.IsZero() {
+			since = now.Add(-window)
+		}
+		if _, err := service.PublishExpiredCards(since, now); err != nil {
+			return err
+		}
+		last = now
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package card
+
+import (
+	"testing"
+	"time"
+
+	"configuration-management/internal/biz/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardEvents(t *testing.T) {
+	now := time.Now()
+	before := []Card{
+		{ID: "1", Value: "A", AppID: "app", Status: StatusUnused},
+		{ID: "2", Value: "B", AppID: "app", Status: StatusUnused},
+		{ID: "3", Value: "C", AppID: "app", Status: StatusUsed},
+		{ID: "4", Value: "D", AppID: "app", Status: StatusLocked},
+		{ID: "5", Value: "E", AppID: "app", Status: StatusUsed},
+	}
+	after := []Card{
+		{ID: "1", Value: "A", AppID: "app", Status: StatusUsed},
+		{ID: "2", Value: "B", AppID: "app", Status: StatusLocked},
+		{ID: "3", Value: "C", AppID: "app", Status: StatusDeleted},
+		{ID: "4", Value: "D", AppID: "app", Status: StatusUnused},
+	}
+
+	events := cardEvents(before, after, now)
+	types := make(map[string]string)
+	for _, event := range events {
+		types[event.Data.(webhookCard).Value] = event.Type
+		assert.Equal(t, "app", event.AppID)
+		assert.NotEmpty(t, event.ID)
+	}
+	assert.Equal(t, map[string]string{
+		"A": webhook.EventCardActivated,
+		"B": webhook.EventCardLocked,
+		"C": webhook.EventCardDeleted,
+		"E": webhook.EventCardDeleted,
+	}, types)
+}
+
+func TestExpiredEventID(t *testing.T) {
+	expiredAt := time.Unix(1700000000, 0)
+	card := Card{ID: "1", AppID: "app", Status: StatusUsed, ExpiredAt: &expiredAt}
+	assert.Equal(t, expiredEvent(card).ID, expiredEvent(card).ID)
+
+	renewed := expiredAt.Add(24 * time.Hour)
+	card.ExpiredAt = &renewed
+	assert.NotEqual(t, "card.expired:1:1700000000", expiredEvent(card).ID)
+}
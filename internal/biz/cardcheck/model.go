@@ -0,0 +1,16 @@
+package cardcheck
+
+import "time"
+
+// CardCheck 一次公开的激活码可用性检查
+type CardCheck struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CardValue string    `gorm:"type:varchar(255);not null" json:"card_value"`
+	Available bool      `gorm:"type:tinyint(1);not null" json:"available"`
+	IP        string    `gorm:"type:varchar(64)" json:"ip"`
+	CreatedAt time.Time `gorm:"type:datetime;not null;default:current_timestamp" json:"created_at"`
+}
+
+func (c *CardCheck) TableName() string {
+	return "card_check"
+}
@@ -0,0 +1,6 @@
+package cardcheck
+
+type Repository interface {
+	CreateCardCheck(check *CardCheck) error
+	GetCardChecksByCardValue(cardValue string) ([]CardCheck, error)
+}
@@ -0,0 +1,35 @@
+package cardcheck
+
+import "gorm.io/gorm"
+
+/*
+表结构如下：
+CREATE TABLE card_check (
+    id         INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    card_value VARCHAR(255) NOT NULL, -- 激活码值
+    available  TINYINT(1)   NOT NULL, -- 检查结果
+    ip         VARCHAR(64)  NULL,     -- 请求方IP
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateCardCheck(check *CardCheck) error {
+	return r.db.Create(check).Error
+}
+
+// GetCardChecksByCardValue 获取激活码所有的可用性检查记录，按时间顺序
+func (r *repository) GetCardChecksByCardValue(cardValue string) ([]CardCheck, error) {
+	checks := make([]CardCheck, 0)
+	if err := r.db.Model(&CardCheck{}).Where("card_value = ?", cardValue).Order("id asc").Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
@@ -0,0 +1,5 @@
+package cardcheck
+
+type Service interface {
+	CreateCardCheck(check *CardCheck) error
+}
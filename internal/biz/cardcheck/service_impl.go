@@ -0,0 +1,15 @@
+package cardcheck
+
+import "configuration-management/global"
+
+type service struct {
+	Repository Repository
+}
+
+func NewService() Service {
+	return &service{Repository: NewRepository(global.DBEngine)}
+}
+
+func (s *service) CreateCardCheck(check *CardCheck) error {
+	return s.Repository.CreateCardCheck(check)
+}
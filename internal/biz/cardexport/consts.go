@@ -0,0 +1,19 @@
+package cardexport
+
+import "time"
+
+// 导出任务的状态
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusExpired   = "expired" // 文件超过保留时间后被删除
+)
+
+// 没有配置时使用的默认值
+const (
+	defaultDir               = "storage/exports"
+	defaultRetention         = 24 * time.Hour
+	defaultMaxConcurrentJobs = 2
+)
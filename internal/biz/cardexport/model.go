@@ -0,0 +1,24 @@
+package cardexport
+
+import "time"
+
+// Job 在后台执行的激活码导出任务，完成后通过下载接口获取文件
+type Job struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`                      // 创建任务的用户，只有该用户可以下载
+	Format     string     `json:"format"`                       // 文件格式: csv, xlsx
+	Args       string     `json:"-"`                            // 导出参数，card.ExportCardsArgs 的 JSON
+	Status     string     `json:"status"`                       // pending, running, succeeded, failed, expired
+	FileName   string     `json:"file_name"`                    // 下载时使用的文件名
+	FilePath   string     `json:"-"`                            // 文件在服务器上的路径
+	Rows       int        `json:"rows" gorm:"column:row_count"` // 导出的激活码数量，ROWS 是 MySQL 的保留字
+	Error      string     `json:"error"`                        // 失败原因
+	CreatedAt  time.Time  `json:"created_at"`                   // 创建时间
+	StartedAt  *time.Time `json:"started_at"`                   // 开始时间
+	FinishedAt *time.Time `json:"finished_at"`                  // 完成时间
+	ExpiresAt  *time.Time `json:"expires_at"`                   // 文件删除时间
+}
+
+func (j *Job) TableName() string {
+	return "card_export_job"
+}
@@ -0,0 +1,25 @@
+package cardexport
+
+import "time"
+
+type QueryJobsArgs struct {
+	UserID string `json:"user_id"`
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+}
+
+type QueryJobsResult struct {
+	List  []Job
+	Total int
+}
+
+type Repository interface {
+	CreateJob(job Job) error
+	GetJob(id string) (Job, error)
+	QueryJobs(args QueryJobsArgs) (QueryJobsResult, error)
+	UpdateJob(id string, fields map[string]interface{}) error
+	// GetExpiredJobs 查询文件在 now 之前到期的任务
+	GetExpiredJobs(now time.Time) ([]Job, error)
+	// FailUnfinishedJobs 把没有完成的任务标记为失败并立即到期，清理时删除写了一半的文件，返回标记的数量
+	FailUnfinishedJobs(reason string, now time.Time) (int64, error)
+}
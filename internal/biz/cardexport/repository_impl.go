@@ -0,0 +1,102 @@
+package cardexport
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+/*
+表结构如下：
+CREATE TABLE card_export_job (
+    id          VARCHAR(36)  NOT NULL PRIMARY KEY,
+    user_id     VARCHAR(32)  NOT NULL,           -- 创建任务的用户
+    format      VARCHAR(8)   NOT NULL,           -- 文件格式: csv, xlsx
+    args        TEXT         NOT NULL,           -- 导出参数
+    status      VARCHAR(16)  NOT NULL,           -- pending, running, succeeded, failed, expired
+    file_name   VARCHAR(255) NOT NULL DEFAULT '', -- 下载时使用的文件名
+    file_path   VARCHAR(512) NOT NULL DEFAULT '', -- 文件在服务器上的路径
+    row_count   INT          NOT NULL DEFAULT 0,  -- 导出的激活码数量
+    error       VARCHAR(255) NOT NULL DEFAULT '', -- 失败原因
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at  TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    expires_at  TIMESTAMP NULL,
+    INDEX idx_card_export_job_user (user_id, created_at),
+    INDEX idx_card_export_job_expires (status, expires_at)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateJob(job Job) error {
+	if err := r.db.Create(&job).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"job": job,
+		}).Error("创建导出任务失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) GetJob(id string) (Job, error) {
+	var job Job
+	if err := r.db.Where("id = ?", id).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Job{}, errcode.NotFound
+		}
+		return Job{}, err
+	}
+	return job, nil
+}
+
+func (r *repository) QueryJobs(args QueryJobsArgs) (QueryJobsResult, error) {
+	db := r.db.Model(&Job{}).Where("user_id = ?", args.UserID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryJobsResult{}, err
+	}
+	jobs := make([]Job, 0)
+	if err := db.Order("created_at desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&jobs).Error; err != nil {
+		return QueryJobsResult{}, err
+	}
+	return QueryJobsResult{List: jobs, Total: int(total)}, nil
+}
+
+func (r *repository) UpdateJob(id string, fields map[string]interface{}) error {
+	if err := r.db.Model(&Job{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":     id,
+			"fields": fields,
+		}).Error("更新导出任务失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) GetExpiredJobs(now time.Time) ([]Job, error) {
+	jobs := make([]Job, 0)
+	if err := r.db.Where("status IN (?) AND expires_at <= ?", []string{StatusSucceeded, StatusFailed}, now).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *repository) FailUnfinishedJobs(reason string, now time.Time) (int64, error) {
+	result := r.db.Model(&Job{}).Where("status IN (?)", []string{StatusPending, StatusRunning}).
+		Updates(map[string]interface{}{"status": StatusFailed, "error": reason, "finished_at": now, "expires_at": now})
+	return result.RowsAffected, result.Error
+}
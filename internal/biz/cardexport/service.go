@@ -0,0 +1,31 @@
+package cardexport
+
+import (
+	"time"
+
+	"configuration-management/internal/biz/card"
+)
+
+type CreateJobArgs struct {
+	UserID string               `json:"user_id"`
+	Export card.ExportCardsArgs `json:"export"`
+}
+
+// Options 导出任务的配置
+type Options struct {
+	Dir               string        // 导出文件保存的目录
+	Retention         time.Duration // 文件保留的时间
+	MaxConcurrentJobs int           // 同时执行的任务数量，超过的任务排队
+}
+
+type Service interface {
+	// CreateJob 创建任务并在后台执行，参数错误时直接返回
+	CreateJob(args CreateJobArgs) (Job, error)
+	QueryJobs(args QueryJobsArgs) (QueryJobsResult, error)
+	// GetJob 查询 userID 创建的任务，其他用户的任务返回不存在
+	GetJob(id string, userID string) (Job, error)
+	// Cleanup 删除到期的文件，返回清理的任务数量
+	Cleanup(now time.Time) (int, error)
+	// FailUnfinishedJobs 服务启动时把上次没有执行完的任务标记为失败
+	FailUnfinishedJobs() error
+}
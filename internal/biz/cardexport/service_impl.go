@@ -0,0 +1,205 @@
+package cardexport
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/utils"
+)
+
+var (
+	initializing sync.Once
+	slots        chan struct{} // 限制同时执行的任务数量，所有 service 共用
+)
+
+type service struct {
+	repo        Repository
+	cardService card.Service
+	options     Options
+}
+
+func NewService() Service {
+	options := Options{
+		Dir:               defaultDir,
+		Retention:         defaultRetention,
+		MaxConcurrentJobs: defaultMaxConcurrentJobs,
+	}
+	if cfg := global.ExportSetting; cfg != nil {
+		if cfg.Dir != "" {
+			options.Dir = cfg.Dir
+		}
+		if cfg.Retention > 0 {
+			options.Retention = cfg.Retention
+		}
+		if cfg.MaxConcurrentJobs > 0 {
+			options.MaxConcurrentJobs = cfg.MaxConcurrentJobs
+		}
+	}
+	initializing.Do(func() {
+		slots = make(chan struct{}, options.MaxConcurrentJobs)
+	})
+	return &service{
+		repo:        NewRepository(global.DBEngine),
+		cardService: card.NewService(),
+		options:     options,
+	}
+}
+
+func (s *service) CreateJob(args CreateJobArgs) (Job, error) {
+	if err := card.CheckExportArgs(args.Export); err != nil {
+		return Job{}, err
+	}
+	data, err := json.Marshal(args.Export)
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now()
+	job := Job{
+		ID:        utils.GenerateUUID(),
+		UserID:    args.UserID,
+		Format:    args.Export.Format,
+		Args:      string(data),
+		Status:    StatusPending,
+		FileName:  FileName(args.Export.Format, now),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateJob(job); err != nil {
+		return Job{}, err
+	}
+	go s.run(job, args.Export)
+	return job, nil
+}
+
+func (s *service) QueryJobs(args QueryJobsArgs) (QueryJobsResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QueryJobs(args)
+}
+
+func (s *service) GetJob(id string, userID string) (Job, error) {
+	job, err := s.repo.GetJob(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.UserID != userID {
+		return Job{}, errcode.NotFound
+	}
+	return job, nil
+}
+
+func (s *service) Cleanup(now time.Time) (int, error) {
+	jobs, err := s.repo.GetExpiredJobs(now)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		if job.FilePath != "" {
+			if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				global.Logger.WithFields(logger.Fields{
+					"job_id": job.ID,
+					"path":   job.FilePath,
+				}).Error("删除导出文件失败", err)
+				continue
+			}
+		}
+		if err := s.repo.UpdateJob(job.ID, map[string]interface{}{"status": StatusExpired}); err != nil {
+			return 0, err
+		}
+	}
+	return len(jobs), nil
+}
+
+func (s *service) FailUnfinishedJobs() error {
+	count, err := s.repo.FailUnfinishedJobs("服务重启，任务中断", time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		global.Logger.WithFields(logger.Fields{
+			"count": count,
+		}).Warning("中断的导出任务已标记为失败")
+	}
+	return nil
+}
+
+// run 等待空闲的位置后执行任务，结果写回任务记录
+func (s *service) run(job Job, args card.ExportCardsArgs) {
+	slots <- struct{}{}
+	defer func() { <-slots }()
+
+	startedAt := time.Now()
+	path := filepath.Join(s.options.Dir, job.ID+"."+job.Format)
+	if err := s.repo.UpdateJob(job.ID, map[string]interface{}{
+		"status":     StatusRunning,
+		"started_at": startedAt,
+		"file_path":  path,
+	}); err != nil {
+		return
+	}
+
+	rows, err := s.export(path, args)
+	finishedAt := time.Now()
+	fields := map[string]interface{}{
+		"status":      StatusSucceeded,
+		"row_count":   rows,
+		"finished_at": finishedAt,
+		"expires_at":  finishedAt.Add(s.options.Retention),
+	}
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"job_id": job.ID,
+		}).Error("导出任务失败", err)
+		fields["status"] = StatusFailed
+		fields["error"] = truncate(err.Error(), 255)
+	}
+	_ = s.repo.UpdateJob(job.ID, fields)
+}
+
+func (s *service) export(path string, args card.ExportCardsArgs) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	rows, err := s.cardService.ExportCards(args, w)
+	if err != nil {
+		return rows, err
+	}
+	if err := w.Flush(); err != nil {
+		return rows, err
+	}
+	return rows, file.Close()
+}
+
+// FileName 下载时使用的文件名
+func FileName(format string, now time.Time) string {
+	return fmt.Sprintf("cards-%s.%s", now.Format("20060102-150405"), format)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,57 @@
+package cardstat
+
+import "time"
+
+// Stat 激活码统计，按所有者、应用、生成日期和当前状态汇总激活码数量
+// 作为增量使用时 Count 可以为负数
+type Stat struct {
+	UserID string    `json:"user_id"`              // 激活码所有者
+	AppID  string    `json:"app_id"`               // 应用ID
+	Day    time.Time `json:"day" gorm:"type:date"` // 激活码的生成日期
+	Status int       `json:"status"`               // 激活码当前状态
+	Count  int64     `json:"count"`                // 激活码数量
+}
+
+func (s *Stat) TableName() string {
+	return "card_stat"
+}
+
+// key 用于合并同一行的增量
+type key struct {
+	UserID string
+	AppID  string
+	Day    string
+	Status int
+}
+
+func (s *Stat) key() key {
+	return key{UserID: s.UserID, AppID: s.AppID, Day: s.Day.Format("2006-01-02"), Status: s.Status}
+}
+
+// Merge 合并同一行的增量，并去掉合并后为 0 的增量
+func Merge(deltas []Stat) []Stat {
+	index := make(map[key]int, len(deltas))
+	merged := make([]Stat, 0, len(deltas))
+	for _, delta := range deltas {
+		k := delta.key()
+		if i, ok := index[k]; ok {
+			merged[i].Count += delta.Count
+			continue
+		}
+		index[k] = len(merged)
+		merged = append(merged, delta)
+	}
+
+	result := merged[:0]
+	for _, stat := range merged {
+		if stat.Count != 0 {
+			result = append(result, stat)
+		}
+	}
+	return result
+}
+
+// Day 返回 t 所在的日期，与 MySQL 的 DATE() 一致
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
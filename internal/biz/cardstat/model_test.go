@@ -0,0 +1,32 @@
+package cardstat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	deltas := []Stat{
+		{UserID: "u1", AppID: "a", Day: day, Status: 1, Count: -2},
+		{UserID: "u1", AppID: "a", Day: day, Status: 2, Count: 2},
+		{UserID: "u1", AppID: "a", Day: day.Add(5 * time.Hour), Status: 2, Count: 1},
+		{UserID: "u1", AppID: "a", Day: day, Status: 1, Count: 2},
+		{UserID: "u2", AppID: "a", Day: day, Status: 2, Count: 1},
+	}
+
+	merged := Merge(deltas)
+	assert.Equal(t, []Stat{
+		{UserID: "u1", AppID: "a", Day: day, Status: 2, Count: 3},
+		{UserID: "u2", AppID: "a", Day: day, Status: 2, Count: 1},
+	}, merged)
+	assert.Empty(t, Merge(nil))
+}
+
+func TestDay(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	got := Day(time.Date(2026, 3, 1, 23, 59, 59, 0, loc))
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, loc), got)
+}
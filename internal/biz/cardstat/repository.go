@@ -0,0 +1,6 @@
+package cardstat
+
+type Repository interface {
+	Apply(deltas []Stat) error
+	Rebuild() (int64, error)
+}
@@ -0,0 +1,74 @@
+package cardstat
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE card_stat (
+    user_id VARCHAR(36) NOT NULL, -- 激活码所有者
+    app_id  VARCHAR(36) NOT NULL, -- 应用ID，没有应用的激活码为空字符串
+    day     DATE        NOT NULL, -- 激活码的生成日期
+    status  INT         NOT NULL, -- 激活码当前状态
+    count   INT         NOT NULL, -- 激活码数量
+    PRIMARY KEY (user_id, app_id, day, status)
+);
+*/
+
+// rebuildSQL 根据 card 表重新汇总统计
+const rebuildSQL = `INSERT INTO card_stat (user_id, app_id, day, status, count)
+SELECT user_id, COALESCE(app_id, ''), DATE(created_at), status, COUNT(*)
+FROM card
+GROUP BY user_id, COALESCE(app_id, ''), DATE(created_at), status`
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository 传入事务时，统计在该事务中和激活码一起更新
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Apply 把增量累加到统计中
+func (r *repository) Apply(deltas []Stat) error {
+	deltas = Merge(deltas)
+	if len(deltas) == 0 {
+		return nil
+	}
+	if err := r.db.Table((&Stat{}).TableName()).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "app_id"}, {Name: "day"}, {Name: "status"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count": gorm.Expr("count + VALUES(count)"),
+		}),
+	}).Create(&deltas).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"deltas": deltas,
+		}).Error("更新激活码统计失败", err)
+		return err
+	}
+	return nil
+}
+
+// Rebuild 清空统计后根据 card 表重新汇总，返回统计的行数
+func (r *repository) Rebuild() (int64, error) {
+	var rows int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM card_stat").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(rebuildSQL)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		global.Logger.Error("重建激活码统计失败", err)
+		return 0, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,6 @@
+package cardstat
+
+type Service interface {
+	// Rebuild 根据 card 表重建统计，用于首次上线或统计出现偏差时
+	Rebuild() (int64, error)
+}
@@ -0,0 +1,15 @@
+package cardstat
+
+import "configuration-management/global"
+
+type service struct {
+	repo Repository
+}
+
+func NewService() Service {
+	return &service{repo: NewRepository(global.DBEngine)}
+}
+
+func (s *service) Rebuild() (int64, error) {
+	return s.repo.Rebuild()
+}
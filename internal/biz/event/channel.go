@@ -0,0 +1,109 @@
+package event
+
+import (
+	"context"
+
+	"configuration-management/global"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/notifier"
+)
+
+// outbound 一条待发送的站外通知
+type outbound struct {
+	Channel string
+	IMKind  string
+	Message notifier.Message
+}
+
+// outboundMessages 根据用户的偏好和模板生成要发送到各个渠道的通知
+func outboundMessages(e Event, p Preferences, tpl Template) ([]outbound, error) {
+	if !p.Wants(e.Type, "") {
+		return nil, nil
+	}
+	subject, body, err := render(tpl, e.Params)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]outbound, 0, 2)
+	if p.Wants(e.Type, ChannelEmail) && p.Email != "" {
+		messages = append(messages, outbound{
+			Channel: ChannelEmail,
+			Message: notifier.Message{To: p.Email, Subject: subject, Body: body},
+		})
+	}
+	if p.Wants(e.Type, ChannelIM) && p.IMWebhook != "" {
+		messages = append(messages, outbound{
+			Channel: ChannelIM,
+			IMKind:  p.IMKind,
+			Message: notifier.Message{To: p.IMWebhook, Subject: subject, Body: body},
+		})
+	}
+	return messages, nil
+}
+
+// deliver 把新创建的通知发送到用户选择的站外渠道，发送失败只写日志
+func (s *service) deliver(events []Event) {
+	preferences := make(map[string]Preferences)
+	for _, e := range events {
+		p, ok := preferences[e.RemindUserID]
+		if !ok {
+			var err error
+			p, err = s.GetPreferences(e.RemindUserID)
+			if err != nil {
+				global.Logger.WithFields(logger.Fields{
+					"remind_user_id": e.RemindUserID,
+				}).Error("查询通知偏好失败", err)
+				continue
+			}
+			preferences[e.RemindUserID] = p
+		}
+		if !p.Wants(e.Type, "") {
+			continue
+		}
+
+		tpl, err := s.template(e.Type, p.Locale)
+		if err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"type":   e.Type,
+				"locale": p.Locale,
+			}).Error("查询通知模板失败", err)
+			continue
+		}
+		messages, err := outboundMessages(e, p, tpl)
+		if err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"event_id": e.ID,
+			}).Error("渲染通知模板失败", err)
+			continue
+		}
+		for _, m := range messages {
+			if err := s.send(m); err != nil {
+				global.Logger.WithFields(logger.Fields{
+					"event_id": e.ID,
+					"channel":  m.Channel,
+					"to":       m.Message.To,
+				}).Error("发送站外通知失败", err)
+			}
+		}
+	}
+}
+
+func (s *service) send(m outbound) error {
+	var n notifier.Notifier
+	switch m.Channel {
+	case ChannelEmail:
+		n = s.email
+	case ChannelIM:
+		n = s.im[m.IMKind]
+	}
+	if n == nil {
+		global.Logger.WithFields(logger.Fields{
+			"channel": m.Channel,
+			"im_kind": m.IMKind,
+		}).Warning("通知渠道没有配置，跳过发送")
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.SendTimeout)
+	defer cancel()
+	return n.Send(ctx, m.Message)
+}
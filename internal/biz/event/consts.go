@@ -0,0 +1,53 @@
+package event
+
+import "time"
+
+// 通知类型
+const (
+	TypeLowStock      = "low_stock"      // 某个应用未使用的激活码不足
+	TypeLowQuota      = "low_quota"      // 额度即将用完
+	TypeBatchExpiring = "batch_expiring" // 一批激活码即将到期
+	TypeAbuseFlagged  = "abuse_flagged"  // 检测到疑似滥用，通知所有 root 用户
+	TypeDailySummary  = "daily_summary"  // 前一天的生成和激活汇总，只发给订阅了的用户
+)
+
+// IsType 是否为支持的通知类型
+func IsType(t string) bool {
+	switch t {
+	case TypeLowStock, TypeLowQuota, TypeBatchExpiring, TypeAbuseFlagged, TypeDailySummary:
+		return true
+	}
+	return false
+}
+
+// 站外通知渠道，站内通知总是会产生
+const (
+	ChannelEmail = "email"
+	ChannelIM    = "im"
+)
+
+// 通知模板支持的语言
+const (
+	LocaleZhCN    = "zh-CN"
+	LocaleEnUS    = "en-US"
+	DefaultLocale = LocaleZhCN
+)
+
+// IsLocale 是否为支持的语言
+func IsLocale(locale string) bool {
+	return locale == LocaleZhCN || locale == LocaleEnUS
+}
+
+// PreferencesConfigKey 用户的通知偏好保存在 user_config 中使用的 key
+const PreferencesConfigKey = "notification_preferences"
+
+// 没有配置时使用的默认值
+const (
+	defaultScanInterval      = 10 * time.Minute
+	defaultLowStockThreshold = 10
+	defaultLowQuotaThreshold = 50
+	defaultExpiringWithin    = 72 * time.Hour
+	defaultCooldown          = 24 * time.Hour
+	defaultDailySummaryHour  = 9
+	defaultSendTimeout       = 10 * time.Second
+)
@@ -0,0 +1,49 @@
+package event
+
+import "sync"
+
+// hub 在进程内通知正在订阅的连接有新的通知，连接收到信号后再从数据库读取
+// 多实例部署时其他实例产生的通知依靠连接的定时轮询补上
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subscribers: make(map[string]map[chan struct{}]struct{})}
+}
+
+// subscribe 订阅发给 userID 的通知，调用返回的函数取消订阅
+func (h *hub) subscribe(userID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers[userID], ch)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+		})
+	}
+}
+
+// notify 唤醒 userID 的所有订阅，已经有未处理的信号时不再重复发送
+func (h *hub) notify(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[userID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
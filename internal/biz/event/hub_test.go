@@ -0,0 +1,41 @@
+package event
+
+import "testing"
+
+func TestHubNotify(t *testing.T) {
+	h := newHub()
+	ch, cancel := h.subscribe("u1")
+	other, cancelOther := h.subscribe("u2")
+	defer cancelOther()
+
+	// 多次通知只保留一个信号，不会阻塞
+	h.notify("u1")
+	h.notify("u1")
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected signal for u1")
+	}
+	select {
+	case <-ch:
+		t.Fatal("signals should be coalesced")
+	default:
+	}
+	select {
+	case <-other:
+		t.Fatal("u2 should not be notified")
+	default:
+	}
+
+	cancel()
+	cancel()
+	h.notify("u1")
+	select {
+	case <-ch:
+		t.Fatal("cancelled subscription should not be notified")
+	default:
+	}
+	if _, ok := h.subscribers["u1"]; ok {
+		t.Fatal("empty subscriber set should be removed")
+	}
+}
@@ -1,14 +1,101 @@
 package event
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
 
-// Event 结构体对应 Event 表
+// Event 结构体对应 Event 表，每条记录是发给一个用户的站内通知
 type Event struct {
-	ID           int       `json:"id"`             // 事件唯一标识符
-	UserID       int       `json:"user_id"`        // 事件所属用户的ID，关联到用户表中的id字段
-	RemindUserID int       `json:"remind_user_id"` // 提醒的用户ID（如果有的话），关联到用户表中的id字段
-	Name         string    `json:"name"`           // 事件名称
-	Detail       string    `json:"detail"`         // 事件详细描述
-	Read         bool      `json:"read"`           // 是否已读
-	CreatedAt    time.Time `json:"created_at"`     // 事件创建时间
+	ID           int64      `json:"id"`                         // 事件唯一标识符
+	UserID       string     `json:"user_id"`                    // 事件涉及的用户，例如额度不足的用户
+	RemindUserID string     `json:"remind_user_id"`             // 接收通知的用户
+	Type         string     `json:"type"`                       // 通知类型
+	Name         string     `json:"name"`                       // 事件名称
+	Detail       string     `json:"detail"`                     // 事件详细描述
+	Params       Params     `json:"params"`                     // 渲染站外通知模板使用的参数
+	DedupKey     string     `json:"-"`                          // 冷却期内同一个用户相同 key 的通知只产生一次
+	Read         bool       `json:"read" gorm:"column:is_read"` // 是否已读，READ 是 MySQL 的保留字
+	ReadAt       *time.Time `json:"read_at"`                    // 已读时间
+	CreatedAt    time.Time  `json:"created_at"`                 // 事件创建时间
+}
+
+func (e *Event) TableName() string {
+	return "event"
+}
+
+// Params 通知模板的参数，保存为 JSON
+type Params map[string]string
+
+// Scan 实现了 sql.Scanner 接口
+func (p *Params) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return errors.New("不支持的 Scan 操作，将 driver.Value 类型存储到 *Params 类型中")
+	}
+}
+
+// Value 实现了 driver.Valuer 接口
+func (p Params) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+// Template 站外通知的模板，使用 text/template 语法，参数为通知的 Params
+// 数据库中只保存修改过的模板，没有修改的使用 defaultTemplates
+type Template struct {
+	Type       string    `json:"type" gorm:"primaryKey"`   // 通知类型
+	Locale     string    `json:"locale" gorm:"primaryKey"` // 语言
+	Subject    string    `json:"subject"`                  // 标题模板
+	Body       string    `json:"body"`                     // 正文模板
+	Customized bool      `json:"customized" gorm:"-"`      // 是否修改过
+	UpdaterID  string    `json:"updater_id"`               // 最后修改人
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (t *Template) TableName() string {
+	return "notification_template"
+}
+
+// LowStock 某个用户在某个应用上未使用的激活码数量
+type LowStock struct {
+	UserID string
+	AppID  string
+	Unused int64
+}
+
+// LowQuota 用户的剩余额度
+type LowQuota struct {
+	UserID  string
+	Balance int64
+}
+
+// ExpiringBatch 同一批次中即将到期的已使用激活码
+type ExpiringBatch struct {
+	UserID         string
+	BatchID        string
+	Remark         string
+	Count          int64
+	FirstExpiredAt time.Time
+}
+
+// DailySummary 用户某一天的激活码汇总
+type DailySummary struct {
+	UserID    string
+	Created   int64 // 当天生成的数量
+	Activated int64 // 当天激活的数量
+	Unused    int64 // 当前未使用的数量
 }
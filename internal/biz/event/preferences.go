@@ -0,0 +1,91 @@
+package event
+
+import (
+	"encoding/json"
+	"net/mail"
+	"net/url"
+
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/notifier"
+)
+
+// Preferences 用户的站外通知偏好，以 JSON 保存在 user_config 中
+type Preferences struct {
+	Locale    string              `json:"locale"`     // 通知使用的语言
+	Email     string              `json:"email"`      // 收件地址
+	IMKind    string              `json:"im_kind"`    // 群机器人类型: dingtalk, wecom, slack
+	IMWebhook string              `json:"im_webhook"` // 群机器人的 webhook 地址
+	Channels  map[string][]string `json:"channels"`   // 每种通知发送到哪些渠道，没有配置的类型只有站内通知
+}
+
+// DefaultPreferences 没有设置过时的偏好，不发送站外通知
+func DefaultPreferences() Preferences {
+	return Preferences{Locale: DefaultLocale, Channels: map[string][]string{}}
+}
+
+// ParsePreferences 解析 user_config 中保存的偏好
+func ParsePreferences(value string) (Preferences, error) {
+	p := DefaultPreferences()
+	if err := json.Unmarshal([]byte(value), &p); err != nil {
+		return Preferences{}, err
+	}
+	if p.Locale == "" {
+		p.Locale = DefaultLocale
+	}
+	if p.Channels == nil {
+		p.Channels = map[string][]string{}
+	}
+	return p, nil
+}
+
+// Wants 该类型的通知是否要发送到 channel，channel 为空时表示任意站外渠道
+func (p Preferences) Wants(eventType string, channel string) bool {
+	for _, c := range p.Channels[eventType] {
+		if channel == "" || c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 检查语言、渠道和接收地址，选择了某个渠道时必须填写对应的地址
+func (p Preferences) Validate() error {
+	if !IsLocale(p.Locale) {
+		return errcode.InvalidParams.WithDetails("不支持的语言 " + p.Locale)
+	}
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			return errcode.InvalidParams.WithDetails("邮箱格式错误")
+		}
+	}
+	if p.IMWebhook != "" {
+		if !notifier.IsIMKind(p.IMKind) {
+			return errcode.InvalidParams.WithDetails("不支持的群机器人类型 " + p.IMKind)
+		}
+		// 只允许 https，发送时还会拒绝解析到内网的地址
+		u, err := url.Parse(p.IMWebhook)
+		if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+			return errcode.InvalidParams.WithDetails("群机器人地址必须是 https 地址")
+		}
+	}
+	for eventType, channels := range p.Channels {
+		if !IsType(eventType) {
+			return errcode.InvalidParams.WithDetails("不支持的通知类型 " + eventType)
+		}
+		for _, channel := range channels {
+			switch channel {
+			case ChannelEmail:
+				if p.Email == "" {
+					return errcode.InvalidParams.WithDetails("使用邮件通知需要填写邮箱")
+				}
+			case ChannelIM:
+				if p.IMWebhook == "" {
+					return errcode.InvalidParams.WithDetails("使用群机器人通知需要填写 webhook 地址")
+				}
+			default:
+				return errcode.InvalidParams.WithDetails("不支持的通知渠道 " + channel)
+			}
+		}
+	}
+	return nil
+}
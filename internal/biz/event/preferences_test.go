@@ -0,0 +1,93 @@
+package event
+
+import (
+	"testing"
+
+	"configuration-management/pkg/notifier"
+)
+
+func TestParsePreferences(t *testing.T) {
+	p, err := ParsePreferences(`{"email":"a@example.com","channels":{"low_stock":["email"]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Locale != DefaultLocale {
+		t.Fatalf("expected default locale, got %s", p.Locale)
+	}
+	if !p.Wants(TypeLowStock, ChannelEmail) || !p.Wants(TypeLowStock, "") {
+		t.Fatal("low_stock should be sent by email")
+	}
+	if p.Wants(TypeLowStock, ChannelIM) || p.Wants(TypeDailySummary, "") {
+		t.Fatal("unexpected channel")
+	}
+	if _, err := ParsePreferences("not json"); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
+
+func TestPreferencesValidate(t *testing.T) {
+	valid := Preferences{
+		Locale:    LocaleEnUS,
+		Email:     "a@example.com",
+		IMKind:    notifier.IMDingTalk,
+		IMWebhook: "https://oapi.dingtalk.com/robot/send?access_token=x",
+		Channels:  map[string][]string{TypeLowStock: {ChannelEmail, ChannelIM}, TypeDailySummary: {ChannelEmail}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]func(p *Preferences){
+		"locale":      func(p *Preferences) { p.Locale = "fr-FR" },
+		"email":       func(p *Preferences) { p.Email = "not an email" },
+		"im kind":     func(p *Preferences) { p.IMKind = "telegram" },
+		"im url":      func(p *Preferences) { p.IMWebhook = "ftp://example.com" },
+		"im http":     func(p *Preferences) { p.IMWebhook = "http://oapi.dingtalk.com/robot/send" },
+		"im no host":  func(p *Preferences) { p.IMWebhook = "https:///robot/send" },
+		"event type":  func(p *Preferences) { p.Channels["unknown"] = []string{ChannelEmail} },
+		"channel":     func(p *Preferences) { p.Channels[TypeLowQuota] = []string{"sms"} },
+		"no email":    func(p *Preferences) { p.Email = "" },
+		"no im hooks": func(p *Preferences) { p.IMWebhook = "" },
+	}
+	for name, mutate := range cases {
+		p := valid
+		p.Channels = map[string][]string{TypeLowStock: {ChannelEmail, ChannelIM}}
+		mutate(&p)
+		if err := p.Validate(); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestOutboundMessages(t *testing.T) {
+	tpl := defaultTemplate(TypeLowStock, LocaleEnUS)
+	e := Event{Type: TypeLowStock, RemindUserID: "u1", Params: Params{"app_name": "App", "unused": "3"}}
+	p := Preferences{
+		Locale:    LocaleEnUS,
+		Email:     "a@example.com",
+		IMKind:    notifier.IMSlack,
+		IMWebhook: "https://hooks.example.com/x",
+		Channels:  map[string][]string{TypeLowStock: {ChannelEmail, ChannelIM}},
+	}
+
+	messages, err := outboundMessages(e, p, tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].Channel != ChannelEmail || messages[0].Message.To != "a@example.com" ||
+		messages[0].Message.Subject != "Low activation code stock: App" {
+		t.Fatalf("unexpected email message: %+v", messages[0])
+	}
+	if messages[1].Channel != ChannelIM || messages[1].IMKind != notifier.IMSlack || messages[1].Message.To != p.IMWebhook {
+		t.Fatalf("unexpected im message: %+v", messages[1])
+	}
+
+	// 没有订阅的类型不发送
+	e.Type = TypeLowQuota
+	if messages, _ := outboundMessages(e, p, tpl); len(messages) != 0 {
+		t.Fatalf("expected no messages, got %d", len(messages))
+	}
+}
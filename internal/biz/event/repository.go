@@ -0,0 +1,49 @@
+package event
+
+import "time"
+
+type QueryEventsArgs struct {
+	RemindUserID string `json:"remind_user_id"`
+	Type         string `json:"type"`
+	UnreadOnly   bool   `json:"unread_only"`
+	Page         int    `json:"page"`
+	Limit        int    `json:"limit"`
+}
+
+type QueryEventsResult struct {
+	List  []Event
+	Total int
+}
+
+// Detector 生成通知需要的统计，数据来自激活码统计、额度流水和激活码表
+type Detector interface {
+	// GetLowStocks 未使用的激活码少于 threshold 的用户和应用，只包括生成过激活码的应用
+	GetLowStocks(threshold int) ([]LowStock, error)
+	// GetLowQuotas 剩余额度少于 threshold 的用户，只包括有额度流水的用户
+	GetLowQuotas(threshold int) ([]LowQuota, error)
+	// GetExpiringBatches (from, to] 内到期的已使用激活码，按所有者和批次汇总
+	GetExpiringBatches(from time.Time, to time.Time) ([]ExpiringBatch, error)
+	// GetDailySummaries userIDs 在 day 当天的生成和激活数量，以及当前未使用的数量
+	GetDailySummaries(userIDs []string, day time.Time) ([]DailySummary, error)
+	GetRootUserIDs() ([]string, error)
+}
+
+type Repository interface {
+	Detector
+
+	// CreateEvents 创建通知，since 之后已经有相同接收人和 dedup_key 的通知时跳过，返回实际创建的通知
+	CreateEvents(events []Event, since time.Time) ([]Event, error)
+	QueryEvents(args QueryEventsArgs) (QueryEventsResult, error)
+	// GetEventsAfter 查询 ID 大于 afterID 的通知，按 ID 升序
+	GetEventsAfter(remindUserID string, afterID int64, limit int) ([]Event, error)
+	GetLatestEventID(remindUserID string) (int64, error)
+	CountUnread(remindUserID string) (int64, error)
+	// MarkRead 把通知标记为已读，ids 为空时标记该用户所有的通知，返回标记的数量
+	MarkRead(remindUserID string, ids []int64, now time.Time) (int64, error)
+
+	// GetTemplates 查询修改过的模板
+	GetTemplates() ([]Template, error)
+	GetTemplate(eventType string, locale string) (Template, error)
+	SaveTemplate(tpl Template) error
+	DeleteTemplate(eventType string, locale string) error
+}
@@ -0,0 +1,255 @@
+package event
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE event (
+    id             BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id        VARCHAR(32)  NOT NULL,            -- 事件涉及的用户
+    remind_user_id VARCHAR(32)  NOT NULL,            -- 接收通知的用户
+    type           VARCHAR(32)  NOT NULL,            -- 通知类型
+    name           VARCHAR(255) NOT NULL,            -- 事件名称
+    detail         VARCHAR(1024) NOT NULL,           -- 事件详细描述
+    params         JSON          NULL,               -- 通知模板的参数
+    dedup_key      VARCHAR(255) NOT NULL DEFAULT '', -- 去重的 key
+    is_read        BOOLEAN      NOT NULL DEFAULT 0,  -- 是否已读
+    read_at        TIMESTAMP    NULL,                -- 已读时间
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_event_remind (remind_user_id, is_read, id),
+    INDEX idx_event_dedup (remind_user_id, dedup_key, created_at)
+);
+
+CREATE TABLE notification_template (
+    type       VARCHAR(32)   NOT NULL,          -- 通知类型
+    locale     VARCHAR(16)   NOT NULL,          -- 语言
+    subject    VARCHAR(255)  NOT NULL,          -- 标题模板
+    body       TEXT          NOT NULL,          -- 正文模板
+    updater_id VARCHAR(32)   NOT NULL,          -- 最后修改人
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (type, locale)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetLowStocks(threshold int) ([]LowStock, error) {
+	stocks := make([]LowStock, 0)
+	if err := r.db.Table("card_stat").
+		Select("user_id, app_id, SUM(IF(status = 1, count, 0)) AS unused").
+		Where("app_id != ''").
+		Group("user_id, app_id").
+		Having("SUM(count) > 0 AND SUM(IF(status = 1, count, 0)) < ?", threshold).
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+func (r *repository) GetLowQuotas(threshold int) ([]LowQuota, error) {
+	quotas := make([]LowQuota, 0)
+	if err := r.db.Table("quota_ledger").
+		Select("user_id, SUM(amount) AS balance").
+		Group("user_id").
+		Having("SUM(amount) < ?", threshold).
+		Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+func (r *repository) GetExpiringBatches(from time.Time, to time.Time) ([]ExpiringBatch, error) {
+	batches := make([]ExpiringBatch, 0)
+	if err := r.db.Table("card").
+		Select("user_id, batch_id, MAX(remark) AS remark, COUNT(*) AS count, MIN(expired_at) AS first_expired_at").
+		Where("status = ? AND batch_id IS NOT NULL AND expired_at > ? AND expired_at <= ?", 2, from, to).
+		Group("user_id, batch_id").
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *repository) GetDailySummaries(userIDs []string, day time.Time) ([]DailySummary, error) {
+	if len(userIDs) == 0 {
+		return []DailySummary{}, nil
+	}
+	summaries := make([]DailySummary, 0, len(userIDs))
+	if err := r.db.Table("card_stat").
+		Select("user_id, SUM(IF(day = ?, count, 0)) AS created, SUM(IF(status = 1, count, 0)) AS unused", day.Format("2006-01-02")).
+		Where("user_id IN (?)", userIDs).
+		Group("user_id").
+		Find(&summaries).Error; err != nil {
+		return nil, err
+	}
+
+	var activations []struct {
+		UserID string
+		Count  int64
+	}
+	if err := r.db.Table("card").
+		Select("user_id, COUNT(*) AS count").
+		Where("user_id IN (?) AND used_at >= ? AND used_at < ?", userIDs, day, day.AddDate(0, 0, 1)).
+		Group("user_id").
+		Find(&activations).Error; err != nil {
+		return nil, err
+	}
+	activated := make(map[string]int64, len(activations))
+	for _, a := range activations {
+		activated[a.UserID] = a.Count
+	}
+	for i := range summaries {
+		summaries[i].Activated = activated[summaries[i].UserID]
+	}
+	return summaries, nil
+}
+
+func (r *repository) GetRootUserIDs() ([]string, error) {
+	ids := make([]string, 0)
+	if err := r.db.Table("user").Where("JSON_CONTAINS(roles, '\"root\"') AND status = 1").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *repository) CreateEvents(events []Event, since time.Time) ([]Event, error) {
+	created := make([]Event, 0, len(events))
+	for _, e := range events {
+		if e.DedupKey != "" {
+			var count int64
+			if err := r.db.Model(&Event{}).
+				Where("remind_user_id = ? AND dedup_key = ? AND created_at >= ?", e.RemindUserID, e.DedupKey, since).
+				Count(&count).Error; err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				continue
+			}
+		}
+		if err := r.db.Create(&e).Error; err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"event": e,
+			}).Error("创建通知失败", err)
+			return nil, err
+		}
+		created = append(created, e)
+	}
+	return created, nil
+}
+
+func (r *repository) QueryEvents(args QueryEventsArgs) (QueryEventsResult, error) {
+	db := r.db.Model(&Event{}).Where("remind_user_id = ?", args.RemindUserID)
+	if args.Type != "" {
+		db = db.Where("type = ?", args.Type)
+	}
+	if args.UnreadOnly {
+		db = db.Where("is_read = ?", false)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryEventsResult{}, err
+	}
+	events := make([]Event, 0)
+	if err := db.Order("id desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&events).Error; err != nil {
+		return QueryEventsResult{}, err
+	}
+	return QueryEventsResult{List: events, Total: int(total)}, nil
+}
+
+func (r *repository) GetEventsAfter(remindUserID string, afterID int64, limit int) ([]Event, error) {
+	events := make([]Event, 0)
+	if err := r.db.Where("remind_user_id = ? AND id > ?", remindUserID, afterID).
+		Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *repository) GetLatestEventID(remindUserID string) (int64, error) {
+	var id int64
+	if err := r.db.Model(&Event{}).Select("COALESCE(MAX(id), 0)").
+		Where("remind_user_id = ?", remindUserID).Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *repository) CountUnread(remindUserID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&Event{}).Where("remind_user_id = ? AND is_read = ?", remindUserID, false).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *repository) MarkRead(remindUserID string, ids []int64, now time.Time) (int64, error) {
+	db := r.db.Model(&Event{}).Where("remind_user_id = ? AND is_read = ?", remindUserID, false)
+	if len(ids) > 0 {
+		db = db.Where("id IN (?)", ids)
+	}
+	result := db.Updates(map[string]interface{}{"is_read": true, "read_at": now})
+	if result.Error != nil {
+		global.Logger.WithFields(logger.Fields{
+			"remind_user_id": remindUserID,
+			"ids":            ids,
+		}).Error("标记通知已读失败", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *repository) GetTemplates() ([]Template, error) {
+	templates := make([]Template, 0)
+	if err := r.db.Order("type, locale").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *repository) GetTemplate(eventType string, locale string) (Template, error) {
+	var tpl Template
+	if err := r.db.Where("type = ? AND locale = ?", eventType, locale).First(&tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Template{}, errcode.NotFound
+		}
+		return Template{}, err
+	}
+	return tpl, nil
+}
+
+// SaveTemplate 新增或者覆盖某个类型和语言的模板
+func (r *repository) SaveTemplate(tpl Template) error {
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "updater_id", "updated_at"}),
+	}).Create(&tpl).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"template": tpl,
+		}).Error("保存通知模板失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) DeleteTemplate(eventType string, locale string) error {
+	return r.db.Where("type = ? AND locale = ?", eventType, locale).Delete(&Template{}).Error
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/pkg/app"
+)
+
+// Options 通知扫描的配置
+type Options struct {
+	ScanInterval      time.Duration // 扫描间隔
+	LowStockThreshold int           // 未使用的激活码少于该值时提醒
+	LowQuotaThreshold int           // 剩余额度少于该值时提醒
+	ExpiringWithin    time.Duration // 提醒多久以内到期的批次
+	Cooldown          time.Duration // 同一个提醒在冷却期内只发送一次
+	DailySummaryHour  int           // 每天几点之后发送前一天的日报
+	SendTimeout       time.Duration // 发送一条站外通知的超时时间
+}
+
+type SaveTemplateArgs struct {
+	Type    string    `json:"type"`
+	Locale  string    `json:"locale"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	Actor   app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type Service interface {
+	// Notifier 检测到疑似滥用时通知所有 root 用户
+	abuse.Notifier
+
+	QueryEvents(args QueryEventsArgs) (QueryEventsResult, error)
+	CountUnread(remindUserID string) (int64, error)
+	MarkRead(remindUserID string, ids []int64) (int64, error)
+	GetEventsAfter(remindUserID string, afterID int64, limit int) ([]Event, error)
+	GetLatestEventID(remindUserID string) (int64, error)
+	// Subscribe 订阅发给 remindUserID 的新通知，收到信号后调用 GetEventsAfter 读取，调用返回的函数取消订阅
+	Subscribe(remindUserID string) (<-chan struct{}, func())
+
+	// GetPreferences 查询用户的站外通知偏好，没有设置过时返回默认偏好
+	GetPreferences(userID string) (Preferences, error)
+	SavePreferences(userID string, preferences Preferences, actor app.Actor) error
+	// QueryTemplates 查询所有类型和语言的模板，没有修改过的返回默认模板
+	QueryTemplates() ([]Template, error)
+	SaveTemplate(args SaveTemplateArgs) error
+	// DeleteTemplate 删除修改过的模板，恢复为默认模板
+	DeleteTemplate(eventType string, locale string, actor app.Actor) error
+
+	// Scan 检查库存、额度和即将到期的批次，生成通知，返回生成的数量
+	Scan(now time.Time) (int, error)
+	// Run 按 ScanInterval 定期执行 Scan，直到 ctx 结束
+	Run(ctx context.Context)
+}
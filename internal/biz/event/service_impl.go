@@ -0,0 +1,476 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/userconfig"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/notifier"
+
+	"gorm.io/gorm"
+)
+
+// defaultHub 所有 service 共用，扫描和滥用检测产生的通知可以唤醒接口中的订阅
+var defaultHub = newHub()
+
+type service struct {
+	db                *gorm.DB
+	repo              Repository
+	appService        apps.Service
+	userConfigService userconfig.Service
+	hub               *hub
+	options           Options
+	email             notifier.Notifier            // 没有配置 SMTP 时为 nil
+	im                map[string]notifier.Notifier // 按群机器人类型区分
+}
+
+func NewService() Service {
+	options := Options{
+		ScanInterval:      defaultScanInterval,
+		LowStockThreshold: defaultLowStockThreshold,
+		LowQuotaThreshold: defaultLowQuotaThreshold,
+		ExpiringWithin:    defaultExpiringWithin,
+		Cooldown:          defaultCooldown,
+		DailySummaryHour:  defaultDailySummaryHour,
+		SendTimeout:       defaultSendTimeout,
+	}
+	var email notifier.Notifier
+	if cfg := global.NotificationSetting; cfg != nil {
+		if cfg.ScanInterval > 0 {
+			options.ScanInterval = cfg.ScanInterval
+		}
+		if cfg.LowStockThreshold > 0 {
+			options.LowStockThreshold = cfg.LowStockThreshold
+		}
+		if cfg.LowQuotaThreshold > 0 {
+			options.LowQuotaThreshold = cfg.LowQuotaThreshold
+		}
+		if cfg.ExpiringWithin > 0 {
+			options.ExpiringWithin = cfg.ExpiringWithin
+		}
+		if cfg.Cooldown > 0 {
+			options.Cooldown = cfg.Cooldown
+		}
+		if cfg.DailySummaryHour > 0 {
+			options.DailySummaryHour = cfg.DailySummaryHour
+		}
+		if cfg.SendTimeout > 0 {
+			options.SendTimeout = cfg.SendTimeout
+		}
+		if cfg.SMTP.Host != "" {
+			email = notifier.NewSMTP(notifier.SMTPConfig{
+				Host:     cfg.SMTP.Host,
+				Port:     cfg.SMTP.Port,
+				Username: cfg.SMTP.Username,
+				Password: cfg.SMTP.Password,
+				From:     cfg.SMTP.From,
+			})
+		}
+	}
+	client := notifier.NewIMClient(options.SendTimeout)
+	return &service{
+		db:                global.DBEngine,
+		repo:              NewRepository(global.DBEngine),
+		appService:        apps.NewService(),
+		userConfigService: userconfig.NewService(),
+		hub:               defaultHub,
+		options:           options,
+		email:             email,
+		im: map[string]notifier.Notifier{
+			notifier.IMDingTalk: notifier.NewIMWebhook(notifier.IMDingTalk, client),
+			notifier.IMWeCom:    notifier.NewIMWebhook(notifier.IMWeCom, client),
+			notifier.IMSlack:    notifier.NewIMWebhook(notifier.IMSlack, client),
+		},
+	}
+}
+
+func (s *service) QueryEvents(args QueryEventsArgs) (QueryEventsResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QueryEvents(args)
+}
+
+func (s *service) CountUnread(remindUserID string) (int64, error) {
+	return s.repo.CountUnread(remindUserID)
+}
+
+func (s *service) MarkRead(remindUserID string, ids []int64) (int64, error) {
+	count, err := s.repo.MarkRead(remindUserID, ids, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	// 未读数量变化，让其他打开的页面刷新
+	if count > 0 {
+		s.hub.notify(remindUserID)
+	}
+	return count, nil
+}
+
+func (s *service) GetEventsAfter(remindUserID string, afterID int64, limit int) ([]Event, error) {
+	return s.repo.GetEventsAfter(remindUserID, afterID, limit)
+}
+
+func (s *service) GetLatestEventID(remindUserID string) (int64, error) {
+	return s.repo.GetLatestEventID(remindUserID)
+}
+
+func (s *service) Subscribe(remindUserID string) (<-chan struct{}, func()) {
+	return s.hub.subscribe(remindUserID)
+}
+
+func (s *service) NotifyFlag(flag abuse.Flag) error {
+	rootIDs, err := s.repo.GetRootUserIDs()
+	if err != nil {
+		return err
+	}
+	events := make([]Event, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		events = append(events, Event{
+			RemindUserID: id,
+			Type:         TypeAbuseFlagged,
+			Name:         "检测到疑似滥用",
+			Detail:       fmt.Sprintf("规则 %s 触发，对象 %s %s，%s", flag.Rule, flag.SubjectType, flag.Subject, flag.Detail),
+			Params: Params{
+				"rule":         flag.Rule,
+				"subject_type": flag.SubjectType,
+				"subject":      flag.Subject,
+				"detail":       flag.Detail,
+			},
+			DedupKey:  fmt.Sprintf("%s:%d", TypeAbuseFlagged, flag.ID),
+			CreatedAt: time.Now(),
+		})
+	}
+	_, err = s.create(events, time.Now().Add(-s.options.Cooldown))
+	return err
+}
+
+func (s *service) GetPreferences(userID string) (Preferences, error) {
+	config, err := s.userConfigService.GetUserConfigByUserIDAndConfigKey(userID, PreferencesConfigKey)
+	if err != nil {
+		if errors.Is(err, errcode.NotFound) {
+			return DefaultPreferences(), nil
+		}
+		return Preferences{}, err
+	}
+	return ParsePreferences(config.ConfigValue)
+}
+
+func (s *service) SavePreferences(userID string, preferences Preferences, actor app.Actor) error {
+	if preferences.Locale == "" {
+		preferences.Locale = DefaultLocale
+	}
+	if err := preferences.Validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.userConfigService.GetUserConfigByUserIDAndConfigKey(userID, PreferencesConfigKey)
+	if errors.Is(err, errcode.NotFound) {
+		return s.userConfigService.CreateUserConfig(userconfig.CreateUserConfigArgs{
+			UserID:      userID,
+			ConfigKey:   PreferencesConfigKey,
+			ConfigValue: string(value),
+			Actor:       actor,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return s.userConfigService.UpdateUserConfig(&userconfig.UserConfig{
+		UserID:      userID,
+		ConfigKey:   PreferencesConfigKey,
+		ConfigValue: string(value),
+	}, actor)
+}
+
+func (s *service) QueryTemplates() ([]Template, error) {
+	customized, err := s.repo.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]Template, len(customized))
+	for _, tpl := range customized {
+		tpl.Customized = true
+		index[tpl.Type+"/"+tpl.Locale] = tpl
+	}
+
+	templates := make([]Template, 0, len(defaultTemplates))
+	for _, eventType := range []string{TypeLowStock, TypeLowQuota, TypeBatchExpiring, TypeAbuseFlagged, TypeDailySummary} {
+		for _, locale := range []string{LocaleZhCN, LocaleEnUS} {
+			if tpl, ok := index[eventType+"/"+locale]; ok {
+				templates = append(templates, tpl)
+				continue
+			}
+			templates = append(templates, defaultTemplate(eventType, locale))
+		}
+	}
+	return templates, nil
+}
+
+func (s *service) SaveTemplate(args SaveTemplateArgs) error {
+	tpl := Template{
+		Type:      args.Type,
+		Locale:    args.Locale,
+		Subject:   args.Subject,
+		Body:      args.Body,
+		UpdaterID: args.Actor.ID,
+		UpdatedAt: time.Now(),
+	}
+	if err := checkTemplate(tpl); err != nil {
+		return err
+	}
+	before, err := s.template(args.Type, args.Locale)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).SaveTemplate(tpl); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionNotificationTemplateUpdate, audit.TargetNotificationTemplate,
+			tpl.Type+"/"+tpl.Locale, before, tpl)
+	})
+}
+
+func (s *service) DeleteTemplate(eventType string, locale string, actor app.Actor) error {
+	before, err := s.repo.GetTemplate(eventType, locale)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).DeleteTemplate(eventType, locale); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionNotificationTemplateDelete, audit.TargetNotificationTemplate,
+			eventType+"/"+locale, before, nil)
+	})
+}
+
+// template 查询修改过的模板，没有修改过时使用默认模板
+func (s *service) template(eventType string, locale string) (Template, error) {
+	tpl, err := s.repo.GetTemplate(eventType, locale)
+	if err == nil {
+		tpl.Customized = true
+		return tpl, nil
+	}
+	if errors.Is(err, errcode.NotFound) {
+		return defaultTemplate(eventType, locale), nil
+	}
+	return Template{}, err
+}
+
+func (s *service) Scan(now time.Time) (int, error) {
+	stocks, err := s.repo.GetLowStocks(s.options.LowStockThreshold)
+	if err != nil {
+		return 0, err
+	}
+	quotas, err := s.repo.GetLowQuotas(s.options.LowQuotaThreshold)
+	if err != nil {
+		return 0, err
+	}
+	batches, err := s.repo.GetExpiringBatches(now, now.Add(s.options.ExpiringWithin))
+	if err != nil {
+		return 0, err
+	}
+	appOptions, err := s.appService.QueryAppOptions()
+	if err != nil {
+		return 0, err
+	}
+	appNames := make(map[string]string, len(appOptions))
+	for _, option := range appOptions {
+		appNames[option.ID] = option.Name
+	}
+
+	count, err := s.create(scanEvents(stocks, quotas, batches, appNames, now), now.Add(-s.options.Cooldown))
+	if err != nil {
+		return 0, err
+	}
+
+	// 日报的去重 key 带有日期，每天到了设置的时间后生成一次
+	if now.Hour() >= s.options.DailySummaryHour {
+		events, err := s.dailySummaries(now)
+		if err != nil {
+			return count, err
+		}
+		created, err := s.create(events, startOfDay(now))
+		if err != nil {
+			return count, err
+		}
+		count += created
+	}
+	return count, nil
+}
+
+// dailySummaries 为订阅了日报的用户生成前一天的汇总
+func (s *service) dailySummaries(now time.Time) ([]Event, error) {
+	configs, err := s.userConfigService.GetUserConfigsByConfigKey(PreferencesConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0)
+	for _, config := range configs {
+		p, err := ParsePreferences(config.ConfigValue)
+		if err != nil {
+			continue
+		}
+		if p.Wants(TypeDailySummary, "") {
+			userIDs = append(userIDs, config.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	day := startOfDay(now).AddDate(0, 0, -1)
+	summaries, err := s.repo.GetDailySummaries(userIDs, day)
+	if err != nil {
+		return nil, err
+	}
+	return dailySummaryEvents(summaries, day, now), nil
+}
+
+func (s *service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.options.ScanInterval)
+	defer ticker.Stop()
+	for {
+		if count, err := s.Scan(time.Now()); err != nil {
+			global.Logger.Error("扫描通知失败", err)
+		} else if count > 0 {
+			global.Logger.WithFields(logger.Fields{
+				"count": count,
+			}).Info("生成通知")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// create 创建通知，唤醒订阅并在后台发送站外通知，返回实际创建的数量
+func (s *service) create(events []Event, since time.Time) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	created, err := s.repo.CreateEvents(events, since)
+	if err != nil {
+		return 0, err
+	}
+	s.wake(created)
+	if len(created) > 0 {
+		go s.deliver(created)
+	}
+	return len(created), nil
+}
+
+func (s *service) wake(events []Event) {
+	for _, e := range events {
+		s.hub.notify(e.RemindUserID)
+	}
+}
+
+// scanEvents 根据统计结果生成通知，提醒发给激活码和额度的所有者
+func scanEvents(stocks []LowStock, quotas []LowQuota, batches []ExpiringBatch, appNames map[string]string, now time.Time) []Event {
+	events := make([]Event, 0, len(stocks)+len(quotas)+len(batches))
+	for _, stock := range stocks {
+		appName := appNames[stock.AppID]
+		if appName == "" {
+			appName = stock.AppID
+		}
+		events = append(events, Event{
+			UserID:       stock.UserID,
+			RemindUserID: stock.UserID,
+			Type:         TypeLowStock,
+			Name:         "激活码库存不足",
+			Detail:       fmt.Sprintf("应用 %s 只剩 %d 个未使用的激活码", appName, stock.Unused),
+			Params:       Params{"app_id": stock.AppID, "app_name": appName, "unused": strconv.FormatInt(stock.Unused, 10)},
+			DedupKey:     fmt.Sprintf("%s:%s", TypeLowStock, stock.AppID),
+			CreatedAt:    now,
+		})
+	}
+	for _, quota := range quotas {
+		events = append(events, Event{
+			UserID:       quota.UserID,
+			RemindUserID: quota.UserID,
+			Type:         TypeLowQuota,
+			Name:         "额度即将用完",
+			Detail:       fmt.Sprintf("剩余额度 %d", quota.Balance),
+			Params:       Params{"balance": strconv.FormatInt(quota.Balance, 10)},
+			DedupKey:     TypeLowQuota,
+			CreatedAt:    now,
+		})
+	}
+	for _, batch := range batches {
+		name := batch.BatchID
+		if batch.Remark != "" {
+			name = batch.Remark
+		}
+		events = append(events, Event{
+			UserID:       batch.UserID,
+			RemindUserID: batch.UserID,
+			Type:         TypeBatchExpiring,
+			Name:         "激活码即将到期",
+			Detail: fmt.Sprintf("批次 %s 有 %d 个激活码将在 %s 之后陆续到期", name, batch.Count,
+				batch.FirstExpiredAt.Format("2006-01-02 15:04:05")),
+			Params: Params{
+				"batch_id":         batch.BatchID,
+				"batch":            name,
+				"count":            strconv.FormatInt(batch.Count, 10),
+				"first_expired_at": batch.FirstExpiredAt.Format("2006-01-02 15:04:05"),
+			},
+			DedupKey:  fmt.Sprintf("%s:%s", TypeBatchExpiring, batch.BatchID),
+			CreatedAt: now,
+		})
+	}
+	return events
+}
+
+// dailySummaryEvents 根据汇总生成日报，day 为汇总的日期
+func dailySummaryEvents(summaries []DailySummary, day time.Time, now time.Time) []Event {
+	date := day.Format("2006-01-02")
+	events := make([]Event, 0, len(summaries))
+	for _, summary := range summaries {
+		events = append(events, Event{
+			UserID:       summary.UserID,
+			RemindUserID: summary.UserID,
+			Type:         TypeDailySummary,
+			Name:         "激活码日报 " + date,
+			Detail: fmt.Sprintf("%s 生成 %d 个激活码，激活 %d 个，当前未使用 %d 个", date,
+				summary.Created, summary.Activated, summary.Unused),
+			Params: Params{
+				"date":      date,
+				"created":   strconv.FormatInt(summary.Created, 10),
+				"activated": strconv.FormatInt(summary.Activated, 10),
+				"unused":    strconv.FormatInt(summary.Unused, 10),
+			},
+			DedupKey:  TypeDailySummary + ":" + date,
+			CreatedAt: now,
+		})
+	}
+	return events
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
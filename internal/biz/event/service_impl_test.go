@@ -0,0 +1,61 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanEvents(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	events := scanEvents(
+		[]LowStock{{UserID: "u1", AppID: "a1", Unused: 3}, {UserID: "u2", AppID: "gone", Unused: 0}},
+		[]LowQuota{{UserID: "u1", Balance: 12}},
+		[]ExpiringBatch{{UserID: "u3", BatchID: "b1", Count: 5, FirstExpiredAt: now.Add(time.Hour)}},
+		map[string]string{"a1": "App One"},
+		now,
+	)
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	stock := events[0]
+	if stock.Type != TypeLowStock || stock.RemindUserID != "u1" || stock.DedupKey != "low_stock:a1" {
+		t.Fatalf("unexpected low stock event: %+v", stock)
+	}
+	if !strings.Contains(stock.Detail, "App One") || !strings.Contains(stock.Detail, "3") {
+		t.Fatalf("unexpected low stock detail: %s", stock.Detail)
+	}
+	// 应用已经不存在时使用应用ID
+	if !strings.Contains(events[1].Detail, "gone") {
+		t.Fatalf("unexpected detail for unknown app: %s", events[1].Detail)
+	}
+	if events[2].Type != TypeLowQuota || events[2].DedupKey != TypeLowQuota {
+		t.Fatalf("unexpected low quota event: %+v", events[2])
+	}
+	batch := events[3]
+	if batch.Type != TypeBatchExpiring || batch.RemindUserID != "u3" || batch.DedupKey != "batch_expiring:b1" {
+		t.Fatalf("unexpected batch event: %+v", batch)
+	}
+	for _, e := range events {
+		if !e.CreatedAt.Equal(now) {
+			t.Fatalf("unexpected created_at: %v", e.CreatedAt)
+		}
+	}
+}
+
+func TestDailySummaryEvents(t *testing.T) {
+	now := time.Date(2024, 5, 2, 9, 30, 0, 0, time.Local)
+	day := startOfDay(now).AddDate(0, 0, -1)
+	events := dailySummaryEvents([]DailySummary{{UserID: "u1", Created: 10, Activated: 4, Unused: 20}}, day, now)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.DedupKey != "daily_summary:2024-05-01" || e.RemindUserID != "u1" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+	if e.Params["created"] != "10" || e.Params["activated"] != "4" || e.Params["unused"] != "20" || e.Params["date"] != "2024-05-01" {
+		t.Fatalf("unexpected params: %v", e.Params)
+	}
+}
@@ -0,0 +1,111 @@
+package event
+
+import (
+	"bytes"
+	"text/template"
+
+	"configuration-management/pkg/errcode"
+)
+
+// defaultTemplates 没有修改过时使用的模板，key 为 通知类型/语言
+var defaultTemplates = map[string]Template{
+	TypeLowStock + "/" + LocaleZhCN: {
+		Subject: "激活码库存不足：{{.app_name}}",
+		Body:    "应用 {{.app_name}} 只剩 {{.unused}} 个未使用的激活码，请及时生成。",
+	},
+	TypeLowStock + "/" + LocaleEnUS: {
+		Subject: "Low activation code stock: {{.app_name}}",
+		Body:    "Only {{.unused}} unused activation codes are left for {{.app_name}}.",
+	},
+	TypeLowQuota + "/" + LocaleZhCN: {
+		Subject: "额度即将用完",
+		Body:    "你的剩余额度为 {{.balance}}，用完后将无法生成激活码。",
+	},
+	TypeLowQuota + "/" + LocaleEnUS: {
+		Subject: "Quota almost used up",
+		Body:    "Your remaining quota is {{.balance}}. You will not be able to generate activation codes once it runs out.",
+	},
+	TypeBatchExpiring + "/" + LocaleZhCN: {
+		Subject: "激活码即将到期：{{.batch}}",
+		Body:    "批次 {{.batch}} 有 {{.count}} 个激活码将在 {{.first_expired_at}} 之后陆续到期。",
+	},
+	TypeBatchExpiring + "/" + LocaleEnUS: {
+		Subject: "Activation codes expiring: {{.batch}}",
+		Body:    "{{.count}} activation codes in batch {{.batch}} start expiring at {{.first_expired_at}}.",
+	},
+	TypeAbuseFlagged + "/" + LocaleZhCN: {
+		Subject: "检测到疑似滥用：{{.rule}}",
+		Body:    "规则 {{.rule}} 触发，对象 {{.subject_type}} {{.subject}}。{{.detail}}",
+	},
+	TypeAbuseFlagged + "/" + LocaleEnUS: {
+		Subject: "Suspected abuse: {{.rule}}",
+		Body:    "Rule {{.rule}} was triggered by {{.subject_type}} {{.subject}}. {{.detail}}",
+	},
+	TypeDailySummary + "/" + LocaleZhCN: {
+		Subject: "激活码日报 {{.date}}",
+		Body:    "{{.date}} 生成 {{.created}} 个激活码，激活 {{.activated}} 个，当前未使用 {{.unused}} 个。",
+	},
+	TypeDailySummary + "/" + LocaleEnUS: {
+		Subject: "Daily activation summary {{.date}}",
+		Body:    "On {{.date}}, {{.created}} activation codes were generated and {{.activated}} were activated. {{.unused}} remain unused.",
+	},
+}
+
+// defaultTemplate 没有该语言的模板时使用默认语言
+func defaultTemplate(eventType string, locale string) Template {
+	tpl, ok := defaultTemplates[eventType+"/"+locale]
+	if !ok {
+		locale = DefaultLocale
+		tpl = defaultTemplates[eventType+"/"+locale]
+	}
+	tpl.Type = eventType
+	tpl.Locale = locale
+	return tpl
+}
+
+// checkTemplate 检查模板的类型、语言和语法
+func checkTemplate(tpl Template) error {
+	if !IsType(tpl.Type) {
+		return errcode.InvalidParams.WithDetails("不支持的通知类型 " + tpl.Type)
+	}
+	if !IsLocale(tpl.Locale) {
+		return errcode.InvalidParams.WithDetails("不支持的语言 " + tpl.Locale)
+	}
+	if tpl.Subject == "" {
+		return errcode.InvalidParams.WithDetails("标题不能为空")
+	}
+	for _, text := range []string{tpl.Subject, tpl.Body} {
+		if _, err := template.New("").Parse(text); err != nil {
+			return errcode.InvalidParams.WithDetails(err.Error())
+		}
+	}
+	return nil
+}
+
+// render 使用通知的参数渲染标题和正文，缺少的参数渲染为空
+func render(tpl Template, params Params) (string, string, error) {
+	subject, err := execute(tpl.Subject, params)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := execute(tpl.Body, params)
+	if err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+func execute(text string, params Params) (string, error) {
+	t, err := template.New("").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	if params == nil {
+		params = Params{}
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"configuration-management/pkg/errcode"
+)
+
+func TestDefaultTemplatesRender(t *testing.T) {
+	for _, eventType := range []string{TypeLowStock, TypeLowQuota, TypeBatchExpiring, TypeAbuseFlagged, TypeDailySummary} {
+		for _, locale := range []string{LocaleZhCN, LocaleEnUS} {
+			tpl := defaultTemplate(eventType, locale)
+			if tpl.Locale != locale {
+				t.Fatalf("missing default template %s/%s", eventType, locale)
+			}
+			if err := checkTemplate(tpl); err != nil {
+				t.Fatalf("invalid default template %s/%s: %v", eventType, locale, err)
+			}
+			subject, _, err := render(tpl, Params{})
+			if err != nil || subject == "" {
+				t.Fatalf("render %s/%s failed: %q %v", eventType, locale, subject, err)
+			}
+		}
+	}
+	// 没有该语言的模板时使用默认语言
+	if tpl := defaultTemplate(TypeLowQuota, "fr-FR"); tpl.Locale != DefaultLocale {
+		t.Fatalf("expected fallback to %s, got %s", DefaultLocale, tpl.Locale)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tpl := Template{Subject: "Low stock: {{.app_name}}", Body: "{{.unused}} left{{.missing}}"}
+	subject, body, err := render(tpl, Params{"app_name": "App", "unused": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Low stock: App" || body != "3 left" {
+		t.Fatalf("unexpected render result: %q %q", subject, body)
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	cases := []struct {
+		tpl Template
+		msg string
+	}{
+		{Template{Type: "unknown", Locale: LocaleZhCN, Subject: "x"}, "通知类型"},
+		{Template{Type: TypeLowStock, Locale: "fr-FR", Subject: "x"}, "语言"},
+		{Template{Type: TypeLowStock, Locale: LocaleZhCN}, "标题"},
+		{Template{Type: TypeLowStock, Locale: LocaleZhCN, Subject: "x", Body: "{{.unused"}, "unclosed"},
+	}
+	for _, c := range cases {
+		var e *errcode.Error
+		if err := checkTemplate(c.tpl); !errors.As(err, &e) || !strings.Contains(strings.Join(e.Details(), ""), c.msg) {
+			t.Fatalf("expected error containing %q, got %v", c.msg, err)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package grant
+
+const (
+	// QuotaUnlimited 不限制该应用的激活码数量
+	QuotaUnlimited = -1
+)
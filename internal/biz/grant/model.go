@@ -0,0 +1,124 @@
+package grant
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DBStruct struct {
+	ID          string          `json:"id"`                          // 唯一标识符(UUID)
+	UserID      string          `json:"user_id"`                     // 用户ID
+	AppID       string          `json:"app_id"`                      // 应用ID
+	Quota       int             `json:"quota"`                       // 该应用最多可生成的激活码数量，-1 表示不限制
+	TimeTypes   json.RawMessage `json:"time_types" gorm:"type:json"` // 允许的时间类型，为空表示不限制
+	MaxDuration int             `json:"max_duration"`                // 单个激活码的最大有效时长（分钟），0 表示不限制
+	CreatorID   string          `json:"creator_id"`                  // 授权人ID
+	CreatedAt   time.Time       `json:"created_at"`                  // 创建时间
+	UpdatedAt   time.Time       `json:"updated_at"`                  // 更新时间
+}
+
+func (s *DBStruct) TableName() string {
+	return "user_app_grant"
+}
+
+func (s *DBStruct) ToModel() (Grant, error) {
+	grant := Grant{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		AppID:       s.AppID,
+		Quota:       s.Quota,
+		MaxDuration: s.MaxDuration,
+		CreatorID:   s.CreatorID,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	if len(s.TimeTypes) > 0 {
+		if err := json.Unmarshal(s.TimeTypes, &grant.TimeTypes); err != nil {
+			return Grant{}, err
+		}
+	}
+	return grant, nil
+}
+
+func BatchToModel(dbStructs []DBStruct) ([]Grant, error) {
+	grants := make([]Grant, 0, len(dbStructs))
+	for _, dbStruct := range dbStructs {
+		grant, err := dbStruct.ToModel()
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// Grant 用户在某个应用上的授权，限制该应用的激活码数量、时间类型和有效时长
+type Grant struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	AppID       string    `json:"app_id"`
+	Quota       int       `json:"quota"`
+	TimeTypes   []string  `json:"time_types"`
+	MaxDuration int       `json:"max_duration"`
+	CreatorID   string    `json:"creator_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (g *Grant) ToDBStruct() DBStruct {
+	timeTypes := g.TimeTypes
+	if timeTypes == nil {
+		timeTypes = []string{}
+	}
+	timeTypesJson, _ := json.Marshal(timeTypes)
+	return DBStruct{
+		ID:          g.ID,
+		UserID:      g.UserID,
+		AppID:       g.AppID,
+		Quota:       g.Quota,
+		TimeTypes:   timeTypesJson,
+		MaxDuration: g.MaxDuration,
+		CreatorID:   g.CreatorID,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
+
+// AllowTimeType 检查是否允许该时间类型
+func (g *Grant) AllowTimeType(timeType string) bool {
+	if len(g.TimeTypes) == 0 {
+		return true
+	}
+	for _, t := range g.TimeTypes {
+		if t == timeType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowDuration 检查有效时长（分钟）是否在允许范围内
+func (g *Grant) AllowDuration(minutes int) bool {
+	return g.MaxDuration <= 0 || minutes <= g.MaxDuration
+}
+
+// Covers 检查 g 的限制是否不比 other 宽松，用于上级向下级授权时的校验
+func (g *Grant) Covers(other Grant) bool {
+	if g.Quota != QuotaUnlimited && (other.Quota == QuotaUnlimited || other.Quota > g.Quota) {
+		return false
+	}
+	if len(g.TimeTypes) > 0 {
+		if len(other.TimeTypes) == 0 {
+			return false
+		}
+		for _, t := range other.TimeTypes {
+			if !g.AllowTimeType(t) {
+				return false
+			}
+		}
+	}
+	if g.MaxDuration > 0 && (other.MaxDuration <= 0 || other.MaxDuration > g.MaxDuration) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,9 @@
+package grant
+
+type Repository interface {
+	GetGrant(userID string, appID string) (Grant, error)
+	GetGrantsByUserID(userID string) ([]Grant, error)
+	SaveGrant(grant Grant) error
+	DeleteGrant(userID string, appID string) error
+	DeleteGrantsByUserID(userID string) error
+}
@@ -0,0 +1,91 @@
+package grant
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE user_app_grant (
+    id           VARCHAR(32) NOT NULL PRIMARY KEY, -- 唯一标识符
+    user_id      VARCHAR(32) NOT NULL,             -- 用户ID
+    app_id       VARCHAR(32) NOT NULL,             -- 应用ID
+    quota        INT DEFAULT -1 NOT NULL,          -- 该应用最多可生成的激活码数量，-1 表示不限制
+    time_types   JSON NULL,                        -- 允许的时间类型，为空表示不限制
+    max_duration INT DEFAULT 0 NOT NULL,           -- 单个激活码的最大有效时长（分钟），0 表示不限制
+    creator_id   VARCHAR(32) NOT NULL,             -- 授权人ID
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE (user_id, app_id)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetGrant(userID string, appID string) (Grant, error) {
+	var dbStruct DBStruct
+	if err := r.db.Table(dbStruct.TableName()).Where("user_id = ? AND app_id = ?", userID, appID).
+		First(&dbStruct).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Grant{}, errcode.NotFound
+		}
+		return Grant{}, err
+	}
+	return dbStruct.ToModel()
+}
+
+func (r *repository) GetGrantsByUserID(userID string) ([]Grant, error) {
+	var dbStructs []DBStruct
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("user_id = ?", userID).
+		Order("created_at").Find(&dbStructs).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": userID,
+		}).Error("查询应用授权失败", err)
+		return nil, err
+	}
+	return BatchToModel(dbStructs)
+}
+
+// SaveGrant 新增或者覆盖用户在某个应用上的授权
+func (r *repository) SaveGrant(grant Grant) error {
+	dbStruct := grant.ToDBStruct()
+	if err := r.db.Table(dbStruct.TableName()).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "app_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quota", "time_types", "max_duration", "creator_id", "updated_at"}),
+	}).Create(&dbStruct).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"grant": grant,
+		}).Error("保存应用授权失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) DeleteGrant(userID string, appID string) error {
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("user_id = ? AND app_id = ?", userID, appID).
+		Delete(&DBStruct{}).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": userID,
+			"app_id":  appID,
+		}).Error("删除应用授权失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) DeleteGrantsByUserID(userID string) error {
+	return r.db.Table((&DBStruct{}).TableName()).Where("user_id = ?", userID).Delete(&DBStruct{}).Error
+}
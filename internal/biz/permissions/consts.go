@@ -9,9 +9,23 @@ const (
 	UPDATE_USED    = "UPDATE_USED"
 	UPDATE_LOCKED  = "UPDATE_LOCKED"
 	UPDATE_DELETED = "UPDATE_DELETED"
+	APP_MANAGE     = "APP_MANAGE"
+	CONFIG_MANAGE  = "CONFIG_MANAGE"
+	USER_MANAGE    = "USER_MANAGE"    // 管理自己的下级用户
+	API_KEY_MANAGE = "API_KEY_MANAGE" // 创建、查询和吊销自己和下级的 API Key
+
+	// 以下权限仅 root 拥有，不能分配给自定义角色
+	ROLE_MANAGE = "ROLE_MANAGE"
+	AUDIT_VIEW  = "AUDIT_VIEW" // 查询和导出审计日志
+
+	ACTIVATION_VIEW     = "ACTIVATION_VIEW"     // 查询激活记录的IP、设备和地理位置
+	ABUSE_MANAGE        = "ABUSE_MANAGE"        // 审核滥用标记和解除IP封禁
+	WEBHOOK_MANAGE      = "WEBHOOK_MANAGE"      // 管理 webhook 订阅和重新投递
+	NOTIFICATION_MANAGE = "NOTIFICATION_MANAGE" // 修改站外通知的模板
 )
 
 var (
+	// AllAllowedPernisions 可以分配给用户或自定义角色的权限
 	AllAllowedPernisions = []string{
 		CREATE,
 		QUERY,
@@ -21,5 +35,59 @@ var (
 		UPDATE_USED,
 		UPDATE_LOCKED,
 		UPDATE_DELETED,
+		APP_MANAGE,
+		CONFIG_MANAGE,
+		USER_MANAGE,
+		API_KEY_MANAGE,
 	}
+
+	// RootPermissions root 拥有的全部权限
+	RootPermissions = append(append([]string{}, AllAllowedPernisions...), ROLE_MANAGE, AUDIT_VIEW, ACTIVATION_VIEW, ABUSE_MANAGE, WEBHOOK_MANAGE, NOTIFICATION_MANAGE)
 )
+
+// IsAllowed 检查权限是否可以被分配
+func IsAllowed(permission string) bool {
+	for _, p := range AllAllowedPernisions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains 检查权限列表中是否包含某个权限
+func Contains(list []string, permission string) bool {
+	for _, p := range list {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge 合并多个权限列表并去重
+func Merge(lists ...[]string) []string {
+	seen := make(map[string]struct{})
+	merged := make([]string, 0)
+	for _, list := range lists {
+		for _, p := range list {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// Intersect 返回同时在两个权限列表中的权限
+func Intersect(a, b []string) []string {
+	result := make([]string, 0)
+	for _, p := range a {
+		if Contains(b, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,9 @@
+package quota
+
+const (
+	TypeCredit      = "credit"       // root 授予或收回额度
+	TypeTransferIn  = "transfer_in"  // 从上级划入的额度
+	TypeTransferOut = "transfer_out" // 划给下级的额度
+	TypeDebit       = "debit"        // 生成激活码扣减额度
+	TypeRefund      = "refund"       // 删除未使用的激活码退还额度
+)
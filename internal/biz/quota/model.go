@@ -0,0 +1,19 @@
+package quota
+
+import "time"
+
+// Entry 额度流水，用户的可用额度为其所有流水 amount 之和
+type Entry struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	UserID    string    `json:"user_id"`    // 额度所属用户ID
+	Amount    int       `json:"amount"`     // 变动数量，正数为增加，负数为扣减
+	Type      string    `json:"type"`       // 流水类型: credit, transfer_in, transfer_out, debit, refund
+	Reason    string    `json:"reason"`     // 变动原因
+	ActorID   string    `json:"actor_id"`   // 操作人ID
+	RelatedID string    `json:"related_id"` // 关联对象ID，比如划转的对方用户
+	CreatedAt time.Time `json:"created_at"` // 创建时间
+}
+
+func (e *Entry) TableName() string {
+	return "quota_ledger"
+}
@@ -0,0 +1,10 @@
+package quota
+
+type Repository interface {
+	GetBalance(userID string) (int64, error)
+	GetBalances(userIDs []string) (map[string]int64, error)
+	QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error)
+	CreateEntries(entries []Entry) error
+	Consume(entry Entry) error
+	Transfer(args TransferArgs) error
+}
@@ -0,0 +1,195 @@
+package quota
+
+import (
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE quota_ledger (
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    user_id    VARCHAR(32)  NOT NULL, -- 额度所属用户ID
+    amount     INT          NOT NULL, -- 变动数量，正数为增加，负数为扣减
+    type       VARCHAR(32)  NOT NULL, -- 流水类型
+    reason     VARCHAR(255) NULL,     -- 变动原因
+    actor_id   VARCHAR(32)  NULL,     -- 操作人ID
+    related_id VARCHAR(64)  NULL,     -- 关联对象ID
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_quota_ledger_user (user_id, created_at)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository 传入事务时，Consume 和 Transfer 会在该事务中锁定用户行
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetBalance(userID string) (int64, error) {
+	var balance int64
+	if err := r.db.Table((&Entry{}).TableName()).Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).Scan(&balance).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": userID,
+		}).Error("查询额度余额失败", err)
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *repository) GetBalances(userIDs []string) (map[string]int64, error) {
+	balances := make(map[string]int64)
+	if len(userIDs) == 0 {
+		return balances, nil
+	}
+
+	var rows []struct {
+		UserID  string
+		Balance int64
+	}
+	if err := r.db.Table((&Entry{}).TableName()).Select("user_id, SUM(amount) AS balance").
+		Where("user_id IN (?)", userIDs).Group("user_id").Find(&rows).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_ids": userIDs,
+		}).Error("查询额度余额失败", err)
+		return nil, err
+	}
+	for _, row := range rows {
+		balances[row.UserID] = row.Balance
+	}
+	return balances, nil
+}
+
+func (r *repository) QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error) {
+	db := r.db.Table((&Entry{}).TableName()).Where("user_id = ?", args.UserID)
+	if args.Type != "" {
+		db = db.Where("type = ?", args.Type)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryEntriesResult{}, err
+	}
+
+	var entries = make([]Entry, 0)
+	if err := db.Order("id DESC").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&entries).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Error("查询额度流水失败", err)
+		return QueryEntriesResult{}, err
+	}
+	return QueryEntriesResult{
+		Total: int(total),
+		List:  entries,
+	}, nil
+}
+
+func (r *repository) CreateEntries(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range entries {
+		if entries[i].CreatedAt.IsZero() {
+			entries[i].CreatedAt = now
+		}
+	}
+	if err := r.db.Create(&entries).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"entries": entries,
+		}).Error("创建额度流水失败", err)
+		return err
+	}
+	return nil
+}
+
+// Consume 锁定用户后检查余额并写入一条扣减流水，必须在事务中调用才能防止并发超额
+func (r *repository) Consume(entry Entry) error {
+	if entry.Amount >= 0 {
+		return r.CreateEntries([]Entry{entry})
+	}
+
+	if err := r.lockUser(entry.UserID); err != nil {
+		return err
+	}
+	balance, err := r.GetBalance(entry.UserID)
+	if err != nil {
+		return err
+	}
+	if balance+int64(entry.Amount) < 0 {
+		global.Logger.WithFields(logger.Fields{
+			"entry":   entry,
+			"balance": balance,
+		}).Info("额度不足")
+		return errcode.NoPermission.WithDetails("额度不足")
+	}
+	return r.CreateEntries([]Entry{entry})
+}
+
+// Transfer 从 FromID 划转额度给 ToID，FromID 为空时表示 root 直接授予（Amount 为负数时收回）
+func (r *repository) Transfer(args TransferArgs) error {
+	if args.Amount == 0 {
+		return nil
+	}
+
+	if args.FromID == "" {
+		return r.Consume(Entry{
+			UserID:  args.ToID,
+			Amount:  args.Amount,
+			Type:    TypeCredit,
+			Reason:  args.Reason,
+			ActorID: args.ActorID,
+		})
+	}
+
+	// 数量为负数时反向划转，即从下级收回额度
+	from, to, amount := args.FromID, args.ToID, args.Amount
+	fromType, toType := TypeTransferOut, TypeTransferIn
+	if amount < 0 {
+		from, to, amount = to, from, -amount
+	}
+	if err := r.Consume(Entry{
+		UserID:    from,
+		Amount:    -amount,
+		Type:      fromType,
+		Reason:    args.Reason,
+		ActorID:   args.ActorID,
+		RelatedID: to,
+	}); err != nil {
+		return err
+	}
+	return r.CreateEntries([]Entry{{
+		UserID:    to,
+		Amount:    amount,
+		Type:      toType,
+		Reason:    args.Reason,
+		ActorID:   args.ActorID,
+		RelatedID: from,
+	}})
+}
+
+// lockUser 使用 SELECT ... FOR UPDATE 锁定用户行，串行化同一用户的额度变动
+func (r *repository) lockUser(userID string) error {
+	var id string
+	if err := r.db.Table("user").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", userID).Scan(&id).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": userID,
+		}).Error("锁定用户失败", err)
+		return err
+	}
+	if id == "" {
+		return errcode.NotFound.WithDetails("用户不存在")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package quota
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// openTestDB 需要真实的 MySQL 才能验证行锁，未设置 CM_TEST_MYSQL_DSN 时跳过
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("CM_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未设置 CM_TEST_MYSQL_DSN")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+	return db
+}
+
+func TestConsumeConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	userID := "quota-test-" + time.Now().Format("150405.000000")
+	if err := db.Exec("INSERT INTO user (id, username, password, created_at) VALUES (?, ?, '', NOW())", userID, userID).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM quota_ledger WHERE user_id = ?", userID)
+		db.Exec("DELETE FROM user WHERE id = ?", userID)
+	})
+	if err := NewRepository(db).CreateEntries([]Entry{{UserID: userID, Amount: 5, Type: TypeCredit}}); err != nil {
+		t.Fatalf("CreateEntries() error = %v", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return NewRepository(tx).Consume(Entry{UserID: userID, Amount: -1, Type: TypeDebit})
+			})
+			var e *errcode.Error
+			switch {
+			case err == nil:
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			case !errors.As(err, &e) || e.Code() != errcode.NoPermission.Code():
+				t.Errorf("Consume() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 5 {
+		t.Errorf("succeeded = %d, want 5", succeeded)
+	}
+	balance, err := NewRepository(db).GetBalance(userID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 0 {
+		t.Errorf("balance = %d, want 0", balance)
+	}
+}
@@ -0,0 +1,27 @@
+package quota
+
+type QueryEntriesArgs struct {
+	UserID string `json:"user_id"`
+	Type   string `json:"type"`
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+}
+
+type QueryEntriesResult struct {
+	List  []Entry
+	Total int
+}
+
+type TransferArgs struct {
+	FromID  string `json:"from_id"` // 为空时表示 root 授予
+	ToID    string `json:"to_id"`
+	Amount  int    `json:"amount"` // 负数表示收回
+	Reason  string `json:"reason"`
+	ActorID string `json:"actor_id"`
+}
+
+type Service interface {
+	GetBalance(userID string) (int64, error)
+	GetBalances(userIDs []string) (map[string]int64, error)
+	QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error)
+}
@@ -0,0 +1,29 @@
+package quota
+
+import "configuration-management/global"
+
+type service struct {
+	repo Repository
+}
+
+func NewService() Service {
+	return &service{repo: NewRepository(global.DBEngine)}
+}
+
+func (s *service) GetBalance(userID string) (int64, error) {
+	return s.repo.GetBalance(userID)
+}
+
+func (s *service) GetBalances(userIDs []string) (map[string]int64, error) {
+	return s.repo.GetBalances(userIDs)
+}
+
+func (s *service) QueryEntries(args QueryEntriesArgs) (QueryEntriesResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	}
+	return s.repo.QueryEntries(args)
+}
@@ -0,0 +1,9 @@
+package redeem
+
+// 兑换页面上激活码的状态
+const (
+	StatusAvailable   = "available"   // 未使用，可以兑换
+	StatusActivated   = "activated"   // 已经激活，仍在有效期内
+	StatusExpired     = "expired"     // 已经过期
+	StatusUnavailable = "unavailable" // 已锁定或已删除
+)
@@ -0,0 +1,19 @@
+package redeem
+
+import "time"
+
+// Info 兑换页面上展示的激活码信息，不包含设备等敏感字段
+type Info struct {
+	Value        string     `json:"value"`
+	AppID        string     `json:"app_id"`
+	AppName      string     `json:"app_name"`
+	Status       string     `json:"status"`
+	Days         int        `json:"days"`
+	Hours        int        `json:"hours"`
+	Minutes      int        `json:"minutes"`
+	TimeType     string     `json:"time_type"`
+	ExpiredAt    *time.Time `json:"expired_at"`
+	Entitlements []string   `json:"entitlements"` // 激活码包含的权益
+	Instructions string     `json:"instructions"` // 兑换说明
+	DeepLink     string     `json:"deep_link"`    // 打开应用并填入激活码的链接，只有可以兑换或已激活时才有
+}
@@ -0,0 +1,21 @@
+package redeem
+
+import (
+	"context"
+	"net/url"
+
+	"configuration-management/pkg/captcha"
+)
+
+type Service interface {
+	// Enabled 是否开启兑换页面
+	Enabled() bool
+	// Title 页面标题
+	Title() string
+	// Challenge 生成查询前的人机验证
+	Challenge() (captcha.Challenge, error)
+	// VerifyChallenge 校验提交的表单中人机验证的回答
+	VerifyChallenge(ctx context.Context, form url.Values, remoteIP string) (bool, error)
+	// Lookup 查询激活码对应的应用、时长和权益，激活码不存在时返回 errcode.CardNotFound
+	Lookup(value string) (Info, error)
+}
@@ -0,0 +1,171 @@
+package redeem
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/captcha"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/setting"
+)
+
+// defaultTitle 没有配置标题时的页面标题
+const defaultTitle = "兑换激活码"
+
+var (
+	// 本地验证码在内存中记录用过的题目，所有请求共用一个实例
+	providerOnce sync.Once
+	provider     captcha.Provider
+	providerErr  error
+)
+
+type service struct {
+	cardRepo card.Repository
+	appRepo  apps.Repository
+	setting  *setting.RedeemSettingS
+}
+
+func NewService() Service {
+	return &service{
+		cardRepo: card.NewRepository(global.DBEngine),
+		appRepo:  apps.NewRepository(global.DBEngine),
+		setting:  global.RedeemSetting,
+	}
+}
+
+func (s *service) Enabled() bool {
+	return s.setting != nil && s.setting.Enabled
+}
+
+func (s *service) Title() string {
+	if s.setting == nil || s.setting.Title == "" {
+		return defaultTitle
+	}
+	return s.setting.Title
+}
+
+func (s *service) getProvider() (captcha.Provider, error) {
+	providerOnce.Do(func() {
+		cfg := setting.CaptchaSettingS{}
+		if s.setting != nil {
+			cfg = s.setting.Captcha
+		}
+		provider, providerErr = captcha.New(captcha.Config{
+			Provider:  cfg.Provider,
+			SiteKey:   cfg.SiteKey,
+			Secret:    cfg.Secret,
+			VerifyURL: cfg.VerifyURL,
+			Timeout:   cfg.Timeout,
+			TTL:       cfg.TTL,
+		})
+	})
+	return provider, providerErr
+}
+
+func (s *service) Challenge() (captcha.Challenge, error) {
+	p, err := s.getProvider()
+	if err != nil {
+		return captcha.Challenge{}, err
+	}
+	return p.Challenge()
+}
+
+func (s *service) VerifyChallenge(ctx context.Context, form url.Values, remoteIP string) (bool, error) {
+	p, err := s.getProvider()
+	if err != nil {
+		return false, err
+	}
+	return p.Verify(ctx, form, remoteIP)
+}
+
+func (s *service) Lookup(value string) (Info, error) {
+	if !s.Enabled() {
+		return Info{}, errcode.NotFound.WithDetails("未开启兑换页面")
+	}
+	c, err := s.cardRepo.GetCardByValue(value)
+	if errors.Is(err, errcode.NotFound) {
+		return Info{}, errcode.CardNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	appName, disabled := "", false
+	result, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{ID: c.AppID})
+	if err != nil {
+		return Info{}, err
+	}
+	if result.Total > 0 {
+		appName = result.List[0].Name
+		disabled = result.List[0].Disabled()
+	}
+	var appSetting *setting.RedeemApp
+	for i := range s.setting.Apps {
+		if s.setting.Apps[i].AppID == c.AppID {
+			appSetting = &s.setting.Apps[i]
+			break
+		}
+	}
+	// 停用的应用和激活、检查接口一样不能使用，不展示打开应用的链接
+	if disabled {
+		info, err := buildInfo(c, appName, nil, time.Now())
+		info.Status = StatusUnavailable
+		return info, err
+	}
+	return buildInfo(c, appName, appSetting, time.Now())
+}
+
+// buildInfo 根据激活码的状态生成页面信息，appSetting 为 nil 时没有权益、说明和深度链接
+func buildInfo(c card.Card, appName string, appSetting *setting.RedeemApp, now time.Time) (Info, error) {
+	info := Info{
+		Value:        c.Value,
+		AppID:        c.AppID,
+		AppName:      appName,
+		Days:         c.Days,
+		Hours:        c.Hours,
+		Minutes:      c.Minutes,
+		TimeType:     c.TimeType,
+		ExpiredAt:    c.ExpiredAt,
+		Entitlements: []string{},
+	}
+	switch {
+	case c.Status == card.StatusLocked || c.Status == card.StatusDeleted:
+		info.Status = StatusUnavailable
+	case c.ExpiredAt != nil && now.After(*c.ExpiredAt):
+		info.Status = StatusExpired
+	case c.Status == card.StatusUsed:
+		info.Status = StatusActivated
+	default:
+		info.Status = StatusAvailable
+	}
+	if appSetting == nil {
+		return info, nil
+	}
+
+	info.Instructions = appSetting.Instructions
+	if appSetting.Entitlements != nil {
+		info.Entitlements = appSetting.Entitlements
+	}
+	if appSetting.DeepLink == "" || (info.Status != StatusAvailable && info.Status != StatusActivated) {
+		return info, nil
+	}
+	tmpl, err := template.New("deep_link").Option("missingkey=zero").Parse(appSetting.DeepLink)
+	if err != nil {
+		return Info{}, err
+	}
+	// 激活码可能是外部导入的，先进行 URL 编码
+	var b strings.Builder
+	if err := tmpl.Execute(&b, struct{ Value, AppID string }{url.QueryEscape(c.Value), c.AppID}); err != nil {
+		return Info{}, err
+	}
+	info.DeepLink = b.String()
+	return info, nil
+}
@@ -0,0 +1,46 @@
+package redeem
+
+import (
+	"testing"
+	"time"
+
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/setting"
+)
+
+func TestBuildInfo(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.Local)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	appSetting := &setting.RedeemApp{
+		AppID:        "a1",
+		DeepLink:     "myapp://redeem?code={{.Value}}&app={{.AppID}}",
+		Instructions: "打开应用",
+		Entitlements: []string{"去广告"},
+	}
+
+	cases := []struct {
+		card     card.Card
+		status   string
+		deepLink string
+	}{
+		{card.Card{Value: "AB 01", AppID: "a1", Status: card.StatusUnused}, StatusAvailable, "myapp://redeem?code=AB+01&app=a1"},
+		{card.Card{Value: "AB02", AppID: "a1", Status: card.StatusUsed, ExpiredAt: &future}, StatusActivated, "myapp://redeem?code=AB02&app=a1"},
+		{card.Card{Value: "AB03", AppID: "a1", Status: card.StatusUsed, ExpiredAt: &past}, StatusExpired, ""},
+		{card.Card{Value: "AB04", AppID: "a1", Status: card.StatusLocked}, StatusUnavailable, ""},
+	}
+	for _, c := range cases {
+		info, err := buildInfo(c.card, "App", appSetting, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Status != c.status || info.DeepLink != c.deepLink || info.AppName != "App" || len(info.Entitlements) != 1 {
+			t.Fatalf("unexpected info for %s: %+v", c.card.Value, info)
+		}
+	}
+
+	// 没有配置的应用只展示基本信息
+	info, err := buildInfo(card.Card{Value: "AB05", Status: card.StatusUnused}, "", nil, now)
+	if err != nil || info.DeepLink != "" || info.Instructions != "" || info.Entitlements == nil {
+		t.Fatalf("unexpected info: %+v, %v", info, err)
+	}
+}
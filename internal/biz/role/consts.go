@@ -0,0 +1,13 @@
+package role
+
+const (
+	// 内置角色，不存储在 role 表中
+	BuiltinRoot           = "root"
+	BuiltinAdmin          = "admin"
+	BuiltinServiceAccount = "service_account"
+)
+
+// IsBuiltin 检查是否为内置角色
+func IsBuiltin(name string) bool {
+	return name == BuiltinRoot || name == BuiltinAdmin || name == BuiltinServiceAccount
+}
@@ -0,0 +1,77 @@
+package role
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DBStruct struct {
+	ID          string          `json:"id"`                           // 角色唯一标识符(UUID)
+	Name        string          `json:"name"`                         // 角色名称，用户的 roles 字段中保存的就是该名称
+	Description string          `json:"description"`                  // 角色描述
+	Permissions json.RawMessage `json:"permissions" gorm:"type:json"` // 角色拥有的权限
+	CreatorID   string          `json:"creator_id"`                   // 创建者ID
+	CreatedAt   time.Time       `json:"created_at"`                   // 创建时间
+	UpdatedAt   time.Time       `json:"updated_at"`                   // 更新时间
+}
+
+func (s *DBStruct) TableName() string {
+	return "role"
+}
+
+func (s *DBStruct) ToModel() (Role, error) {
+	role := Role{
+		ID:          s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		CreatorID:   s.CreatorID,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	if len(s.Permissions) > 0 {
+		if err := json.Unmarshal(s.Permissions, &role.Permissions); err != nil {
+			return Role{}, err
+		}
+	}
+	return role, nil
+}
+
+func BatchToModel(dbStructs []DBStruct) ([]Role, error) {
+	roles := make([]Role, 0, len(dbStructs))
+	for _, dbStruct := range dbStructs {
+		role, err := dbStruct.ToModel()
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// Role 角色，将一组权限打包后分配给用户
+type Role struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatorID   string    `json:"creator_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (r *Role) ToDBStruct() DBStruct {
+	permissions := r.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	permissionsJson, _ := json.Marshal(permissions)
+	return DBStruct{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissionsJson,
+		CreatorID:   r.CreatorID,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package role
+
+type Repository interface {
+	GetRoleByID(id string) (Role, error)
+	GetRoleByName(name string) (Role, error)
+	GetRolesByNames(names []string) ([]Role, error)
+	QueryRoleList(args QueryRoleListArgs) (QueryRoleListResult, error)
+	CreateRole(role Role) error
+	UpdateRole(role Role) error
+	DeleteRole(id string) error
+	CountUsersWithRole(name string) (int64, error)
+}
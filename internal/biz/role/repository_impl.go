@@ -0,0 +1,153 @@
+package role
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+/*
+表结构如下：
+CREATE TABLE role (
+    id          VARCHAR(32)  NOT NULL PRIMARY KEY, -- 角色唯一标识符
+    name        VARCHAR(64)  NOT NULL UNIQUE,      -- 角色名称
+    description VARCHAR(255) NULL,                 -- 角色描述
+    permissions JSON         NULL,                 -- 角色拥有的权限
+    creator_id  VARCHAR(32)  NOT NULL,             -- 创建者ID
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetRoleByID(id string) (Role, error) {
+	var dbStruct DBStruct
+	if err := r.db.Table(dbStruct.TableName()).Where("id = ?", id).First(&dbStruct).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			global.Logger.WithFields(logger.Fields{
+				"id":     id,
+				"error:": err,
+			}).Error("查询时记录不存在")
+			return Role{}, errcode.NotFound
+		}
+		return Role{}, err
+	}
+	return dbStruct.ToModel()
+}
+
+func (r *repository) GetRoleByName(name string) (Role, error) {
+	var dbStruct DBStruct
+	if err := r.db.Table(dbStruct.TableName()).Where("name = ?", name).First(&dbStruct).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Role{}, errcode.NotFound
+		}
+		return Role{}, err
+	}
+	return dbStruct.ToModel()
+}
+
+func (r *repository) GetRolesByNames(names []string) ([]Role, error) {
+	if len(names) == 0 {
+		return []Role{}, nil
+	}
+	var dbStructs []DBStruct
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("name IN (?)", names).Find(&dbStructs).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"names":  names,
+			"error:": err,
+		}).Error("查询角色失败")
+		return nil, err
+	}
+	return BatchToModel(dbStructs)
+}
+
+func (r *repository) QueryRoleList(args QueryRoleListArgs) (QueryRoleListResult, error) {
+	db := r.db.Table((&DBStruct{}).TableName())
+	if args.Name != "" {
+		db = db.Where("name like ?", "%"+args.Name+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryRoleListResult{}, err
+	}
+
+	if args.Page != 0 {
+		db = db.Offset((args.Page - 1) * args.Limit)
+	}
+	if args.Limit != 0 {
+		db = db.Limit(args.Limit)
+	}
+
+	var dbStructs = make([]DBStruct, 0)
+	if err := db.Order("created_at desc").Find(&dbStructs).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"error:": err,
+		}).Error("查询角色列表失败")
+		return QueryRoleListResult{}, err
+	}
+	roles, err := BatchToModel(dbStructs)
+	if err != nil {
+		return QueryRoleListResult{}, err
+	}
+	return QueryRoleListResult{
+		Total: int(total),
+		List:  roles,
+	}, nil
+}
+
+func (r *repository) CreateRole(role Role) error {
+	dbStruct := role.ToDBStruct()
+	if err := r.db.Table(dbStruct.TableName()).Create(&dbStruct).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"role": role,
+		}).Error("创建角色失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) UpdateRole(role Role) error {
+	dbStruct := role.ToDBStruct()
+	if err := r.db.Table(dbStruct.TableName()).Where("id = ?", role.ID).Updates(map[string]interface{}{
+		"description": dbStruct.Description,
+		"permissions": dbStruct.Permissions,
+		"updated_at":  dbStruct.UpdatedAt,
+	}).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"role": role,
+		}).Error("更新角色失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) DeleteRole(id string) error {
+	if err := r.db.Table((&DBStruct{}).TableName()).Where("id = ?", id).Delete(&DBStruct{}).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("删除角色失败", err)
+		return err
+	}
+	return nil
+}
+
+// CountUsersWithRole 统计拥有某个角色的用户数量
+func (r *repository) CountUsersWithRole(name string) (int64, error) {
+	var count int64
+	if err := r.db.Table("user").Where("JSON_CONTAINS(roles, JSON_QUOTE(?))", name).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
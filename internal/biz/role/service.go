@@ -0,0 +1,34 @@
+package role
+
+type QueryRoleListArgs struct {
+	Name  string `json:"name"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+}
+
+type QueryRoleListResult struct {
+	List  []Role
+	Total int
+}
+
+type CreateRoleArgs struct {
+	CreatorID   string   `json:"creator_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleArgs struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type Service interface {
+	GetRoleByID(id string) (Role, error)
+	GetRolesByNames(names []string) ([]Role, error)
+	QueryRoleList(args QueryRoleListArgs) (QueryRoleListResult, error)
+	CreateRole(args CreateRoleArgs) error
+	UpdateRole(args UpdateRoleArgs) error
+	DeleteRole(id string) error
+}
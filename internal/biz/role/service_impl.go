@@ -0,0 +1,126 @@
+package role
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/permissions"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/utils"
+)
+
+type service struct {
+	repo Repository
+}
+
+func NewService() Service {
+	return &service{
+		repo: NewRepository(global.DBEngine),
+	}
+}
+
+func (s *service) GetRoleByID(id string) (Role, error) {
+	return s.repo.GetRoleByID(id)
+}
+
+func (s *service) GetRolesByNames(names []string) ([]Role, error) {
+	return s.repo.GetRolesByNames(names)
+}
+
+func (s *service) QueryRoleList(args QueryRoleListArgs) (QueryRoleListResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	}
+	return s.repo.QueryRoleList(args)
+}
+
+func (s *service) CreateRole(args CreateRoleArgs) error {
+	if IsBuiltin(args.Name) {
+		return errcode.InvalidParams.WithDetails("不能使用内置角色名称")
+	}
+	if err := checkPermissions(args.Permissions); err != nil {
+		return err
+	}
+
+	// 检查角色是否已经存在
+	_, err := s.repo.GetRoleByName(args.Name)
+	if err == nil {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[CreateRole] 角色已经存在")
+		return errcode.DuplicateKey.WithDetails("角色已经存在")
+	}
+	if !errors.Is(err, errcode.NotFound) {
+		return err
+	}
+
+	now := time.Now()
+	return s.repo.CreateRole(Role{
+		ID:          utils.GenerateUUID(),
+		Name:        args.Name,
+		Description: args.Description,
+		Permissions: permissions.Merge(args.Permissions),
+		CreatorID:   args.CreatorID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (s *service) UpdateRole(args UpdateRoleArgs) error {
+	if err := checkPermissions(args.Permissions); err != nil {
+		return err
+	}
+
+	role, err := s.repo.GetRoleByID(args.ID)
+	if err != nil {
+		if errors.Is(err, errcode.NotFound) {
+			return errcode.NotFound.WithDetails("角色不存在")
+		}
+		return err
+	}
+
+	role.Description = args.Description
+	role.Permissions = permissions.Merge(args.Permissions)
+	role.UpdatedAt = time.Now()
+	return s.repo.UpdateRole(role)
+}
+
+func (s *service) DeleteRole(id string) error {
+	role, err := s.repo.GetRoleByID(id)
+	if err != nil {
+		if errors.Is(err, errcode.NotFound) {
+			return errcode.NotFound.WithDetails("角色不存在")
+		}
+		return err
+	}
+
+	// 仍有用户使用的角色不能删除
+	count, err := s.repo.CountUsersWithRole(role.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		global.Logger.WithFields(logger.Fields{
+			"role":  role,
+			"count": count,
+		}).Info("[DeleteRole] 角色仍在使用中")
+		return errcode.InvalidParams.WithDetails("角色仍在使用中")
+	}
+
+	return s.repo.DeleteRole(id)
+}
+
+// checkPermissions 检查权限是否都可以分配给自定义角色
+func checkPermissions(list []string) error {
+	for _, p := range list {
+		if !permissions.IsAllowed(p) {
+			return errcode.InvalidParams.WithDetails("invalid permission: " + p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package session
+
+import "time"
+
+const (
+	// touchInterval 最近活跃时间的更新间隔，避免每个请求都写数据库
+	touchInterval = time.Minute
+	// validCacheTTL 校验通过的会话在内存中缓存的时间
+	validCacheTTL = time.Minute
+)
@@ -0,0 +1,24 @@
+package session
+
+import "time"
+
+// Session 一次登录产生的会话，token 中的 sid 指向这里
+type Session struct {
+	ID         string     `json:"id"`           // 唯一标识符(UUID)
+	UserID     string     `json:"user_id"`      // 所属用户ID
+	IP         string     `json:"ip"`           // 登录时的IP
+	UserAgent  string     `json:"user_agent"`   // 登录时的 User-Agent
+	ExpiresAt  time.Time  `json:"expires_at"`   // 过期时间，与 token 的过期时间一致
+	LastSeenAt time.Time  `json:"last_seen_at"` // 最近活跃时间
+	RevokedAt  *time.Time `json:"revoked_at"`   // 吊销时间
+	CreatedAt  time.Time  `json:"created_at"`   // 创建时间
+}
+
+func (s *Session) TableName() string {
+	return "user_session"
+}
+
+// IsActive 未吊销且未过期
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package session
+
+import "time"
+
+type Repository interface {
+	GetSessionByID(id string) (Session, error)
+	GetActiveSessionsByUserID(userID string, now time.Time) ([]Session, error)
+	CreateSession(session Session) error
+	// RevokeSessions 吊销用户的会话，ids 为空时吊销除 exceptID 之外的所有会话，返回被吊销的会话ID
+	RevokeSessions(userID string, ids []string, exceptID string, revokedAt time.Time) ([]string, error)
+	UpdateLastSeenAt(id string, lastSeenAt time.Time) error
+}
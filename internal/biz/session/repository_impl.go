@@ -0,0 +1,102 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+/*
+表结构如下：
+CREATE TABLE user_session (
+    id           VARCHAR(32)  NOT NULL PRIMARY KEY, -- 唯一标识符，写入 token 的 sid
+    user_id      VARCHAR(32)  NOT NULL,             -- 所属用户ID
+    ip           VARCHAR(64)  NOT NULL,             -- 登录时的IP
+    user_agent   VARCHAR(512) NOT NULL,             -- 登录时的 User-Agent
+    expires_at   TIMESTAMP    NOT NULL,             -- 过期时间
+    last_seen_at TIMESTAMP    NOT NULL,             -- 最近活跃时间
+    revoked_at   TIMESTAMP    NULL,                 -- 吊销时间
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetSessionByID(id string) (Session, error) {
+	var session Session
+	if err := r.db.Table(session.TableName()).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Session{}, errcode.NotFound
+		}
+		return Session{}, err
+	}
+	return session, nil
+}
+
+func (r *repository) GetActiveSessionsByUserID(userID string, now time.Time) ([]Session, error) {
+	sessions := make([]Session, 0)
+	if err := r.db.Table((&Session{}).TableName()).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").Find(&sessions).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": userID,
+		}).Error("查询会话列表失败", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *repository) CreateSession(session Session) error {
+	if err := r.db.Table(session.TableName()).Create(&session).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": session.UserID,
+		}).Error("创建会话失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) RevokeSessions(userID string, ids []string, exceptID string, revokedAt time.Time) ([]string, error) {
+	revoked := make([]string, 0)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		db := tx.Table((&Session{}).TableName()).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if len(ids) > 0 {
+			db = db.Where("id IN (?)", ids)
+		}
+		if exceptID != "" {
+			db = db.Where("id != ?", exceptID)
+		}
+		if err := db.Pluck("id", &revoked).Error; err != nil {
+			return err
+		}
+		if len(revoked) == 0 {
+			return nil
+		}
+		return tx.Table((&Session{}).TableName()).Where("id IN (?)", revoked).
+			Update("revoked_at", revokedAt).Error
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": userID,
+			"ids":     ids,
+		}).Error("吊销会话失败", err)
+		return nil, err
+	}
+	return revoked, nil
+}
+
+func (r *repository) UpdateLastSeenAt(id string, lastSeenAt time.Time) error {
+	return r.db.Table((&Session{}).TableName()).Where("id = ?", id).
+		Update("last_seen_at", lastSeenAt).Error
+}
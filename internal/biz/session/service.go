@@ -0,0 +1,22 @@
+package session
+
+type CreateSessionArgs struct {
+	UserID    string `json:"user_id"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+type RevokeSessionArgs struct {
+	UserID string `json:"user_id"` // 只能吊销自己的会话
+	ID     string `json:"id"`
+}
+
+type Service interface {
+	CreateSession(args CreateSessionArgs) (Session, error)
+	GetActiveSessions(userID string) ([]Session, error)
+	RevokeSession(args RevokeSessionArgs) error
+	// RevokeOtherSessions 吊销用户除 keepID 之外的所有会话，keepID 为空时吊销全部
+	RevokeOtherSessions(userID string, keepID string) error
+	// Validate 校验会话是否仍然有效，并更新最近活跃时间
+	Validate(id string) error
+}
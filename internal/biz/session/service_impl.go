@@ -0,0 +1,111 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/utils"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// validSessions 缓存最近校验通过的会话，吊销时从缓存中删除
+var validSessions = cache.New(validCacheTTL, 5*time.Minute)
+
+type service struct {
+	repo Repository
+}
+
+func NewService() Service {
+	return &service{
+		repo: NewRepository(global.DBEngine),
+	}
+}
+
+func (s *service) CreateSession(args CreateSessionArgs) (Session, error) {
+	now := time.Now()
+	session := Session{
+		ID:         utils.GenerateUUID(),
+		UserID:     args.UserID,
+		IP:         args.IP,
+		UserAgent:  truncate(args.UserAgent, 512),
+		ExpiresAt:  now.Add(app.TokenExp),
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+func (s *service) GetActiveSessions(userID string) ([]Session, error) {
+	return s.repo.GetActiveSessionsByUserID(userID, time.Now())
+}
+
+func (s *service) RevokeSession(args RevokeSessionArgs) error {
+	revoked, err := s.repo.RevokeSessions(args.UserID, []string{args.ID}, "", time.Now())
+	if err != nil {
+		return err
+	}
+	if len(revoked) == 0 {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[RevokeSession] 会话不存在或已经吊销")
+		return errcode.NotFound.WithDetails("会话不存在")
+	}
+	forget(revoked)
+	return nil
+}
+
+func (s *service) RevokeOtherSessions(userID string, keepID string) error {
+	revoked, err := s.repo.RevokeSessions(userID, nil, keepID, time.Now())
+	if err != nil {
+		return err
+	}
+	forget(revoked)
+	return nil
+}
+
+func (s *service) Validate(id string) error {
+	if _, ok := validSessions.Get(id); ok {
+		return nil
+	}
+
+	session, err := s.repo.GetSessionByID(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if !session.IsActive(now) {
+		return errors.New("会话已经失效")
+	}
+
+	if now.Sub(session.LastSeenAt) > touchInterval {
+		if err := s.repo.UpdateLastSeenAt(session.ID, now); err != nil {
+			// 更新活跃时间失败不影响本次请求
+			global.Logger.WithFields(logger.Fields{
+				"session_id": session.ID,
+			}).Error("更新会话活跃时间失败", err)
+		}
+	}
+	validSessions.Set(id, struct{}{}, cache.DefaultExpiration)
+	return nil
+}
+
+func forget(ids []string) {
+	for _, id := range ids {
+		validSessions.Delete(id)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,143 @@
+package session
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+)
+
+type fakeRepository struct {
+	Repository
+	sessions map[string]Session
+}
+
+func (f *fakeRepository) GetSessionByID(id string) (Session, error) {
+	session, ok := f.sessions[id]
+	if !ok {
+		return Session{}, errcode.NotFound
+	}
+	return session, nil
+}
+
+func (f *fakeRepository) CreateSession(session Session) error {
+	f.sessions[session.ID] = session
+	return nil
+}
+
+func (f *fakeRepository) RevokeSessions(userID string, ids []string, exceptID string, revokedAt time.Time) ([]string, error) {
+	revoked := make([]string, 0)
+	for id, session := range f.sessions {
+		if session.UserID != userID || session.RevokedAt != nil || id == exceptID {
+			continue
+		}
+		if len(ids) > 0 && !contains(ids, id) {
+			continue
+		}
+		session.RevokedAt = &revokedAt
+		f.sessions[id] = session
+		revoked = append(revoked, id)
+	}
+	return revoked, nil
+}
+
+func (f *fakeRepository) UpdateLastSeenAt(id string, lastSeenAt time.Time) error {
+	session := f.sessions[id]
+	session.LastSeenAt = lastSeenAt
+	f.sessions[id] = session
+	return nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestService(t *testing.T) *service {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+	t.Cleanup(validSessions.Flush)
+	return &service{repo: &fakeRepository{sessions: make(map[string]Session)}}
+}
+
+func createSession(t *testing.T, s *service, userID string) Session {
+	session, err := s.CreateSession(CreateSessionArgs{UserID: userID})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	return session
+}
+
+func TestRevokeSession(t *testing.T) {
+	s := newTestService(t)
+	session := createSession(t, s, "u1")
+
+	// 先校验一次，让会话进入缓存，吊销后缓存也必须失效
+	if err := s.Validate(session.ID); err != nil {
+		t.Fatalf("Validate() before revoke error = %v", err)
+	}
+	if err := s.RevokeSession(RevokeSessionArgs{UserID: "u1", ID: session.ID}); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if err := s.Validate(session.ID); err == nil {
+		t.Fatalf("Validate() after revoke error = nil, want error")
+	}
+}
+
+func TestRevokeSessionOfOtherUser(t *testing.T) {
+	s := newTestService(t)
+	session := createSession(t, s, "u1")
+
+	err := s.RevokeSession(RevokeSessionArgs{UserID: "u2", ID: session.ID})
+	var e *errcode.Error
+	if !errors.As(err, &e) || e.Code() != errcode.NotFound.Code() {
+		t.Fatalf("RevokeSession() error = %v, want NotFound", err)
+	}
+	if err := s.Validate(session.ID); err != nil {
+		t.Fatalf("Validate() error = %v, session of u1 must stay valid", err)
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	s := newTestService(t)
+	current := createSession(t, s, "u1")
+	other := createSession(t, s, "u1")
+	foreign := createSession(t, s, "u2")
+	for _, id := range []string{current.ID, other.ID, foreign.ID} {
+		if err := s.Validate(id); err != nil {
+			t.Fatalf("Validate(%s) error = %v", id, err)
+		}
+	}
+
+	if err := s.RevokeOtherSessions("u1", current.ID); err != nil {
+		t.Fatalf("RevokeOtherSessions() error = %v", err)
+	}
+	if err := s.Validate(current.ID); err != nil {
+		t.Errorf("Validate(current) error = %v, want nil", err)
+	}
+	if err := s.Validate(other.ID); err == nil {
+		t.Errorf("Validate(other) error = nil, want error")
+	}
+	if err := s.Validate(foreign.ID); err != nil {
+		t.Errorf("Validate(foreign) error = %v, want nil", err)
+	}
+}
+
+func TestValidateExpiredSession(t *testing.T) {
+	s := newTestService(t)
+	session := createSession(t, s, "u1")
+	repo := s.repo.(*fakeRepository)
+	session.ExpiresAt = time.Now().Add(-time.Second)
+	repo.sessions[session.ID] = session
+
+	if err := s.Validate(session.ID); err == nil {
+		t.Fatalf("Validate() error = nil, want error for expired session")
+	}
+}
@@ -0,0 +1,17 @@
+package sso
+
+import "time"
+
+// Identity 外部身份提供方的账号与本地用户的绑定
+type Identity struct {
+	ID          string    `json:"id"`            // 唯一标识符(UUID)
+	Issuer      string    `json:"issuer"`        // 身份提供方
+	Subject     string    `json:"subject"`       // 身份提供方中的用户标识(sub)
+	UserID      string    `json:"user_id"`       // 本地用户ID
+	CreatedAt   time.Time `json:"created_at"`    // 首次登录时间
+	LastLoginAt time.Time `json:"last_login_at"` // 最近登录时间
+}
+
+func (i *Identity) TableName() string {
+	return "user_identity"
+}
@@ -0,0 +1,9 @@
+package sso
+
+import "time"
+
+type Repository interface {
+	GetIdentity(issuer string, subject string) (Identity, error)
+	CreateIdentity(identity Identity) error
+	UpdateLastLoginAt(id string, lastLoginAt time.Time) error
+}
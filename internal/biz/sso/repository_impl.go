@@ -0,0 +1,60 @@
+package sso
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+/*
+表结构如下：
+CREATE TABLE user_identity (
+    id            VARCHAR(32)  NOT NULL PRIMARY KEY, -- 唯一标识符
+    issuer        VARCHAR(255) NOT NULL,             -- 身份提供方
+    subject       VARCHAR(255) NOT NULL,             -- 身份提供方中的用户标识
+    user_id       VARCHAR(32)  NOT NULL,             -- 本地用户ID
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (issuer, subject)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetIdentity(issuer string, subject string) (Identity, error) {
+	var identity Identity
+	if err := r.db.Table(identity.TableName()).Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Identity{}, errcode.NotFound
+		}
+		return Identity{}, err
+	}
+	return identity, nil
+}
+
+func (r *repository) CreateIdentity(identity Identity) error {
+	if err := r.db.Table(identity.TableName()).Create(&identity).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"identity": identity,
+		}).Error("创建外部身份绑定失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) UpdateLastLoginAt(id string, lastLoginAt time.Time) error {
+	return r.db.Table((&Identity{}).TableName()).Where("id = ?", id).
+		Update("last_login_at", lastLoginAt).Error
+}
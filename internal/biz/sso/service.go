@@ -0,0 +1,21 @@
+package sso
+
+import (
+	"context"
+
+	"configuration-management/internal/biz/user"
+)
+
+type FinishLoginArgs struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type Service interface {
+	// Enabled 是否开启了 OIDC 登录
+	Enabled() bool
+	// BeginLogin 生成 state、nonce 和 PKCE 参数，返回身份提供方的授权地址
+	BeginLogin(ctx context.Context) (string, error)
+	// FinishLogin 校验回调并返回对应的本地用户，首次登录时自动创建
+	FinishLogin(ctx context.Context, args FinishLoginArgs) (user.User, error)
+}
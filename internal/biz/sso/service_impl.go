@@ -0,0 +1,254 @@
+package sso
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/oidc"
+	"configuration-management/pkg/setting"
+	"configuration-management/utils"
+
+	"github.com/patrickmn/go-cache"
+	"gorm.io/gorm"
+)
+
+var (
+	// loginStateCache 保存未完成的登录请求，key 为 state
+	loginStateCache    = cache.New(loginStateCacheTTL, loginStateCacheTTL)
+	loginStateCacheTTL = time.Minute * 10
+
+	providerMu sync.Mutex
+	provider   *oidc.Provider // 发现文档只在第一次使用时读取，失败时下次重试
+)
+
+type loginState struct {
+	nonce        string
+	codeVerifier string
+}
+
+type service struct {
+	db       *gorm.DB
+	repo     Repository
+	userRepo user.Repository
+	setting  *setting.OIDCSettingS
+}
+
+func NewService() Service {
+	return &service{
+		db:       global.DBEngine,
+		repo:     NewRepository(global.DBEngine),
+		userRepo: user.NewRepository(global.DBEngine),
+		setting:  global.OIDCSetting,
+	}
+}
+
+func (s *service) Enabled() bool {
+	return s.setting != nil && s.setting.Enabled
+}
+
+func (s *service) BeginLogin(ctx context.Context) (string, error) {
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := oidc.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := oidc.GeneratePKCE()
+	if err != nil {
+		return "", err
+	}
+	loginStateCache.SetDefault(state, loginState{nonce: nonce, codeVerifier: verifier})
+
+	return p.AuthCodeURL(state, nonce, challenge), nil
+}
+
+func (s *service) FinishLogin(ctx context.Context, args FinishLoginArgs) (user.User, error) {
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	// state 只能使用一次
+	value, ok := loginStateCache.Get(args.State)
+	if !ok {
+		return user.User{}, errcode.UnauthorizedTokenError.WithDetails("登录请求不存在或已过期")
+	}
+	loginStateCache.Delete(args.State)
+	state := value.(loginState)
+
+	token, err := p.Exchange(ctx, args.Code, state.codeVerifier)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"state": args.State,
+		}).Error("[FinishLogin] 换取令牌失败", err)
+		return user.User{}, errcode.UnauthorizedTokenError.WithDetails("换取令牌失败")
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, state.nonce)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"state": args.State,
+		}).Error("[FinishLogin] ID Token 校验失败", err)
+		return user.User{}, errcode.UnauthorizedTokenError.WithDetails("ID Token 校验失败")
+	}
+
+	return s.provision(claims)
+}
+
+// provision 根据声明找到或创建本地用户，并同步分组映射的角色和应用
+func (s *service) provision(claims oidc.Claims) (user.User, error) {
+	subject := claims.String("sub")
+	if subject == "" {
+		return user.User{}, errcode.UnauthorizedTokenError.WithDetails("ID Token 缺少 sub")
+	}
+	username, roles, apps := MapClaims(claims, *s.setting)
+	now := time.Now()
+
+	identity, err := s.repo.GetIdentity(s.setting.Issuer, subject)
+	if err != nil && !errors.Is(err, errcode.NotFound) {
+		return user.User{}, err
+	}
+
+	// 已经绑定过的用户
+	if err == nil {
+		u, err := s.userRepo.GetUserByID(identity.UserID)
+		if err != nil {
+			return user.User{}, err
+		}
+		if u.Status != user.StatusNormal {
+			return user.User{}, errcode.NoPermission.WithDetails("用户已经被停封")
+		}
+		if len(s.setting.GroupMappings) > 0 {
+			u.Roles = roles
+			u.Apps = apps
+			if err := s.userRepo.UpdateUser(u); err != nil {
+				return user.User{}, err
+			}
+		}
+		if err := s.repo.UpdateLastLoginAt(identity.ID, now); err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"identity": identity,
+			}).Error("[provision] 更新最近登录时间失败", err)
+		}
+		return u, nil
+	}
+
+	// 首次登录，不能和本地已有的用户名冲突，否则任何人都可以通过身份提供方接管本地账号
+	if username == "" {
+		return user.User{}, errcode.UnauthorizedTokenError.WithDetails("ID Token 缺少用户名")
+	}
+	if existing, _ := s.userRepo.GetUserByUsername(username); existing.ID != "" {
+		global.Logger.WithFields(logger.Fields{
+			"username": username,
+			"subject":  subject,
+		}).Info("[provision] 用户名已经存在")
+		return user.User{}, errcode.DuplicateKey.WithDetails("用户名已经存在")
+	}
+
+	u := user.User{
+		ID:          utils.GenerateUUID(),
+		Username:    username,
+		Password:    utils.GenerateUUID(), // 单点登录的用户不使用本地密码
+		Status:      user.StatusNormal,
+		CreatedAt:   now,
+		Roles:       roles,
+		Apps:        apps,
+		Permissions: user.Permissions{},
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := user.NewRepository(tx).CreateUser(u); err != nil {
+			return err
+		}
+		return NewRepository(tx).CreateIdentity(Identity{
+			ID:          utils.GenerateUUID(),
+			Issuer:      s.setting.Issuer,
+			Subject:     subject,
+			UserID:      u.ID,
+			CreatedAt:   now,
+			LastLoginAt: now,
+		})
+	})
+	if err != nil {
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+func (s *service) getProvider(ctx context.Context) (*oidc.Provider, error) {
+	if !s.Enabled() {
+		return nil, errcode.NotFound.WithDetails("未开启单点登录")
+	}
+
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider != nil {
+		return provider, nil
+	}
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       s.setting.Issuer,
+		ClientID:     s.setting.ClientID,
+		ClientSecret: s.setting.ClientSecret,
+		RedirectURL:  s.setting.RedirectURL,
+		Scopes:       s.setting.Scopes,
+	}, nil)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"issuer": s.setting.Issuer,
+		}).Error("读取 OIDC 发现文档失败", err)
+		return nil, errcode.ServerError.WithDetails("身份提供方不可用")
+	}
+	provider = p
+	return provider, nil
+}
+
+// MapClaims 根据配置从声明中取出用户名，并把分组映射为角色和应用
+func MapClaims(claims oidc.Claims, cfg setting.OIDCSettingS) (username string, roles user.Roles, apps user.Apps) {
+	usernameClaim := cfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	groupsClaim := cfg.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+
+	username = claims.String(usernameClaim)
+	roles = user.Roles{user.RoleAdmin}
+	apps = user.Apps{}
+	seen := make(map[string]bool)
+	for _, group := range claims.Strings(groupsClaim) {
+		for _, mapping := range cfg.GroupMappings {
+			if mapping.Group != group {
+				continue
+			}
+			for _, r := range mapping.Roles {
+				// 内置角色不能通过分组获得
+				if r == user.RoleRoot || r == user.RoleAdmin || r == user.RoleServiceAccount || seen["role:"+r] {
+					continue
+				}
+				seen["role:"+r] = true
+				roles = append(roles, r)
+			}
+			for _, a := range mapping.Apps {
+				if seen["app:"+a] {
+					continue
+				}
+				seen["app:"+a] = true
+				apps = append(apps, a)
+			}
+		}
+	}
+	return username, roles, apps
+}
@@ -0,0 +1,44 @@
+package sso
+
+import (
+	"testing"
+
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/oidc"
+	"configuration-management/pkg/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapClaims(t *testing.T) {
+	cfg := setting.OIDCSettingS{
+		GroupMappings: []setting.OIDCGroupMapping{
+			{Group: "cm-operators", Roles: []string{"operator"}, Apps: []string{"app-a"}},
+			{Group: "cm-app-b", Apps: []string{"app-b", "app-a"}},
+			{Group: "cm-escalate", Roles: []string{"root", "service_account"}},
+		},
+	}
+	claims := oidc.Claims{
+		"preferred_username": "alice",
+		"groups":             []any{"cm-operators", "cm-app-b", "cm-escalate", "unrelated"},
+	}
+
+	username, roles, apps := MapClaims(claims, cfg)
+	assert.Equal(t, "alice", username)
+	assert.Equal(t, user.Roles{"admin", "operator"}, roles)
+	assert.Equal(t, user.Apps{"app-a", "app-b"}, apps)
+}
+
+func TestMapClaimsCustomClaimNames(t *testing.T) {
+	cfg := setting.OIDCSettingS{
+		UsernameClaim: "email",
+		GroupsClaim:   "roles",
+		GroupMappings: []setting.OIDCGroupMapping{{Group: "ops", Roles: []string{"operator"}}},
+	}
+	claims := oidc.Claims{"email": "bob@example.com", "roles": "ops"}
+
+	username, roles, apps := MapClaims(claims, cfg)
+	assert.Equal(t, "bob@example.com", username)
+	assert.Equal(t, user.Roles{"admin", "operator"}, roles)
+	assert.Empty(t, apps)
+}
@@ -9,6 +9,17 @@ const (
 
 	RoleAdmin = "admin"
 	RoleRoot  = "root"
+	// RoleServiceAccount 服务账号，不能登录，只能通过 API Key 访问
+	RoleServiceAccount = "service_account"
+
+	AncestrySeparator = "/"
+	// MaxAncestryLength ancestry 字段的长度，每一级占 33 个字符，大约可以容纳 31 级代理
+	MaxAncestryLength = 1024
+
+	// 删除用户时对其激活码的处理方式
+	CascadeLock     = "lock"     // 锁定所有未使用的激活码
+	CascadeTransfer = "transfer" // 把所有激活码转移给其他用户
+	CascadeOrphan   = "orphan"   // 保留激活码，不做处理
 )
 
 var (
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -238,7 +239,8 @@ type User struct {
 	Roles        Roles       `json:"roles" structs:"roles"`               // 用户角色
 	Introduction string      `json:"introduction" structs:"introduction"` // 介绍
 	Avatar       string      `json:"avatar" structs:"avatar"`             // 头像
-	MaxCnt       int         `json:"max_cnt" structs:"max_cnt"`           // 最大激活码数量
+	MaxCnt       int         `json:"max_cnt" structs:"max_cnt"`           // 累计获得的额度
+	QuotaBalance int64       `json:"quota_balance" structs:"-" gorm:"-"`  // 当前可用额度，由额度流水汇总得到
 	Permissions  Permissions `json:"permissions" structs:"permissions"`   // 用户权限
 	Apps         Apps        `json:"apps" structs:"apps"`                 // 用户有权限的应用
 }
@@ -267,6 +269,7 @@ func (u *User) ToView() UserView {
 		LockedCnt:    u.LockedCnt,
 		CreatedAt:    createdAt,
 		MaxCnt:       u.MaxCnt,
+		QuotaBalance: u.QuotaBalance,
 		Roles:        u.Roles,
 		Introduction: u.Introduction,
 		Avatar:       u.Avatar,
@@ -333,6 +336,30 @@ func (u *User) HasApp(appId string) bool {
 	return false
 }
 
+// SubtreePath 返回该用户作为祖先时，下级用户 ancestry 字段的前缀
+// ancestry 是以 / 分隔的祖先ID路径，例如 rootId/parentId
+func (u *User) SubtreePath() string {
+	if u.Ancestry == "" {
+		return u.ID
+	}
+	return u.Ancestry + AncestrySeparator + u.ID
+}
+
+// ParentID 返回直接上级用户的ID
+func (u *User) ParentID() string {
+	if u.Ancestry == "" {
+		return ""
+	}
+	ancestors := strings.Split(u.Ancestry, AncestrySeparator)
+	return ancestors[len(ancestors)-1]
+}
+
+// IsAncestorOf 检查当前用户是否为 other 的祖先
+func (u *User) IsAncestorOf(other User) bool {
+	path := u.SubtreePath()
+	return other.Ancestry == path || strings.HasPrefix(other.Ancestry, path+AncestrySeparator)
+}
+
 func BatchToView(users []User) []UserView {
 	var userViews []UserView
 	for _, user := range users {
@@ -343,20 +370,21 @@ func BatchToView(users []User) []UserView {
 
 // UserView 仅仅是为了前端展示的 User 结构
 type UserView struct {
-	ID           string      `json:"id"`           // 用户唯一标识符(UUID)
-	Username     string      `json:"username"`     // 用户名
-	Status       int         `json:"status"`       // 状态: -1-停封, 1-正常
-	Ancestry     string      `json:"ancestry"`     // 祖先用户ID（如果有的话）
-	TotalCnt     int         `json:"total_cnt"`    // 总激活码数量
-	UsedCnt      int         `json:"used_cnt"`     // 已使用激活码数量
-	NousedCnt    int         `json:"noused_cnt"`   // 未使用激活码数量
-	DeletedCnt   int         `json:"deleted_cnt"`  // 删除的激活码数量
-	LockedCnt    int         `json:"locked_cnt"`   // 锁定的激活码数量
-	CreatedAt    string      `json:"created_at"`   // 用户创建时间
-	MaxCnt       int         `json:"max_cnt"`      // 最大激活码数量
-	Roles        Roles       `json:"roles"`        // 用户角色
-	Introduction string      `json:"introduction"` // 介绍
-	Avatar       string      `json:"avatar"`       // 头像
-	Permissions  Permissions `json:"permissions"`  // 用户权限
-	Apps         Apps        `json:"apps"`         // 用户有权限的应用
+	ID           string      `json:"id"`            // 用户唯一标识符(UUID)
+	Username     string      `json:"username"`      // 用户名
+	Status       int         `json:"status"`        // 状态: -1-停封, 1-正常
+	Ancestry     string      `json:"ancestry"`      // 祖先用户ID（如果有的话）
+	TotalCnt     int         `json:"total_cnt"`     // 总激活码数量
+	UsedCnt      int         `json:"used_cnt"`      // 已使用激活码数量
+	NousedCnt    int         `json:"noused_cnt"`    // 未使用激活码数量
+	DeletedCnt   int         `json:"deleted_cnt"`   // 删除的激活码数量
+	LockedCnt    int         `json:"locked_cnt"`    // 锁定的激活码数量
+	CreatedAt    string      `json:"created_at"`    // 用户创建时间
+	MaxCnt       int         `json:"max_cnt"`       // 累计获得的额度
+	QuotaBalance int64       `json:"quota_balance"` // 当前可用额度
+	Roles        Roles       `json:"roles"`         // 用户角色
+	Introduction string      `json:"introduction"`  // 介绍
+	Avatar       string      `json:"avatar"`        // 头像
+	Permissions  Permissions `json:"permissions"`   // 用户权限
+	Apps         Apps        `json:"apps"`          // 用户有权限的应用
 }
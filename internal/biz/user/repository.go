@@ -1,5 +1,12 @@
 package user
 
+import (
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/quota"
+	"configuration-management/pkg/app"
+)
+
 type Repository interface {
 	GetUserByID(id string) (User, error)
 	QueryUserList(args QueryUserListArgs) (QueryUserListResult, error)
@@ -7,4 +14,22 @@ type Repository interface {
 	CreateUser(user User) error
 	UpdateUser(user User) error
 	DeleteUser(user User) error
+	CreateUserWithQuota(user User, transfer quota.TransferArgs) error
+	UpdateUserWithQuota(user User, transfer quota.TransferArgs) error
+	GetDescendantIDs(subtreePath string) ([]string, error)
+	DeleteUserWithCascade(args DeleteUserWithCascadeArgs) error
+	DisableUsers(ids []string, actor app.Actor) (int64, error)
+	SaveAppGrant(g grant.Grant) error
+	DeleteAppGrant(userID string, appID string) error
+	// Transaction 在同一个事务中执行 fn，fn 中的修改和审计日志一起提交或回滚
+	Transaction(fn func(repo Repository, auditRepo audit.Repository) error) error
+}
+
+type DeleteUserWithCascadeArgs struct {
+	User        User   // 被删除的用户
+	Cascade     string // 激活码的处理方式
+	TransferTo  User   // cascade 为 transfer 时接收激活码的用户
+	QuotaSource string // 剩余额度退还给谁，为空时表示 root
+	OperatorID  string
+	Actor       app.Actor // 操作人，写入激活码的审计日志
 }
@@ -4,6 +4,11 @@ import (
 	"errors"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/quota"
+	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
 
@@ -67,6 +72,10 @@ func (r *repository) QueryUserList(args QueryUserListArgs) (QueryUserListResult,
 	if args.Status != StatusUnknown {
 		db.Where("status = ?", args.Status)
 	}
+	if args.AncestryPath != "" {
+		// 物化路径查询整棵子树
+		db.Where("(ancestry = ? OR ancestry LIKE ?)", args.AncestryPath, args.AncestryPath+AncestrySeparator+"%")
+	}
 
 	// 获取数量
 	var total int64
@@ -177,3 +186,113 @@ func (r *repository) DeleteUser(user User) error {
 	}
 	return nil
 }
+
+// CreateUserWithQuota 创建用户，并在同一个事务中写入初始额度的流水
+func (r *repository) CreateUserWithQuota(user User, transfer quota.TransferArgs) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		dbStruct := user.ToDBStruct()
+		if err := tx.Table(dbStruct.TableName()).Create(&dbStruct).Error; err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"user_id": user.ID,
+			}).Error("创建用户失败", err)
+			return err
+		}
+		return quota.NewRepository(tx).Transfer(transfer)
+	})
+}
+
+// UpdateUserWithQuota 更新用户，并在同一个事务中划转额度
+func (r *repository) UpdateUserWithQuota(user User, transfer quota.TransferArgs) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := quota.NewRepository(tx).Transfer(transfer); err != nil {
+			return err
+		}
+		return NewRepository(tx).UpdateUser(user)
+	})
+}
+
+// GetDescendantIDs 查询 subtreePath 下所有下级用户的ID
+func (r *repository) GetDescendantIDs(subtreePath string) ([]string, error) {
+	ids := make([]string, 0)
+	if err := r.db.Table((&DBStruct{}).TableName()).
+		Where("ancestry = ? OR ancestry LIKE ?", subtreePath, subtreePath+AncestrySeparator+"%").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// DeleteUserWithCascade 在同一个事务中处理激活码、退还剩余额度并删除用户
+func (r *repository) DeleteUserWithCascade(args DeleteUserWithCascadeArgs) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		cardRepo := card.NewRepository(tx)
+		switch args.Cascade {
+		case CascadeLock:
+			if _, err := cardRepo.LockUnusedCardsByUserIds([]string{args.User.ID}, args.Actor); err != nil {
+				return err
+			}
+		case CascadeTransfer:
+			if _, err := cardRepo.TransferCards(args.User.ID, args.TransferTo.ID, args.TransferTo.Username, args.Actor); err != nil {
+				return err
+			}
+		}
+
+		// 剩余额度退还给上级
+		quotaRepo := quota.NewRepository(tx)
+		balance, err := quotaRepo.GetBalance(args.User.ID)
+		if err != nil {
+			return err
+		}
+		if balance > 0 {
+			if err := quotaRepo.Transfer(quota.TransferArgs{
+				FromID:  args.QuotaSource,
+				ToID:    args.User.ID,
+				Amount:  -int(balance),
+				Reason:  "删除用户时退还剩余额度",
+				ActorID: args.OperatorID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := grant.NewRepository(tx).DeleteGrantsByUserID(args.User.ID); err != nil {
+			return err
+		}
+		return NewRepository(tx).DeleteUser(args.User)
+	})
+}
+
+// DisableUsers 停封用户并锁定他们所有未使用的激活码
+func (r *repository) DisableUsers(ids []string, actor app.Actor) (int64, error) {
+	var locked int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table((&DBStruct{}).TableName()).Where("id IN (?)", ids).
+			Update("status", StatusBanned).Error; err != nil {
+			return err
+		}
+		var err error
+		locked, err = card.NewRepository(tx).LockUnusedCardsByUserIds(ids, actor)
+		return err
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"ids": ids,
+		}).Error("停封用户失败", err)
+		return 0, err
+	}
+	return locked, nil
+}
+
+func (r *repository) SaveAppGrant(g grant.Grant) error {
+	return grant.NewRepository(r.db).SaveGrant(g)
+}
+
+func (r *repository) DeleteAppGrant(userID string, appID string) error {
+	return grant.NewRepository(r.db).DeleteGrant(userID, appID)
+}
+
+func (r *repository) Transaction(fn func(repo Repository, auditRepo audit.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepository(tx), audit.NewRepository(tx))
+	})
+}
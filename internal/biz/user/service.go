@@ -1,5 +1,11 @@
 package user
 
+import (
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/quota"
+	"configuration-management/pkg/app"
+)
+
 type CreateUserArgs struct {
 	CreatorID    string      `json:"creator"`
 	Username     string      `json:"username"`
@@ -8,6 +14,13 @@ type CreateUserArgs struct {
 	Apps         Apps        `json:"apps"`
 	Permissions  Permissions `json:"permissions"`
 	Introduction string      `json:"introduction"`
+
+	ServiceAccount bool      `json:"service_account"` // 是否为服务账号
+	Actor          app.Actor `json:"-"`               // 操作人，写入审计日志
+
+	// GrantorPermissions 创建者在本次请求中的有效权限，通过 API Key 调用时已经和 Key 的权限取交集
+	GrantorPermissions Permissions `json:"-"`
+	ViaAPIKey          bool        `json:"via_api_key"` // 调用方是否通过 API Key 鉴权
 }
 
 type LoginArgs struct {
@@ -20,10 +33,12 @@ type GetUserInfoArgs struct {
 }
 
 type QueryUserListArgs struct {
-	Username string
-	Status   Status
-	Page     int `json:"page"`
-	Limit    int `json:"limit"`
+	ViewerID     string `json:"viewer_id"` // 查询者ID，非 root 只能查询自己的下级
+	AncestryPath string `json:"-"`
+	Username     string
+	Status       Status
+	Page         int `json:"page"`
+	Limit        int `json:"limit"`
 }
 
 type QueryUserListResult struct {
@@ -40,11 +55,93 @@ type UpdateUserArgs struct {
 	Roles        Roles       `json:"roles"`
 	Permissions  Permissions `json:"permissions"`
 	Introduction string      `json:"introduction" binding:"required"`
+	Actor        app.Actor   `json:"-"` // 操作人，写入审计日志
+
+	GrantorPermissions Permissions `json:"-"` // 更新者在本次请求中的有效权限
 }
 
 type ResetPasswordArgs struct {
-	ID       string `json:"id"`
-	Password string `json:"password"`
+	OperatorID string    `json:"operator_id"`
+	ID         string    `json:"id"`
+	Password   string    `json:"password"`
+	ViaAPIKey  bool      `json:"via_api_key"` // 调用方是否通过 API Key 鉴权
+	Actor      app.Actor `json:"-"`           // 操作人，写入审计日志
+}
+
+type UpdateProfileArgs struct {
+	ID           string    `json:"id"`
+	Avatar       string    `json:"avatar"`
+	Introduction string    `json:"introduction"`
+	Actor        app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type ChangePasswordArgs struct {
+	ID                 string    `json:"id"`
+	CurrentPasswordMD5 string    `json:"current_password_md5"` // 当前密码的md5值，和登录时一致
+	NewPassword        string    `json:"new_password"`
+	Actor              app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type SetUserRolesArgs struct {
+	ID    string    `json:"id"`
+	Roles Roles     `json:"roles"`
+	Actor app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type GrantQuotaArgs struct {
+	OperatorID string    `json:"operator_id"`
+	UserID     string    `json:"user_id"`
+	Amount     int       `json:"amount"` // 负数表示收回
+	Reason     string    `json:"reason"`
+	Actor      app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type QueryQuotaLedgerArgs struct {
+	ViewerID string `json:"viewer_id"`
+	UserID   string `json:"user_id"` // 为空时查询自己
+	Type     string `json:"type"`
+	Page     int    `json:"page"`
+	Limit    int    `json:"limit"`
+}
+
+type QueryQuotaLedgerResult struct {
+	Balance int64
+	List    []quota.Entry
+	Total   int
+}
+
+type SetAppGrantArgs struct {
+	OperatorID  string    `json:"operator_id"`
+	UserID      string    `json:"user_id"`
+	AppID       string    `json:"app_id"`
+	Quota       int       `json:"quota"`        // -1 表示不限制
+	TimeTypes   []string  `json:"time_types"`   // 为空表示不限制
+	MaxDuration int       `json:"max_duration"` // 单位为分钟，0 表示不限制
+	Actor       app.Actor `json:"-"`            // 操作人，写入审计日志
+
+	GrantorPermissions Permissions `json:"-"` // 操作人在本次请求中的有效权限
+}
+
+type DeleteAppGrantArgs struct {
+	OperatorID string    `json:"operator_id"`
+	UserID     string    `json:"user_id"`
+	AppID      string    `json:"app_id"`
+	Actor      app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type DeleteUserArgs struct {
+	OperatorID   string    `json:"operator_id"`
+	ID           string    `json:"id"`
+	Cascade      string    `json:"cascade"`        // 激活码的处理方式: lock, transfer, orphan
+	TransferToID string    `json:"transfer_to_id"` // cascade 为 transfer 时接收激活码的用户
+	Actor        app.Actor `json:"-"`              // 操作人，写入审计日志
+}
+
+type DisableUsersArgs struct {
+	OperatorID         string    `json:"operator_id"`
+	IDs                []string  `json:"ids"`
+	IncludeDescendants bool      `json:"include_descendants"` // 是否同时停封所有下级
+	Actor              app.Actor `json:"-"`                   // 操作人，写入审计日志
 }
 
 type Service interface {
@@ -53,8 +150,21 @@ type Service interface {
 	QueryUserList(args QueryUserListArgs) (QueryUserListResult, error)
 	CreateUser(args CreateUserArgs) error
 	UpdateUser(args UpdateUserArgs) error
-	DeleteUser(user User) error
+	DeleteUser(args DeleteUserArgs) error
+	// DisableUsers 停封用户并在同一个事务中锁定其所有未使用的激活码，返回锁定的数量
+	DisableUsers(args DisableUsersArgs) (int64, error)
 	Login(args LoginArgs) (User, error)
 	GetUserInfo(args GetUserInfoArgs) (UserView, error)
 	ResetPassword(args ResetPasswordArgs) error
+	// UpdateProfile 用户更新自己的头像和介绍
+	UpdateProfile(args UpdateProfileArgs) error
+	// ChangePassword 用户确认当前密码后修改自己的密码
+	ChangePassword(args ChangePasswordArgs) error
+	GetUserPermissions(id string) (Permissions, error)
+	SetUserRoles(args SetUserRolesArgs) error
+	GrantQuota(args GrantQuotaArgs) error
+	QueryQuotaLedger(args QueryQuotaLedgerArgs) (QueryQuotaLedgerResult, error)
+	GetAppGrants(viewerID string, userID string) ([]grant.Grant, error)
+	SetAppGrant(args SetAppGrantArgs) error
+	DeleteAppGrant(args DeleteAppGrantArgs) error
 }
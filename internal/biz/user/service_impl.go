@@ -5,9 +5,15 @@ import (
 	"strings"
 	"time"
 
+	"configuration-management/internal/biz/audit"
 	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/permissions"
+	"configuration-management/internal/biz/quota"
+	"configuration-management/internal/biz/role"
 
 	"configuration-management/global"
+	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
 	"configuration-management/utils"
@@ -15,15 +21,24 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
+// errPasswordViaAPIKey 通过 API Key 设置了密码就可以登录该用户，绕过 Key 的权限范围
+var errPasswordViaAPIKey = errcode.NoPermission.WithDetails("不能使用 API Key 设置用户密码，请登录后操作")
+
 type service struct {
-	repo     Repository
-	cardRepo card.Repository
+	repo      Repository
+	cardRepo  card.Repository
+	roleRepo  role.Repository
+	quotaRepo quota.Repository
+	grantRepo grant.Repository
 }
 
 func NewService() Service {
 	return &service{
-		repo:     NewRepository(global.DBEngine),
-		cardRepo: card.NewRepository(global.DBEngine),
+		repo:      NewRepository(global.DBEngine),
+		cardRepo:  card.NewRepository(global.DBEngine),
+		roleRepo:  role.NewRepository(global.DBEngine),
+		quotaRepo: quota.NewRepository(global.DBEngine),
+		grantRepo: grant.NewRepository(global.DBEngine),
 	}
 }
 
@@ -56,6 +71,17 @@ func (s *service) QueryUserList(args QueryUserListArgs) (QueryUserListResult, er
 		args.Limit = 10
 	}
 
+	// 非 root 只能查询自己的下级
+	if args.ViewerID != "" {
+		viewer, err := s.repo.GetUserByID(args.ViewerID)
+		if err != nil {
+			return QueryUserListResult{}, err
+		}
+		if !viewer.IsRoot() {
+			args.AncestryPath = viewer.SubtreePath()
+		}
+	}
+
 	userList, err := s.repo.QueryUserList(args)
 	if err != nil {
 		global.Logger.WithFields(logger.Fields{
@@ -79,6 +105,15 @@ func (s *service) QueryUserList(args QueryUserListArgs) (QueryUserListResult, er
 		return QueryUserListResult{}, err
 	}
 
+	// 查询额度余额
+	balances, err := s.quotaRepo.GetBalances(userIds)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Error("[QueryUserList] 查询用户额度失败", err)
+		return QueryUserListResult{}, err
+	}
+
 	for i, _ := range userList.List {
 		user := &userList.List[i]
 		user.TotalCnt = int(userCardCount[user.ID].Total)
@@ -86,6 +121,7 @@ func (s *service) QueryUserList(args QueryUserListArgs) (QueryUserListResult, er
 		user.NousedCnt = int(userCardCount[user.ID].Unused)
 		user.DeletedCnt = int(userCardCount[user.ID].Deleted)
 		user.LockedCnt = int(userCardCount[user.ID].Locked)
+		user.QuotaBalance = balances[user.ID]
 	}
 
 	return userList, nil
@@ -101,6 +137,11 @@ func (s *service) CreateUser(args CreateUserArgs) error {
 		return errcode.DuplicateKey.WithDetails("用户已经存在")
 	}
 
+	// 服务账号的密码随机生成，不能登录
+	if args.ViaAPIKey && !args.ServiceAccount {
+		return errPasswordViaAPIKey
+	}
+
 	creator, err := s.repo.GetUserByID(args.CreatorID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -112,12 +153,22 @@ func (s *service) CreateUser(args CreateUserArgs) error {
 		return err
 	}
 
-	// 权限检查
-	if !creator.HasRole(RoleRoot) {
+	// 权限检查，非 root 不能授予自己没有的权限和应用
+	if err := s.checkGrant(creator, args.GrantorPermissions, args.Permissions, args.Apps); err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"args": args,
 		}).Info("[CreateUser] 创建者没有权限")
-		return errcode.NoPermission
+		return err
+	}
+	if args.MaxCnt < 0 {
+		return errcode.InvalidParams.WithDetails("max_cnt 不能小于 0")
+	}
+	// 超过 ancestry 字段长度的路径会被截断，子树的前缀匹配就会出错
+	if len(creator.SubtreePath()) > MaxAncestryLength {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[CreateUser] 代理层级过深")
+		return errcode.InvalidParams.WithDetails("代理层级过深，不能再创建下级")
 	}
 
 	user = User{
@@ -125,7 +176,7 @@ func (s *service) CreateUser(args CreateUserArgs) error {
 		Username:     args.Username,
 		Password:     args.Password,
 		Status:       StatusNormal,
-		Ancestry:     args.CreatorID,
+		Ancestry:     creator.SubtreePath(),
 		CreatedAt:    time.Now(),
 		Apps:         args.Apps,
 		Permissions:  args.Permissions,
@@ -133,7 +184,26 @@ func (s *service) CreateUser(args CreateUserArgs) error {
 		MaxCnt:       args.MaxCnt,
 		Introduction: args.Introduction,
 	}
-	return s.repo.CreateUser(user)
+	if args.ServiceAccount {
+		// 服务账号不能登录，密码随机生成
+		user.Roles = Roles{RoleAdmin, RoleServiceAccount}
+		user.Password = utils.GenerateUUID()
+	}
+
+	// 初始额度由 root 直接授予，或者从创建者自己的额度中划出
+	transfer := quota.TransferArgs{
+		FromID:  quotaSource(creator),
+		ToID:    user.ID,
+		Amount:  args.MaxCnt,
+		Reason:  "创建用户时分配额度",
+		ActorID: creator.ID,
+	}
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.CreateUserWithQuota(user, transfer); err != nil {
+			return err
+		}
+		return auditRepo.Record(args.Actor, audit.ActionUserCreate, audit.TargetUser, user.ID, nil, user)
+	})
 }
 
 func (s *service) UpdateUser(args UpdateUserArgs) error {
@@ -148,14 +218,6 @@ func (s *service) UpdateUser(args UpdateUserArgs) error {
 		return err
 	}
 
-	// 权限检查
-	if !updater.IsRoot() {
-		global.Logger.WithFields(logger.Fields{
-			"args": args,
-		}).Info("[UpdateUser] 没有权限")
-		return errcode.NoPermission
-	}
-
 	user, err := s.repo.GetUserByID(args.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -174,6 +236,34 @@ func (s *service) UpdateUser(args UpdateUserArgs) error {
 		return errcode.NoPermission
 	}
 
+	// 权限检查，非 root 只能更新自己的下级
+	if !updater.IsRoot() && !updater.IsAncestorOf(user) {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[UpdateUser] 没有权限")
+		return errcode.NoPermission
+	}
+	if err := s.checkGrant(updater, args.GrantorPermissions, args.Permissions, args.Apps); err != nil {
+		return err
+	}
+	if args.MaxCnt < 0 {
+		return errcode.InvalidParams.WithDetails("max_cnt 不能小于 0")
+	}
+
+	// 额度变化从直接上级的额度中划转，减少时退还给上级，余额不足时失败
+	parentID, err := s.quotaSourceOf(user)
+	if err != nil {
+		return err
+	}
+	transfer := quota.TransferArgs{
+		FromID:  parentID,
+		ToID:    user.ID,
+		Amount:  args.MaxCnt - user.MaxCnt,
+		Reason:  "调整用户额度",
+		ActorID: updater.ID,
+	}
+
+	before := user
 	user.MaxCnt = args.MaxCnt
 	user.Status = NewStatus(args.Status)
 	//user.Roles = args.Roles
@@ -181,11 +271,129 @@ func (s *service) UpdateUser(args UpdateUserArgs) error {
 	user.Permissions = args.Permissions
 	user.Introduction = args.Introduction
 
-	return s.repo.UpdateUser(user)
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.UpdateUserWithQuota(user, transfer); err != nil {
+			return err
+		}
+		return auditRepo.Record(args.Actor, audit.ActionUserUpdate, audit.TargetUser, user.ID, before, user)
+	})
 }
 
-func (s *service) DeleteUser(user User) error {
-	return s.repo.DeleteUser(user)
+// DeleteUser 删除用户，cascade 决定其激活码的处理方式，剩余额度退还给上级
+func (s *service) DeleteUser(args DeleteUserArgs) error {
+	operator, user, err := s.getManagedUser(args.OperatorID, args.ID)
+	if err != nil {
+		return err
+	}
+	if user.IsRoot() || user.Username == SuperAdminUserName {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[DeleteUser] 不能删除超级管理员")
+		return errcode.NoPermission
+	}
+
+	// 有下级的用户需要先处理下级，避免出现没有上级的子树
+	descendants, err := s.repo.GetDescendantIDs(user.SubtreePath())
+	if err != nil {
+		return err
+	}
+	if len(descendants) > 0 {
+		return errcode.InvalidParams.WithDetails("请先删除该用户的下级用户")
+	}
+
+	var transferTo User
+	switch args.Cascade {
+	case CascadeLock, CascadeOrphan:
+	case CascadeTransfer:
+		if args.TransferToID == "" || args.TransferToID == user.ID {
+			return errcode.InvalidParams.WithDetails("transfer_to_id 不合法")
+		}
+		if args.TransferToID == operator.ID {
+			transferTo = operator
+		} else if _, transferTo, err = s.getManagedUser(operator.ID, args.TransferToID); err != nil {
+			return err
+		}
+		if transferTo.Status != StatusNormal {
+			return errcode.InvalidParams.WithDetails("接收激活码的用户已经被停封")
+		}
+	default:
+		return errcode.InvalidParams.WithDetails("invalid cascade: " + args.Cascade)
+	}
+
+	quotaSource, err := s.quotaSourceOf(user)
+	if err != nil {
+		return err
+	}
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.DeleteUserWithCascade(DeleteUserWithCascadeArgs{
+			User:        user,
+			Cascade:     args.Cascade,
+			TransferTo:  transferTo,
+			QuotaSource: quotaSource,
+			OperatorID:  operator.ID,
+			Actor:       args.Actor,
+		}); err != nil {
+			return err
+		}
+		return auditRepo.Record(args.Actor, audit.ActionUserDelete, audit.TargetUser, user.ID, user, nil)
+	})
+}
+
+func (s *service) DisableUsers(args DisableUsersArgs) (int64, error) {
+	if len(args.IDs) == 0 {
+		return 0, errcode.InvalidParams.WithDetails("ids 不能为空")
+	}
+
+	users := make([]User, 0, len(args.IDs))
+	for _, id := range args.IDs {
+		_, user, err := s.getManagedUser(args.OperatorID, id)
+		if err != nil {
+			return 0, err
+		}
+		if user.IsRoot() || user.Username == SuperAdminUserName {
+			return 0, errcode.NoPermission.WithDetails("不能停封超级管理员")
+		}
+		users = append(users, user)
+
+		if args.IncludeDescendants {
+			descendants, err := s.repo.GetDescendantIDs(user.SubtreePath())
+			if err != nil {
+				return 0, err
+			}
+			for _, descendantID := range descendants {
+				descendant, err := s.repo.GetUserByID(descendantID)
+				if err != nil {
+					return 0, err
+				}
+				users = append(users, descendant)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	var locked int64
+	err := s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		var err error
+		if locked, err = repo.DisableUsers(ids, args.Actor); err != nil {
+			return err
+		}
+		for _, before := range users {
+			after := before
+			after.Status = StatusBanned
+			if err := auditRepo.Record(args.Actor, audit.ActionUserDisable, audit.TargetUser, before.ID, before, after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return locked, nil
 }
 
 func (s *service) Login(args LoginArgs) (User, error) {
@@ -203,6 +411,14 @@ func (s *service) Login(args LoginArgs) (User, error) {
 		return User{}, err
 	}
 
+	// 服务账号只能通过 API Key 访问
+	if user.HasRole(RoleServiceAccount) {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[Login] 服务账号不能登录")
+		return User{}, errors.New("服务账号不能登录")
+	}
+
 	// 检查用户是否被封
 	if user.Status == StatusBanned {
 		global.Logger.WithFields(logger.Fields{
@@ -231,10 +447,18 @@ func (s *service) GetUserInfo(args GetUserInfoArgs) (UserView, error) {
 		return UserView{}, err
 	}
 
+	user.QuotaBalance, err = s.quotaRepo.GetBalance(user.ID)
+	if err != nil {
+		return UserView{}, err
+	}
 	return user.ToView(), nil
 }
 
 func (s *service) ResetPassword(args ResetPasswordArgs) error {
+	if args.ViaAPIKey {
+		return errPasswordViaAPIKey
+	}
+
 	user, err := s.repo.GetUserByID(args.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -246,6 +470,429 @@ func (s *service) ResetPassword(args ResetPasswordArgs) error {
 		return err
 	}
 
+	if args.OperatorID != "" {
+		operator, err := s.repo.GetUserByID(args.OperatorID)
+		if err != nil {
+			return err
+		}
+		if !operator.IsRoot() && !operator.IsAncestorOf(user) {
+			global.Logger.WithFields(logger.Fields{
+				"args": args,
+			}).Info("[ResetPassword] 没有权限")
+			return errcode.NoPermission
+		}
+	}
+
+	before := user
 	user.Password = args.Password
-	return s.repo.UpdateUser(user)
+	return s.updateUserAudited(args.Actor, audit.ActionUserResetPassword, before, user)
+}
+
+func (s *service) UpdateProfile(args UpdateProfileArgs) error {
+	user, err := s.repo.GetUserByID(args.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.NotFound.WithDetails("用户不存在")
+		}
+		return err
+	}
+
+	before := user
+	user.Avatar = args.Avatar
+	user.Introduction = args.Introduction
+	return s.updateUserAudited(args.Actor, audit.ActionUserUpdateProfile, before, user)
+}
+
+func (s *service) ChangePassword(args ChangePasswordArgs) error {
+	user, err := s.repo.GetUserByID(args.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.NotFound.WithDetails("用户不存在")
+		}
+		return err
+	}
+
+	// 服务账号不能登录，也就没有密码可以修改
+	if user.HasRole(RoleServiceAccount) {
+		return errcode.NoPermission.WithDetails("服务账号不能修改密码")
+	}
+
+	// 和登录一样校验当前密码
+	if !strings.EqualFold(utils.MD5(user.Password), args.CurrentPasswordMD5) {
+		global.Logger.WithFields(logger.Fields{
+			"id": args.ID,
+		}).Info("[ChangePassword] 当前密码错误")
+		return errcode.InvalidParams.WithDetails("当前密码错误")
+	}
+	if args.NewPassword == user.Password {
+		return errcode.InvalidParams.WithDetails("新密码不能与当前密码相同")
+	}
+
+	before := user
+	user.Password = args.NewPassword
+	return s.updateUserAudited(args.Actor, audit.ActionUserChangePassword, before, user)
+}
+
+// GetUserPermissions 获取用户的有效权限：用户自身的权限加上所有角色的权限
+func (s *service) GetUserPermissions(id string) (Permissions, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Permissions{}, errcode.NotFound.WithDetails("用户不存在")
+		}
+		return Permissions{}, err
+	}
+
+	// 被停封的用户没有任何权限
+	if user.Status != StatusNormal {
+		return Permissions{}, nil
+	}
+	if user.IsRoot() {
+		return permissions.RootPermissions, nil
+	}
+
+	roles, err := s.roleRepo.GetRolesByNames(user.Roles)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"user_id": id,
+			"roles":   user.Roles,
+		}).Error("[GetUserPermissions] 查询角色失败", err)
+		return Permissions{}, err
+	}
+
+	lists := [][]string{user.Permissions}
+	for _, r := range roles {
+		lists = append(lists, r.Permissions)
+	}
+	return permissions.Merge(lists...), nil
+}
+
+// SetUserRoles 为用户分配角色，内置的 admin 角色会被保留
+func (s *service) SetUserRoles(args SetUserRolesArgs) error {
+	user, err := s.repo.GetUserByID(args.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.NotFound.WithDetails("用户不存在")
+		}
+		return err
+	}
+	if user.IsRoot() || user.Username == SuperAdminUserName {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[SetUserRoles] 不能修改超级管理员的角色")
+		return errcode.NoPermission
+	}
+
+	customRoles := make([]string, 0)
+	for _, name := range permissions.Merge(args.Roles) {
+		if name == RoleRoot || name == RoleServiceAccount {
+			return errcode.NoPermission.WithDetails("不能分配内置角色: " + name)
+		}
+		if name != RoleAdmin {
+			customRoles = append(customRoles, name)
+		}
+	}
+
+	// 检查自定义角色是否都存在
+	existing, err := s.roleRepo.GetRolesByNames(customRoles)
+	if err != nil {
+		return err
+	}
+	if len(existing) != len(customRoles) {
+		return errcode.NotFound.WithDetails("角色不存在")
+	}
+
+	before := user
+	roles := Roles{RoleAdmin}
+	if user.HasRole(RoleServiceAccount) {
+		roles = append(roles, RoleServiceAccount)
+	}
+	user.Roles = append(roles, customRoles...)
+	return s.updateUserAudited(args.Actor, audit.ActionUserSetRoles, before, user)
+}
+
+// checkGrant 检查 grantor 是否可以把这些权限和应用授予下级
+// grantorPermissions 是本次请求的有效权限，通过 API Key 调用时只有 Key 范围内的权限
+func (s *service) checkGrant(grantor User, grantorPermissions Permissions, grantPermissions Permissions, grantApps Apps) error {
+	// 仅 root 拥有的权限即使是 root 也不能授予其他用户
+	for _, p := range grantPermissions {
+		if !permissions.IsAllowed(p) {
+			return errcode.NoPermission.WithDetails("不能授予的权限: " + p)
+		}
+	}
+
+	if !permissions.Contains(grantorPermissions, permissions.USER_MANAGE) {
+		return errcode.NoPermission
+	}
+	for _, p := range grantPermissions {
+		if !permissions.Contains(grantorPermissions, p) {
+			return errcode.NoPermission.WithDetails("不能授予自己没有的权限: " + p)
+		}
+	}
+	// root 可以授予任何应用
+	if grantor.IsRoot() {
+		return nil
+	}
+	for _, appId := range grantApps {
+		if !grantor.HasApp(appId) {
+			return errcode.NoPermission.WithDetails("不能授予自己没有的应用: " + appId)
+		}
+	}
+	return nil
+}
+
+// GrantQuota 给用户增加或收回额度，root 可以操作任何用户，其他用户只能操作自己的直接下级
+func (s *service) GrantQuota(args GrantQuotaArgs) error {
+	if args.Amount == 0 {
+		return errcode.InvalidParams.WithDetails("amount 不能为 0")
+	}
+
+	operator, err := s.repo.GetUserByID(args.OperatorID)
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.GetUserByID(args.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.NotFound.WithDetails("用户不存在")
+		}
+		return err
+	}
+	if !operator.IsRoot() && user.ParentID() != operator.ID {
+		global.Logger.WithFields(logger.Fields{
+			"args": args,
+		}).Info("[GrantQuota] 没有权限")
+		return errcode.NoPermission
+	}
+
+	parentID, err := s.quotaSourceOf(user)
+	if err != nil {
+		return err
+	}
+	reason := args.Reason
+	if reason == "" {
+		reason = "调整用户额度"
+	}
+
+	before := user
+	user.MaxCnt += args.Amount
+	transfer := quota.TransferArgs{
+		FromID:  parentID,
+		ToID:    user.ID,
+		Amount:  args.Amount,
+		Reason:  reason,
+		ActorID: operator.ID,
+	}
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.UpdateUserWithQuota(user, transfer); err != nil {
+			return err
+		}
+		return auditRepo.Record(args.Actor, audit.ActionUserGrantQuota, audit.TargetUser, user.ID, before, user)
+	})
+}
+
+// QueryQuotaLedger 查询用户的额度余额和流水，只能查询自己或者自己的下级
+func (s *service) QueryQuotaLedger(args QueryQuotaLedgerArgs) (QueryQuotaLedgerResult, error) {
+	if args.UserID == "" {
+		args.UserID = args.ViewerID
+	}
+	if args.UserID != args.ViewerID {
+		viewer, err := s.repo.GetUserByID(args.ViewerID)
+		if err != nil {
+			return QueryQuotaLedgerResult{}, err
+		}
+		user, err := s.repo.GetUserByID(args.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return QueryQuotaLedgerResult{}, errcode.NotFound.WithDetails("用户不存在")
+			}
+			return QueryQuotaLedgerResult{}, err
+		}
+		if !viewer.IsRoot() && !viewer.IsAncestorOf(user) {
+			return QueryQuotaLedgerResult{}, errcode.NoPermission
+		}
+	}
+
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	}
+
+	balance, err := s.quotaRepo.GetBalance(args.UserID)
+	if err != nil {
+		return QueryQuotaLedgerResult{}, err
+	}
+	entries, err := s.quotaRepo.QueryEntries(quota.QueryEntriesArgs{
+		UserID: args.UserID,
+		Type:   args.Type,
+		Page:   args.Page,
+		Limit:  args.Limit,
+	})
+	if err != nil {
+		return QueryQuotaLedgerResult{}, err
+	}
+	return QueryQuotaLedgerResult{
+		Balance: balance,
+		List:    entries.List,
+		Total:   entries.Total,
+	}, nil
+}
+
+// GetAppGrants 查询用户在各个应用上的授权，只能查询自己或者自己的下级
+func (s *service) GetAppGrants(viewerID string, userID string) ([]grant.Grant, error) {
+	if userID == "" {
+		userID = viewerID
+	}
+	if userID != viewerID {
+		if _, _, err := s.getManagedUser(viewerID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.grantRepo.GetGrantsByUserID(userID)
+}
+
+// SetAppGrant 设置下级用户在某个应用上的授权，同时把应用加入用户的应用列表
+// 非 root 授予的限制不能比自己在该应用上的限制更宽松
+func (s *service) SetAppGrant(args SetAppGrantArgs) error {
+	if args.Quota < grant.QuotaUnlimited || args.MaxDuration < 0 {
+		return errcode.InvalidParams.WithDetails("quota 或 max_duration 不合法")
+	}
+	for _, t := range args.TimeTypes {
+		if !card.IsTimeType(t) {
+			return errcode.InvalidParams.WithDetails("invalid time type: " + t)
+		}
+	}
+
+	operator, user, err := s.getManagedUser(args.OperatorID, args.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	g := grant.Grant{
+		ID:          utils.GenerateUUID(),
+		UserID:      user.ID,
+		AppID:       args.AppID,
+		Quota:       args.Quota,
+		TimeTypes:   args.TimeTypes,
+		MaxDuration: args.MaxDuration,
+		CreatorID:   operator.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if !operator.IsRoot() {
+		if err := s.checkGrant(operator, args.GrantorPermissions, nil, Apps{args.AppID}); err != nil {
+			return err
+		}
+		operatorGrant, err := s.grantRepo.GetGrant(operator.ID, args.AppID)
+		if err != nil && !errors.Is(err, errcode.NotFound) {
+			return err
+		}
+		if err == nil && !operatorGrant.Covers(g) {
+			global.Logger.WithFields(logger.Fields{
+				"args":           args,
+				"operator_grant": operatorGrant,
+			}).Info("[SetAppGrant] 授权超出自己的限制")
+			return errcode.NoPermission.WithDetails("授权不能超出自己在该应用上的限制")
+		}
+	}
+
+	// 审计日志中记录授权和应用列表的变化
+	var beforeGrant any
+	existing, err := s.grantRepo.GetGrant(user.ID, args.AppID)
+	if err != nil && !errors.Is(err, errcode.NotFound) {
+		return err
+	}
+	if err == nil {
+		beforeGrant = existing
+	}
+	before := map[string]any{"grant": beforeGrant, "apps": user.Apps}
+
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.SaveAppGrant(g); err != nil {
+			return err
+		}
+		if !user.HasApp(args.AppID) {
+			user.Apps = append(user.Apps, args.AppID)
+			if err := repo.UpdateUser(user); err != nil {
+				return err
+			}
+		}
+		after := map[string]any{"grant": g, "apps": user.Apps}
+		return auditRepo.Record(args.Actor, audit.ActionUserSetAppGrant, audit.TargetUser, user.ID, before, after)
+	})
+}
+
+// DeleteAppGrant 删除下级用户在某个应用上的限制，用户仍然保留该应用的权限
+func (s *service) DeleteAppGrant(args DeleteAppGrantArgs) error {
+	if _, _, err := s.getManagedUser(args.OperatorID, args.UserID); err != nil {
+		return err
+	}
+	existing, err := s.grantRepo.GetGrant(args.UserID, args.AppID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.DeleteAppGrant(args.UserID, args.AppID); err != nil {
+			return err
+		}
+		return auditRepo.Record(args.Actor, audit.ActionUserDeleteAppGrant, audit.TargetUser, args.UserID,
+			map[string]any{"grant": existing}, map[string]any{"grant": nil})
+	})
+}
+
+// updateUserAudited 更新用户，并在同一个事务中写入审计日志
+func (s *service) updateUserAudited(actor app.Actor, action string, before User, after User) error {
+	return s.repo.Transaction(func(repo Repository, auditRepo audit.Repository) error {
+		if err := repo.UpdateUser(after); err != nil {
+			return err
+		}
+		return auditRepo.Record(actor, action, audit.TargetUser, after.ID, before, after)
+	})
+}
+
+// getManagedUser 查询 operator 能管理的用户：root 能管理所有用户，其他用户只能管理自己的下级
+func (s *service) getManagedUser(operatorID string, userID string) (User, User, error) {
+	operator, err := s.repo.GetUserByID(operatorID)
+	if err != nil {
+		return User{}, User{}, err
+	}
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return User{}, User{}, errcode.NotFound.WithDetails("用户不存在")
+		}
+		return User{}, User{}, err
+	}
+	if !operator.IsRoot() && !operator.IsAncestorOf(user) {
+		global.Logger.WithFields(logger.Fields{
+			"operator_id": operatorID,
+			"user_id":     userID,
+		}).Info("[getManagedUser] 没有权限")
+		return User{}, User{}, errcode.NoPermission
+	}
+	return operator, user, nil
+}
+
+// quotaSource 用户作为额度来源时的 ID，root 的额度直接授予，返回空字符串
+func quotaSource(user User) string {
+	if user.IsRoot() {
+		return ""
+	}
+	return user.ID
+}
+
+// quotaSourceOf 用户的额度来源，即直接上级；没有上级或上级为 root 时返回空字符串
+func (s *service) quotaSourceOf(user User) (string, error) {
+	if user.ParentID() == "" {
+		return "", nil
+	}
+	parent, err := s.repo.GetUserByID(user.ParentID())
+	if err != nil {
+		return "", err
+	}
+	return quotaSource(parent), nil
 }
@@ -0,0 +1,404 @@
+package user
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/grant"
+	"configuration-management/internal/biz/permissions"
+	"configuration-management/internal/biz/quota"
+	"configuration-management/internal/biz/role"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/jinzhu/gorm"
+)
+
+// fakeRepository 在内存中保存用户，只实现测试用到的方法
+type fakeRepository struct {
+	Repository
+	users     map[string]User
+	audit     *fakeAuditRepository
+	transfers []quota.TransferArgs
+	grants    []grant.Grant
+	deleted   []DeleteUserWithCascadeArgs
+	disabled  []string
+	// disabledBy 锁定激活码时传入的操作人，激活码的审计日志使用
+	disabledBy []app.Actor
+}
+
+func (f *fakeRepository) GetUserByID(id string) (User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return User{}, gorm.ErrRecordNotFound
+	}
+	return u, nil
+}
+
+func (f *fakeRepository) GetUserByUsername(username string) (User, error) {
+	for _, u := range f.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return User{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) UpdateUser(user User) error {
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeRepository) CreateUserWithQuota(user User, transfer quota.TransferArgs) error {
+	f.users[user.ID] = user
+	f.transfers = append(f.transfers, transfer)
+	return nil
+}
+
+func (f *fakeRepository) UpdateUserWithQuota(user User, transfer quota.TransferArgs) error {
+	f.users[user.ID] = user
+	f.transfers = append(f.transfers, transfer)
+	return nil
+}
+
+func (f *fakeRepository) GetDescendantIDs(subtreePath string) ([]string, error) {
+	ids := make([]string, 0)
+	for _, u := range f.users {
+		if u.Ancestry == subtreePath || strings.HasPrefix(u.Ancestry, subtreePath+AncestrySeparator) {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeRepository) DeleteUserWithCascade(args DeleteUserWithCascadeArgs) error {
+	f.deleted = append(f.deleted, args)
+	delete(f.users, args.User.ID)
+	return nil
+}
+
+func (f *fakeRepository) DisableUsers(ids []string, actor app.Actor) (int64, error) {
+	f.disabled = append(f.disabled, ids...)
+	f.disabledBy = append(f.disabledBy, actor)
+	return int64(len(ids)), nil
+}
+
+func (f *fakeRepository) SaveAppGrant(g grant.Grant) error {
+	f.grants = append(f.grants, g)
+	return nil
+}
+
+func (f *fakeRepository) Transaction(fn func(repo Repository, auditRepo audit.Repository) error) error {
+	return fn(f, f.audit)
+}
+
+type fakeAuditRepository struct {
+	audit.Repository
+	actions []string
+}
+
+func (f *fakeAuditRepository) Record(actor app.Actor, action string, targetType string, targetID string, before any, after any) error {
+	f.actions = append(f.actions, action+":"+targetID)
+	return nil
+}
+
+type fakeRoleRepository struct {
+	role.Repository
+}
+
+func (f *fakeRoleRepository) GetRolesByNames(names []string) ([]role.Role, error) {
+	return []role.Role{}, nil
+}
+
+type fakeGrantRepository struct {
+	grant.Repository
+	grants map[string]grant.Grant
+}
+
+func (f *fakeGrantRepository) GetGrant(userID string, appID string) (grant.Grant, error) {
+	g, ok := f.grants[userID+"/"+appID]
+	if !ok {
+		return grant.Grant{}, errcode.NotFound
+	}
+	return g, nil
+}
+
+// newTestService 用户树: root -> seller -> sub -> subsub, root -> other
+func newTestService() (*service, *fakeRepository) {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+	repo := &fakeRepository{
+		users: map[string]User{
+			"root":   {ID: "root", Username: SuperAdminUserName, Status: StatusNormal, Roles: Roles{RoleRoot, RoleAdmin}},
+			"seller": {ID: "seller", Username: "seller", Status: StatusNormal, Ancestry: "root", Roles: Roles{RoleAdmin}, Apps: Apps{"a1"}, Permissions: Permissions{permissions.USER_MANAGE, permissions.QUERY}},
+			"sub":    {ID: "sub", Username: "sub", Status: StatusNormal, Ancestry: "root/seller", Roles: Roles{RoleAdmin}, Apps: Apps{"a1"}, Permissions: Permissions{permissions.USER_MANAGE, permissions.QUERY}},
+			"subsub": {ID: "subsub", Username: "subsub", Status: StatusNormal, Ancestry: "root/seller/sub", Roles: Roles{RoleAdmin}},
+			"other":  {ID: "other", Username: "other", Status: StatusNormal, Ancestry: "root", Roles: Roles{RoleAdmin}, Apps: Apps{"a2"}, Permissions: Permissions{permissions.USER_MANAGE}},
+		},
+		audit: &fakeAuditRepository{},
+	}
+	return &service{
+		repo:      repo,
+		roleRepo:  &fakeRoleRepository{},
+		grantRepo: &fakeGrantRepository{grants: map[string]grant.Grant{}},
+	}, repo
+}
+
+func assertErrCode(t *testing.T, err error, want *errcode.Error) {
+	t.Helper()
+	var e *errcode.Error
+	if !errors.As(err, &e) || e.Code() != want.Code() {
+		t.Fatalf("expected error code %d, got %v", want.Code(), err)
+	}
+}
+
+// sessionPermissions 登录后请求的有效权限，即用户自身的全部权限
+func sessionPermissions(t *testing.T, s *service, id string) Permissions {
+	t.Helper()
+	p, err := s.GetUserPermissions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCreateUserUnderReseller(t *testing.T) {
+	s, repo := newTestService()
+
+	err := s.CreateUser(CreateUserArgs{
+		CreatorID:          "sub",
+		Username:           "new",
+		Apps:               Apps{"a1"},
+		Permissions:        Permissions{permissions.QUERY},
+		MaxCnt:             10,
+		GrantorPermissions: sessionPermissions(t, s, "sub"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := repo.GetUserByUsername("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Ancestry != "root/seller/sub" || created.ParentID() != "sub" {
+		t.Fatalf("unexpected ancestry: %s", created.Ancestry)
+	}
+	// 额度从创建者自己的额度中划出
+	if len(repo.transfers) != 1 || repo.transfers[0].FromID != "sub" || repo.transfers[0].Amount != 10 {
+		t.Fatalf("unexpected transfer: %+v", repo.transfers)
+	}
+	if len(repo.audit.actions) != 1 || repo.audit.actions[0] != audit.ActionUserCreate+":"+created.ID {
+		t.Fatalf("unexpected audit: %v", repo.audit.actions)
+	}
+}
+
+func TestCreateUserCannotGrantMoreThanCreator(t *testing.T) {
+	s, repo := newTestService()
+
+	err := s.CreateUser(CreateUserArgs{CreatorID: "sub", GrantorPermissions: sessionPermissions(t, s, "sub"), Username: "p", Permissions: Permissions{permissions.DELETE}})
+	assertErrCode(t, err, errcode.NoPermission)
+	err = s.CreateUser(CreateUserArgs{CreatorID: "sub", GrantorPermissions: sessionPermissions(t, s, "sub"), Username: "a", Apps: Apps{"a2"}})
+	assertErrCode(t, err, errcode.NoPermission)
+	// 没有 USER_MANAGE 的用户不能创建下级
+	err = s.CreateUser(CreateUserArgs{CreatorID: "subsub", GrantorPermissions: sessionPermissions(t, s, "subsub"), Username: "m"})
+	assertErrCode(t, err, errcode.NoPermission)
+	if len(repo.audit.actions) != 0 {
+		t.Fatalf("nothing should be recorded: %v", repo.audit.actions)
+	}
+}
+
+func TestAPIKeyScopeLimitsGrants(t *testing.T) {
+	s, repo := newTestService()
+	// Key 只有 USER_MANAGE，不能把用户自己的其他权限授予下级
+	keyPermissions := Permissions{permissions.USER_MANAGE}
+
+	err := s.CreateUser(CreateUserArgs{CreatorID: "seller", Username: "k1", Permissions: Permissions{permissions.QUERY}, ServiceAccount: true, GrantorPermissions: keyPermissions, ViaAPIKey: true})
+	assertErrCode(t, err, errcode.NoPermission)
+	err = s.CreateUser(CreateUserArgs{CreatorID: "root", Username: "k2", Permissions: Permissions{permissions.QUERY}, ServiceAccount: true, GrantorPermissions: keyPermissions, ViaAPIKey: true})
+	assertErrCode(t, err, errcode.NoPermission)
+	err = s.UpdateUser(UpdateUserArgs{ID: "subsub", UpdaterID: "seller", Status: int(StatusNormal), Permissions: Permissions{permissions.QUERY}, Introduction: "x", GrantorPermissions: keyPermissions})
+	assertErrCode(t, err, errcode.NoPermission)
+
+	// 通过 Key 设置了密码就可以登录该用户，绕过 Key 的权限范围
+	err = s.CreateUser(CreateUserArgs{CreatorID: "seller", Username: "k3", Password: "p", GrantorPermissions: keyPermissions, ViaAPIKey: true})
+	assertErrCode(t, err, errcode.NoPermission)
+	err = s.ResetPassword(ResetPasswordArgs{OperatorID: "seller", ID: "subsub", Password: "p", ViaAPIKey: true})
+	assertErrCode(t, err, errcode.NoPermission)
+	if len(repo.audit.actions) != 0 {
+		t.Fatalf("nothing should be recorded: %v", repo.audit.actions)
+	}
+
+	// Key 范围内的权限可以授予服务账号
+	err = s.CreateUser(CreateUserArgs{CreatorID: "seller", Username: "k4", Permissions: Permissions{permissions.QUERY}, ServiceAccount: true, GrantorPermissions: Permissions{permissions.USER_MANAGE, permissions.QUERY}, ViaAPIKey: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateUserTooDeep(t *testing.T) {
+	s, repo := newTestService()
+	// 31 级以上的代理，下级的 ancestry 会超过字段长度
+	ancestry := "root" + strings.Repeat(AncestrySeparator+strings.Repeat("x", 32), 31)
+	repo.users["deep"] = User{ID: "deep", Username: "deep", Status: StatusNormal, Ancestry: ancestry, Roles: Roles{RoleAdmin}, Permissions: Permissions{permissions.USER_MANAGE}}
+
+	err := s.CreateUser(CreateUserArgs{CreatorID: "deep", GrantorPermissions: sessionPermissions(t, s, "deep"), Username: "deeper"})
+	assertErrCode(t, err, errcode.InvalidParams)
+	if _, err := repo.GetUserByUsername("deeper"); err == nil || len(repo.audit.actions) != 0 {
+		t.Fatalf("user should not be created: %v", repo.audit.actions)
+	}
+}
+
+func TestRootCannotGrantRootOnlyPermissions(t *testing.T) {
+	s, repo := newTestService()
+
+	for _, p := range []string{permissions.ROLE_MANAGE, permissions.AUDIT_VIEW, permissions.WEBHOOK_MANAGE} {
+		err := s.CreateUser(CreateUserArgs{CreatorID: "root", GrantorPermissions: sessionPermissions(t, s, "root"), Username: "r" + p, Permissions: Permissions{p}})
+		assertErrCode(t, err, errcode.NoPermission)
+		err = s.UpdateUser(UpdateUserArgs{ID: "seller", UpdaterID: "root", GrantorPermissions: sessionPermissions(t, s, "root"), Status: int(StatusNormal), Permissions: Permissions{p}, Introduction: "x"})
+		assertErrCode(t, err, errcode.NoPermission)
+	}
+	if len(repo.audit.actions) != 0 {
+		t.Fatalf("nothing should be recorded: %v", repo.audit.actions)
+	}
+}
+
+func TestUpdateUserOnlyInSubtree(t *testing.T) {
+	s, repo := newTestService()
+
+	// 上级可以修改任意层级的下级
+	err := s.UpdateUser(UpdateUserArgs{ID: "subsub", UpdaterID: "seller", GrantorPermissions: sessionPermissions(t, s, "seller"), Status: int(StatusNormal), Apps: Apps{"a1"}, Introduction: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := repo.users["subsub"]
+	if !updated.HasApp("a1") {
+		t.Fatalf("user not updated: %+v", updated)
+	}
+
+	// 下级和其他分支的用户不能修改
+	err = s.UpdateUser(UpdateUserArgs{ID: "seller", UpdaterID: "sub", GrantorPermissions: sessionPermissions(t, s, "sub"), Status: int(StatusNormal), Introduction: "x"})
+	assertErrCode(t, err, errcode.NoPermission)
+	err = s.UpdateUser(UpdateUserArgs{ID: "sub", UpdaterID: "other", GrantorPermissions: sessionPermissions(t, s, "other"), Status: int(StatusNormal), Introduction: "x"})
+	assertErrCode(t, err, errcode.NoPermission)
+}
+
+func TestSetAppGrant(t *testing.T) {
+	s, repo := newTestService()
+	s.grantRepo.(*fakeGrantRepository).grants["seller/a1"] = grant.Grant{UserID: "seller", AppID: "a1", Quota: 100, MaxDuration: 60}
+
+	err := s.SetAppGrant(SetAppGrantArgs{OperatorID: "seller", GrantorPermissions: sessionPermissions(t, s, "seller"), UserID: "subsub", AppID: "a1", Quota: 50, MaxDuration: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 应用同时加入下级的应用列表
+	updated := repo.users["subsub"]
+	if !updated.HasApp("a1") || len(repo.grants) != 1 || repo.grants[0].CreatorID != "seller" {
+		t.Fatalf("grant not saved: %+v, %+v", updated, repo.grants)
+	}
+	if len(repo.audit.actions) != 1 || repo.audit.actions[0] != audit.ActionUserSetAppGrant+":subsub" {
+		t.Fatalf("unexpected audit: %v", repo.audit.actions)
+	}
+
+	// root 不受限制
+	if err := s.SetAppGrant(SetAppGrantArgs{OperatorID: "root", GrantorPermissions: sessionPermissions(t, s, "root"), UserID: "seller", AppID: "a2", Quota: grant.QuotaUnlimited}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetAppGrantForbidden(t *testing.T) {
+	s, repo := newTestService()
+	s.grantRepo.(*fakeGrantRepository).grants["seller/a1"] = grant.Grant{UserID: "seller", AppID: "a1", Quota: 100, MaxDuration: 60}
+
+	cases := []SetAppGrantArgs{
+		// 超出自己在该应用上的限制
+		{OperatorID: "seller", UserID: "sub", AppID: "a1", Quota: 200, MaxDuration: 30},
+		{OperatorID: "seller", UserID: "sub", AppID: "a1", Quota: grant.QuotaUnlimited, MaxDuration: 30},
+		{OperatorID: "seller", UserID: "sub", AppID: "a1", Quota: 50},
+		// 自己没有的应用
+		{OperatorID: "seller", UserID: "sub", AppID: "a2", Quota: 1},
+		// 不是自己的下级
+		{OperatorID: "seller", UserID: "other", AppID: "a1", Quota: 1, MaxDuration: 30},
+		{OperatorID: "sub", UserID: "seller", AppID: "a1", Quota: 1, MaxDuration: 30},
+	}
+	for _, args := range cases {
+		assertErrCode(t, s.SetAppGrant(args), errcode.NoPermission)
+	}
+	if len(repo.grants) != 0 || len(repo.audit.actions) != 0 {
+		t.Fatalf("nothing should be saved: %+v, %v", repo.grants, repo.audit.actions)
+	}
+}
+
+func TestDeleteUserWithCascade(t *testing.T) {
+	s, repo := newTestService()
+
+	actor := app.Actor{ID: "seller", Name: "seller"}
+	err := s.DeleteUser(DeleteUserArgs{OperatorID: "seller", ID: "subsub", Cascade: CascadeTransfer, TransferToID: "sub", Actor: actor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.deleted) != 1 {
+		t.Fatalf("expected one delete, got %+v", repo.deleted)
+	}
+	deleted := repo.deleted[0]
+	// 剩余额度退还给直接上级
+	if deleted.User.ID != "subsub" || deleted.TransferTo.ID != "sub" || deleted.QuotaSource != "sub" || deleted.OperatorID != "seller" || deleted.Actor != actor {
+		t.Fatalf("unexpected cascade args: %+v", deleted)
+	}
+	if len(repo.audit.actions) != 1 || repo.audit.actions[0] != audit.ActionUserDelete+":subsub" {
+		t.Fatalf("unexpected audit: %v", repo.audit.actions)
+	}
+
+	if err := s.DeleteUser(DeleteUserArgs{OperatorID: "root", ID: "other", Cascade: CascadeLock}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.deleted[1].QuotaSource != "" {
+		t.Fatalf("quota of root's direct child should go back to root: %+v", repo.deleted[1])
+	}
+}
+
+func TestDeleteUserForbidden(t *testing.T) {
+	s, repo := newTestService()
+
+	// 不能删除上级、其他分支的用户和超级管理员
+	assertErrCode(t, s.DeleteUser(DeleteUserArgs{OperatorID: "sub", ID: "seller", Cascade: CascadeLock}), errcode.NoPermission)
+	assertErrCode(t, s.DeleteUser(DeleteUserArgs{OperatorID: "other", ID: "subsub", Cascade: CascadeLock}), errcode.NoPermission)
+	assertErrCode(t, s.DeleteUser(DeleteUserArgs{OperatorID: "root", ID: "root", Cascade: CascadeLock}), errcode.NoPermission)
+	// 不能把激活码转移给自己管理范围之外的用户
+	assertErrCode(t, s.DeleteUser(DeleteUserArgs{OperatorID: "seller", ID: "subsub", Cascade: CascadeTransfer, TransferToID: "other"}), errcode.NoPermission)
+	// 有下级时需要先删除下级
+	assertErrCode(t, s.DeleteUser(DeleteUserArgs{OperatorID: "seller", ID: "sub", Cascade: CascadeLock}), errcode.InvalidParams)
+	assertErrCode(t, s.DeleteUser(DeleteUserArgs{OperatorID: "seller", ID: "subsub", Cascade: "drop"}), errcode.InvalidParams)
+
+	if len(repo.deleted) != 0 || len(repo.audit.actions) != 0 {
+		t.Fatalf("nothing should be deleted: %+v, %v", repo.deleted, repo.audit.actions)
+	}
+}
+
+func TestDisableUsers(t *testing.T) {
+	s, repo := newTestService()
+
+	actor := app.Actor{ID: "seller", Name: "seller"}
+	locked, err := s.DisableUsers(DisableUsersArgs{OperatorID: "seller", IDs: []string{"sub"}, IncludeDescendants: true, Actor: actor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.disabledBy) != 1 || repo.disabledBy[0] != actor {
+		t.Fatalf("cards should be locked on behalf of the operator: %+v", repo.disabledBy)
+	}
+	if locked != 2 || len(repo.disabled) != 2 || len(repo.audit.actions) != 2 {
+		t.Fatalf("expected sub and subsub disabled, got %v, %v", repo.disabled, repo.audit.actions)
+	}
+
+	// 列表中有一个用户不能管理时全部失败
+	_, err = s.DisableUsers(DisableUsersArgs{OperatorID: "seller", IDs: []string{"subsub", "other"}})
+	assertErrCode(t, err, errcode.NoPermission)
+	_, err = s.DisableUsers(DisableUsersArgs{OperatorID: "root", IDs: []string{"root"}})
+	assertErrCode(t, err, errcode.NoPermission)
+	if len(repo.disabled) != 2 {
+		t.Fatalf("no more users should be disabled: %v", repo.disabled)
+	}
+}
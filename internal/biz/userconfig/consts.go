@@ -0,0 +1,36 @@
+package userconfig
+
+import "time"
+
+// 远程配置项的值类型
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeJSON   = "json"
+)
+
+// ValueTypes 所有的值类型
+var ValueTypes = []string{TypeString, TypeInt, TypeFloat, TypeBool, TypeJSON}
+
+// 远程配置值的作用范围，下发时激活码的值优先于权益等级，权益等级优先于应用，都没有时使用配置项的默认值
+const (
+	ScopeApp  = "app"
+	ScopeTier = "tier" // 权益等级，即激活码的时间类型
+	ScopeCard = "card"
+)
+
+// IsScope 检查是否为有效的作用范围
+func IsScope(scope string) bool {
+	return scope == ScopeApp || scope == ScopeTier || scope == ScopeCard
+}
+
+const (
+	// maxConfigKeyLength 配置项名称的最大长度
+	maxConfigKeyLength = 128
+	// maxValueLength 配置值的最大长度
+	maxValueLength = 64 * 1024
+	// remoteCacheTTL 应用的远程配置缓存时间，修改时会清除缓存
+	remoteCacheTTL = time.Minute
+)
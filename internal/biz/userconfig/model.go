@@ -1,6 +1,9 @@
 package userconfig
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // UserConfig 结构体用于映射数据库中的 user_config 表
 type UserConfig struct {
@@ -17,3 +20,56 @@ type UserConfig struct {
 func (UserConfig) TableName() string {
 	return "user_config"
 }
+
+// RemoteKey 应用的远程配置项，值的类型在创建后不能修改
+type RemoteKey struct {
+	ID           string    `json:"id"`
+	AppID        string    `json:"app_id"`
+	ConfigKey    string    `json:"config_key"`
+	ValueType    string    `json:"value_type"`    // 值类型: string, int, float, bool, json
+	DefaultValue string    `json:"default_value"` // 默认值，JSON 格式，为空表示没有默认值，不下发
+	Description  string    `json:"description"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (RemoteKey) TableName() string {
+	return "remote_config_key"
+}
+
+// RemoteValue 配置项在某个作用范围上的值，每次修改版本号加一
+type RemoteValue struct {
+	ID         string    `json:"id"`
+	KeyID      string    `json:"key_id"`
+	AppID      string    `json:"app_id"`
+	Scope      string    `json:"scope"`       // 作用范围: app, tier, card
+	ScopeValue string    `json:"scope_value"` // 权益等级或激活码的值，作用范围为 app 时为空
+	Value      string    `json:"value"`       // JSON 格式
+	Version    int       `json:"version"`
+	UpdaterID  string    `json:"updater_id"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (RemoteValue) TableName() string {
+	return "remote_config_value"
+}
+
+// RemoteValueHistory 配置值的历史版本，用于查看和回滚
+type RemoteValueHistory struct {
+	ID        int64     `json:"id"`
+	ValueID   string    `json:"value_id"`
+	Version   int       `json:"version"`
+	Value     string    `json:"value"`
+	UpdaterID string    `json:"updater_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (RemoteValueHistory) TableName() string {
+	return "remote_config_history"
+}
+
+// RemoteConfig 下发给客户端的配置，ETag 由配置内容计算
+type RemoteConfig struct {
+	Values map[string]json.RawMessage `json:"values"`
+	ETag   string                     `json:"etag"`
+}
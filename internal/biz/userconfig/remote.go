@@ -0,0 +1,144 @@
+package userconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var configKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// IsValueType 检查是否为有效的值类型
+func IsValueType(valueType string) bool {
+	for _, t := range ValueTypes {
+		if t == valueType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConfigKey 检查配置项名称：字母开头，只包含字母、数字、下划线、点和减号
+func IsConfigKey(key string) bool {
+	return len(key) <= maxConfigKeyLength && configKeyPattern.MatchString(key)
+}
+
+// normalizeValue 检查 JSON 格式的值是否符合类型，返回压缩后的 JSON
+func normalizeValue(valueType string, raw json.RawMessage) (string, error) {
+	if len(raw) > maxValueLength {
+		return "", fmt.Errorf("配置值不能超过 %d 字节", maxValueLength)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return "", fmt.Errorf("配置值不是有效的 JSON: %v", err)
+	}
+	if decoder.More() {
+		return "", fmt.Errorf("配置值不是有效的 JSON")
+	}
+
+	ok := false
+	switch valueType {
+	case TypeString:
+		_, ok = v.(string)
+	case TypeInt:
+		if n, isNumber := v.(json.Number); isNumber {
+			_, err := n.Int64()
+			ok = err == nil
+		}
+	case TypeFloat:
+		if n, isNumber := v.(json.Number); isNumber {
+			_, err := n.Float64()
+			ok = err == nil
+		}
+	case TypeBool:
+		_, ok = v.(bool)
+	case TypeJSON:
+		ok = v != nil
+	default:
+		return "", fmt.Errorf("不支持的值类型: %s", valueType)
+	}
+	if !ok {
+		return "", fmt.Errorf("配置值不是 %s 类型", valueType)
+	}
+
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// resolveRemoteConfig 为激活码计算下发的配置，激活码的值优先于权益等级，权益等级优先于应用，最后使用默认值
+func resolveRemoteConfig(keys []RemoteKey, values []RemoteValue, tier string, cardValue string) RemoteConfig {
+	// 作用范围越具体优先级越高
+	priority := func(v RemoteValue) int {
+		switch {
+		case v.Scope == ScopeCard && cardValue != "" && v.ScopeValue == cardValue:
+			return 3
+		case v.Scope == ScopeTier && tier != "" && v.ScopeValue == tier:
+			return 2
+		case v.Scope == ScopeApp:
+			return 1
+		}
+		return 0
+	}
+	best := make(map[string]RemoteValue, len(keys))
+	for _, v := range values {
+		p := priority(v)
+		if p == 0 {
+			continue
+		}
+		if current, ok := best[v.KeyID]; !ok || p > priority(current) {
+			best[v.KeyID] = v
+		}
+	}
+
+	config := RemoteConfig{Values: make(map[string]json.RawMessage, len(keys))}
+	for _, key := range keys {
+		value := key.DefaultValue
+		if v, ok := best[key.ID]; ok {
+			value = v.Value
+		}
+		if value == "" {
+			continue
+		}
+		config.Values[key.ConfigKey] = json.RawMessage(value)
+	}
+	config.ETag = computeETag(config.Values)
+	return config
+}
+
+// computeETag 按配置项名称排序后计算配置内容的摘要
+func computeETag(values map[string]json.RawMessage) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write(values[name])
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// MatchETag 检查 If-None-Match 请求头中是否包含 etag，支持多个值、弱校验和 *
+func MatchETag(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || strings.Trim(candidate, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
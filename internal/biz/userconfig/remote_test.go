@@ -0,0 +1,98 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	cases := []struct {
+		valueType string
+		raw       string
+		want      string
+		ok        bool
+	}{
+		{TypeString, `"hello"`, `"hello"`, true},
+		{TypeString, `1`, "", false},
+		{TypeInt, ` 42 `, `42`, true},
+		{TypeInt, `4.2`, "", false},
+		{TypeFloat, `4.2`, `4.2`, true},
+		{TypeFloat, `"4.2"`, "", false},
+		{TypeBool, `true`, `true`, true},
+		{TypeBool, `1`, "", false},
+		{TypeJSON, `{ "a": [1, 2] }`, `{"a":[1,2]}`, true},
+		{TypeJSON, `null`, "", false},
+		{TypeJSON, `{} {}`, "", false},
+		{"unknown", `1`, "", false},
+	}
+	for _, c := range cases {
+		got, err := normalizeValue(c.valueType, json.RawMessage(c.raw))
+		if (err == nil) != c.ok {
+			t.Fatalf("normalizeValue(%s, %s) err = %v, want ok = %v", c.valueType, c.raw, err, c.ok)
+		}
+		if got != c.want {
+			t.Fatalf("normalizeValue(%s, %s) = %s, want %s", c.valueType, c.raw, got, c.want)
+		}
+	}
+}
+
+func TestResolveRemoteConfig(t *testing.T) {
+	keys := []RemoteKey{
+		{ID: "k1", ConfigKey: "theme", DefaultValue: `"light"`},
+		{ID: "k2", ConfigKey: "max_tabs", DefaultValue: `5`},
+		{ID: "k3", ConfigKey: "banner"},
+	}
+	values := []RemoteValue{
+		{KeyID: "k1", Scope: ScopeCard, ScopeValue: "CARD-1", Value: `"dark"`},
+		{KeyID: "k1", Scope: ScopeTier, ScopeValue: "yearly", Value: `"blue"`},
+		{KeyID: "k1", Scope: ScopeApp, Value: `"system"`},
+		{KeyID: "k2", Scope: ScopeTier, ScopeValue: "yearly", Value: `20`},
+		{KeyID: "k2", Scope: ScopeCard, ScopeValue: "CARD-2", Value: `99`},
+	}
+
+	got := resolveRemoteConfig(keys, values, "yearly", "CARD-1")
+	if string(got.Values["theme"]) != `"dark"` || string(got.Values["max_tabs"]) != `20` {
+		t.Fatalf("card and tier values not applied: %v", got.Values)
+	}
+	if _, ok := got.Values["banner"]; ok {
+		t.Fatalf("key without value or default should be omitted")
+	}
+
+	got = resolveRemoteConfig(keys, values, "monthly", "CARD-3")
+	if string(got.Values["theme"]) != `"system"` || string(got.Values["max_tabs"]) != `5` {
+		t.Fatalf("app value and default not applied: %v", got.Values)
+	}
+
+	// 值的顺序不影响结果和 ETag
+	reversed := make([]RemoteValue, len(values))
+	for i, v := range values {
+		reversed[len(values)-1-i] = v
+	}
+	a := resolveRemoteConfig(keys, values, "yearly", "CARD-1")
+	b := resolveRemoteConfig(keys, reversed, "yearly", "CARD-1")
+	if a.ETag != b.ETag || a.ETag == "" {
+		t.Fatalf("etag not stable: %s vs %s", a.ETag, b.ETag)
+	}
+	if a.ETag == got.ETag {
+		t.Fatalf("different configs should have different etags")
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"x", "abc"`, true},
+		{`"x"`, false},
+		{"*", true},
+	}
+	for _, c := range cases {
+		if got := MatchETag(c.header, "abc"); got != c.want {
+			t.Fatalf("MatchETag(%q) = %v, want %v", c.header, got, c.want)
+		}
+	}
+}
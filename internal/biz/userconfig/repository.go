@@ -2,7 +2,30 @@ package userconfig
 
 type Repository interface {
 	GetUserConfigByUserIDAndConfigKey(userID string, configKey string) (*UserConfig, error)
+	GetUserConfigsByConfigKey(configKey string) ([]UserConfig, error)
 	CreateUserConfig(userConfig *UserConfig) error
 	UpdateUserConfig(userConfig *UserConfig) error
 	DeleteUserConfig(userConfig *UserConfig) error
+
+	// 远程配置
+	GetRemoteKeys(appID string) ([]RemoteKey, error)
+	GetRemoteKeyByID(id string) (RemoteKey, error)
+	GetRemoteKeyByName(appID string, configKey string) (RemoteKey, error)
+	CreateRemoteKey(key RemoteKey) error
+	UpdateRemoteKey(key RemoteKey) error
+	// DeleteRemoteKey 删除配置项和它所有的值及历史版本
+	DeleteRemoteKey(id string) error
+
+	GetRemoteValues(keyID string) ([]RemoteValue, error)
+	// GetSharedRemoteValues 获取应用中作用范围为应用和权益等级的值
+	GetSharedRemoteValues(appID string) ([]RemoteValue, error)
+	// GetCardRemoteValues 获取应用中某个激活码的值
+	GetCardRemoteValues(appID string, cardValue string) ([]RemoteValue, error)
+	GetRemoteValueByID(id string) (RemoteValue, error)
+	GetRemoteValueByScope(keyID string, scope string, scopeValue string) (RemoteValue, error)
+	// SaveRemoteValue 保存值并记录历史版本
+	SaveRemoteValue(value RemoteValue) error
+	// DeleteRemoteValue 删除值和它的历史版本
+	DeleteRemoteValue(id string) error
+	GetRemoteValueHistory(valueID string) ([]RemoteValueHistory, error)
 }
@@ -50,6 +50,14 @@ func (r *repository) GetUserConfigByUserIDAndConfigKey(userID string, configKey
 	return &userConfig, nil
 }
 
+func (r *repository) GetUserConfigsByConfigKey(configKey string) ([]UserConfig, error) {
+	userConfigs := make([]UserConfig, 0)
+	if err := r.db.Where("config_key = ?", configKey).Find(&userConfigs).Error; err != nil {
+		return nil, err
+	}
+	return userConfigs, nil
+}
+
 func (r *repository) CreateUserConfig(userConfig *UserConfig) error {
 	err := r.db.Create(userConfig).Error
 	if err != nil {
@@ -87,3 +95,164 @@ func (r *repository) UpdateUserConfig(userConfig *UserConfig) error {
 func (r *repository) DeleteUserConfig(userConfig *UserConfig) error {
 	return r.db.Where("user_id = ? AND config_key = ?", userConfig.UserID, userConfig.ConfigKey).Delete(userConfig).Error
 }
+
+/*
+CREATE TABLE remote_config_key (
+    id            VARCHAR(36)  NOT NULL PRIMARY KEY,
+    app_id        VARCHAR(36)  NOT NULL,             -- 应用ID
+    config_key    VARCHAR(128) NOT NULL,             -- 配置项名称
+    value_type    VARCHAR(16)  NOT NULL,             -- 值类型: string, int, float, bool, json
+    default_value TEXT         NOT NULL,             -- 默认值，JSON 格式，为空表示没有默认值
+    description   VARCHAR(255) NOT NULL DEFAULT '',  -- 说明
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE (app_id, config_key)
+);
+
+CREATE TABLE remote_config_value (
+    id          VARCHAR(36)  NOT NULL PRIMARY KEY,
+    key_id      VARCHAR(36)  NOT NULL,             -- 配置项ID
+    app_id      VARCHAR(36)  NOT NULL,             -- 应用ID
+    scope       VARCHAR(16)  NOT NULL,             -- 作用范围: app, tier, card
+    scope_value VARCHAR(255) NOT NULL DEFAULT '',  -- 权益等级或激活码的值
+    value       TEXT         NOT NULL,             -- JSON 格式
+    version     INT          NOT NULL,             -- 版本号，每次修改加一
+    updater_id  VARCHAR(36)  NOT NULL,             -- 最后修改人
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE (key_id, scope, scope_value),
+    INDEX idx_remote_config_value_app (app_id, scope, scope_value)
+);
+
+CREATE TABLE remote_config_history (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    value_id   VARCHAR(36) NOT NULL,  -- 配置值ID
+    version    INT         NOT NULL,  -- 版本号
+    value      TEXT        NOT NULL,  -- JSON 格式
+    updater_id VARCHAR(36) NOT NULL,  -- 修改人
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (value_id, version)
+);
+*/
+
+func (r *repository) GetRemoteKeys(appID string) ([]RemoteKey, error) {
+	keys := make([]RemoteKey, 0)
+	if err := r.db.Where("app_id = ?", appID).Order("config_key asc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *repository) GetRemoteKeyByID(id string) (RemoteKey, error) {
+	var key RemoteKey
+	if err := r.db.Where("id = ?", id).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RemoteKey{}, errcode.NotFound.WithDetails("配置项不存在")
+		}
+		return RemoteKey{}, err
+	}
+	return key, nil
+}
+
+func (r *repository) GetRemoteKeyByName(appID string, configKey string) (RemoteKey, error) {
+	var key RemoteKey
+	if err := r.db.Where("app_id = ? AND config_key = ?", appID, configKey).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RemoteKey{}, errcode.NotFound.WithDetails("配置项不存在")
+		}
+		return RemoteKey{}, err
+	}
+	return key, nil
+}
+
+func (r *repository) CreateRemoteKey(key RemoteKey) error {
+	return r.db.Create(&key).Error
+}
+
+func (r *repository) UpdateRemoteKey(key RemoteKey) error {
+	return r.db.Save(&key).Error
+}
+
+func (r *repository) DeleteRemoteKey(id string) error {
+	valueIDs := r.db.Model(&RemoteValue{}).Select("id").Where("key_id = ?", id)
+	if err := r.db.Where("value_id in (?)", valueIDs).Delete(&RemoteValueHistory{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("key_id = ?", id).Delete(&RemoteValue{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("id = ?", id).Delete(&RemoteKey{}).Error
+}
+
+func (r *repository) GetRemoteValues(keyID string) ([]RemoteValue, error) {
+	values := make([]RemoteValue, 0)
+	if err := r.db.Where("key_id = ?", keyID).Order("scope asc, scope_value asc").Find(&values).Error; err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (r *repository) GetSharedRemoteValues(appID string) ([]RemoteValue, error) {
+	values := make([]RemoteValue, 0)
+	if err := r.db.Where("app_id = ? AND scope in ?", appID, []string{ScopeApp, ScopeTier}).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (r *repository) GetCardRemoteValues(appID string, cardValue string) ([]RemoteValue, error) {
+	values := make([]RemoteValue, 0)
+	if err := r.db.Where("app_id = ? AND scope = ? AND scope_value = ?", appID, ScopeCard, cardValue).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (r *repository) GetRemoteValueByID(id string) (RemoteValue, error) {
+	var value RemoteValue
+	if err := r.db.Where("id = ?", id).First(&value).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RemoteValue{}, errcode.NotFound.WithDetails("配置值不存在")
+		}
+		return RemoteValue{}, err
+	}
+	return value, nil
+}
+
+func (r *repository) GetRemoteValueByScope(keyID string, scope string, scopeValue string) (RemoteValue, error) {
+	var value RemoteValue
+	if err := r.db.Where("key_id = ? AND scope = ? AND scope_value = ?", keyID, scope, scopeValue).First(&value).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RemoteValue{}, errcode.NotFound.WithDetails("配置值不存在")
+		}
+		return RemoteValue{}, err
+	}
+	return value, nil
+}
+
+func (r *repository) SaveRemoteValue(value RemoteValue) error {
+	if err := r.db.Save(&value).Error; err != nil {
+		return err
+	}
+	return r.db.Create(&RemoteValueHistory{
+		ValueID:   value.ID,
+		Version:   value.Version,
+		Value:     value.Value,
+		UpdaterID: value.UpdaterID,
+		CreatedAt: value.UpdatedAt,
+	}).Error
+}
+
+func (r *repository) DeleteRemoteValue(id string) error {
+	if err := r.db.Where("value_id = ?", id).Delete(&RemoteValueHistory{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("id = ?", id).Delete(&RemoteValue{}).Error
+}
+
+func (r *repository) GetRemoteValueHistory(valueID string) ([]RemoteValueHistory, error) {
+	history := make([]RemoteValueHistory, 0)
+	if err := r.db.Where("value_id = ?", valueID).Order("version desc").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -1,14 +1,74 @@
 package userconfig
 
+import (
+	"encoding/json"
+
+	"configuration-management/pkg/app"
+)
+
 type CreateUserConfigArgs struct {
-	UserID      string `json:"user_id"`
-	ConfigKey   string `json:"config_key"`
-	ConfigValue string `json:"config_value"`
+	UserID      string    `json:"user_id"`
+	ConfigKey   string    `json:"config_key"`
+	ConfigValue string    `json:"config_value"`
+	Actor       app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+// Caller 调用远程配置管理接口的用户，零值表示 root，不限制
+type Caller struct {
+	UserID      string   // 为空时不限制
+	SubtreePath string   // 不为空时可以管理所有下级用户的激活码上的值
+	AppIDs      []string // 有权限的应用
+}
+
+type CreateRemoteKeyArgs struct {
+	AppID        string          `json:"app_id"`
+	ConfigKey    string          `json:"config_key"`
+	ValueType    string          `json:"value_type"`
+	DefaultValue json.RawMessage `json:"default_value"` // 为空表示没有默认值
+	Description  string          `json:"description"`
+	Caller       Caller          `json:"-"` // 只能在有权限的应用下创建
+	Actor        app.Actor       `json:"-"` // 操作人，写入审计日志
+}
+
+type UpdateRemoteKeyArgs struct {
+	ID           string          `json:"id"`
+	DefaultValue json.RawMessage `json:"default_value"`
+	Description  string          `json:"description"`
+	Caller       Caller          `json:"-"`
+	Actor        app.Actor       `json:"-"`
+}
+
+type SaveRemoteValueArgs struct {
+	KeyID      string          `json:"key_id"`
+	Scope      string          `json:"scope"`
+	ScopeValue string          `json:"scope_value"`
+	Value      json.RawMessage `json:"value"`
+	Caller     Caller          `json:"-"`
+	Actor      app.Actor       `json:"-"`
 }
 
 type Service interface {
 	GetUserConfigByUserIDAndConfigKey(userID string, configKey string) (*UserConfig, error)
+	// GetUserConfigsByConfigKey 查询所有用户的某项配置
+	GetUserConfigsByConfigKey(configKey string) ([]UserConfig, error)
 	CreateUserConfig(args CreateUserConfigArgs) error
-	UpdateUserConfig(userConfig *UserConfig) error
-	DeleteUserConfig(userConfig *UserConfig) error
+	UpdateUserConfig(userConfig *UserConfig, actor app.Actor) error
+	DeleteUserConfig(userConfig *UserConfig, actor app.Actor) error
+
+	// 远程配置，非 root 只能管理有权限的应用，激活码上的值只能管理自己和下级的激活码
+	GetRemoteKeys(appID string, caller Caller) ([]RemoteKey, error)
+	CreateRemoteKey(args CreateRemoteKeyArgs) (RemoteKey, error)
+	UpdateRemoteKey(args UpdateRemoteKeyArgs) error
+	// DeleteRemoteKey 删除配置项和它所有的值
+	DeleteRemoteKey(id string, caller Caller, actor app.Actor) error
+	// GetRemoteValues 查询配置项的值，不返回调用者管理范围之外的激活码上的值
+	GetRemoteValues(keyID string, caller Caller) ([]RemoteValue, error)
+	// SaveRemoteValue 新增或修改某个作用范围上的值，版本号加一
+	SaveRemoteValue(args SaveRemoteValueArgs) (RemoteValue, error)
+	DeleteRemoteValue(id string, caller Caller, actor app.Actor) error
+	GetRemoteValueHistory(valueID string, caller Caller) ([]RemoteValueHistory, error)
+	// RollbackRemoteValue 使用历史版本的值保存为新的版本
+	RollbackRemoteValue(valueID string, version int, caller Caller, actor app.Actor) (RemoteValue, error)
+	// ResolveRemoteConfig 计算下发给激活码的配置，tier 为激活码的权益等级
+	ResolveRemoteConfig(appID string, tier string, cardValue string) (RemoteConfig, error)
 }
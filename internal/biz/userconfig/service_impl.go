@@ -2,16 +2,43 @@ package userconfig
 
 import (
 	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
 	"configuration-management/utils"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/patrickmn/go-cache"
+	"gorm.io/gorm"
 )
 
+// remoteCache 客户端每次拉取配置都要读取应用的配置项，按应用ID缓存配置项和作用范围为应用、权益等级的值
+var remoteCache = cache.New(remoteCacheTTL, remoteCacheTTL)
+
+// remoteBundle 应用的配置项和共用的值
+type remoteBundle struct {
+	keys   []RemoteKey
+	values []RemoteValue
+}
+
 type service struct {
-	repo Repository
+	db       *gorm.DB
+	repo     Repository
+	appRepo  apps.Repository
+	cardRepo card.Repository
 }
 
 func NewService() Service {
-	return &service{repo: NewRepository(global.DBEngine)}
+	return &service{
+		db:       global.DBEngine,
+		repo:     NewRepository(global.DBEngine),
+		appRepo:  apps.NewRepository(global.DBEngine),
+		cardRepo: card.NewRepository(global.DBEngine),
+	}
 }
 
 //type UserConfig struct {
@@ -43,6 +70,10 @@ func (s *service) GetUserConfigByUserIDAndConfigKey(userID string, configKey str
 	return userConfig, nil
 }
 
+func (s *service) GetUserConfigsByConfigKey(configKey string) ([]UserConfig, error) {
+	return s.repo.GetUserConfigsByConfigKey(configKey)
+}
+
 func (s *service) CreateUserConfig(args CreateUserConfigArgs) error {
 	userConfig := &UserConfig{
 		ID:          utils.GenerateUUID(),
@@ -53,25 +84,433 @@ func (s *service) CreateUserConfig(args CreateUserConfigArgs) error {
 		IsDeleted:   false,
 	}
 
-	err := s.repo.CreateUserConfig(userConfig)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).CreateUserConfig(userConfig); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionUserConfigCreate, audit.TargetUserConfig, userConfig.ID, nil, userConfig)
+	})
+}
+
+func (s *service) UpdateUserConfig(userConfig *UserConfig, actor app.Actor) error {
+	before, err := s.repo.GetUserConfigByUserIDAndConfigKey(userConfig.UserID, userConfig.ConfigKey)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		if err := repo.UpdateUserConfig(userConfig); err != nil {
+			return err
+		}
+		// 只更新了非零值字段，重新读取更新后的记录
+		after, err := repo.GetUserConfigByUserIDAndConfigKey(userConfig.UserID, userConfig.ConfigKey)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionUserConfigUpdate, audit.TargetUserConfig, before.ID, before, after)
+	})
+}
+
+func (s *service) DeleteUserConfig(userConfig *UserConfig, actor app.Actor) error {
+	before, err := s.repo.GetUserConfigByUserIDAndConfigKey(userConfig.UserID, userConfig.ConfigKey)
 	if err != nil {
 		return err
 	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).DeleteUserConfig(userConfig); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionUserConfigDelete, audit.TargetUserConfig, before.ID, before, nil)
+	})
+}
+
+func (s *service) GetRemoteKeys(appID string, caller Caller) ([]RemoteKey, error) {
+	if err := caller.checkApp(appID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRemoteKeys(appID)
+}
+
+func (s *service) CreateRemoteKey(args CreateRemoteKeyArgs) (RemoteKey, error) {
+	if !IsConfigKey(args.ConfigKey) {
+		return RemoteKey{}, errcode.InvalidParams.WithDetails("无效的配置项名称: " + args.ConfigKey)
+	}
+	if !IsValueType(args.ValueType) {
+		return RemoteKey{}, errcode.InvalidParams.WithDetails("不支持的值类型: " + args.ValueType)
+	}
+	if err := args.Caller.checkApp(args.AppID); err != nil {
+		return RemoteKey{}, err
+	}
+	defaultValue, err := normalizeDefaultValue(args.ValueType, args.DefaultValue)
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	result, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{ID: args.AppID})
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	if result.Total == 0 {
+		return RemoteKey{}, errcode.NotFound.WithDetails("应用不存在")
+	}
+	if _, err := s.repo.GetRemoteKeyByName(args.AppID, args.ConfigKey); err == nil {
+		return RemoteKey{}, errcode.DuplicateKey.WithDetails("配置项已经存在: " + args.ConfigKey)
+	} else if !errors.Is(err, errcode.NotFound) {
+		return RemoteKey{}, err
+	}
+
+	now := time.Now()
+	key := RemoteKey{
+		ID:           utils.GenerateUUID(),
+		AppID:        args.AppID,
+		ConfigKey:    args.ConfigKey,
+		ValueType:    args.ValueType,
+		DefaultValue: defaultValue,
+		Description:  args.Description,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).CreateRemoteKey(key); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionRemoteConfigKeyCreate, audit.TargetRemoteConfigKey, key.ID, nil, key)
+	})
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	remoteCache.Delete(key.AppID)
+	return key, nil
+}
+
+func (s *service) UpdateRemoteKey(args UpdateRemoteKeyArgs) error {
+	before, err := s.repo.GetRemoteKeyByID(args.ID)
+	if err != nil {
+		return err
+	}
+	if err := args.Caller.checkApp(before.AppID); err != nil {
+		return err
+	}
+	defaultValue, err := normalizeDefaultValue(before.ValueType, args.DefaultValue)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.DefaultValue = defaultValue
+	after.Description = args.Description
+	after.UpdatedAt = time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).UpdateRemoteKey(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionRemoteConfigKeyUpdate, audit.TargetRemoteConfigKey, after.ID, before, after)
+	})
+	if err != nil {
+		return err
+	}
+	remoteCache.Delete(after.AppID)
 	return nil
 }
 
-func (s *service) UpdateUserConfig(userConfig *UserConfig) error {
-	err := s.repo.UpdateUserConfig(userConfig)
+func (s *service) DeleteRemoteKey(id string, caller Caller, actor app.Actor) error {
+	before, err := s.repo.GetRemoteKeyByID(id)
 	if err != nil {
 		return err
 	}
+	if err := caller.checkApp(before.AppID); err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).DeleteRemoteKey(id); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionRemoteConfigKeyDelete, audit.TargetRemoteConfigKey, id, before, nil)
+	})
+	if err != nil {
+		return err
+	}
+	remoteCache.Delete(before.AppID)
 	return nil
 }
 
-func (s *service) DeleteUserConfig(userConfig *UserConfig) error {
-	err := s.repo.DeleteUserConfig(userConfig)
+func (s *service) GetRemoteValues(keyID string, caller Caller) ([]RemoteValue, error) {
+	key, err := s.repo.GetRemoteKeyByID(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := caller.checkApp(key.AppID); err != nil {
+		return nil, err
+	}
+	values, err := s.repo.GetRemoteValues(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	cardValues := make([]string, 0)
+	for _, v := range values {
+		if v.Scope == ScopeCard {
+			cardValues = append(cardValues, v.ScopeValue)
+		}
+	}
+	owned, err := s.ownedCards(caller, cardValues)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]RemoteValue, 0, len(values))
+	for _, v := range values {
+		if v.Scope != ScopeCard || owned[v.ScopeValue] {
+			visible = append(visible, v)
+		}
+	}
+	return visible, nil
+}
+
+func (s *service) SaveRemoteValue(args SaveRemoteValueArgs) (RemoteValue, error) {
+	key, err := s.repo.GetRemoteKeyByID(args.KeyID)
+	if err != nil {
+		return RemoteValue{}, err
+	}
+	if err := args.Caller.checkApp(key.AppID); err != nil {
+		return RemoteValue{}, err
+	}
+	if err := s.checkScope(key.AppID, args.Scope, args.ScopeValue); err != nil {
+		return RemoteValue{}, err
+	}
+	if err := s.checkValueOwner(args.Caller, args.Scope, args.ScopeValue); err != nil {
+		return RemoteValue{}, err
+	}
+	value, err := normalizeValue(key.ValueType, args.Value)
+	if err != nil {
+		return RemoteValue{}, errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	before, err := s.repo.GetRemoteValueByScope(key.ID, args.Scope, args.ScopeValue)
+	if err != nil && !errors.Is(err, errcode.NotFound) {
+		return RemoteValue{}, err
+	}
+	if err != nil {
+		before = RemoteValue{}
+	}
+	return s.saveRemoteValue(key, before, value, audit.ActionRemoteConfigValueSave, args.Actor, args.Scope, args.ScopeValue)
+}
+
+func (s *service) DeleteRemoteValue(id string, caller Caller, actor app.Actor) error {
+	before, err := s.getManagedRemoteValue(id, caller)
+	if err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).DeleteRemoteValue(id); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionRemoteConfigValueDelete, audit.TargetRemoteConfigValue, id, before, nil)
+	})
+	if err != nil {
+		return err
+	}
+	remoteCache.Delete(before.AppID)
+	return nil
+}
+
+func (s *service) GetRemoteValueHistory(valueID string, caller Caller) ([]RemoteValueHistory, error) {
+	if _, err := s.getManagedRemoteValue(valueID, caller); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRemoteValueHistory(valueID)
+}
+
+func (s *service) RollbackRemoteValue(valueID string, version int, caller Caller, actor app.Actor) (RemoteValue, error) {
+	before, err := s.getManagedRemoteValue(valueID, caller)
+	if err != nil {
+		return RemoteValue{}, err
+	}
+	key, err := s.repo.GetRemoteKeyByID(before.KeyID)
+	if err != nil {
+		return RemoteValue{}, err
+	}
+	history, err := s.repo.GetRemoteValueHistory(valueID)
+	if err != nil {
+		return RemoteValue{}, err
+	}
+	for _, h := range history {
+		if h.Version == version {
+			return s.saveRemoteValue(key, before, h.Value, audit.ActionRemoteConfigValueRollback, actor, before.Scope, before.ScopeValue)
+		}
+	}
+	return RemoteValue{}, errcode.NotFound.WithDetails(fmt.Sprintf("版本 %d 不存在", version))
+}
+
+func (s *service) ResolveRemoteConfig(appID string, tier string, cardValue string) (RemoteConfig, error) {
+	bundle, err := s.getRemoteBundle(appID)
+	if err != nil {
+		return RemoteConfig{}, err
+	}
+	values := bundle.values
+	if cardValue != "" {
+		cardValues, err := s.repo.GetCardRemoteValues(appID, cardValue)
+		if err != nil {
+			return RemoteConfig{}, err
+		}
+		values = append(append(make([]RemoteValue, 0, len(values)+len(cardValues)), values...), cardValues...)
+	}
+	return resolveRemoteConfig(bundle.keys, values, tier, cardValue), nil
+}
+
+// saveRemoteValue 保存新的版本，before 的 ID 为空时新增
+func (s *service) saveRemoteValue(key RemoteKey, before RemoteValue, value string, action string, actor app.Actor, scope string, scopeValue string) (RemoteValue, error) {
+	after := before
+	if after.ID == "" {
+		after = RemoteValue{
+			ID:         utils.GenerateUUID(),
+			KeyID:      key.ID,
+			AppID:      key.AppID,
+			Scope:      scope,
+			ScopeValue: scopeValue,
+		}
+	}
+	after.Value = value
+	after.Version++
+	after.UpdaterID = actor.ID
+	after.UpdatedAt = time.Now()
+
+	var auditBefore interface{}
+	if before.ID != "" {
+		auditBefore = before
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).SaveRemoteValue(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, action, audit.TargetRemoteConfigValue, after.ID, auditBefore, after)
+	})
+	if err != nil {
+		return RemoteValue{}, err
+	}
+	remoteCache.Delete(key.AppID)
+	return after, nil
+}
+
+// checkScope 权益等级必须是激活码的时间类型，激活码必须属于配置项的应用
+func (s *service) checkScope(appID string, scope string, scopeValue string) error {
+	switch scope {
+	case ScopeApp:
+		if scopeValue != "" {
+			return errcode.InvalidParams.WithDetails("作用范围为应用时不能指定 scope_value")
+		}
+	case ScopeTier:
+		if !card.IsTimeType(scopeValue) {
+			return errcode.InvalidParams.WithDetails("无效的权益等级: " + scopeValue)
+		}
+	case ScopeCard:
+		c, err := s.cardRepo.GetCardByValue(scopeValue)
+		if errors.Is(err, errcode.NotFound) || (err == nil && c.AppID != appID) {
+			return errcode.InvalidParams.WithDetails("激活码不存在或不属于该应用: " + scopeValue)
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return errcode.InvalidParams.WithDetails("无效的作用范围: " + scope)
+	}
+	return nil
+}
+
+// checkApp 非 root 只能管理有权限的应用的配置
+func (c Caller) checkApp(appID string) error {
+	if c.UserID == "" {
+		return nil
+	}
+	for _, id := range c.AppIDs {
+		if id == appID {
+			return nil
+		}
+	}
+	return errcode.NoPermission.WithDetails("没有该应用的权限")
+}
+
+// checkValueOwner 激活码上的值只能由激活码的所有者或其上级管理
+func (s *service) checkValueOwner(caller Caller, scope string, scopeValue string) error {
+	if scope != ScopeCard {
+		return nil
+	}
+	owned, err := s.ownedCards(caller, []string{scopeValue})
 	if err != nil {
 		return err
 	}
+	if !owned[scopeValue] {
+		return errcode.NoPermission.WithDetails("只能管理自己和下级的激活码上的配置")
+	}
 	return nil
 }
+
+// ownedCards 返回 values 中属于调用者或其下级的激活码，root 不限制
+func (s *service) ownedCards(caller Caller, values []string) (map[string]bool, error) {
+	owned := make(map[string]bool, len(values))
+	if caller.UserID == "" {
+		for _, v := range values {
+			owned[v] = true
+		}
+		return owned, nil
+	}
+	if len(values) == 0 {
+		return owned, nil
+	}
+	result, err := s.cardRepo.GetCards(card.GetCardsArgs{
+		UserId:      caller.UserID,
+		SubtreePath: caller.SubtreePath,
+		Values:      values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range result.List {
+		owned[c.Value] = true
+	}
+	return owned, nil
+}
+
+// getManagedRemoteValue 查询调用者能管理的值，没有权限时返回 errcode.NoPermission
+func (s *service) getManagedRemoteValue(id string, caller Caller) (RemoteValue, error) {
+	value, err := s.repo.GetRemoteValueByID(id)
+	if err != nil {
+		return RemoteValue{}, err
+	}
+	if err := caller.checkApp(value.AppID); err != nil {
+		return RemoteValue{}, err
+	}
+	if err := s.checkValueOwner(caller, value.Scope, value.ScopeValue); err != nil {
+		return RemoteValue{}, err
+	}
+	return value, nil
+}
+
+func (s *service) getRemoteBundle(appID string) (remoteBundle, error) {
+	if cached, ok := remoteCache.Get(appID); ok {
+		return cached.(remoteBundle), nil
+	}
+	keys, err := s.repo.GetRemoteKeys(appID)
+	if err != nil {
+		return remoteBundle{}, err
+	}
+	values, err := s.repo.GetSharedRemoteValues(appID)
+	if err != nil {
+		return remoteBundle{}, err
+	}
+	bundle := remoteBundle{keys: keys, values: values}
+	remoteCache.SetDefault(appID, bundle)
+	return bundle, nil
+}
+
+// normalizeDefaultValue 默认值可以为空或 null，表示没有默认值
+func normalizeDefaultValue(valueType string, raw []byte) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	value, err := normalizeValue(valueType, raw)
+	if err != nil {
+		return "", errcode.InvalidParams.WithDetails(err.Error())
+	}
+	return value, nil
+}
@@ -0,0 +1,192 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+)
+
+type fakeRepository struct {
+	Repository
+	keys   []RemoteKey
+	values []RemoteValue
+}
+
+func (r *fakeRepository) GetRemoteKeys(appID string) ([]RemoteKey, error) {
+	var keys []RemoteKey
+	for _, k := range r.keys {
+		if k.AppID == appID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeRepository) GetRemoteKeyByID(id string) (RemoteKey, error) {
+	for _, k := range r.keys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return RemoteKey{}, errcode.NotFound
+}
+
+func (r *fakeRepository) GetRemoteValues(keyID string) ([]RemoteValue, error) {
+	var values []RemoteValue
+	for _, v := range r.values {
+		if v.KeyID == keyID {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func (r *fakeRepository) GetRemoteValueByID(id string) (RemoteValue, error) {
+	for _, v := range r.values {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return RemoteValue{}, errcode.NotFound
+}
+
+func (r *fakeRepository) GetRemoteValueHistory(valueID string) ([]RemoteValueHistory, error) {
+	return nil, nil
+}
+
+// fakeCardRepository 的 subtrees 记录每个路径下的下级用户
+type fakeCardRepository struct {
+	card.Repository
+	cards    []card.Card
+	subtrees map[string][]string
+}
+
+func (r *fakeCardRepository) GetCardByValue(value string) (card.Card, error) {
+	for _, c := range r.cards {
+		if c.Value == value {
+			return c, nil
+		}
+	}
+	return card.Card{}, errcode.NotFound
+}
+
+func (r *fakeCardRepository) GetCards(args card.GetCardsArgs) (card.GetCardsResult, error) {
+	owners := map[string]bool{args.UserId: true}
+	for _, id := range r.subtrees[args.SubtreePath] {
+		owners[id] = true
+	}
+	values := make(map[string]bool, len(args.Values))
+	for _, v := range args.Values {
+		values[v] = true
+	}
+	var result card.GetCardsResult
+	for _, c := range r.cards {
+		if owners[c.UserID] && values[c.Value] {
+			result.List = append(result.List, c)
+		}
+	}
+	result.Total = len(result.List)
+	return result, nil
+}
+
+// newTestService 代理 seller 有 app1 的权限，下级是 sub；other 是另一个代理
+func newTestService() (*service, Caller) {
+	repo := &fakeRepository{
+		keys: []RemoteKey{
+			{ID: "key1", AppID: "app1", ConfigKey: "feature", ValueType: TypeBool},
+			{ID: "key2", AppID: "app2", ConfigKey: "feature", ValueType: TypeBool},
+		},
+		values: []RemoteValue{
+			{ID: "value-app", KeyID: "key1", AppID: "app1", Scope: ScopeApp, Value: "true"},
+			{ID: "value-sub", KeyID: "key1", AppID: "app1", Scope: ScopeCard, ScopeValue: "CARD-SUB", Value: "true"},
+			{ID: "value-other", KeyID: "key1", AppID: "app1", Scope: ScopeCard, ScopeValue: "CARD-OTHER", Value: "true"},
+			{ID: "value-app2", KeyID: "key2", AppID: "app2", Scope: ScopeApp, Value: "true"},
+		},
+	}
+	cardRepo := &fakeCardRepository{
+		cards: []card.Card{
+			{ID: "c1", AppID: "app1", UserID: "sub", Value: "CARD-SUB"},
+			{ID: "c2", AppID: "app1", UserID: "other", Value: "CARD-OTHER"},
+		},
+		subtrees: map[string][]string{"/seller/": {"sub"}},
+	}
+	caller := Caller{UserID: "seller", SubtreePath: "/seller/", AppIDs: []string{"app1"}}
+	return &service{repo: repo, cardRepo: cardRepo}, caller
+}
+
+func assertNoPermission(t *testing.T, name string, err error) {
+	t.Helper()
+	var e *errcode.Error
+	if !errors.As(err, &e) || e.Code() != errcode.NoPermission.Code() {
+		t.Fatalf("%s: expected NoPermission, got %v", name, err)
+	}
+}
+
+func TestRemoteKeysRequireAppGrant(t *testing.T) {
+	s, caller := newTestService()
+	actor := app.Actor{ID: caller.UserID}
+
+	keys, err := s.GetRemoteKeys("app1", caller)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("granted app: got %v, %v", keys, err)
+	}
+	if keys, err := s.GetRemoteKeys("app2", Caller{}); err != nil || len(keys) != 1 {
+		t.Fatalf("root: got %v, %v", keys, err)
+	}
+
+	_, err = s.GetRemoteKeys("app2", caller)
+	assertNoPermission(t, "GetRemoteKeys", err)
+	_, err = s.CreateRemoteKey(CreateRemoteKeyArgs{AppID: "app2", ConfigKey: "other", ValueType: TypeBool, Caller: caller, Actor: actor})
+	assertNoPermission(t, "CreateRemoteKey", err)
+	err = s.UpdateRemoteKey(UpdateRemoteKeyArgs{ID: "key2", Caller: caller, Actor: actor})
+	assertNoPermission(t, "UpdateRemoteKey", err)
+	err = s.DeleteRemoteKey("key2", caller, actor)
+	assertNoPermission(t, "DeleteRemoteKey", err)
+	_, err = s.GetRemoteValues("key2", caller)
+	assertNoPermission(t, "GetRemoteValues", err)
+	_, err = s.GetRemoteValueHistory("value-app2", caller)
+	assertNoPermission(t, "GetRemoteValueHistory", err)
+}
+
+func TestRemoteValuesRequireOwnedCard(t *testing.T) {
+	s, caller := newTestService()
+	actor := app.Actor{ID: caller.UserID}
+
+	values, err := s.GetRemoteValues("key1", caller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	for _, v := range values {
+		got[v.ID] = true
+	}
+	if len(got) != 2 || !got["value-app"] || !got["value-sub"] {
+		t.Fatalf("expected only the app value and the subtree card value, got %v", values)
+	}
+	if values, err := s.GetRemoteValues("key1", Caller{}); err != nil || len(values) != 3 {
+		t.Fatalf("root: got %v, %v", values, err)
+	}
+	if _, err := s.GetRemoteValueHistory("value-sub", caller); err != nil {
+		t.Fatalf("subtree card value: %v", err)
+	}
+
+	_, err = s.SaveRemoteValue(SaveRemoteValueArgs{
+		KeyID:      "key1",
+		Scope:      ScopeCard,
+		ScopeValue: "CARD-OTHER",
+		Value:      json.RawMessage(`false`),
+		Caller:     caller,
+		Actor:      actor,
+	})
+	assertNoPermission(t, "SaveRemoteValue", err)
+	err = s.DeleteRemoteValue("value-other", caller, actor)
+	assertNoPermission(t, "DeleteRemoteValue", err)
+	_, err = s.GetRemoteValueHistory("value-other", caller)
+	assertNoPermission(t, "GetRemoteValueHistory", err)
+	_, err = s.RollbackRemoteValue("value-other", 1, caller, actor)
+	assertNoPermission(t, "RollbackRemoteValue", err)
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import "time"
+
+// 激活码生命周期事件
+const (
+	EventCardActivated = "card.activated"
+	EventCardExpired   = "card.expired"
+	EventCardLocked    = "card.locked"
+	EventCardDeleted   = "card.deleted"
+)
+
+// EventTypes 可以订阅的事件类型
+var EventTypes = []string{EventCardActivated, EventCardExpired, EventCardLocked, EventCardDeleted}
+
+// IsEventType 检查是否为可以订阅的事件类型
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// 投递状态
+const (
+	DeliveryPending   = "pending"   // 等待投递或等待重试
+	DeliverySucceeded = "succeeded" // 接收方返回 2xx
+	DeliveryDead      = "dead"      // 超过最大尝试次数，进入死信列表，只能手动重新投递
+)
+
+// 投递请求的请求头
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// 没有配置时使用的默认值
+const (
+	defaultPollInterval   = 5 * time.Second
+	defaultBatchSize      = 50
+	defaultMaxAttempts    = 8
+	defaultInitialBackoff = 30 * time.Second
+	defaultMaxBackoff     = 6 * time.Hour
+	defaultTimeout        = 10 * time.Second
+)
+
+const (
+	// secretLength 签名密钥的字节数
+	secretLength = 32
+	// maxErrorLength 保存的最后一次错误信息的最大长度
+	maxErrorLength = 255
+	// maxResponseBody 读取接收方响应的最大字节数
+	maxResponseBody = 1024
+)
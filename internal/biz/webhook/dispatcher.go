@@ -0,0 +1,220 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"configuration-management/global"
+	"configuration-management/pkg/logger"
+)
+
+// Options 投递的参数，为零值时使用默认值
+type Options struct {
+	PollInterval   time.Duration // 轮询发件箱的间隔
+	BatchSize      int           // 每轮最多领取的投递数量
+	MaxAttempts    int           // 最多尝试次数，超过后进入死信列表
+	InitialBackoff time.Duration // 第一次失败后的等待时间，之后每次翻倍
+	MaxBackoff     time.Duration // 最长的等待时间
+	Timeout        time.Duration // 单次请求的超时时间
+}
+
+func (o Options) withDefaults() Options {
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultPollInterval
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultBatchSize
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaultMaxAttempts
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = defaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultMaxBackoff
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	return o
+}
+
+// Producer 每轮投递前运行，用于产生没有对应写操作的事件，例如激活码到期
+type Producer func(now time.Time) error
+
+// Dispatcher 从发件箱领取到期的投递并发送给接收方
+type Dispatcher struct {
+	outbox    Outbox
+	client    *http.Client
+	options   Options
+	producers []Producer
+}
+
+func NewDispatcher(outbox Outbox, options Options, producers ...Producer) *Dispatcher {
+	return &Dispatcher{
+		outbox:    outbox,
+		client:    &http.Client{},
+		options:   options.withDefaults(),
+		producers: producers,
+	}
+}
+
+// Run 按间隔轮询发件箱，直到 ctx 结束
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		for _, produce := range d.producers {
+			if err := produce(now); err != nil {
+				global.Logger.Error("产生 webhook 事件失败", err)
+			}
+		}
+		if _, err := d.DispatchDue(ctx, now); err != nil {
+			global.Logger.Error("投递 webhook 失败", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue 投递到期的投递，返回本轮尝试的数量
+func (d *Dispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	// 领取后依次投递，租约要覆盖整批都超时的情况
+	lease := d.options.Timeout*time.Duration(d.options.BatchSize) + d.options.PollInterval
+	deliveries, err := d.outbox.ClaimDue(now, d.options.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.SubscriptionID)
+	}
+	subs, err := d.outbox.GetSubscriptionsByIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		var statusCode int
+		sub, ok := subs[delivery.SubscriptionID]
+		if ok {
+			statusCode, err = d.send(ctx, sub, delivery)
+		} else {
+			err = errors.New("订阅已删除")
+		}
+		result := d.settle(delivery, statusCode, err, time.Now())
+		if !ok {
+			result.Status = DeliveryDead
+		}
+		// 保存失败时继续投递其他的，这条投递在租约到期后会被重新领取
+		if err := d.outbox.SaveAttempt(result); err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"delivery_id":     result.ID,
+				"subscription_id": result.SubscriptionID,
+				"event_id":        result.EventID,
+			}).Error("保存 webhook 投递结果失败", err)
+			continue
+		}
+		if result.Status == DeliveryDead {
+			global.Logger.WithFields(logger.Fields{
+				"delivery_id":     result.ID,
+				"subscription_id": result.SubscriptionID,
+				"event_id":        result.EventID,
+				"attempts":        result.Attempts,
+			}).Warning("webhook 投递进入死信列表", result.LastError)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// send 发送一次请求，接收方返回 2xx 时视为成功
+func (d *Dispatcher) send(ctx context.Context, sub Subscription, delivery Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("接收方返回 %d: %s", resp.StatusCode, respBody)
+	}
+	return resp.StatusCode, nil
+}
+
+// settle 根据本次尝试的结果更新投递：成功、等待重试或者进入死信列表
+func (d *Dispatcher) settle(delivery Delivery, statusCode int, err error, now time.Time) Delivery {
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.UpdatedAt = now
+	if err == nil {
+		delivery.Status = DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+
+	// 接收方的响应在读取时可能被截断在多字节字符中间，先去掉不完整的字符
+	delivery.LastError = truncate(strings.ToValidUTF8(err.Error(), ""), maxErrorLength)
+	if delivery.Attempts >= d.options.MaxAttempts {
+		delivery.Status = DeliveryDead
+		return delivery
+	}
+	delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts, d.options.InitialBackoff, d.options.MaxBackoff))
+	return delivery
+}
+
+// backoff 第 attempts 次失败后的等待时间，从 initial 开始每次翻倍，最多为 max
+func backoff(attempts int, initial time.Duration, max time.Duration) time.Duration {
+	wait := initial
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		return max
+	}
+	return wait
+}
+
+// truncate 截断到最多 n 个字节，不会截断在多字节字符的中间
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
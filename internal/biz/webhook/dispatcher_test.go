@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"configuration-management/global"
+	"configuration-management/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOutbox struct {
+	mu         sync.Mutex
+	deliveries map[int64]Delivery
+	subs       map[string]Subscription
+	failSave   map[int64]bool // 保存这些投递的结果时返回错误
+}
+
+func (f *fakeOutbox) ClaimDue(now time.Time, limit int, lease time.Duration) ([]Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	due := make([]Delivery, 0)
+	for id, d := range f.deliveries {
+		if d.Status != DeliveryPending || d.NextAttemptAt.After(now) || len(due) == limit {
+			continue
+		}
+		due = append(due, d)
+		d.NextAttemptAt = now.Add(lease)
+		f.deliveries[id] = d
+	}
+	return due, nil
+}
+
+func (f *fakeOutbox) GetSubscriptionsByIDs(ids []string) (map[string]Subscription, error) {
+	result := make(map[string]Subscription)
+	for _, id := range ids {
+		if sub, ok := f.subs[id]; ok {
+			result[id] = sub
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeOutbox) SaveAttempt(delivery Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failSave[delivery.ID] {
+		return errors.New("Incorrect string value")
+	}
+	f.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// receiver 本地的 webhook 接收方，校验签名并记录收到的事件
+type receiver struct {
+	mu     sync.Mutex
+	secret string
+	status int
+	events []Event
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	if !Verify(r.secret, req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil || event.Type != req.Header.Get(HeaderEvent) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+		return
+	}
+	r.events = append(r.events, event)
+}
+
+func newTestDispatcher(t *testing.T, url string, secret string) (*Dispatcher, *fakeOutbox) {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+
+	payload, err := json.Marshal(Event{ID: "evt-1", Type: EventCardActivated, AppID: "app-1", Data: map[string]string{"value": "ABC"}})
+	assert.NoError(t, err)
+	outbox := &fakeOutbox{
+		subs: map[string]Subscription{
+			"sub-1": {ID: "sub-1", AppID: "app-1", URL: url, Secret: secret, Events: EventCardActivated, Enabled: true},
+		},
+		deliveries: map[int64]Delivery{
+			1: {ID: 1, SubscriptionID: "sub-1", EventID: "evt-1", EventType: EventCardActivated, Payload: string(payload), Status: DeliveryPending},
+		},
+	}
+	return NewDispatcher(outbox, Options{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, Timeout: time.Second}), outbox
+}
+
+func TestDispatchDueDeliversSignedEvent(t *testing.T) {
+	recv := &receiver{secret: "whsec_test"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	dispatcher, outbox := newTestDispatcher(t, server.URL, "whsec_test")
+	now := time.Now()
+	n, err := dispatcher.DispatchDue(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Len(t, recv.events, 1)
+	assert.Equal(t, "evt-1", recv.events[0].ID)
+	delivery := outbox.deliveries[1]
+	assert.Equal(t, DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.LastStatusCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// 成功后不会再次投递
+	n, err = dispatcher.DispatchDue(context.Background(), now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestDispatchDueRetriesThenDeadLetters(t *testing.T) {
+	recv := &receiver{secret: "whsec_test", status: http.StatusInternalServerError}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	dispatcher, outbox := newTestDispatcher(t, server.URL, "whsec_test")
+	now := time.Now()
+	_, err := dispatcher.DispatchDue(context.Background(), now)
+	assert.NoError(t, err)
+
+	delivery := outbox.deliveries[1]
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	assert.Contains(t, delivery.LastError, "500")
+	assert.True(t, delivery.NextAttemptAt.After(now.Add(59*time.Second)))
+
+	// 还没有到下次尝试时间
+	n, err := dispatcher.DispatchDue(context.Background(), now.Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	for i := 0; i < 2; i++ {
+		_, err = dispatcher.DispatchDue(context.Background(), outbox.deliveries[1].NextAttemptAt)
+		assert.NoError(t, err)
+	}
+	delivery = outbox.deliveries[1]
+	assert.Equal(t, DeliveryDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, recv.events)
+}
+
+func TestDispatchDueRejectsWrongSecret(t *testing.T) {
+	recv := &receiver{secret: "whsec_receiver"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	dispatcher, outbox := newTestDispatcher(t, server.URL, "whsec_other")
+	_, err := dispatcher.DispatchDue(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, outbox.deliveries[1].LastStatusCode)
+	assert.Empty(t, recv.events)
+}
+
+func TestDispatchDueContinuesAfterSaveFailure(t *testing.T) {
+	recv := &receiver{secret: "whsec_test"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	dispatcher, outbox := newTestDispatcher(t, server.URL, "whsec_test")
+	second := outbox.deliveries[1]
+	second.ID = 2
+	outbox.deliveries[2] = second
+	outbox.failSave = map[int64]bool{1: true}
+
+	n, err := dispatcher.DispatchDue(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, DeliveryPending, outbox.deliveries[1].Status)
+	assert.Equal(t, DeliverySucceeded, outbox.deliveries[2].Status)
+}
+
+func TestSettleKeepsErrorValidUTF8(t *testing.T) {
+	dispatcher := NewDispatcher(&fakeOutbox{}, Options{})
+	// 截断在多字节字符中间的响应
+	body := strings.Repeat("错误", 200)[:1023]
+	delivery := dispatcher.settle(Delivery{}, http.StatusInternalServerError, errors.New("接收方返回 500: "+body), time.Now())
+	assert.True(t, utf8.ValidString(delivery.LastError))
+	assert.LessOrEqual(t, len(delivery.LastError), maxErrorLength)
+	assert.True(t, strings.HasPrefix(delivery.LastError, "接收方返回 500: 错误"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 5))
+	assert.Equal(t, "ab", truncate("abc", 2))
+	// "错" 占 3 个字节，不能截断在中间
+	assert.Equal(t, "a", truncate("a错", 3))
+	assert.Equal(t, "a错", truncate("a错", 4))
+	assert.Equal(t, "", truncate("错", 2))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1, 30*time.Second, time.Hour))
+	assert.Equal(t, 60*time.Second, backoff(2, 30*time.Second, time.Hour))
+	assert.Equal(t, 4*time.Minute, backoff(4, 30*time.Second, time.Hour))
+	assert.Equal(t, time.Hour, backoff(20, 30*time.Second, time.Hour))
+}
+
+func TestSubscribes(t *testing.T) {
+	sub := Subscription{Events: EventCardActivated + "," + EventCardLocked}
+	assert.True(t, sub.Subscribes(EventCardLocked))
+	assert.False(t, sub.Subscribes(EventCardDeleted))
+	assert.False(t, sub.Subscribes("card"))
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+)
+
+// Subscription 应用的 webhook 订阅
+type Subscription struct {
+	ID        string    `json:"id"`
+	AppID     string    `json:"app_id"`     // 应用ID，只接收该应用激活码的事件
+	URL       string    `json:"url"`        // 接收地址
+	Secret    string    `json:"-"`          // 签名密钥，只在创建和重置时返回
+	Events    string    `json:"events"`     // 订阅的事件类型，逗号分隔
+	Enabled   bool      `json:"enabled"`    // 停用后不再产生新的投递
+	CreatorID string    `json:"creator_id"` // 创建人
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *Subscription) TableName() string {
+	return "webhook_subscription"
+}
+
+// Subscribes 检查是否订阅了某个事件类型
+func (s *Subscription) Subscribes(eventType string) bool {
+	for _, t := range strings.Split(s.Events, ",") {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery 发件箱中的一次投递，事件和激活码的变化在同一个事务中写入
+type Delivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID string     `json:"subscription_id"`
+	EventID        string     `json:"event_id"`         // 事件ID，同一个订阅的同一个事件只投递一次
+	EventType      string     `json:"event_type"`       // 事件类型
+	Payload        string     `json:"payload"`          // 请求体，JSON 格式
+	Status         string     `json:"status"`           // pending, succeeded, dead
+	Attempts       int        `json:"attempts"`         // 已尝试次数
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`  // 下次尝试时间
+	LastStatusCode int        `json:"last_status_code"` // 最后一次尝试时接收方返回的状态码
+	LastError      string     `json:"last_error"`       // 最后一次尝试的错误信息
+	DeliveredAt    *time.Time `json:"delivered_at"`     // 投递成功的时间
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (d *Delivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// Event 投递给接收方的事件
+type Event struct {
+	ID         string    `json:"id"`          // 事件ID，接收方可以用来去重
+	Type       string    `json:"type"`        // 事件类型
+	AppID      string    `json:"app_id"`      // 应用ID
+	OccurredAt time.Time `json:"occurred_at"` // 发生时间
+	Data       any       `json:"data"`        // 事件内容，激活码事件为激活码信息
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import "time"
+
+type QuerySubscriptionsArgs struct {
+	AppID string `json:"app_id"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+}
+
+type QuerySubscriptionsResult struct {
+	List  []Subscription
+	Total int
+}
+
+type QueryDeliveriesArgs struct {
+	SubscriptionID string `json:"subscription_id"`
+	EventType      string `json:"event_type"`
+	Status         string `json:"status"` // dead 为死信列表
+	Page           int    `json:"page"`
+	Limit          int    `json:"limit"`
+}
+
+type QueryDeliveriesResult struct {
+	List  []Delivery
+	Total int
+}
+
+// Outbox 投递时需要的发件箱操作
+type Outbox interface {
+	// ClaimDue 领取到期的投递，并把下次尝试时间推迟 lease，避免多个实例重复投递
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]Delivery, error)
+	GetSubscriptionsByIDs(ids []string) (map[string]Subscription, error)
+	// SaveAttempt 保存一次尝试的结果
+	SaveAttempt(delivery Delivery) error
+}
+
+type Repository interface {
+	Outbox
+
+	// Enqueue 为订阅了事件的订阅创建投递，传入事务时和激活码的变化一起提交
+	// 同一个订阅的同一个事件只会创建一次投递
+	Enqueue(events []Event) error
+
+	CreateSubscription(sub Subscription) error
+	GetSubscriptionByID(id string) (Subscription, error)
+	UpdateSubscription(sub Subscription) error
+	DeleteSubscription(id string) error
+	QuerySubscriptions(args QuerySubscriptionsArgs) (QuerySubscriptionsResult, error)
+
+	GetDeliveryByID(id int64) (Delivery, error)
+	QueryDeliveries(args QueryDeliveriesArgs) (QueryDeliveriesResult, error)
+	// Redeliver 把投递重置为等待投递，重新计算尝试次数
+	Redeliver(id int64, now time.Time) error
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+表结构如下：
+CREATE TABLE webhook_subscription (
+    id         VARCHAR(36)  NOT NULL PRIMARY KEY,
+    app_id     VARCHAR(36)  NOT NULL,            -- 应用ID
+    url        VARCHAR(512) NOT NULL,            -- 接收地址
+    secret     VARCHAR(128) NOT NULL,            -- 签名密钥
+    events     VARCHAR(255) NOT NULL,            -- 订阅的事件类型，逗号分隔
+    enabled    BOOLEAN      NOT NULL DEFAULT 1,  -- 是否启用
+    creator_id VARCHAR(32)  NOT NULL,            -- 创建人
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_delivery (
+    id               BIGINT AUTO_INCREMENT PRIMARY KEY,
+    subscription_id  VARCHAR(36)  NOT NULL,           -- 订阅ID
+    event_id         VARCHAR(128) NOT NULL,           -- 事件ID
+    event_type       VARCHAR(32)  NOT NULL,           -- 事件类型
+    payload          TEXT         NOT NULL,           -- 请求体
+    status           VARCHAR(16)  NOT NULL,           -- pending, succeeded, dead
+    attempts         INT          NOT NULL DEFAULT 0, -- 已尝试次数
+    next_attempt_at  TIMESTAMP    NOT NULL,           -- 下次尝试时间
+    last_status_code INT          NOT NULL DEFAULT 0, -- 最后一次的响应状态码
+    last_error       VARCHAR(255) NOT NULL DEFAULT '', -- 最后一次的错误信息
+    delivered_at     TIMESTAMP    NULL,               -- 投递成功的时间
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, event_id),
+    INDEX idx_webhook_delivery_due (status, next_attempt_at)
+);
+*/
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Enqueue(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	appIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if !seen[event.AppID] {
+			seen[event.AppID] = true
+			appIDs = append(appIDs, event.AppID)
+		}
+	}
+	subs := make([]Subscription, 0)
+	if err := r.db.Where("app_id IN (?) AND enabled = ?", appIDs, true).Find(&subs).Error; err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	deliveries := make([]Delivery, 0)
+	for _, event := range events {
+		var payload []byte
+		for _, sub := range subs {
+			if sub.AppID != event.AppID || !sub.Subscribes(event.Type) {
+				continue
+			}
+			if payload == nil {
+				var err error
+				if payload, err = json.Marshal(event); err != nil {
+					return err
+				}
+			}
+			deliveries = append(deliveries, Delivery{
+				SubscriptionID: sub.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        string(payload),
+				Status:         DeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(deliveries, 500).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"event_type": events[0].Type,
+			"count":      len(deliveries),
+		}).Error("写入 webhook 发件箱失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+			Order("next_attempt_at").Limit(limit).Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		ids := make([]int64, 0, len(deliveries))
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+		}
+		return tx.Model(&Delivery{}).Where("id IN (?)", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		global.Logger.Error("领取 webhook 投递失败", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *repository) GetSubscriptionsByIDs(ids []string) (map[string]Subscription, error) {
+	result := make(map[string]Subscription, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	subs := make([]Subscription, 0)
+	if err := r.db.Where("id IN (?)", ids).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		result[sub.ID] = sub
+	}
+	return result, nil
+}
+
+func (r *repository) SaveAttempt(delivery Delivery) error {
+	if err := r.db.Model(&Delivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"delivered_at":     delivery.DeliveredAt,
+		"updated_at":       delivery.UpdatedAt,
+	}).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"delivery_id": delivery.ID,
+		}).Error("保存 webhook 投递结果失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) CreateSubscription(sub Subscription) error {
+	if err := r.db.Create(&sub).Error; err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"subscription": sub,
+		}).Error("创建 webhook 订阅失败", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) GetSubscriptionByID(id string) (Subscription, error) {
+	var sub Subscription
+	if err := r.db.Where("id = ?", id).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Subscription{}, errcode.NotFound.WithDetails("webhook 订阅不存在")
+		}
+		return Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (r *repository) UpdateSubscription(sub Subscription) error {
+	return r.db.Model(&Subscription{}).Where("id = ?", sub.ID).Updates(map[string]interface{}{
+		"url":        sub.URL,
+		"secret":     sub.Secret,
+		"events":     sub.Events,
+		"enabled":    sub.Enabled,
+		"updated_at": sub.UpdatedAt,
+	}).Error
+}
+
+func (r *repository) DeleteSubscription(id string) error {
+	return r.db.Where("id = ?", id).Delete(&Subscription{}).Error
+}
+
+func (r *repository) QuerySubscriptions(args QuerySubscriptionsArgs) (QuerySubscriptionsResult, error) {
+	db := r.db.Model(&Subscription{})
+	if args.AppID != "" {
+		db = db.Where("app_id = ?", args.AppID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QuerySubscriptionsResult{}, err
+	}
+	subs := make([]Subscription, 0)
+	if err := db.Order("created_at desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&subs).Error; err != nil {
+		return QuerySubscriptionsResult{}, err
+	}
+	return QuerySubscriptionsResult{List: subs, Total: int(total)}, nil
+}
+
+func (r *repository) GetDeliveryByID(id int64) (Delivery, error) {
+	var delivery Delivery
+	if err := r.db.Where("id = ?", id).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Delivery{}, errcode.NotFound.WithDetails("webhook 投递不存在")
+		}
+		return Delivery{}, err
+	}
+	return delivery, nil
+}
+
+func (r *repository) QueryDeliveries(args QueryDeliveriesArgs) (QueryDeliveriesResult, error) {
+	db := r.db.Model(&Delivery{})
+	if args.SubscriptionID != "" {
+		db = db.Where("subscription_id = ?", args.SubscriptionID)
+	}
+	if args.EventType != "" {
+		db = db.Where("event_type = ?", args.EventType)
+	}
+	if args.Status != "" {
+		db = db.Where("status = ?", args.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return QueryDeliveriesResult{}, err
+	}
+	deliveries := make([]Delivery, 0)
+	if err := db.Order("id desc").Offset((args.Page - 1) * args.Limit).Limit(args.Limit).Find(&deliveries).Error; err != nil {
+		return QueryDeliveriesResult{}, err
+	}
+	return QueryDeliveriesResult{List: deliveries, Total: int(total)}, nil
+}
+
+func (r *repository) Redeliver(id int64, now time.Time) error {
+	return r.db.Model(&Delivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"updated_at":      now,
+	}).Error
+}
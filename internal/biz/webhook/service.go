@@ -0,0 +1,32 @@
+package webhook
+
+import "configuration-management/pkg/app"
+
+type CreateSubscriptionArgs struct {
+	AppID  string    `json:"app_id"`
+	URL    string    `json:"url"`
+	Events []string  `json:"events"`
+	Actor  app.Actor `json:"-"` // 操作人，写入审计日志
+}
+
+type UpdateSubscriptionArgs struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`
+	Events  []string  `json:"events"`
+	Enabled bool      `json:"enabled"`
+	Actor   app.Actor `json:"-"`
+}
+
+type Service interface {
+	QuerySubscriptions(args QuerySubscriptionsArgs) (QuerySubscriptionsResult, error)
+	// CreateSubscription 返回订阅和签名密钥，密钥只在这里返回一次
+	CreateSubscription(args CreateSubscriptionArgs) (Subscription, string, error)
+	UpdateSubscription(args UpdateSubscriptionArgs) error
+	// RotateSecret 重新生成签名密钥，返回新的密钥
+	RotateSecret(id string, actor app.Actor) (string, error)
+	DeleteSubscription(id string, actor app.Actor) error
+
+	QueryDeliveries(args QueryDeliveriesArgs) (QueryDeliveriesResult, error)
+	// Redeliver 手动重新投递，通常用于死信列表中的投递
+	Redeliver(id int64, actor app.Actor) error
+}
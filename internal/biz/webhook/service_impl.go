@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/utils"
+
+	"gorm.io/gorm"
+)
+
+type service struct {
+	db      *gorm.DB
+	repo    Repository
+	appRepo apps.Repository
+}
+
+func NewService() Service {
+	return &service{
+		db:      global.DBEngine,
+		repo:    NewRepository(global.DBEngine),
+		appRepo: apps.NewRepository(global.DBEngine),
+	}
+}
+
+func (s *service) QuerySubscriptions(args QuerySubscriptionsArgs) (QuerySubscriptionsResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QuerySubscriptions(args)
+}
+
+func (s *service) CreateSubscription(args CreateSubscriptionArgs) (Subscription, string, error) {
+	if err := checkURL(args.URL); err != nil {
+		return Subscription{}, "", err
+	}
+	events, err := joinEvents(args.Events)
+	if err != nil {
+		return Subscription{}, "", err
+	}
+	result, err := s.appRepo.QueryAppList(apps.QueryAppListArgs{ID: args.AppID})
+	if err != nil {
+		return Subscription{}, "", err
+	}
+	if result.Total == 0 {
+		return Subscription{}, "", errcode.NotFound.WithDetails("应用不存在")
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return Subscription{}, "", err
+	}
+
+	now := time.Now()
+	sub := Subscription{
+		ID:        utils.GenerateUUID(),
+		AppID:     args.AppID,
+		URL:       args.URL,
+		Secret:    secret,
+		Events:    events,
+		Enabled:   true,
+		CreatorID: args.Actor.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewRepository(tx).CreateSubscription(sub); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionWebhookCreate, audit.TargetWebhook, sub.ID, nil, sub)
+	})
+	if err != nil {
+		return Subscription{}, "", err
+	}
+	return sub, secret, nil
+}
+
+func (s *service) UpdateSubscription(args UpdateSubscriptionArgs) error {
+	if err := checkURL(args.URL); err != nil {
+		return err
+	}
+	events, err := joinEvents(args.Events)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := repo.GetSubscriptionByID(args.ID)
+		if err != nil {
+			return err
+		}
+		after := before
+		after.URL = args.URL
+		after.Events = events
+		after.Enabled = args.Enabled
+		after.UpdatedAt = time.Now()
+		if err := repo.UpdateSubscription(after); err != nil {
+			return err
+		}
+		return audit.Record(tx, args.Actor, audit.ActionWebhookUpdate, audit.TargetWebhook, args.ID, before, after)
+	})
+}
+
+func (s *service) RotateSecret(id string, actor app.Actor) (string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		sub, err := repo.GetSubscriptionByID(id)
+		if err != nil {
+			return err
+		}
+		sub.Secret = secret
+		sub.UpdatedAt = time.Now()
+		if err := repo.UpdateSubscription(sub); err != nil {
+			return err
+		}
+		// 密钥不写入审计日志，只记录发生了重置
+		return audit.Record(tx, actor, audit.ActionWebhookRotateSecret, audit.TargetWebhook, id, nil, nil)
+	})
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+func (s *service) DeleteSubscription(id string, actor app.Actor) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := repo.GetSubscriptionByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteSubscription(id); err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.ActionWebhookDelete, audit.TargetWebhook, id, before, nil)
+	})
+}
+
+func (s *service) QueryDeliveries(args QueryDeliveriesArgs) (QueryDeliveriesResult, error) {
+	if args.Page <= 0 {
+		args.Page = 1
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+	return s.repo.QueryDeliveries(args)
+}
+
+func (s *service) Redeliver(id int64, actor app.Actor) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx)
+		before, err := repo.GetDeliveryByID(id)
+		if err != nil {
+			return err
+		}
+		if before.Status == DeliveryPending {
+			return errcode.InvalidParams.WithDetails("投递正在等待发送")
+		}
+		if _, err := repo.GetSubscriptionByID(before.SubscriptionID); err != nil {
+			return err
+		}
+		if err := repo.Redeliver(id, time.Now()); err != nil {
+			return err
+		}
+		after, err := repo.GetDeliveryByID(id)
+		if err != nil {
+			return err
+		}
+		// 请求体可能很大，审计日志只记录投递状态
+		before.Payload, after.Payload = "", ""
+		return audit.Record(tx, actor, audit.ActionWebhookRedeliver, audit.TargetWebhookDelivery, strconv.FormatInt(id, 10), before, after)
+	})
+}
+
+// checkURL 接收地址只能是 http 或 https
+func checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errcode.InvalidParams.WithDetails("url 必须是 http 或 https 地址")
+	}
+	return nil
+}
+
+// joinEvents 校验并去重订阅的事件类型
+func joinEvents(events []string) (string, error) {
+	if len(events) == 0 {
+		return "", errcode.InvalidParams.WithDetails("至少订阅一个事件")
+	}
+	list := make([]string, 0, len(events))
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if !IsEventType(event) {
+			return "", errcode.InvalidParams.WithDetails("不支持的事件类型: " + event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			list = append(list, event)
+		}
+	}
+	return strings.Join(list, ","), nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
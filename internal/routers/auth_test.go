@@ -0,0 +1,74 @@
+package routers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"configuration-management/global"
+	sessionbiz "configuration-management/internal/biz/session"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeSessionService struct {
+	sessionbiz.Service
+	revoked map[string]bool
+}
+
+func (f *fakeSessionService) Validate(id string) error {
+	if f.revoked[id] {
+		return errors.New("会话已经失效")
+	}
+	return nil
+}
+
+func TestAuthMiddlewareRejectsRevokedSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+
+	sessions := &fakeSessionService{revoked: map[string]bool{"revoked": true}}
+	r := gin.New()
+	r.GET("/private/v1/ping", authMiddleware(nil, sessions), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	newToken := func(sessionID string) string {
+		token, err := app.CreateToken(app.UserInfo{
+			UserId:    "u1",
+			Username:  "u1",
+			Roles:     []string{},
+			SessionID: sessionID,
+		})
+		if err != nil {
+			t.Fatalf("CreateToken() error = %v", err)
+		}
+		return token
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"有效会话", newToken("active"), http.StatusOK},
+		{"已吊销的会话", newToken("revoked"), http.StatusUnauthorized},
+		{"没有 sid 的旧 token", newToken(""), http.StatusUnauthorized},
+		{"没有 token", "", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/private/v1/ping", nil)
+		if c.token != "" {
+			req.Header.Set("V-Token", c.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("%s: status = %d, want %d", c.name, w.Code, c.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package routers
+
+import (
+	"configuration-management/internal/biz/permissions"
+)
+
+// routePermissions 路由与所需权限的映射，key 为 "METHOD 路由模板"
+// 每个私有路由都必须在这里声明，权限为空表示只要求登录，没有声明的私有路由拒绝访问
+var routePermissions = map[string][]string{
+	// Configuration
+	"GET /private/v1/configuration":                     {permissions.CONFIG_MANAGE},
+	"POST /private/v1/configuration":                    {permissions.CONFIG_MANAGE},
+	"GET /private/v1/remote-config/keys":                {permissions.CONFIG_MANAGE},
+	"POST /private/v1/remote-config/key":                {permissions.CONFIG_MANAGE},
+	"PUT /private/v1/remote-config/key":                 {permissions.CONFIG_MANAGE},
+	"DELETE /private/v1/remote-config/key/:id":          {permissions.CONFIG_MANAGE},
+	"GET /private/v1/remote-config/values":              {permissions.CONFIG_MANAGE},
+	"PUT /private/v1/remote-config/value":               {permissions.CONFIG_MANAGE},
+	"DELETE /private/v1/remote-config/value/:id":        {permissions.CONFIG_MANAGE},
+	"GET /private/v1/remote-config/value/:id/history":   {permissions.CONFIG_MANAGE},
+	"POST /private/v1/remote-config/value/:id/rollback": {permissions.CONFIG_MANAGE},
+
+	// Card
+	"GET /private/v1/card/:value":                  {permissions.QUERY},
+	"GET /private/v1/card/:value/history":          {permissions.QUERY},
+	"GET /private/v1/card/:value/qrcode":           {permissions.QUERY},
+	"GET /private/v1/cards":                        {permissions.QUERY},
+	"GET /private/v1/export-cards":                 {permissions.QUERY},
+	"POST /private/v1/card-export-jobs":            {permissions.QUERY},
+	"GET /private/v1/card-export-jobs":             {permissions.QUERY},
+	"GET /private/v1/card-export-job/:id":          {permissions.QUERY},
+	"GET /private/v1/card-export-job/:id/download": {permissions.QUERY},
+	"GET /private/v1/card-sheet":                   {permissions.QUERY},
+	"GET /private/v1/card-sheet-layouts":           {permissions.QUERY},
+	"GET /private/v1/batch-query":                  {permissions.QUERY},
+	"GET /private/v1/get-card-count-by-status":     {permissions.QUERY},
+	"POST /private/v1/card":                        {permissions.CREATE},
+	"POST /private/v1/cards":                       {permissions.CREATE},
+	"POST /private/v1/cards/import":                {permissions.CREATE},
+	"PUT /private/v1/card":                         {permissions.UPDATE},
+	"PUT /private/v1/set-expired-at":               {permissions.UPDATE},
+	"PUT /private/v1/batch-update-card-status":     {permissions.UPDATE},
+	"DELETE /private/v1/card/:value":               {permissions.DELETE},
+	"DELETE /private/v1/cards":                     {permissions.DELETE},
+
+	// App
+	"GET /private/v1/apps":             {},
+	"GET /private/v1/app-options":      {},
+	"POST /private/v1/app":             {permissions.APP_MANAGE},
+	"PUT /private/v1/app":              {permissions.APP_MANAGE},
+	"PUT /private/v1/app/:id/settings": {permissions.APP_MANAGE},
+	"PUT /private/v1/app/:id/archive":  {permissions.APP_MANAGE},
+	"DELETE /private/v1/app/:id":       {permissions.APP_MANAGE},
+
+	// App Version
+	"GET /private/v1/app-versions":         {},
+	"POST /private/v1/app-version":         {permissions.APP_MANAGE},
+	"DELETE /private/v1/app-version/:id":   {permissions.APP_MANAGE},
+	"GET /private/v1/app-versions/devices": {permissions.QUERY},
+
+	// User
+	"GET /private/v1/get-user-info":      {},
+	"GET /private/v1/me":                 {},
+	"PUT /private/v1/me":                 {},
+	"PUT /private/v1/me/password":        {},
+	"GET /private/v1/me/sessions":        {},
+	"DELETE /private/v1/me/sessions/:id": {},
+	"GET /private/v1/quota-ledger":       {},
+	"GET /private/v1/user-app-grants":    {},
+	"GET /private/v1/users":              {permissions.USER_MANAGE},
+	"POST /private/v1/user":              {permissions.USER_MANAGE},
+	"PUT /private/v1/user":               {permissions.USER_MANAGE},
+	"DELETE /private/v1/user/:id":        {permissions.USER_MANAGE},
+	"POST /private/v1/disable-users":     {permissions.USER_MANAGE},
+	"POST /private/v1/reset-password":    {permissions.USER_MANAGE},
+	"POST /private/v1/user-quota":        {permissions.USER_MANAGE},
+	"PUT /private/v1/user-app-grant":     {permissions.USER_MANAGE},
+	"DELETE /private/v1/user-app-grant":  {permissions.USER_MANAGE},
+	"POST /private/v1/service-account":   {permissions.USER_MANAGE},
+
+	// API Key
+	"GET /private/v1/api-keys":       {permissions.API_KEY_MANAGE},
+	"POST /private/v1/api-key":       {permissions.API_KEY_MANAGE},
+	"DELETE /private/v1/api-key/:id": {permissions.API_KEY_MANAGE},
+
+	// Role
+	"GET /private/v1/roles":       {permissions.ROLE_MANAGE},
+	"POST /private/v1/role":       {permissions.ROLE_MANAGE},
+	"PUT /private/v1/role":        {permissions.ROLE_MANAGE},
+	"DELETE /private/v1/role/:id": {permissions.ROLE_MANAGE},
+	"PUT /private/v1/user-roles":  {permissions.ROLE_MANAGE},
+
+	// Audit
+	"GET /private/v1/audit-logs":        {permissions.AUDIT_VIEW},
+	"GET /private/v1/audit-logs/export": {permissions.AUDIT_VIEW},
+
+	// Activation Attempt
+	"GET /private/v1/activation-attempts": {permissions.ACTIVATION_VIEW},
+
+	// Analytics
+	"GET /private/v1/analytics/activations": {permissions.QUERY},
+	"GET /private/v1/analytics/conversion":  {permissions.QUERY},
+	"GET /private/v1/analytics/churn":       {permissions.QUERY},
+
+	// Abuse
+	"GET /private/v1/abuse-flags":     {permissions.ABUSE_MANAGE},
+	"PUT /private/v1/abuse-flag":      {permissions.ABUSE_MANAGE},
+	"GET /private/v1/ip-blocks":       {permissions.ABUSE_MANAGE},
+	"DELETE /private/v1/ip-block/:ip": {permissions.ABUSE_MANAGE},
+
+	// Notification
+	"GET /private/v1/notifications":              {},
+	"GET /private/v1/notifications/unread-count": {},
+	"PUT /private/v1/notifications/read":         {},
+	"GET /private/v1/notifications/stream":       {},
+	"GET /private/v1/notifications/preferences":  {},
+	"PUT /private/v1/notifications/preferences":  {},
+
+	// Webhook
+	"GET /private/v1/webhooks":                               {permissions.WEBHOOK_MANAGE},
+	"POST /private/v1/webhook":                               {permissions.WEBHOOK_MANAGE},
+	"PUT /private/v1/webhook":                                {permissions.WEBHOOK_MANAGE},
+	"DELETE /private/v1/webhook/:id":                         {permissions.WEBHOOK_MANAGE},
+	"POST /private/v1/webhook/:id/rotate-secret":             {permissions.WEBHOOK_MANAGE},
+	"GET /private/v1/webhook-deliveries":                     {permissions.WEBHOOK_MANAGE},
+	"POST /private/v1/webhook-delivery/:id/redeliver":        {permissions.WEBHOOK_MANAGE},
+	"GET /private/v1/notification-templates":                 {permissions.NOTIFICATION_MANAGE},
+	"PUT /private/v1/notification-template":                  {permissions.NOTIFICATION_MANAGE},
+	"DELETE /private/v1/notification-template/:type/:locale": {permissions.NOTIFICATION_MANAGE},
+}
@@ -0,0 +1,42 @@
+package routers
+
+import (
+	"strings"
+	"testing"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/permissions"
+	"configuration-management/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEveryPrivateRouteDeclaresPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	global.ServerSetting = &setting.ServerSettingS{}
+	r := NewRouter()
+
+	registered := make(map[string]struct{})
+	for _, route := range r.Routes() {
+		if !strings.HasPrefix(route.Path, "/private/") {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = struct{}{}
+		if _, ok := routePermissions[key]; !ok {
+			t.Errorf("private route %s is not declared in routePermissions", key)
+		}
+	}
+
+	// 映射中不能有已经删除的路由或不存在的权限
+	for key, required := range routePermissions {
+		if _, ok := registered[key]; !ok {
+			t.Errorf("routePermissions declares unknown route %s", key)
+		}
+		for _, p := range required {
+			if !permissions.Contains(permissions.RootPermissions, p) {
+				t.Errorf("route %s requires unknown permission %s", key, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package abuse
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetIPBlocks 获取所有封禁中的IP
+func (handler *Handler) GetIPBlocks(c *gin.Context) {
+	blocks, err := handler.AbuseService.GetActiveIPBlocks()
+	if err != nil {
+		global.Logger.Error("get ip blocks failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(blocks, len(blocks))
+}
@@ -0,0 +1,15 @@
+package abuse
+
+import (
+	"configuration-management/internal/biz/abuse"
+)
+
+type Handler struct {
+	AbuseService abuse.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		AbuseService: abuse.NewService(),
+	}
+}
@@ -0,0 +1,50 @@
+package abuse
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryAbuseFlagsRequest struct {
+	Status      string `form:"status" binding:"omitempty,oneof=open confirmed dismissed"`
+	Rule        string `form:"rule"`
+	SubjectType string `form:"subject_type" binding:"omitempty,oneof=card seid ip"`
+	Subject     string `form:"subject"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit" binding:"max=100"`
+}
+
+// QueryAbuseFlags 分页查询滥用检测产生的标记，按时间倒序
+func (handler *Handler) QueryAbuseFlags(c *gin.Context) {
+	var req QueryAbuseFlagsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.AbuseService.QueryFlags(abuse.QueryFlagsArgs{
+		Status:      req.Status,
+		Rule:        req.Rule,
+		SubjectType: req.SubjectType,
+		Subject:     req.Subject,
+		Page:        req.Page,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query abuse flags failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
@@ -0,0 +1,55 @@
+package abuse
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewAbuseFlagRequest struct {
+	ID     int64  `json:"id" binding:"required"`
+	Status string `json:"status" binding:"required,oneof=confirmed dismissed"`
+	Note   string `json:"note" binding:"max=255"`
+}
+
+// ReviewAbuseFlag 审核标记，确认为滥用或者标记为误报
+func (handler *Handler) ReviewAbuseFlag(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req ReviewAbuseFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.AbuseService.ReviewFlag(abuse.ReviewFlagArgs{
+		ID:     req.ID,
+		Status: req.Status,
+		Note:   req.Note,
+		Actor:  app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("review abuse flag failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,39 @@
+package abuse
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnblockIP 解除IP封禁，立即生效
+func (handler *Handler) UnblockIP(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	ip := c.Param("ip")
+	if ip == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("ip", ip))
+		return
+	}
+
+	if err := handler.AbuseService.UnblockIP(ip, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"ip":     ip,
+			"userId": userInfo.UserId,
+		}).Error("unblock ip failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
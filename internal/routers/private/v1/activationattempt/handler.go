@@ -0,0 +1,15 @@
+package activationattempt
+
+import (
+	"configuration-management/internal/biz/activationattempt"
+)
+
+type Handler struct {
+	ActivationAttemptService activationattempt.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		ActivationAttemptService: activationattempt.NewService(),
+	}
+}
@@ -0,0 +1,62 @@
+package activationattempt
+
+import (
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/activationattempt"
+	"configuration-management/internal/biz/common"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryActivationAttemptsRequest struct {
+	CardValue          string       `form:"card_value"`
+	IP                 string       `form:"ip"`
+	SEID               string       `form:"seid"`
+	AppID              string       `form:"app_id"`
+	Country            string       `form:"country"`
+	Success            *bool        `form:"success"`
+	CreatedAtDateRange [2]time.Time `form:"created_at_date_range[]"`
+	Page               int          `form:"page"`
+	Limit              int          `form:"limit" binding:"max=100"`
+}
+
+// QueryActivationAttempts 按激活码、IP、设备、应用、国家等条件分页查询激活记录
+func (handler *Handler) QueryActivationAttempts(c *gin.Context) {
+	var req QueryActivationAttemptsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.ActivationAttemptService.QueryActivationAttempts(activationattempt.QueryActivationAttemptsArgs{
+		CardValue: req.CardValue,
+		IP:        req.IP,
+		SEID:      req.SEID,
+		AppID:     req.AppID,
+		Country:   req.Country,
+		Success:   req.Success,
+		CreatedAtDateRange: common.TimeRange{
+			StartTime: req.CreatedAtDateRange[0],
+			EndTime:   req.CreatedAtDateRange[1],
+		},
+		Page:  req.Page,
+		Limit: req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query activation attempts failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
@@ -0,0 +1,68 @@
+package analytics
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/analytics"
+	"configuration-management/internal/biz/common"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ActivationSeriesRequest struct {
+	Bucket  string       `form:"bucket" binding:"required"`
+	GroupBy string       `form:"group_by"`
+	Range   [2]time.Time `form:"range[]"`
+}
+
+// ActivationSeries 按小时、天或月统计激活数量，可以按应用、代理、时间类型或批次分组
+func (handler *Handler) ActivationSeries(c *gin.Context) {
+	var req ActivationSeriesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	userInfo := app.GetUserInfoFromContext(c)
+	scope, err := handler.getScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("get analytics scope failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.AnalyticsService.ActivationSeries(analytics.ActivationSeriesArgs{
+		Scope:  scope,
+		Bucket: req.Bucket,
+		Group:  req.GroupBy,
+		Range: common.TimeRange{
+			StartTime: req.Range[0],
+			EndTime:   req.Range[1],
+		},
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("query activation series failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(result)
+}
@@ -0,0 +1,66 @@
+package analytics
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/analytics"
+	"configuration-management/internal/biz/common"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConversionRequest struct {
+	GroupBy string       `form:"group_by"`
+	Range   [2]time.Time `form:"range[]"`
+}
+
+// Conversion 统计生成的激活码中被激活的比例
+func (handler *Handler) Conversion(c *gin.Context) {
+	var req ConversionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	userInfo := app.GetUserInfoFromContext(c)
+	scope, err := handler.getScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("get analytics scope failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.AnalyticsService.Conversion(analytics.ConversionArgs{
+		Scope: scope,
+		Group: req.GroupBy,
+		Range: common.TimeRange{
+			StartTime: req.Range[0],
+			EndTime:   req.Range[1],
+		},
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("query conversion failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(result)
+}
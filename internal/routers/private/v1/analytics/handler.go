@@ -0,0 +1,39 @@
+package analytics
+
+import (
+	"configuration-management/internal/biz/analytics"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+)
+
+type Handler struct {
+	AnalyticsService analytics.Service
+	UserService      user.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		AnalyticsService: analytics.NewService(),
+		UserService:      user.NewService(),
+	}
+}
+
+// getScope 返回当前用户能统计的激活码范围：root 不限制，其他用户为自己和所有下级在有权限应用上的激活码
+func (handler *Handler) getScope(userInfo app.UserInfo) (analytics.Scope, error) {
+	if userInfo.IsRoot() {
+		return analytics.Scope{}, nil
+	}
+	currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
+	if err != nil {
+		return analytics.Scope{}, err
+	}
+	if len(currentUser.Apps) == 0 {
+		return analytics.Scope{}, errcode.NoPermission.WithDetails("没有任何应用的权限")
+	}
+	return analytics.Scope{
+		UserId:      currentUser.ID,
+		SubtreePath: currentUser.SubtreePath(),
+		AppIDs:      currentUser.Apps,
+	}, nil
+}
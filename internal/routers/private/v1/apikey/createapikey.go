@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"errors"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apikey"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAPIKeyRequest struct {
+	UserID      string     `json:"user_id"` // 为空时为自己创建
+	Name        string     `json:"name" binding:"required,max=64"`
+	Permissions []string   `json:"permissions" binding:"required"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKey 创建 API Key，完整的 Key 只在这里返回一次
+func (handler *Handler) CreateAPIKey(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	key, plaintext, err := handler.APIKeyService.CreateAPIKey(apikey.CreateAPIKeyArgs{
+		CreatorID:   userInfo.UserId,
+		UserID:      req.UserID,
+		Name:        req.Name,
+		Permissions: req.Permissions,
+		ExpiresAt:   req.ExpiresAt,
+		ViaAPIKey:   app.IsAPIKeyRequest(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("create api key failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(map[string]any{
+		"api_key": key,
+		"key":     plaintext,
+	})
+}
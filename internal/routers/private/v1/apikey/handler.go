@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"configuration-management/internal/biz/apikey"
+)
+
+type Handler struct {
+	APIKeyService apikey.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		APIKeyService: apikey.NewService(),
+	}
+}
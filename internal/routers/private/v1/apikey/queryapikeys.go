@@ -0,0 +1,57 @@
+package apikey
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apikey"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryAPIKeysRequest struct {
+	UserID         string `form:"user_id"`
+	IncludeRevoked bool   `form:"include_revoked"`
+	Page           int    `form:"page"`
+	Limit          int    `form:"limit"`
+}
+
+// QueryAPIKeys 查询 API Key 列表，不传 user_id 时查询自己的
+func (handler *Handler) QueryAPIKeys(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req QueryAPIKeysRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.APIKeyService.QueryAPIKeys(apikey.QueryAPIKeysArgs{
+		ViewerID:       userInfo.UserId,
+		UserID:         req.UserID,
+		IncludeRevoked: req.IncludeRevoked,
+		Page:           req.Page,
+		Limit:          req.Limit,
+		ViaAPIKey:      app.IsAPIKeyRequest(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query api keys failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
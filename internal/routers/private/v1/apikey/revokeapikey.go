@@ -0,0 +1,44 @@
+package apikey
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apikey"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeAPIKey 吊销 API Key，吊销后立即失效
+func (handler *Handler) RevokeAPIKey(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	id := c.Param("id")
+	if id == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", id))
+		return
+	}
+
+	if err := handler.APIKeyService.RevokeAPIKey(apikey.RevokeAPIKeyArgs{
+		OperatorID: userInfo.UserId,
+		ID:         id,
+		ViaAPIKey:  app.IsAPIKeyRequest(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":     id,
+			"userId": userInfo.UserId,
+		}).Error("revoke api key failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
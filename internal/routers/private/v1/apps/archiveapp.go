@@ -0,0 +1,43 @@
+package apps
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveAppRequest struct {
+	Archived *bool `json:"archived" binding:"required"`
+}
+
+// ArchiveApp 归档或取消归档应用，归档后不能再生成激活码，已有的激活码不受影响
+func (handler *Handler) ArchiveApp(c *gin.Context) {
+	id := c.Param("id")
+	var req ArchiveAppRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.AppService.ArchiveApp(id, *req.Archived, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":       id,
+			"archived": *req.Archived,
+		}).Error("archive app failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -27,6 +27,7 @@ func (handler *Handler) CreateApp(c *gin.Context) {
 		Name:       req.Name,
 		CardLength: req.CardLength,
 		CardPrefix: req.CardPrefix,
+		Actor:      app.GetActorFromContext(c),
 	})
 	if err != nil {
 		global.Logger.Error("create app failed", err)
@@ -0,0 +1,36 @@
+package apps
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteApp 删除应用，还有未使用或未过期的激活码时返回 errcode.AppHasLiveCards
+func (handler *Handler) DeleteApp(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", id))
+		return
+	}
+
+	if err := handler.AppService.DeleteApp(id, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("delete app failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
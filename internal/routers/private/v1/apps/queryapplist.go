@@ -11,10 +11,12 @@ import (
 )
 
 type QueryAppListRequest struct {
-	ID    string `form:"id"`
-	Name  string `form:"name"`
-	Page  int    `form:"page" binding:"min=1"`
-	Limit int    `form:"limit" binding:"min=1,max=50"`
+	ID       string `form:"id"`
+	Name     string `form:"name"`
+	Status   string `form:"status" binding:"omitempty,oneof=active disabled"`
+	Archived *bool  `form:"archived"`
+	Page     int    `form:"page" binding:"min=1"`
+	Limit    int    `form:"limit" binding:"min=1,max=50"`
 }
 
 // QueryAppList 批量获取app信息
@@ -41,10 +43,12 @@ func (handler *Handler) QueryAppList(c *gin.Context) {
 	}
 
 	result, err := handler.AppService.QueryAppList(apps.QueryAppListArgs{
-		ID:    req.ID,
-		Name:  req.Name,
-		Page:  req.Page,
-		Limit: req.Limit,
+		ID:       req.ID,
+		Name:     req.Name,
+		Status:   req.Status,
+		Archived: req.Archived,
+		Page:     req.Page,
+		Limit:    req.Limit,
 	})
 	if err != nil {
 		global.Logger.Error("get apps failed", err)
@@ -29,6 +29,7 @@ func (handler *Handler) UpdateApp(c *gin.Context) {
 		Name:       req.Name,
 		CardLength: req.CardLength,
 		CardPrefix: req.CardPrefix,
+		Actor:      app.GetActorFromContext(c),
 	})
 	if err != nil {
 		global.Logger.Error("update app failed", err)
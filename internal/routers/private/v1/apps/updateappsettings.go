@@ -0,0 +1,64 @@
+package apps
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateAppSettingsRequest struct {
+	Status       string   `json:"status" binding:"required,oneof=active disabled"`
+	TimeTypes    []string `json:"time_types"`
+	MaxDuration  int      `json:"max_duration" binding:"min=0"`
+	DevicePolicy string   `json:"device_policy" binding:"max=64"`
+	ContactURL   string   `json:"contact_url" binding:"max=512"`
+}
+
+// UpdateAppSettings 修改应用的状态、默认时间类型、最大有效时长、设备绑定策略和联系链接
+func (handler *Handler) UpdateAppSettings(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateAppSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	for _, t := range req.TimeTypes {
+		if !card.IsTimeType(t) {
+			app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("无效的时间类型: " + t))
+			return
+		}
+	}
+
+	err := handler.AppService.UpdateAppSettings(apps.UpdateAppSettingsArgs{
+		ID:           id,
+		Status:       req.Status,
+		TimeTypes:    req.TimeTypes,
+		MaxDuration:  req.MaxDuration,
+		DevicePolicy: req.DevicePolicy,
+		ContactURL:   req.ContactURL,
+		Actor:        app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":  id,
+			"req": req,
+		}).Error("update app settings failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
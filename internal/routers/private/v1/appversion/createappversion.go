@@ -0,0 +1,56 @@
+package appversion
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/appversion"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAppVersionRequest struct {
+	AppID      string `json:"app_id" binding:"required"`
+	Channel    string `json:"channel"`
+	Version    string `json:"version" binding:"required"`
+	MinVersion string `json:"min_version"`
+	Notes      string `json:"notes"`
+}
+
+// CreateAppVersion 发布客户端版本，渠道中版本号最高的版本的最低支持版本会立即生效
+func (handler *Handler) CreateAppVersion(c *gin.Context) {
+	var req CreateAppVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	v, err := handler.VersionService.CreateVersion(appversion.CreateVersionArgs{
+		AppID:      req.AppID,
+		Channel:    req.Channel,
+		Version:    req.Version,
+		MinVersion: req.MinVersion,
+		Notes:      req.Notes,
+		Actor:      app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("create app version failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(v)
+}
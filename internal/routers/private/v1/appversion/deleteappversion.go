@@ -0,0 +1,36 @@
+package appversion
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteAppVersion 删除发布的版本，渠道的版本策略会重新计算
+func (handler *Handler) DeleteAppVersion(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", id))
+		return
+	}
+
+	if err := handler.VersionService.DeleteVersion(id, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("delete app version failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,51 @@
+package appversion
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/appversion"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeviceBreakdownRequest struct {
+	AppID   string `form:"app_id" binding:"required"`
+	Channel string `form:"channel"`
+	Days    int    `form:"days" binding:"min=0"`
+}
+
+// DeviceBreakdown 按渠道和版本统计最近上报过的活跃设备，并标记低于最低支持版本的版本
+func (handler *Handler) DeviceBreakdown(c *gin.Context) {
+	var req DeviceBreakdownRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.VersionService.DeviceBreakdown(appversion.DeviceBreakdownArgs{
+		AppID:   req.AppID,
+		Channel: req.Channel,
+		Days:    req.Days,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("device breakdown failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(result)
+}
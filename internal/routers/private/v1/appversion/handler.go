@@ -0,0 +1,15 @@
+package appversion
+
+import (
+	"configuration-management/internal/biz/appversion"
+)
+
+type Handler struct {
+	VersionService appversion.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		VersionService: appversion.NewService(),
+	}
+}
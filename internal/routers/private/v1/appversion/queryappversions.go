@@ -0,0 +1,46 @@
+package appversion
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/appversion"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryAppVersionsRequest struct {
+	AppID   string `form:"app_id"`
+	Channel string `form:"channel"`
+	Page    int    `form:"page"`
+	Limit   int    `form:"limit" binding:"max=100"`
+}
+
+// QueryAppVersions 分页查询应用发布的客户端版本
+func (handler *Handler) QueryAppVersions(c *gin.Context) {
+	var req QueryAppVersionsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.VersionService.QueryVersions(appversion.QueryVersionsArgs{
+		AppID:   req.AppID,
+		Channel: req.Channel,
+		Page:    req.Page,
+		Limit:   req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query app versions failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
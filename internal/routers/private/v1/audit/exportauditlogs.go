@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"strconv"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/audit"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+var exportHeader = []string{
+	"id", "created_at", "actor_id", "actor_name", "action", "target_type", "target_id",
+	"before", "after", "ip", "request_id",
+}
+
+// ExportAuditLogs 以 CSV 格式导出符合条件的全部审计日志，边查询边写入响应
+func (handler *Handler) ExportAuditLogs(c *gin.Context) {
+	var req QueryAuditLogsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	filename := "audit_log_" + time.Now().Format("20060102150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	// 带 BOM 避免 Excel 打开时中文乱码，并转义以公式字符开头的内容
+	writer := spreadsheet.NewCSVWriter(c.Writer)
+	_ = writer.Write(exportHeader)
+
+	err := handler.AuditService.ExportEntries(req.toArgs(), func(entries []audit.Entry) error {
+		for _, e := range entries {
+			if err := writer.Write([]string{
+				strconv.FormatInt(e.ID, 10),
+				e.CreatedAt.Format(time.DateTime),
+				e.ActorID,
+				e.ActorName,
+				e.Action,
+				e.TargetType,
+				e.TargetID,
+				string(e.Before),
+				string(e.After),
+				e.IP,
+				e.RequestID,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// 响应头已经发出，只能记录日志
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("export audit logs failed", err)
+	}
+}
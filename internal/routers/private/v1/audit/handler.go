@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"time"
+
+	"configuration-management/internal/biz/audit"
+	"configuration-management/internal/biz/common"
+)
+
+type Handler struct {
+	AuditService audit.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		AuditService: audit.NewService(),
+	}
+}
+
+// QueryAuditLogsRequest 查询和导出共用的过滤条件
+type QueryAuditLogsRequest struct {
+	ActorID            string       `form:"actor_id"`
+	Action             string       `form:"action"`
+	TargetType         string       `form:"target_type"`
+	TargetID           string       `form:"target_id"`
+	RequestID          string       `form:"request_id"`
+	CreatedAtDateRange [2]time.Time `form:"created_at_date_range[]"`
+	Page               int          `form:"page"`
+	Limit              int          `form:"limit" binding:"max=100"`
+}
+
+func (req QueryAuditLogsRequest) toArgs() audit.QueryEntriesArgs {
+	return audit.QueryEntriesArgs{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		RequestID:  req.RequestID,
+		CreatedAtDateRange: common.TimeRange{
+			StartTime: req.CreatedAtDateRange[0],
+			EndTime:   req.CreatedAtDateRange[1],
+		},
+		Page:  req.Page,
+		Limit: req.Limit,
+	}
+}
@@ -2,10 +2,12 @@ package card
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
 	"configuration-management/internal/biz/activationattempt"
 	"configuration-management/internal/biz/card"
 	"configuration-management/pkg/app"
@@ -17,8 +19,10 @@ import (
 )
 
 type ActivateDecryptedData struct {
-	Value string `json:"value"` // 激活码值
-	SEID  string `json:"seid"`  // 使用的设备SEID
+	Value   string `json:"value"`   // 激活码值
+	SEID    string `json:"seid"`    // 使用的设备SEID
+	Version string `json:"version"` // 客户端版本，旧客户端不上报
+	Channel string `json:"channel"` // 客户端的发布渠道，为空时为 stable
 }
 
 type ActivateRequestBody struct {
@@ -66,6 +70,13 @@ func (handler *Handler) Activate(c *gin.Context) {
 		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
 		return
 	}
+	if !handler.allowCard(c, data.Value) {
+		return
+	}
+	// 客户端版本过低时返回签名的更新提示，不激活激活码
+	if _, ok := handler.checkClientVersion(c, data.Value, data.Channel, data.Version); !ok {
+		return
+	}
 	requestData, err := json.Marshal(data)
 	if err != nil {
 		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
@@ -73,18 +84,28 @@ func (handler *Handler) Activate(c *gin.Context) {
 	}
 
 	// 业务逻辑
-	_, err = handler.CardService.ActivateCard(card.ActivateCardArgs{
+	activatedCard, err := handler.CardService.ActivateCard(card.ActivateCardArgs{
 		Value: data.Value,
 		SEID:  data.SEID,
+		Actor: app.GetActorFromContext(c),
 	})
 	if err != nil {
-		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+		} else {
+			app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		}
 		if err = handler.ActivationAttempt.CreateActivationAttempt(&activationattempt.ActivationAttempt{
 			CardValue:    data.Value,
 			Success:      false,
 			ErrorMessage: err.Error(),
 			RequestData:  string(requestData),
 			ResponseData: err.Error(),
+			IP:           c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+			SEID:         data.SEID,
+			AppID:        activatedCard.AppID,
 			CreatedAt:    time.Now(),
 		}); err != nil {
 			global.Logger.WithFields(logger.Fields{
@@ -93,6 +114,12 @@ func (handler *Handler) Activate(c *gin.Context) {
 			}).Error("create activation attempt failed", err)
 			return
 		}
+		handler.evaluateAbuse(abuse.Event{
+			Type:      abuse.EventActivation,
+			CardValue: data.Value,
+			SEID:      data.SEID,
+			IP:        c.ClientIP(),
+		})
 		return
 	}
 
@@ -102,11 +129,23 @@ func (handler *Handler) Activate(c *gin.Context) {
 		ErrorMessage: "",
 		RequestData:  string(requestData),
 		ResponseData: "",
+		IP:           c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		SEID:         data.SEID,
+		AppID:        activatedCard.AppID,
 		CreatedAt:    time.Now(),
 	}); err != nil {
 		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
 		return
 	}
+	handler.recordClientVersion(activatedCard.AppID, data.SEID, data.Channel, data.Version)
+	verdict := handler.evaluateAbuse(abuse.Event{
+		Type:      abuse.EventActivation,
+		CardValue: data.Value,
+		SEID:      data.SEID,
+		IP:        c.ClientIP(),
+		Success:   true,
+	})
 
 	var response ActivateResponseBody
 	encryptedResult, err := security.GetAESEncrypted(data.Value)
@@ -130,7 +169,7 @@ func (handler *Handler) Activate(c *gin.Context) {
 	}
 
 	// ExtraData，暗号
-	response.ExtraData = security.GenerateCipherText(data.Value, hourCount > 3 || totalCount > 5)
+	response.ExtraData = security.GenerateCipherText(data.Value, hourCount > 3 || totalCount > 5 || verdict.Flagged)
 
 	// 加载 RSA 私钥，用于签名，签名内容为 Status + ExtraData
 	signature, err := security.GetSignature(fmt.Sprintf("%s%s", response.Result, response.ExtraData))
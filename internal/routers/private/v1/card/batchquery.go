@@ -8,7 +8,6 @@ import (
 	"configuration-management/global"
 	"configuration-management/internal/biz/card"
 	"configuration-management/internal/biz/common"
-	"configuration-management/internal/biz/permissions"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
@@ -37,23 +36,13 @@ func (handler *Handler) BatchQuery(c *gin.Context) {
 		return
 	}
 
-	var userId string
-	if !userInfo.IsRoot() {
-		currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
-		if err != nil {
-			global.Logger.WithFields(logger.Fields{
-				"userInfo": userInfo,
-			}).Error("用户不存在", err)
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
-			return
-		}
-		// 检查用户是否有查询权限
-		if !currentUser.HasPermission(permissions.QUERY) {
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
-			return
-		}
-
-		userId = userInfo.UserId
+	userId, subtreePath, err := handler.getViewScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("用户不存在", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
 	}
 	var appIds, values []string
 	if req.AppIds != "" {
@@ -81,6 +70,7 @@ func (handler *Handler) BatchQuery(c *gin.Context) {
 
 	result, err := handler.CardService.GetCards(card.GetCardsArgs{
 		UserId:         userId,
+		SubtreePath:    subtreePath,
 		AppIDs:         appIds,
 		UserName:       req.UserName,
 		Values:         values,
@@ -1,6 +1,7 @@
 package card
 
 import (
+	"errors"
 	"strings"
 
 	"configuration-management/global"
@@ -39,15 +40,6 @@ func (handler *Handler) BatchUpdateStatus(c *gin.Context) {
 
 	var userId string
 	if !userInfo.IsRoot() {
-		currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
-		if err != nil {
-			global.Logger.WithFields(logger.Fields{
-				"userInfo": userInfo,
-			}).Error("用户不存在", err)
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
-			return
-		}
-
 		var subPermission string
 		switch req.Status {
 		case card.StatusUsed:
@@ -66,7 +58,7 @@ func (handler *Handler) BatchUpdateStatus(c *gin.Context) {
 			return
 		}
 		// 检查用户是否有更新权限
-		if !currentUser.HasPermission(subPermission) {
+		if !app.HasPermission(c, subPermission) {
 			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
 			return
 		}
@@ -74,13 +66,29 @@ func (handler *Handler) BatchUpdateStatus(c *gin.Context) {
 		userId = userInfo.UserId
 	}
 
+	appScope, err := handler.getAppScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("get app scope failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
 	if err := handler.CardService.BatchUpdateStatus(card.BatchUpdateStatusArgs{
 		UserId: userId,
+		Actor:  app.GetActorFromContext(c),
 		Values: strings.Split(req.Values, ","),
 		Status: req.Status,
 		SEID:   req.SEID,
+		AppIDs: appScope,
 	}); err != nil {
 		global.Logger.Error("batch update status failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
 		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
 		return
 	}
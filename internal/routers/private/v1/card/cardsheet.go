@@ -0,0 +1,138 @@
+package card
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RenderCardSheetRequest struct {
+	BatchID string `form:"batch_id"`
+	Value   string `form:"value"` // 逗号分隔
+	AppId   string `form:"app_id"`
+	Status  int    `form:"status"`
+	Layout  string `form:"layout"` // 为空时使用默认版式
+}
+
+// RenderCardSheet 把一个批次或指定的激活码排版为可打印的 PDF
+func (handler *Handler) RenderCardSheet(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req RenderCardSheetRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if req.BatchID == "" && req.Value == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("需要 batch_id 或 value"))
+		return
+	}
+
+	// 和导出使用相同的查看范围
+	args, err := handler.exportArgs(userInfo, ExportRequest{
+		Value:  req.Value,
+		Status: req.Status,
+		AppId:  req.AppId,
+	})
+	if err != nil {
+		handler.toCardSheetError(c, req, err)
+		return
+	}
+	args.Filter.BatchID = req.BatchID
+
+	// 生成完整的文件后再返回，出错时可以返回错误信息
+	var buf bytes.Buffer
+	if _, err := handler.CardService.RenderCardSheet(card.RenderCardSheetArgs{
+		Filter: args.Filter,
+		Layout: req.Layout,
+	}, &buf); err != nil {
+		handler.toCardSheetError(c, req, err)
+		return
+	}
+
+	name := "cards-" + time.Now().Format("20060102150405") + ".pdf"
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// GetSheetLayouts 获取可用的打印版式
+func (handler *Handler) GetSheetLayouts(c *gin.Context) {
+	app.NewResponse(c).ResponseOK(handler.CardService.GetSheetLayouts())
+}
+
+type GetCardQRCodeRequest struct {
+	Size int `form:"size"` // 图片边长，默认 256 像素
+}
+
+// GetCardQRCode 获取激活码兑换链接的二维码 PNG 图片
+func (handler *Handler) GetCardQRCode(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+	value := strings.TrimSpace(c.Param("value"))
+
+	var req GetCardQRCodeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if req.Size == 0 {
+		req.Size = 256
+	}
+
+	userId, subtreePath, err := handler.getViewScope(userInfo)
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	appScope, err := handler.getAppScope(userInfo)
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
+	png, err := handler.CardService.GetCardQRCode(card.GetCardQRCodeArgs{
+		Value:       value,
+		Size:        req.Size,
+		UserId:      userId,
+		SubtreePath: subtreePath,
+		AppIDs:      appScope,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"value":    value,
+			"userInfo": userInfo,
+		}).Error("get card qrcode failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+func (handler *Handler) toCardSheetError(c *gin.Context, req RenderCardSheetRequest, err error) {
+	global.Logger.WithFields(logger.Fields{
+		"req": req,
+	}).Error("render card sheet failed", err)
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		app.NewResponse(c).ToErrorResponse(e)
+		return
+	}
+	app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+}
@@ -1,10 +1,17 @@
 package card
 
 import (
+	"errors"
+	"time"
+
 	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
 	card2 "configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/cardcheck"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,14 +29,40 @@ func (handler *Handler) CheckAvailability(c *gin.Context) {
 		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
 		return
 	}
+	if !handler.allowCard(c, req.CardValue) {
+		return
+	}
 
 	isAvailable, err := handler.CardService.CheckAvailability(card2.CheckAvailabilityArgs{CardValue: req.CardValue})
 	if err != nil {
 		global.Logger.Error("get card failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
 		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
 		return
 	}
 
+	// 记录检查，用于激活码的时间线，记录失败不影响返回结果
+	if err := handler.CardCheck.CreateCardCheck(&cardcheck.CardCheck{
+		CardValue: req.CardValue,
+		Available: isAvailable,
+		IP:        c.ClientIP(),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": req.CardValue,
+		}).Error("create card check failed", err)
+	}
+	handler.evaluateAbuse(abuse.Event{
+		Type:      abuse.EventCheck,
+		CardValue: req.CardValue,
+		IP:        c.ClientIP(),
+		Success:   isAvailable,
+	})
+
 	rsp := CheckAvailabilityResponse{
 		IsAvailable: isAvailable,
 	}
@@ -2,6 +2,7 @@ package card
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"configuration-management/internal/biz/card"
@@ -13,8 +14,10 @@ import (
 )
 
 type CheckDecryptedData struct {
-	Value string `json:"value"` // 激活码值
-	SEID  string `json:"seid"`  // 使用的设备SEID
+	Value   string `json:"value"`   // 激活码值
+	SEID    string `json:"seid"`    // 使用的设备SEID
+	Version string `json:"version"` // 客户端版本，旧客户端不上报
+	Channel string `json:"channel"` // 客户端的发布渠道，为空时为 stable
 }
 
 type CheckRequestBody struct {
@@ -63,16 +66,31 @@ func (handler *Handler) CheckWithSignature(c *gin.Context) {
 		return
 	}
 
+	// 客户端版本过低时返回签名的更新提示
+	appID, ok := handler.checkClientVersion(c, data.Value, data.Channel, data.Version)
+	if !ok {
+		return
+	}
+
 	// 业务逻辑
 	activated, err := handler.CardService.CheckCardStatus(card.CheckCardStatusArgs{
 		Value: data.Value,
 		SEID:  data.SEID,
 	})
 	if err != nil {
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
 		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
 		return
 	}
 
+	if activated {
+		handler.recordClientVersion(appID, data.SEID, data.Channel, data.Version)
+	}
+
 	var response CheckResponseBody
 	// Result
 	encryptedResult, err := security.GetAESEncrypted(fmt.Sprintf("%t", activated))
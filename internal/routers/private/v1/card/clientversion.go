@@ -0,0 +1,102 @@
+package card
+
+import (
+	"encoding/json"
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/appversion"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/utils/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateRequiredResponseBody 客户端版本低于最低支持版本时的响应
+// Result 解密后为 update_required，Update 解密后为 appversion.Verdict 的 JSON，签名内容为 Result + ExtraData + Update
+type UpdateRequiredResponseBody struct {
+	Result    string `json:"rs"`
+	ExtraData string `json:"x"`
+	Update    string `json:"u"`
+	Signature string `json:"s"`
+}
+
+// checkClientVersion 客户端上报了版本时检查是否需要更新，需要更新时返回签名的结果并返回 false
+// 返回激活码所属的应用用于记录设备的版本，激活码不存在或检查失败时不拦截，由后续的逻辑处理
+func (handler *Handler) checkClientVersion(c *gin.Context, value string, channel string, clientVersion string) (string, bool) {
+	if clientVersion == "" {
+		return "", true
+	}
+	code, err := handler.CardService.GetCardByValue(value)
+	if err != nil {
+		if !errors.Is(err, errcode.NotFound) {
+			global.Logger.WithFields(logger.Fields{
+				"card_value": value,
+			}).Error("get card failed", err)
+		}
+		return "", true
+	}
+	verdict, err := handler.VersionService.Evaluate(code.AppID, channel, clientVersion)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id":  code.AppID,
+			"channel": channel,
+			"version": clientVersion,
+		}).Error("evaluate client version failed", err)
+		return code.AppID, true
+	}
+	if !verdict.UpdateRequired {
+		return code.AppID, true
+	}
+
+	response, err := signUpdateRequired(value, verdict)
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return code.AppID, false
+	}
+	app.NewResponse(c).ResponseOK(response)
+	return code.AppID, false
+}
+
+func signUpdateRequired(value string, verdict appversion.Verdict) (UpdateRequiredResponseBody, error) {
+	var response UpdateRequiredResponseBody
+	result, err := security.GetAESEncrypted(appversion.UpdateRequired)
+	if err != nil {
+		return response, err
+	}
+	verdictJson, err := json.Marshal(verdict)
+	if err != nil {
+		return response, err
+	}
+	update, err := security.GetAESEncrypted(string(verdictJson))
+	if err != nil {
+		return response, err
+	}
+	response.Result = result
+	// 版本过低不是异常行为，不能给出要求客户端处理滥用的暗号
+	response.ExtraData = security.GenerateCipherText(value, false)
+	response.Update = update
+	response.Signature, err = security.GetSignature(response.Result + response.ExtraData + response.Update)
+	if err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// recordClientVersion 记录设备上报的版本，失败不影响请求结果
+func (handler *Handler) recordClientVersion(appID string, seid string, channel string, clientVersion string) {
+	if err := handler.VersionService.RecordDevice(appversion.DeviceVersion{
+		AppID:   appID,
+		SEID:    seid,
+		Channel: channel,
+		Version: clientVersion,
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id":  appID,
+			"seid":    seid,
+			"version": clientVersion,
+		}).Error("record client version failed", err)
+	}
+}
@@ -1,11 +1,11 @@
 package card
 
 import (
+	"errors"
 	"net/http"
 
 	"configuration-management/global"
 	"configuration-management/internal/biz/card"
-	"configuration-management/internal/biz/permissions"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
@@ -36,26 +36,6 @@ func (handler *Handler) CreateCards(c *gin.Context) {
 		return
 	}
 
-	// 权限校验
-	currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
-	if err != nil {
-		global.Logger.WithFields(logger.Fields{
-			"error": err,
-		}).Error("[CreateCards] 查询用户失败")
-		app.NewResponse(c).ToErrorResponse(errcode.ServerError)
-		return
-	}
-	if !userInfo.IsRoot() {
-		// 检查用户是否有查询权限
-		if !currentUser.HasPermission(permissions.CREATE) {
-			global.Logger.WithFields(logger.Fields{
-				"error": err,
-			}).Error("用户权限异常，无法创建激活码")
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
-			return
-		}
-	}
-
 	// 对时间类型和时间进行校验
 	//if !biz.IsValidTimeType(req.TimeType, req.Days, req.Minutes) {
 	//	global.Logger.WithFields(logger.Fields{
@@ -66,11 +46,20 @@ func (handler *Handler) CreateCards(c *gin.Context) {
 	//	return
 	//}
 
+	// 非 root 需要检查应用授权
+	appScope, err := handler.getAppScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("[CreateCards] 查询用户应用权限失败", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
 	// 创建
 	newCards, err := handler.CardService.CreateCards(card.CreateCardsArgs{
 		UserID:   userInfo.UserId,
 		UserName: userInfo.Username,
-		MaxCnt:   currentUser.MaxCnt,
 		//Minutes:  req.Minutes,
 		Hours:    req.Hours,
 		Days:     req.Days,
@@ -78,11 +67,21 @@ func (handler *Handler) CreateCards(c *gin.Context) {
 		Remark:   req.Remark,
 		Count:    req.Count,
 		AppID:    req.AppID,
+
+		CheckGrant: !userInfo.IsRoot(),
+		Apps:       appScope,
+		Actor:      app.GetActorFromContext(c),
 	})
 	if err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"error": err,
 		}).Error("create card failed")
+		// 额度不足等业务错误直接返回
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
 		app.NewResponse(c).ToErrorResponse(errcode.ServerError)
 		return
 	}
@@ -0,0 +1,38 @@
+package card
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/cardexport"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateExportJob 创建后台导出任务，查询参数和 Export 相同，不支持 json 格式
+func (handler *Handler) CreateExportJob(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	args, err := handler.exportArgs(userInfo, req)
+	if err != nil {
+		handler.toExportError(c, req, err)
+		return
+	}
+
+	job, err := handler.ExportService.CreateJob(cardexport.CreateJobArgs{
+		UserID: userInfo.UserId,
+		Export: args,
+	})
+	if err != nil {
+		handler.toExportError(c, req, err)
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(toExportJobView(job))
+}
@@ -2,7 +2,6 @@ package card
 
 import (
 	"configuration-management/global"
-	"configuration-management/internal/biz/permissions"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
@@ -28,22 +27,10 @@ func (handler *Handler) DeleteCardsByValues(c *gin.Context) {
 
 	var userId string
 	if !userInfo.IsRoot() {
-		currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
-		if err != nil {
-			global.Logger.WithFields(logger.Fields{
-				"userInfo": userInfo,
-			}).Error("用户不存在", err)
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
-			return
-		}
-		// 检查用户是否有查询权限
-		if !currentUser.HasPermission(permissions.DELETE) {
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
-			return
-		}
+		userId = userInfo.UserId
 	}
 
-	err := handler.CardService.DeleteCardsByValues(req.Values, userId)
+	err := handler.CardService.DeleteCardsByValues(req.Values, userId, app.GetActorFromContext(c))
 	if err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"error": err,
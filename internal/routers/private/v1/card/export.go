@@ -1,35 +1,129 @@
 package card
 
 import (
+	"errors"
+	"net/http"
 	"strings"
+	"time"
 
 	"configuration-management/global"
 	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/cardexport"
+	"configuration-management/internal/biz/common"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/spreadsheet"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ExportRequest struct {
-	Value  string `form:"value"`
-	Status int    `form:"status"`
-	Remark string `form:"remark"`
-	Page   int    `form:"page" binding:"required,min=1"`
-	Limit  int    `form:"limit" binding:"required,min=1,max=50"`
+	Value              string       `form:"value"`
+	Status             int          `form:"status"`
+	Remark             string       `form:"remark"`
+	AppId              string       `form:"app_id"`
+	UserName           string       `form:"user_name"`
+	TimeType           string       `form:"time_type"`
+	SEID               string       `form:"seid"`
+	CreatedAtDateRange [2]time.Time `form:"created_at_date_range[]"`
+	UsedAtDateRange    [2]time.Time `form:"used_at_date_range[]"`
+	Format             string       `form:"format" binding:"omitempty,oneof=csv xlsx json"` // 默认为 csv
+	Columns            string       `form:"columns"`                                        // 导出的列，逗号分隔
+	Locale             string       `form:"locale" binding:"omitempty,oneof=zh-CN en-US"`   // 表头使用的语言
+	Page               int          `form:"page" binding:"omitempty,min=1"`                 // 只有 json 格式分页
+	Limit              int          `form:"limit" binding:"omitempty,min=1,max=50"`
 }
 
-// Export 导出卡信息的 JSON
+// Export 导出激活码，csv 和 xlsx 使用游标逐行写入响应，json 格式按分页返回
 func (handler *Handler) Export(c *gin.Context) {
 	userInfo := app.GetUserInfoFromContext(c)
 
-	var req GetCardsRequest
+	var req ExportRequest
 	if err := c.ShouldBind(&req); err != nil {
 		global.Logger.Error("invalid params", err)
 		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
 		return
 	}
+	args, err := handler.exportArgs(userInfo, req)
+	if err != nil {
+		handler.toExportError(c, req, err)
+		return
+	}
+
+	if args.Format == "json" {
+		if req.Page == 0 || req.Limit == 0 {
+			app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("json 格式需要 page 和 limit"))
+			return
+		}
+		args.Filter.NeedPagination = true
+		args.Filter.Page = req.Page
+		args.Filter.Limit = req.Limit
+		result, err := handler.CardService.GetCards(args.Filter)
+		if err != nil {
+			global.Logger.Error("get cards failed", err)
+			app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+			return
+		}
+		appOptions, err := handler.AppService.QueryAppOptions()
+		if err != nil {
+			global.Logger.Error("get app options failed", err)
+			app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+			return
+		}
+		app.NewResponse(c).ToResponseList(card.BatchToView(result.List, appOptions), result.Total)
+		return
+	}
+
+	if err := card.CheckExportArgs(args); err != nil {
+		handler.toExportError(c, req, err)
+		return
+	}
+	// 导出大量激活码时可能超过服务端的写超时
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		global.Logger.Warning("clear write deadline failed", err)
+	}
+	c.Header("Content-Type", spreadsheet.ContentType(args.Format))
+	c.Header("Content-Disposition", `attachment; filename="`+cardexport.FileName(args.Format, time.Now())+`"`)
+	c.Status(http.StatusOK)
 
+	// 响应头已经发出，出错时只能中断连接
+	if rows, err := handler.CardService.ExportCards(args, c.Writer); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+			"rows":   rows,
+		}).Error("export cards failed", err)
+		c.Abort()
+	}
+}
+
+// exportArgs 根据请求和当前用户的查看范围生成导出参数，请求的应用必须在用户有权限的应用中
+func (handler *Handler) exportArgs(userInfo app.UserInfo, req ExportRequest) (card.ExportCardsArgs, error) {
+	userId, subtreePath, err := handler.getViewScope(userInfo)
+	if err != nil {
+		return card.ExportCardsArgs{}, errcode.NoPermission.WithDetails(err.Error())
+	}
+	appScope, err := handler.getAppScope(userInfo)
+	if err != nil {
+		return card.ExportCardsArgs{}, err
+	}
+
+	appIds := appScope
+	if req.AppId != "" {
+		appIds = strings.Split(req.AppId, ",")
+		if appScope != nil {
+			allowed := make(map[string]bool, len(appScope))
+			for _, appId := range appScope {
+				allowed[appId] = true
+			}
+			for _, appId := range appIds {
+				if !allowed[appId] {
+					return card.ExportCardsArgs{}, errcode.NoPermission.WithDetails("没有应用 " + appId + " 的权限")
+				}
+			}
+		}
+	}
 	var values []string
 	if req.Value != "" {
 		values = strings.Split(req.Value, ",")
@@ -38,27 +132,49 @@ func (handler *Handler) Export(c *gin.Context) {
 	if req.Status != 0 {
 		status = append(status, req.Status)
 	}
-
-	result, err := handler.CardService.GetCards(card.GetCardsArgs{
-		UserId:         userInfo.UserId,
-		Values:         values,
-		Status:         status,
-		Remark:         req.Remark,
-		NeedPagination: false,
-	})
-	if err != nil {
-		global.Logger.Error("get cards failed", err)
-		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
-		return
+	var columns []string
+	if req.Columns != "" {
+		columns = strings.Split(req.Columns, ",")
+	}
+	format := req.Format
+	if format == "" {
+		format = spreadsheet.FormatCSV
 	}
 
-	appOptions, err := handler.AppService.QueryAppOptions()
-	if err != nil {
-		global.Logger.Error("get app options failed", err)
-		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
+	return card.ExportCardsArgs{
+		Filter: card.GetCardsArgs{
+			UserId:      userId,
+			SubtreePath: subtreePath,
+			AppIDs:      appIds,
+			Values:      values,
+			Status:      status,
+			Remark:      req.Remark,
+			TimeType:    req.TimeType,
+			UserName:    req.UserName,
+			SEID:        req.SEID,
+			CreatedAtDateRange: common.TimeRange{
+				StartTime: req.CreatedAtDateRange[0].UTC(),
+				EndTime:   req.CreatedAtDateRange[1].UTC(),
+			},
+			UsedAtDateRange: common.TimeRange{
+				StartTime: req.UsedAtDateRange[0],
+				EndTime:   req.UsedAtDateRange[1],
+			},
+		},
+		Format:  format,
+		Columns: columns,
+		Locale:  req.Locale,
+	}, nil
+}
+
+func (handler *Handler) toExportError(c *gin.Context, req ExportRequest, err error) {
+	global.Logger.WithFields(logger.Fields{
+		"req": req,
+	}).Error("export cards failed", err)
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		app.NewResponse(c).ToErrorResponse(e)
 		return
 	}
-
-	app.NewResponse(c).ToResponseList(card.BatchToView(result.List, appOptions), result.Total)
-	return
+	app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
 }
@@ -0,0 +1,115 @@
+package card
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/cardexport"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportJobView 导出任务，完成后带有下载地址
+type ExportJobView struct {
+	cardexport.Job
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+func toExportJobView(job cardexport.Job) ExportJobView {
+	view := ExportJobView{Job: job}
+	if job.Status == cardexport.StatusSucceeded {
+		view.DownloadURL = "/private/v1/card-export-job/" + job.ID + "/download"
+	}
+	return view
+}
+
+type QueryExportJobsRequest struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit" binding:"max=100"`
+}
+
+// QueryExportJobs 分页查询当前用户的导出任务
+func (handler *Handler) QueryExportJobs(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req QueryExportJobsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.ExportService.QueryJobs(cardexport.QueryJobsArgs{
+		UserID: userInfo.UserId,
+		Page:   req.Page,
+		Limit:  req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("query export jobs failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	views := make([]ExportJobView, 0, len(result.List))
+	for _, job := range result.List {
+		views = append(views, toExportJobView(job))
+	}
+	app.NewResponse(c).ToResponseList(views, result.Total)
+}
+
+// GetExportJob 查询导出任务的状态
+func (handler *Handler) GetExportJob(c *gin.Context) {
+	job, ok := handler.getExportJob(c)
+	if !ok {
+		return
+	}
+	app.NewResponse(c).ResponseOK(toExportJobView(job))
+}
+
+// DownloadExportJob 下载已完成的导出文件
+func (handler *Handler) DownloadExportJob(c *gin.Context) {
+	job, ok := handler.getExportJob(c)
+	if !ok {
+		return
+	}
+	switch job.Status {
+	case cardexport.StatusSucceeded:
+	case cardexport.StatusExpired:
+		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails("导出文件已过期"))
+		return
+	default:
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("导出任务没有完成"))
+		return
+	}
+
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		global.Logger.Warning("clear write deadline failed", err)
+	}
+	c.FileAttachment(job.FilePath, job.FileName)
+}
+
+func (handler *Handler) getExportJob(c *gin.Context) (cardexport.Job, bool) {
+	userInfo := app.GetUserInfoFromContext(c)
+	job, err := handler.ExportService.GetJob(c.Param("id"), userInfo.UserId)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":     c.Param("id"),
+			"userId": userInfo.UserId,
+		}).Error("get export job failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return cardexport.Job{}, false
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return cardexport.Job{}, false
+	}
+	return job, true
+}
@@ -12,12 +12,14 @@ import (
 func (handler *Handler) GetCardCountByStatus(c *gin.Context) {
 	userInfo := app.GetUserInfoFromContext(c)
 
-	var userId string
-	if !userInfo.IsRoot() {
-		userId = userInfo.UserId
+	userId, subtreePath, err := handler.getViewScope(userInfo)
+	if err != nil {
+		global.Logger.Error("get view scope failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
 	}
 
-	statusCount, err := handler.CardService.GetCardCountByUserIdAndStatus(userId)
+	statusCount, err := handler.CardService.GetCardCountByUserIdAndStatus(userId, subtreePath)
 	if err != nil {
 		global.Logger.Error("get card failed", err)
 		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
@@ -7,7 +7,6 @@ import (
 	"configuration-management/global"
 	"configuration-management/internal/biz/card"
 	"configuration-management/internal/biz/common"
-	"configuration-management/internal/biz/permissions"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
@@ -55,7 +54,7 @@ func (handler *Handler) GetCards(c *gin.Context) {
 		status = append(status, req.Status)
 	}
 
-	var userId string
+	var userId, subtreePath string
 	if !userInfo.IsRoot() {
 		currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
 		if err != nil {
@@ -65,12 +64,6 @@ func (handler *Handler) GetCards(c *gin.Context) {
 			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
 			return
 		}
-		// 检查用户是否有查询权限
-		if !currentUser.HasPermission(permissions.QUERY) {
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
-			return
-		}
-
 		// 检验 appIds 是否在用户的权限范围内
 		if len(appIds) > 0 {
 			for _, appId := range appIds {
@@ -88,8 +81,14 @@ func (handler *Handler) GetCards(c *gin.Context) {
 			// 没有指定 appIds，则使用用户有权限的 appIds
 			appIds = currentUser.Apps
 		}
+		// 没有任何应用权限时，空的 appIds 会变成不限制应用
+		if len(appIds) == 0 {
+			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails("没有任何应用的权限"))
+			return
+		}
 
 		userId = userInfo.UserId
+		subtreePath = currentUser.SubtreePath()
 	}
 
 	// 默认分页值
@@ -128,6 +127,7 @@ func (handler *Handler) GetCards(c *gin.Context) {
 
 	result, err := handler.CardService.GetCards(card.GetCardsArgs{
 		UserId:         userId,
+		SubtreePath:    subtreePath,
 		AppIDs:         appIds,
 		TimeType:       req.TimeType,
 		UserName:       req.UserName,
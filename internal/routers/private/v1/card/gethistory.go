@@ -0,0 +1,58 @@
+package card
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCardHistory 获取激活码的时间线：创建、激活尝试、状态修改、过期时间修改、换绑设备和可用性检查
+func (handler *Handler) GetCardHistory(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+	value := c.Param("value")
+
+	userId, subtreePath, err := handler.getViewScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("用户不存在", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	appScope, err := handler.getAppScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("get app scope failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
+	events, err := handler.CardService.GetCardHistory(card.GetCardHistoryArgs{
+		Value:       value,
+		UserId:      userId,
+		SubtreePath: subtreePath,
+		AppIDs:      appScope,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"value":    value,
+			"userInfo": userInfo,
+		}).Error("get card history failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(events)
+}
@@ -1,10 +1,21 @@
 package card
 
 import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
 	"configuration-management/internal/biz/activationattempt"
 	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/appversion"
 	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/cardcheck"
+	"configuration-management/internal/biz/cardexport"
 	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
@@ -12,6 +23,11 @@ type Handler struct {
 	AppService        apps.Service
 	UserService       user.Service
 	ActivationAttempt activationattempt.Service
+	CardCheck         cardcheck.Service
+	AbuseService      abuse.Service
+	ExportService     cardexport.Service
+	VersionService    appversion.Service
+	RateLimiter       *ratelimit.Limiter
 }
 
 func NewHandler() *Handler {
@@ -20,5 +36,65 @@ func NewHandler() *Handler {
 		AppService:        apps.NewService(),
 		UserService:       user.NewService(),
 		ActivationAttempt: activationattempt.NewService(),
+		CardCheck:         cardcheck.NewService(),
+		AbuseService:      abuse.NewService(),
+		ExportService:     cardexport.NewService(),
+		VersionService:    appversion.NewService(),
+		RateLimiter:       global.RateLimiter,
+	}
+}
+
+// getViewScope 返回当前用户能查看的激活码范围：root 不限制，其他用户为自己和所有下级
+func (handler *Handler) getViewScope(userInfo app.UserInfo) (userId string, subtreePath string, err error) {
+	if userInfo.IsRoot() {
+		return "", "", nil
+	}
+	currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
+	if err != nil {
+		return "", "", err
+	}
+	return currentUser.ID, currentUser.SubtreePath(), nil
+}
+
+// getAppScope 返回当前用户有权限的应用：root 返回 nil 表示不限制，其他用户没有任何应用时返回错误
+func (handler *Handler) getAppScope(userInfo app.UserInfo) ([]string, error) {
+	if userInfo.IsRoot() {
+		return nil, nil
+	}
+	currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if len(currentUser.Apps) == 0 {
+		return nil, errcode.NoPermission.WithDetails("没有任何应用的权限")
+	}
+	return currentUser.Apps, nil
+}
+
+// evaluateAbuse 在激活或检查记录写入后执行滥用检测，检测失败不影响请求结果
+func (handler *Handler) evaluateAbuse(event abuse.Event) abuse.Verdict {
+	verdict, err := handler.AbuseService.Evaluate(event)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"event": event,
+		}).Error("滥用检测失败", err)
+	}
+	return verdict
+}
+
+// allowCard 按激活码限流，被限流时直接返回错误
+func (handler *Handler) allowCard(c *gin.Context, value string) bool {
+	ok, retryAfter, err := handler.RateLimiter.Allow(c.FullPath(), ratelimit.KeyCard, value)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": value,
+			"path":       c.FullPath(),
+		}).Error("限流检查失败", err)
+		return true
+	}
+	if !ok {
+		app.NewResponse(c).ToTooManyRequestsResponse(retryAfter)
+		return false
 	}
+	return true
 }
@@ -33,6 +33,9 @@ func (handler *Handler) Identity(c *gin.Context) {
 		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("参数错误"))
 		return
 	}
+	if !handler.allowCard(c, req.Value) {
+		return
+	}
 
 	// 检查激活码是否存在
 	code, err := handler.CardService.GetCardByValue(req.Value)
@@ -46,6 +49,17 @@ func (handler *Handler) Identity(c *gin.Context) {
 		return
 	}
 
+	// 检查应用是否停用
+	if err := handler.AppService.CheckAppEnabled(code.AppID); err != nil {
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
 	// 检查是否过期
 	location, err := time.LoadLocation("Asia/Shanghai")
 	if err != nil {
@@ -74,8 +88,14 @@ func (handler *Handler) Identity(c *gin.Context) {
 		activatedCode, err := handler.CardService.ActivateCard(card.ActivateCardArgs{
 			Value: req.Value,
 			SEID:  req.SEID,
+			Actor: app.GetActorFromContext(c),
 		})
 		if err != nil {
+			var e *errcode.Error
+			if errors.As(err, &e) {
+				app.NewResponse(c).ToErrorResponse(e)
+				return
+			}
 			app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
 			return
 		}
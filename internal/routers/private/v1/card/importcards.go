@@ -0,0 +1,114 @@
+package card
+
+import (
+	"errors"
+	"fmt"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/card"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件的大小上限
+const maxImportFileSize = 10 << 20
+
+// ImportCardsRequest JSON 导入时激活码放在 rows 中，上传 CSV 文件时其他参数放在表单中
+type ImportCardsRequest struct {
+	AppID    string           `json:"app_id" form:"app_id" binding:"required"`
+	DryRun   bool             `json:"dry_run" form:"dry_run"`
+	Minutes  int              `json:"minutes" form:"minutes"`
+	Hours    int              `json:"hours" form:"hours"`
+	Days     int              `json:"days" form:"days"`
+	TimeType string           `json:"time_type" form:"time_type"`
+	Remark   string           `json:"remark" form:"remark"`
+	Rows     []card.ImportRow `json:"rows" form:"-"`
+}
+
+// ImportCards 导入外部生成的激活码，支持 JSON 和上传 CSV 文件，dry_run 时只返回每行的校验结果
+func (handler *Handler) ImportCards(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req ImportCardsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		rows, err := readImportFile(c)
+		if err != nil {
+			var e *errcode.Error
+			if !errors.As(err, &e) {
+				e = errcode.InvalidParams.WithDetails(err.Error())
+			}
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		req.Rows = rows
+	}
+
+	appScope, err := handler.getAppScope(userInfo)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userInfo": userInfo,
+		}).Error("[ImportCards] 查询用户应用权限失败", err)
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.CardService.ImportCards(card.ImportCardsArgs{
+		UserID:   userInfo.UserId,
+		UserName: userInfo.Username,
+		AppID:    req.AppID,
+		Rows:     req.Rows,
+		DryRun:   req.DryRun,
+		Minutes:  req.Minutes,
+		Hours:    req.Hours,
+		Days:     req.Days,
+		TimeType: req.TimeType,
+		Remark:   req.Remark,
+
+		CheckGrant: !userInfo.IsRoot(),
+		Apps:       appScope,
+		Actor:      app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id":   req.AppID,
+			"imported": result.Imported,
+		}).Error("import cards failed", err)
+		var e *errcode.Error
+		if !errors.As(err, &e) {
+			e = errcode.ServerError
+		}
+		// 部分批次已经提交时在错误中返回已导入的数量
+		if result.Imported > 0 {
+			e = e.WithDetails(append(e.Details(), fmt.Sprintf("已导入 %d 个激活码，批次 %s", result.Imported, result.BatchID))...)
+		}
+		app.NewResponse(c).ToErrorResponse(e)
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(result)
+}
+
+// readImportFile 读取上传的 CSV 文件
+func readImportFile(c *gin.Context) ([]card.ImportRow, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, errcode.InvalidParams.WithDetails("缺少导入文件 file")
+	}
+	if header.Size > maxImportFileSize {
+		return nil, errcode.InvalidParams.WithDetails(fmt.Sprintf("导入文件不能超过 %dMB", maxImportFileSize>>20))
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return card.ParseImportCSV(file)
+}
@@ -5,10 +5,8 @@ import (
 
 	"configuration-management/global"
 	"configuration-management/internal/biz/card"
-	"configuration-management/internal/biz/permissions"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
-	"configuration-management/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,26 +29,14 @@ func (handler *Handler) SetCardExpiredAt(c *gin.Context) {
 	// 检查用户是否有权限
 	var userId string
 	if !userInfo.IsRoot() {
-		currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
-		if err != nil {
-			global.Logger.WithFields(logger.Fields{
-				"userInfo": userInfo,
-			}).Error("用户不存在", err)
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
-			return
-		}
-		// 检查用户是否有查询权限
-		if !currentUser.HasPermission(permissions.UPDATE) {
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
-			return
-		}
-		userId = currentUser.ID
+		userId = userInfo.UserId
 	}
 
 	if err := handler.CardService.SetCardExpiredAt(card.SetCardExpiredAtArgs{
 		Value:        req.Value,
 		UserId:       userId,
 		NewExpiredAt: req.NewExpiredAt,
+		Actor:        app.GetActorFromContext(c),
 	}); err != nil {
 		global.Logger.Error("update card failed", err)
 		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
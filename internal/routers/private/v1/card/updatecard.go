@@ -54,19 +54,7 @@ func (handler *Handler) UpdateCard(c *gin.Context) {
 		return
 	}
 	if !isRoot {
-		currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
-		if err != nil {
-			global.Logger.WithFields(logger.Fields{
-				"userInfo": userInfo,
-			}).Error("用户不存在", err)
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
-			return
-		}
-		// 检查用户是否有查询权限
-		if !currentUser.HasPermission(permissions.UPDATE) {
-			app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
-			return
-		}
+		userId = userInfo.UserId
 
 		// 检查是否有更新状态
 		if req.Status != 0 && req.Status != currentCard.Status {
@@ -88,7 +76,7 @@ func (handler *Handler) UpdateCard(c *gin.Context) {
 				return
 			}
 			// 检查用户是否有更新权限
-			if !currentUser.HasPermission(subPermission) {
+			if !app.HasPermission(c, subPermission) {
 				app.NewResponse(c).ToErrorResponse(errcode.NoPermission)
 				return
 			}
@@ -107,6 +95,7 @@ func (handler *Handler) UpdateCard(c *gin.Context) {
 
 	if err := handler.CardService.UpdateCard(card.UpdateCardArgs{
 		UserId:      userId,
+		Actor:       app.GetActorFromContext(c),
 		CurrentCard: currentCard,
 		ID:          req.ID,
 		Status:      req.Status,
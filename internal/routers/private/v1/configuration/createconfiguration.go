@@ -35,6 +35,7 @@ func (handler *Handler) CreateConfiguration(c *gin.Context) {
 		UserID:      request.UserID,
 		ConfigKey:   request.ConfigKey,
 		ConfigValue: request.ConfigValue,
+		Actor:       app.GetActorFromContext(c),
 	})
 	if err != nil {
 		global.Logger.Error("create user config failed", err)
@@ -0,0 +1,61 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/userconfig"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRemoteKeyRequest struct {
+	AppID        string          `json:"app_id" binding:"required"`
+	ConfigKey    string          `json:"config_key" binding:"required"`
+	ValueType    string          `json:"value_type" binding:"required"`
+	DefaultValue json.RawMessage `json:"default_value"`
+	Description  string          `json:"description" binding:"max=255"`
+}
+
+// CreateRemoteKey 为应用创建远程配置项，值的类型创建后不能修改
+func (handler *Handler) CreateRemoteKey(c *gin.Context) {
+	var req CreateRemoteKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	key, err := handler.UserConfigService.CreateRemoteKey(userconfig.CreateRemoteKeyArgs{
+		AppID:        req.AppID,
+		ConfigKey:    req.ConfigKey,
+		ValueType:    req.ValueType,
+		DefaultValue: req.DefaultValue,
+		Description:  req.Description,
+		Caller:       caller,
+		Actor:        app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id":     req.AppID,
+			"config_key": req.ConfigKey,
+		}).Error("create remote config key failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(key)
+}
@@ -0,0 +1,36 @@
+package configuration
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteRemoteValue 删除某个作用范围上的值和它的历史版本，客户端会使用优先级更低的值
+func (handler *Handler) DeleteRemoteValue(c *gin.Context) {
+	id := c.Param("id")
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	if err := handler.UserConfigService.DeleteRemoteValue(id, caller, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("delete remote config value failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,170 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/userconfig"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/ratelimit"
+	"configuration-management/utils/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FetchRemoteConfigDecryptedData struct {
+	Value string `json:"value"` // 激活码值
+	SEID  string `json:"seid"`  // 使用的设备SEID
+}
+
+// FetchRemoteConfigRequest 参数放在 query 中，方便客户端带上 If-None-Match 使用条件请求
+type FetchRemoteConfigRequest struct {
+	EncryptedData string `form:"data" binding:"required"`
+	Signature     string `form:"signature" binding:"required"`
+	Timestamp     string `form:"timestamp" binding:"required"`
+}
+
+type FetchRemoteConfigResponseBody struct {
+	Result    string `json:"rs"` // 加密后的配置，内容为配置项到值的 JSON 对象
+	ETag      string `json:"e"`
+	Signature string `json:"s"` // 签名内容为 Result + ETag
+}
+
+// FetchRemoteConfig 客户端拉取远程配置，配置未变化时返回 304
+func (handler *Handler) FetchRemoteConfig(c *gin.Context) {
+	var req FetchRemoteConfigRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	// 校验时间戳
+	if !security.IsValidTimestamp(req.Timestamp) {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("invalid timestamp"))
+		return
+	}
+
+	// 校验签名
+	if !security.IsValidSignature(req.Signature, req.Timestamp, req.EncryptedData) {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("invalid signature"))
+		return
+	}
+
+	// 解密
+	decrypted, err := security.GetAESDecrypted(req.EncryptedData)
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	var data FetchRemoteConfigDecryptedData
+	if err := json.Unmarshal(decrypted, &data); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if data.Value == "" || data.SEID == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("参数错误"))
+		return
+	}
+	if !handler.allowCard(c, data.Value) {
+		return
+	}
+
+	// 只有已激活且设备匹配的激活码可以拉取配置
+	code, err := handler.CardService.GetCardByValue(data.Value)
+	if err != nil {
+		if errors.Is(err, errcode.NotFound) {
+			app.NewResponse(c).ToErrorResponse(errcode.CardNotFound.WithDetails(err.Error()))
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+	if err := handler.AppService.CheckAppEnabled(code.AppID); err != nil {
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+	if code.Status != card.StatusUsed {
+		app.NewResponse(c).ToErrorResponse(errcode.CardNotAvailable.WithDetails("激活码未激活或不可用"))
+		return
+	}
+	if code.SEID != data.SEID {
+		app.NewResponse(c).ToErrorResponse(errcode.DeviceNotAvailable.WithDetails("设备不匹配"))
+		return
+	}
+	location, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+	if code.ExpiredAt != nil && time.Now().In(location).After(*code.ExpiredAt) {
+		app.NewResponse(c).ToErrorResponse(errcode.CardExpired.WithDetails("激活码已过期"))
+		return
+	}
+
+	config, err := handler.UserConfigService.ResolveRemoteConfig(code.AppID, code.TimeType, code.Value)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": code.Value,
+			"app_id":     code.AppID,
+		}).Error("resolve remote config failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	c.Header("ETag", fmt.Sprintf(`"%s"`, config.ETag))
+	c.Header("Cache-Control", "private, no-cache")
+	if userconfig.MatchETag(c.GetHeader("If-None-Match"), config.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	values, err := json.Marshal(config.Values)
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+	var response FetchRemoteConfigResponseBody
+	response.Result, err = security.GetAESEncrypted(string(values))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+	response.ETag = config.ETag
+	response.Signature, err = security.GetSignature(fmt.Sprintf("%s%s", response.Result, response.ETag))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(response)
+}
+
+// allowCard 按激活码限流，被限流时直接返回错误
+func (handler *Handler) allowCard(c *gin.Context, value string) bool {
+	ok, retryAfter, err := handler.RateLimiter.Allow(c.FullPath(), ratelimit.KeyCard, value)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": value,
+			"path":       c.FullPath(),
+		}).Error("限流检查失败", err)
+		return true
+	}
+	if !ok {
+		app.NewResponse(c).ToTooManyRequestsResponse(retryAfter)
+		return false
+	}
+	return true
+}
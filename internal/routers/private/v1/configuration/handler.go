@@ -1,15 +1,45 @@
 package configuration
 
 import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/apps"
+	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/user"
 	"configuration-management/internal/biz/userconfig"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/ratelimit"
 )
 
 type Handler struct {
 	UserConfigService userconfig.Service
+	CardService       card.Service
+	AppService        apps.Service
+	UserService       user.Service
+	RateLimiter       *ratelimit.Limiter
 }
 
 func NewHandler() *Handler {
 	return &Handler{
 		UserConfigService: userconfig.NewService(),
+		CardService:       card.NewService(),
+		AppService:        apps.NewService(),
+		UserService:       user.NewService(),
+		RateLimiter:       global.RateLimiter,
 	}
 }
+
+// getCaller 返回当前用户管理远程配置的范围：root 不限制，其他用户为有权限的应用以及自己和下级的激活码
+func (handler *Handler) getCaller(userInfo app.UserInfo) (userconfig.Caller, error) {
+	if userInfo.IsRoot() {
+		return userconfig.Caller{}, nil
+	}
+	currentUser, err := handler.UserService.GetUserByID(userInfo.UserId)
+	if err != nil {
+		return userconfig.Caller{}, err
+	}
+	return userconfig.Caller{
+		UserID:      currentUser.ID,
+		SubtreePath: currentUser.SubtreePath(),
+		AppIDs:      currentUser.Apps,
+	}, nil
+}
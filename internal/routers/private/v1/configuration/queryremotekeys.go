@@ -0,0 +1,46 @@
+package configuration
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryRemoteKeysRequest struct {
+	AppID string `form:"app_id" binding:"required"`
+}
+
+// QueryRemoteKeys 查询应用的远程配置项
+func (handler *Handler) QueryRemoteKeys(c *gin.Context) {
+	var req QueryRemoteKeysRequest
+	if err := c.ShouldBind(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	keys, err := handler.UserConfigService.GetRemoteKeys(req.AppID, caller)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"app_id": req.AppID,
+		}).Error("query remote config keys failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(keys, len(keys))
+}
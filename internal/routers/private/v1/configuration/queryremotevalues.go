@@ -0,0 +1,46 @@
+package configuration
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryRemoteValuesRequest struct {
+	KeyID string `form:"key_id" binding:"required"`
+}
+
+// QueryRemoteValues 查询配置项在各个作用范围上的值
+func (handler *Handler) QueryRemoteValues(c *gin.Context) {
+	var req QueryRemoteValuesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	values, err := handler.UserConfigService.GetRemoteValues(req.KeyID, caller)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"key_id": req.KeyID,
+		}).Error("query remote config values failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(values, len(values))
+}
@@ -0,0 +1,73 @@
+package configuration
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RollbackRemoteValueRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+// GetRemoteValueHistory 查询配置值的历史版本，新的版本在前
+func (handler *Handler) GetRemoteValueHistory(c *gin.Context) {
+	id := c.Param("id")
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	history, err := handler.UserConfigService.GetRemoteValueHistory(id, caller)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("get remote config value history failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(history, len(history))
+}
+
+// RollbackRemoteValue 回滚到历史版本，回滚会保存为一个新的版本
+func (handler *Handler) RollbackRemoteValue(c *gin.Context) {
+	id := c.Param("id")
+	var req RollbackRemoteValueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	value, err := handler.UserConfigService.RollbackRemoteValue(id, req.Version, caller, app.GetActorFromContext(c))
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":      id,
+			"version": req.Version,
+		}).Error("rollback remote config value failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(value)
+}
@@ -0,0 +1,60 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/userconfig"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SaveRemoteValueRequest struct {
+	KeyID      string          `json:"key_id" binding:"required"`
+	Scope      string          `json:"scope" binding:"required,oneof=app tier card"`
+	ScopeValue string          `json:"scope_value" binding:"max=255"`
+	Value      json.RawMessage `json:"value" binding:"required"`
+}
+
+// SaveRemoteValue 新增或修改配置项在某个作用范围上的值，每次保存生成一个新的版本
+func (handler *Handler) SaveRemoteValue(c *gin.Context) {
+	var req SaveRemoteValueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	value, err := handler.UserConfigService.SaveRemoteValue(userconfig.SaveRemoteValueArgs{
+		KeyID:      req.KeyID,
+		Scope:      req.Scope,
+		ScopeValue: req.ScopeValue,
+		Value:      req.Value,
+		Caller:     caller,
+		Actor:      app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"key_id":      req.KeyID,
+			"scope":       req.Scope,
+			"scope_value": req.ScopeValue,
+		}).Error("save remote config value failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(value)
+}
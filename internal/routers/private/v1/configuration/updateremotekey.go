@@ -0,0 +1,56 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/userconfig"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateRemoteKeyRequest struct {
+	ID           string          `json:"id" binding:"required"`
+	DefaultValue json.RawMessage `json:"default_value"`
+	Description  string          `json:"description" binding:"max=255"`
+}
+
+// UpdateRemoteKey 修改配置项的默认值和说明
+func (handler *Handler) UpdateRemoteKey(c *gin.Context) {
+	var req UpdateRemoteKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	caller, err := handler.getCaller(app.GetUserInfoFromContext(c))
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.NoPermission.WithDetails(err.Error()))
+		return
+	}
+	err = handler.UserConfigService.UpdateRemoteKey(userconfig.UpdateRemoteKeyArgs{
+		ID:           req.ID,
+		DefaultValue: req.DefaultValue,
+		Description:  req.Description,
+		Caller:       caller,
+		Actor:        app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": req.ID,
+		}).Error("update remote config key failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,26 @@
+package event
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CountUnreadNotifications 当前用户的未读通知数量
+func (handler *Handler) CountUnreadNotifications(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	count, err := handler.EventService.CountUnread(userInfo.UserId)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("count unread notifications failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(gin.H{"count": count})
+}
@@ -0,0 +1,36 @@
+package event
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteNotificationTemplate 删除修改过的模板，恢复为默认模板
+func (handler *Handler) DeleteNotificationTemplate(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+	eventType := c.Param("type")
+	locale := c.Param("locale")
+
+	if err := handler.EventService.DeleteTemplate(eventType, locale, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"type":   eventType,
+			"locale": locale,
+			"userId": userInfo.UserId,
+		}).Error("delete notification template failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
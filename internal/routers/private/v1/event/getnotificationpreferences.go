@@ -0,0 +1,26 @@
+package event
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotificationPreferences 查询当前用户的站外通知偏好
+func (handler *Handler) GetNotificationPreferences(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	preferences, err := handler.EventService.GetPreferences(userInfo.UserId)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("get notification preferences failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(preferences)
+}
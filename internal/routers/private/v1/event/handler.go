@@ -0,0 +1,15 @@
+package event
+
+import (
+	"configuration-management/internal/biz/event"
+)
+
+type Handler struct {
+	EventService event.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		EventService: event.NewService(),
+	}
+}
@@ -0,0 +1,41 @@
+package event
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MarkNotificationsReadRequest struct {
+	IDs []int64 `json:"ids" binding:"max=500"` // 为空时标记所有通知
+}
+
+// MarkNotificationsRead 把当前用户的通知标记为已读
+func (handler *Handler) MarkNotificationsRead(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req MarkNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	count, err := handler.EventService.MarkRead(userInfo.UserId, req.IDs)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("mark notifications read failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(gin.H{"count": count})
+}
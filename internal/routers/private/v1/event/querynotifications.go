@@ -0,0 +1,51 @@
+package event
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/event"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryNotificationsRequest struct {
+	Type       string `form:"type" binding:"omitempty,oneof=low_stock low_quota batch_expiring abuse_flagged"`
+	UnreadOnly bool   `form:"unread_only"`
+	Page       int    `form:"page"`
+	Limit      int    `form:"limit" binding:"max=100"`
+}
+
+// QueryNotifications 分页查询当前用户的通知，按时间倒序
+func (handler *Handler) QueryNotifications(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req QueryNotificationsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.EventService.QueryEvents(event.QueryEventsArgs{
+		RemindUserID: userInfo.UserId,
+		Type:         req.Type,
+		UnreadOnly:   req.UnreadOnly,
+		Page:         req.Page,
+		Limit:        req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("query notifications failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
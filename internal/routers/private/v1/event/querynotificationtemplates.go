@@ -0,0 +1,21 @@
+package event
+
+import (
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryNotificationTemplates 查询所有类型和语言的站外通知模板
+func (handler *Handler) QueryNotificationTemplates(c *gin.Context) {
+	templates, err := handler.EventService.QueryTemplates()
+	if err != nil {
+		global.Logger.Error("query notification templates failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(templates, len(templates))
+}
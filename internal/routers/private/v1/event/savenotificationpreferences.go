@@ -0,0 +1,58 @@
+package event
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/event"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SaveNotificationPreferencesRequest struct {
+	Locale    string              `json:"locale"`
+	Email     string              `json:"email" binding:"max=255"`
+	IMKind    string              `json:"im_kind"`
+	IMWebhook string              `json:"im_webhook" binding:"max=512"`
+	Channels  map[string][]string `json:"channels"`
+}
+
+// SaveNotificationPreferences 设置当前用户的站外通知偏好，保存在用户配置中
+func (handler *Handler) SaveNotificationPreferences(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req SaveNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.EventService.SavePreferences(userInfo.UserId, event.Preferences{
+		Locale:    req.Locale,
+		Email:     req.Email,
+		IMKind:    req.IMKind,
+		IMWebhook: req.IMWebhook,
+		Channels:  req.Channels,
+	}, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("save notification preferences failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,57 @@
+package event
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/event"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SaveNotificationTemplateRequest struct {
+	Type    string `json:"type" binding:"required"`
+	Locale  string `json:"locale" binding:"required"`
+	Subject string `json:"subject" binding:"required,max=255"`
+	Body    string `json:"body" binding:"max=4096"`
+}
+
+// SaveNotificationTemplate 修改某个类型和语言的站外通知模板
+func (handler *Handler) SaveNotificationTemplate(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req SaveNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.EventService.SaveTemplate(event.SaveTemplateArgs{
+		Type:    req.Type,
+		Locale:  req.Locale,
+		Subject: req.Subject,
+		Body:    req.Body,
+		Actor:   app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("save notification template failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,132 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	streamPollInterval = 15 * time.Second // 没有收到进程内信号时轮询的间隔，同时作为心跳间隔
+	streamBatchSize    = 100
+)
+
+// StreamNotifications 通过 server-sent events 推送当前用户的新通知
+// 认证和其他接口一样使用 V-Token 请求头，浏览器原生的 EventSource 不能设置请求头，前端需要用 fetch 读取流。
+// 每条通知的事件名为 notification，id 为通知ID，断线重连时带上 Last-Event-ID 可以补发错过的通知；
+// 连接建立和已读状态变化时发送 unread 事件，内容为未读数量。
+func (handler *Handler) StreamNotifications(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var lastID int64
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("invalid Last-Event-ID"))
+			return
+		}
+		lastID = id
+	} else {
+		id, err := handler.EventService.GetLatestEventID(userInfo.UserId)
+		if err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"userId": userInfo.UserId,
+			}).Error("get latest notification failed", err)
+			app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+			return
+		}
+		lastID = id
+	}
+
+	// 长连接不受服务端写超时的限制
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		global.Logger.Warning("clear write deadline failed", err)
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	signal, cancel := handler.EventService.Subscribe(userInfo.UserId)
+	defer cancel()
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	unread := int64(-1)
+	first := true
+	c.Stream(func(w io.Writer) bool {
+		if !first {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case <-signal:
+			case <-ticker.C:
+			}
+		}
+		first = false
+
+		sent := false
+		events, err := handler.EventService.GetEventsAfter(userInfo.UserId, lastID, streamBatchSize)
+		if err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"userId": userInfo.UserId,
+			}).Error("stream notifications failed", err)
+			return false
+		}
+		for _, e := range events {
+			if err := writeEvent(w, strconv.FormatInt(e.ID, 10), "notification", e); err != nil {
+				return false
+			}
+			lastID = e.ID
+			sent = true
+		}
+
+		count, err := handler.EventService.CountUnread(userInfo.UserId)
+		if err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"userId": userInfo.UserId,
+			}).Error("count unread notifications failed", err)
+			return false
+		}
+		if count != unread {
+			if err := writeEvent(w, "", "unread", gin.H{"count": count}); err != nil {
+				return false
+			}
+			unread = count
+			sent = true
+		}
+
+		// 没有新内容时发送注释行作为心跳，避免代理断开空闲连接
+		if !sent {
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// writeEvent 写入一条 SSE 消息，id 为空时不更新客户端的 Last-Event-ID
+func writeEvent(w io.Writer, id string, name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
@@ -0,0 +1,25 @@
+package redeem
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/internal/biz/cardcheck"
+	"configuration-management/internal/biz/redeem"
+	"configuration-management/pkg/ratelimit"
+)
+
+type Handler struct {
+	RedeemService redeem.Service
+	CardCheck     cardcheck.Service
+	AbuseService  abuse.Service
+	RateLimiter   *ratelimit.Limiter
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		RedeemService: redeem.NewService(),
+		CardCheck:     cardcheck.NewService(),
+		AbuseService:  abuse.NewService(),
+		RateLimiter:   global.RateLimiter,
+	}
+}
@@ -0,0 +1,142 @@
+package redeem
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/redeem"
+	"configuration-management/pkg/captcha"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageData 兑换页面的数据，Info 为空时显示查询表单
+type pageData struct {
+	Title     string
+	Code      string
+	Error     string
+	Challenge captcha.Challenge
+	Info      *redeem.Info
+	Status    string       // 状态说明
+	Duration  string       // 有效时长
+	DeepLink  template.URL // 已经检查过协议的深度链接
+}
+
+var statusTexts = map[string]string{
+	redeem.StatusAvailable:   "可以兑换",
+	redeem.StatusActivated:   "已激活",
+	redeem.StatusExpired:     "已过期",
+	redeem.StatusUnavailable: "不可用",
+}
+
+// formatDuration 有效时长，例如 30天12小时
+func formatDuration(days, hours, minutes int) string {
+	var b strings.Builder
+	if days > 0 {
+		fmt.Fprintf(&b, "%d天", days)
+	}
+	if hours > 0 {
+		fmt.Fprintf(&b, "%d小时", hours)
+	}
+	if minutes > 0 {
+		fmt.Fprintf(&b, "%d分钟", minutes)
+	}
+	return b.String()
+}
+
+// safeDeepLink 深度链接通常是应用自定义的协议，html/template 默认会过滤，这里只排除可以执行脚本的协议
+func safeDeepLink(link string) template.URL {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "javascript", "vbscript", "data":
+		return ""
+	}
+	return template.URL(link)
+}
+
+func (handler *Handler) render(c *gin.Context, status int, data pageData) {
+	data.Title = handler.RedeemService.Title()
+	if data.Info == nil {
+		challenge, err := handler.RedeemService.Challenge()
+		if err != nil {
+			global.Logger.Error("create captcha challenge failed", err)
+			data.Error = "人机验证暂时不可用，请稍后再试"
+		}
+		data.Challenge = challenge
+	} else {
+		data.Status = statusTexts[data.Info.Status]
+		data.Duration = formatDuration(data.Info.Days, data.Info.Hours, data.Info.Minutes)
+		data.DeepLink = safeDeepLink(data.Info.DeepLink)
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Status(status)
+	if err := pageTemplate.Execute(c.Writer, data); err != nil {
+		global.Logger.Error("render redeem page failed", err)
+	}
+}
+
+var pageTemplate = template.Must(template.New("redeem").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{with .Challenge.ScriptURL}}<script src="{{.}}" async defer></script>{{end}}
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; background: #f5f6f8; margin: 0; color: #222; }
+main { max-width: 420px; margin: 40px auto; background: #fff; border-radius: 8px; padding: 24px; box-shadow: 0 1px 4px rgba(0,0,0,.08); }
+h1 { font-size: 20px; margin: 0 0 16px; }
+label { display: block; margin: 12px 0 4px; font-size: 14px; color: #555; }
+input[type=text] { width: 100%; box-sizing: border-box; padding: 10px; font-size: 16px; border: 1px solid #ccc; border-radius: 4px; }
+button, .button { display: block; width: 100%; box-sizing: border-box; margin-top: 16px; padding: 12px; font-size: 16px; border: 0; border-radius: 4px; background: #1677ff; color: #fff; text-align: center; text-decoration: none; }
+.error { color: #d4380d; margin: 8px 0; }
+dl { display: grid; grid-template-columns: 88px 1fr; gap: 8px; margin: 0; }
+dt { color: #888; }
+dd { margin: 0; word-break: break-all; }
+.code { font-family: monospace; font-size: 18px; }
+.muted { color: #888; font-size: 14px; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+{{if .Info}}
+<dl>
+<dt>激活码</dt><dd class="code">{{.Info.Value}}</dd>
+<dt>应用</dt><dd>{{if .Info.AppName}}{{.Info.AppName}}{{else}}{{.Info.AppID}}{{end}}</dd>
+<dt>状态</dt><dd>{{.Status}}</dd>
+{{with .Duration}}<dt>有效时长</dt><dd>{{.}}</dd>{{end}}
+{{with .Info.ExpiredAt}}<dt>到期时间</dt><dd>{{.Format "2006-01-02 15:04:05"}}</dd>{{end}}
+{{if .Info.Entitlements}}<dt>包含权益</dt><dd>{{range $i, $e := .Info.Entitlements}}{{if $i}}、{{end}}{{$e}}{{end}}</dd>{{end}}
+</dl>
+{{with .Info.Instructions}}<p>{{.}}</p>{{end}}
+{{with .DeepLink}}<a class="button" href="{{.}}">打开应用兑换</a>{{end}}
+<p class="muted"><a href="?">查询其他激活码</a></p>
+{{else}}
+<form method="post">
+<label for="code">激活码</label>
+<input type="text" id="code" name="code" value="{{.Code}}" autocomplete="off" autocapitalize="characters" required>
+{{if .Challenge.Question}}
+<label for="captcha_answer">请计算 {{.Challenge.Question}}</label>
+<input type="text" id="captcha_answer" name="captcha_answer" inputmode="numeric" autocomplete="off" required>
+<input type="hidden" name="captcha_token" value="{{.Challenge.Token}}">
+{{else if .Challenge.SiteKey}}
+<div class="{{.Challenge.WidgetClass}}" data-sitekey="{{.Challenge.SiteKey}}"></div>
+{{end}}
+<button type="submit">查询</button>
+</form>
+{{end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,114 @@
+package redeem
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/internal/biz/cardcheck"
+	"configuration-management/internal/biz/redeem"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+	"configuration-management/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedeemPage 兑换页面，扫描卡片上的二维码打开时 code 参数中带有激活码
+func (handler *Handler) RedeemPage(c *gin.Context) {
+	if !handler.RedeemService.Enabled() {
+		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails("未开启兑换页面"))
+		return
+	}
+	handler.render(c, http.StatusOK, pageData{Code: strings.TrimSpace(c.Query("code"))})
+}
+
+// Redeem 通过人机验证后查询激活码，展示应用、时长、权益和打开应用的链接，不会激活激活码
+func (handler *Handler) Redeem(c *gin.Context) {
+	if !handler.RedeemService.Enabled() {
+		app.NewResponse(c).ToErrorResponse(errcode.NotFound.WithDetails("未开启兑换页面"))
+		return
+	}
+	if err := c.Request.ParseForm(); err != nil {
+		handler.render(c, http.StatusBadRequest, pageData{Error: "请求格式错误"})
+		return
+	}
+	code := strings.TrimSpace(c.Request.PostForm.Get("code"))
+	if code == "" {
+		handler.render(c, http.StatusBadRequest, pageData{Error: "请输入激活码"})
+		return
+	}
+
+	ok, err := handler.RedeemService.VerifyChallenge(c.Request.Context(), c.Request.PostForm, c.ClientIP())
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"ip": c.ClientIP(),
+		}).Error("verify captcha failed", err)
+		handler.render(c, http.StatusServiceUnavailable, pageData{Code: code, Error: "人机验证暂时不可用，请稍后再试"})
+		return
+	}
+	if !ok {
+		handler.render(c, http.StatusBadRequest, pageData{Code: code, Error: "人机验证未通过，请重试"})
+		return
+	}
+
+	allowed, retryAfter, err := handler.RateLimiter.Allow(c.FullPath(), ratelimit.KeyCard, code)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": code,
+		}).Error("限流检查失败", err)
+	} else if !allowed {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		handler.render(c, http.StatusTooManyRequests, pageData{Code: code, Error: "查询过于频繁，请稍后再试"})
+		return
+	}
+
+	info, err := handler.RedeemService.Lookup(code)
+	notFound := errors.Is(err, errcode.CardNotFound)
+	if err != nil && !notFound {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": code,
+		}).Error("lookup card failed", err)
+		handler.render(c, http.StatusInternalServerError, pageData{Code: code, Error: "查询失败，请稍后再试"})
+		return
+	}
+
+	// 和检查可用性接口一样记录检查并进行滥用检测，防止通过兑换页面枚举激活码
+	available := !notFound && info.Status == redeem.StatusAvailable
+	if err := handler.CardCheck.CreateCardCheck(&cardcheck.CardCheck{
+		CardValue: code,
+		Available: available,
+		IP:        c.ClientIP(),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": code,
+		}).Error("create card check failed", err)
+	}
+	if _, err := handler.AbuseService.Evaluate(abuse.Event{
+		Type:      abuse.EventCheck,
+		CardValue: code,
+		IP:        c.ClientIP(),
+		Success:   available,
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"card_value": code,
+		}).Error("滥用检测失败", err)
+	}
+
+	if notFound {
+		handler.render(c, http.StatusNotFound, pageData{Code: code, Error: "激活码不存在"})
+		return
+	}
+	handler.render(c, http.StatusOK, pageData{Code: code, Info: &info})
+}
@@ -0,0 +1,54 @@
+package role
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/role"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=64"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// CreateRole 创建自定义角色
+func (handler *Handler) CreateRole(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.RoleService.CreateRole(role.CreateRoleArgs{
+		CreatorID:   userInfo.UserId,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("create role failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
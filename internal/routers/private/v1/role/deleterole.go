@@ -0,0 +1,36 @@
+package role
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteRole 删除自定义角色，仍有用户使用的角色不能删除
+func (handler *Handler) DeleteRole(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", id))
+		return
+	}
+
+	if err := handler.RoleService.DeleteRole(id); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id": id,
+		}).Error("delete role failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
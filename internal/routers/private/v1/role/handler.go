@@ -0,0 +1,15 @@
+package role
+
+import (
+	"configuration-management/internal/biz/role"
+)
+
+type Handler struct {
+	RoleService role.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		RoleService: role.NewService(),
+	}
+}
@@ -0,0 +1,44 @@
+package role
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/role"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryRoleListRequest struct {
+	Name  string `form:"name"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
+}
+
+// QueryRoleList 查询自定义角色列表
+func (handler *Handler) QueryRoleList(c *gin.Context) {
+	var req QueryRoleListRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.RoleService.QueryRoleList(role.QueryRoleListArgs{
+		Name:  req.Name,
+		Page:  req.Page,
+		Limit: req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query role list failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
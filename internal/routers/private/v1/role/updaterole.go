@@ -0,0 +1,50 @@
+package role
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/role"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateRoleRequest struct {
+	ID          string   `json:"id" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// UpdateRole 更新自定义角色的描述和权限
+func (handler *Handler) UpdateRole(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.RoleService.UpdateRole(role.UpdateRoleArgs{
+		ID:          req.ID,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("update role failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
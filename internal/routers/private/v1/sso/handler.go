@@ -0,0 +1,18 @@
+package sso
+
+import (
+	"configuration-management/internal/biz/session"
+	"configuration-management/internal/biz/sso"
+)
+
+type Handler struct {
+	SSOService     sso.Service
+	SessionService session.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		SSOService:     sso.NewService(),
+		SessionService: session.NewService(),
+	}
+}
@@ -0,0 +1,90 @@
+package sso
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/session"
+	"configuration-management/internal/biz/sso"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// OIDCCallback 身份提供方的回调，登录成功后签发和账号密码登录相同的 token
+func (handler *Handler) OIDCCallback(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+	if req.Error != "" || req.Code == "" {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("oidc callback returned error")
+		app.NewResponse(c).ToErrorResponse(errcode.UnauthorizedTokenError.WithDetails(req.Error, req.ErrorDescription))
+		return
+	}
+
+	targetUser, err := handler.SSOService.FinishLogin(c.Request.Context(), sso.FinishLoginArgs{
+		Code:  req.Code,
+		State: req.State,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"state": req.State,
+		}).Error("finish oidc login failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	sess, err := handler.SessionService.CreateSession(session.CreateSessionArgs{
+		UserID:    targetUser.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	// 生成 token
+	token, err := app.CreateToken(app.UserInfo{
+		UserId:    targetUser.ID,
+		Username:  targetUser.Username,
+		MaxCnt:    targetUser.MaxCnt,
+		Roles:     targetUser.Roles,
+		SessionID: sess.ID,
+	})
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.UnauthorizedTokenGenerate)
+		return
+	}
+
+	// 配置了前端地址时跳转回前端，token 放在 fragment 中，不会出现在服务器日志里
+	if redirectURL := global.OIDCSetting.FrontendRedirectURL; redirectURL != "" {
+		c.Redirect(http.StatusFound, redirectURL+"#token="+url.QueryEscape(token))
+		return
+	}
+
+	app.NewResponse(c).ToResponse(app.ResponseContent{
+		StatusCode: http.StatusOK,
+		Data:       map[string]interface{}{"token": token},
+	})
+}
@@ -0,0 +1,29 @@
+package sso
+
+import (
+	"errors"
+	"net/http"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCLogin 跳转到身份提供方进行登录
+func (handler *Handler) OIDCLogin(c *gin.Context) {
+	authURL, err := handler.SSOService.BeginLogin(c.Request.Context())
+	if err != nil {
+		global.Logger.Error("begin oidc login failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPasswordMD5 string `json:"current_password_md5" binding:"required"` // 当前密码的md5值
+	NewPassword        string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangePassword 确认当前密码后修改自己的密码，并吊销其他所有会话
+func (handler *Handler) ChangePassword(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.ChangePassword(user.ChangePasswordArgs{
+		ID:                 userInfo.UserId,
+		CurrentPasswordMD5: req.CurrentPasswordMD5,
+		NewPassword:        req.NewPassword,
+		Actor:              app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("change password failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	// 密码已经修改，保留当前会话，其他设备需要重新登录
+	if err := handler.SessionService.RevokeOtherSessions(userInfo.UserId, userInfo.SessionID); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("revoke other sessions failed", err)
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
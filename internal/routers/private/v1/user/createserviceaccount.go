@@ -0,0 +1,64 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateServiceAccountRequest struct {
+	Username     string           `json:"username" binding:"required"`
+	MaxCnt       int              `json:"max_cnt"`
+	Permissions  user.Permissions `json:"permissions"`
+	Apps         user.Apps        `json:"apps"`
+	Introduction string           `json:"introduction"`
+}
+
+// CreateServiceAccount 创建服务账号，服务账号不能登录，只能通过 API Key 访问
+func (handler *Handler) CreateServiceAccount(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.CreateUser(user.CreateUserArgs{
+		CreatorID:      userInfo.UserId,
+		Username:       req.Username,
+		MaxCnt:         req.MaxCnt,
+		Apps:           req.Apps,
+		Permissions:    req.Permissions,
+		Introduction:   req.Introduction,
+		ServiceAccount: true,
+		Actor:          app.GetActorFromContext(c),
+
+		GrantorPermissions: app.GetPermissionsFromContext(c),
+		ViaAPIKey:          app.IsAPIKeyRequest(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("create service account failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -40,6 +40,10 @@ func (handler *Handler) CreateUser(c *gin.Context) {
 		Apps:         req.Apps,
 		Permissions:  req.Permissions,
 		Introduction: req.Introduction,
+		Actor:        app.GetActorFromContext(c),
+
+		GrantorPermissions: app.GetPermissionsFromContext(c),
+		ViaAPIKey:          app.IsAPIKeyRequest(c),
 	}); err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"req":    req,
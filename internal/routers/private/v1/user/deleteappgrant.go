@@ -0,0 +1,53 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteAppGrantRequest struct {
+	UserID string `form:"user_id" binding:"required"`
+	AppID  string `form:"app_id" binding:"required"`
+}
+
+// DeleteAppGrant 删除下级用户在某个应用上的限制
+func (handler *Handler) DeleteAppGrant(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req DeleteAppGrantRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.DeleteAppGrant(user.DeleteAppGrantArgs{
+		OperatorID: userInfo.UserId,
+		UserID:     req.UserID,
+		AppID:      req.AppID,
+		Actor:      app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("delete app grant failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,55 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteUserRequest struct {
+	Cascade    string `form:"cascade" binding:"required,oneof=lock transfer orphan"` // 激活码的处理方式
+	TransferTo string `form:"transfer_to"`                                           // cascade 为 transfer 时接收激活码的用户ID
+}
+
+// DeleteUser 删除下级用户
+func (handler *Handler) DeleteUser(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req DeleteUserRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.DeleteUser(user.DeleteUserArgs{
+		OperatorID:   userInfo.UserId,
+		ID:           c.Param("id"),
+		Cascade:      req.Cascade,
+		TransferToID: req.TransferTo,
+		Actor:        app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"id":     c.Param("id"),
+			"userId": userInfo.UserId,
+		}).Error("delete user failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
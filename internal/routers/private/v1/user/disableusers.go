@@ -0,0 +1,58 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DisableUsersRequest struct {
+	IDs                []string `json:"ids" binding:"required,min=1"`
+	IncludeDescendants bool     `json:"include_descendants"`
+}
+
+type DisableUsersResponse struct {
+	LockedCards int64 `json:"locked_cards"` // 被锁定的未使用激活码数量
+}
+
+// DisableUsers 批量停封用户，并锁定他们所有未使用的激活码
+func (handler *Handler) DisableUsers(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req DisableUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	locked, err := handler.UserService.DisableUsers(user.DisableUsersArgs{
+		OperatorID:         userInfo.UserId,
+		IDs:                req.IDs,
+		IncludeDescendants: req.IncludeDescendants,
+		Actor:              app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("disable users failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(DisableUsersResponse{LockedCards: locked})
+}
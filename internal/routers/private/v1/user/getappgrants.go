@@ -0,0 +1,46 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetAppGrantsRequest struct {
+	UserID string `form:"user_id"`
+}
+
+// GetAppGrants 查询用户在各个应用上的授权，不传 user_id 时查询自己
+func (handler *Handler) GetAppGrants(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req GetAppGrantsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	grants, err := handler.UserService.GetAppGrants(userInfo.UserId, req.UserID)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("get app grants failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(grants, len(grants))
+}
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMe 查看自己的资料
+func (handler *Handler) GetMe(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	view, err := handler.UserService.GetUserInfo(user.GetUserInfoArgs{UserId: userInfo.UserId})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("get me failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(view)
+}
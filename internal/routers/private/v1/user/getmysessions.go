@@ -0,0 +1,36 @@
+package user
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/session"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MySession struct {
+	session.Session
+	Current bool `json:"current"` // 是否为发起请求的会话
+}
+
+// GetMySessions 查看自己当前有效的登录会话
+func (handler *Handler) GetMySessions(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	sessions, err := handler.SessionService.GetActiveSessions(userInfo.UserId)
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+		}).Error("get my sessions failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	list := make([]MySession, 0, len(sessions))
+	for _, s := range sessions {
+		list = append(list, MySession{Session: s, Current: s.ID == userInfo.SessionID})
+	}
+	app.NewResponse(c).ToResponseList(list, len(list))
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GrantQuotaRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	Amount int    `json:"amount" binding:"required"` // 负数表示收回
+	Reason string `json:"reason"`
+}
+
+// GrantQuota 给下级用户增加或收回额度
+func (handler *Handler) GrantQuota(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req GrantQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.GrantQuota(user.GrantQuotaArgs{
+		OperatorID: userInfo.UserId,
+		UserID:     req.UserID,
+		Amount:     req.Amount,
+		Reason:     req.Reason,
+		Actor:      app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("grant quota failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -1,15 +1,18 @@
 package user
 
 import (
+	"configuration-management/internal/biz/session"
 	"configuration-management/internal/biz/user"
 )
 
 type Handler struct {
-	UserService user.Service
+	UserService    user.Service
+	SessionService session.Service
 }
 
 func NewHandler() *Handler {
 	return &Handler{
-		UserService: user.NewService(),
+		UserService:    user.NewService(),
+		SessionService: session.NewService(),
 	}
 }
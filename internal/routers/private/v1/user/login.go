@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/session"
 	"configuration-management/internal/biz/user"
 	"configuration-management/pkg/app"
 	"configuration-management/pkg/errcode"
@@ -47,12 +48,24 @@ func (handler *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	// 每次登录创建一个会话，用户可以在 /me/sessions 中查看和吊销
+	sess, err := handler.SessionService.CreateSession(session.CreateSessionArgs{
+		UserID:    targetUser.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
 	// 生成 token
 	token, err := app.CreateToken(app.UserInfo{
-		UserId:   targetUser.ID,
-		Username: targetUser.Username,
-		MaxCnt:   targetUser.MaxCnt,
-		Roles:    targetUser.Roles,
+		UserId:    targetUser.ID,
+		Username:  targetUser.Username,
+		MaxCnt:    targetUser.MaxCnt,
+		Roles:     targetUser.Roles,
+		SessionID: sess.ID,
 	})
 	if err != nil {
 		return
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryQuotaLedgerRequest struct {
+	UserID string `form:"user_id"`
+	Type   string `form:"type"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// QueryQuotaLedger 查询额度余额和流水，不传 user_id 时查询自己
+func (handler *Handler) QueryQuotaLedger(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req QueryQuotaLedgerRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.UserService.QueryQuotaLedger(user.QueryQuotaLedgerArgs{
+		ViewerID: userInfo.UserId,
+		UserID:   req.UserID,
+		Type:     req.Type,
+		Page:     req.Page,
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query quota ledger failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(map[string]any{
+		"balance": result.Balance,
+		"items":   result.List,
+		"pager": app.Pager{
+			Page:     app.GetPage(c),
+			PageSize: app.GetPageSize(c),
+			Total:    result.Total,
+		},
+	})
+}
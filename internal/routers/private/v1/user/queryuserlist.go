@@ -18,6 +18,7 @@ type QueryUserListRequest struct {
 }
 
 func (handler *Handler) QueryUserList(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
 
 	var req QueryUserListRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -29,6 +30,7 @@ func (handler *Handler) QueryUserList(c *gin.Context) {
 	}
 
 	result, err := handler.UserService.QueryUserList(user.QueryUserListArgs{
+		ViewerID: userInfo.UserId,
 		Username: req.Username,
 		Status:   req.Status,
 		Page:     req.Page,
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"errors"
+
 	"configuration-management/global"
 	"configuration-management/internal/biz/user"
 	"configuration-management/pkg/app"
@@ -16,6 +18,8 @@ type ResetPasswordRequest struct {
 }
 
 func (handler *Handler) ResetPassword(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
 	var request ResetPasswordRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		global.Logger.WithFields(logger.Fields{
@@ -26,13 +30,21 @@ func (handler *Handler) ResetPassword(c *gin.Context) {
 	}
 
 	if err := handler.UserService.ResetPassword(user.ResetPasswordArgs{
-		ID:       request.ID,
-		Password: request.Password,
+		OperatorID: userInfo.UserId,
+		ID:         request.ID,
+		Password:   request.Password,
+		ViaAPIKey:  app.IsAPIKeyRequest(c),
+		Actor:      app.GetActorFromContext(c),
 	}); err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"request": request,
 			"error":   err.Error(),
 		}).Error("重置密码失败", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
 		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails("重置密码失败"))
 		return
 	}
@@ -0,0 +1,37 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/session"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeMySession 吊销自己的某个登录会话，吊销后该会话的 token 立即失效
+func (handler *Handler) RevokeMySession(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	if err := handler.SessionService.RevokeSession(session.RevokeSessionArgs{
+		UserID: userInfo.UserId,
+		ID:     c.Param("id"),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"userId": userInfo.UserId,
+			"id":     c.Param("id"),
+		}).Error("revoke my session failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetAppGrantRequest struct {
+	UserID      string   `json:"user_id" binding:"required"`
+	AppID       string   `json:"app_id" binding:"required"`
+	Quota       int      `json:"quota"`        // -1 表示不限制
+	TimeTypes   []string `json:"time_types"`   // 为空表示不限制
+	MaxDuration int      `json:"max_duration"` // 单位为分钟，0 表示不限制
+}
+
+// SetAppGrant 设置下级用户在某个应用上的额度、时间类型和最大有效时长
+func (handler *Handler) SetAppGrant(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req SetAppGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.SetAppGrant(user.SetAppGrantArgs{
+		OperatorID:  userInfo.UserId,
+		UserID:      req.UserID,
+		AppID:       req.AppID,
+		Quota:       req.Quota,
+		TimeTypes:   req.TimeTypes,
+		MaxDuration: req.MaxDuration,
+		Actor:       app.GetActorFromContext(c),
+
+		GrantorPermissions: app.GetPermissionsFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("set app grant failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
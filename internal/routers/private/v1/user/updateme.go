@@ -0,0 +1,53 @@
+package user
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/user"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateMeRequest struct {
+	Avatar       string `json:"avatar" binding:"max=255"`
+	Introduction string `json:"introduction" binding:"max=255"`
+}
+
+// UpdateMe 更新自己的头像和介绍
+func (handler *Handler) UpdateMe(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req UpdateMeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.UserService.UpdateProfile(user.UpdateProfileArgs{
+		ID:           userInfo.UserId,
+		Avatar:       req.Avatar,
+		Introduction: req.Introduction,
+		Actor:        app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("update me failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
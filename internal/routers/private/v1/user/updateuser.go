@@ -46,6 +46,9 @@ func (handler *Handler) UpdateUser(c *gin.Context) {
 		Permissions:  req.Permissions,
 		Introduction: req.Introduction,
 		Status:       req.Status,
+		Actor:        app.GetActorFromContext(c),
+
+		GrantorPermissions: app.GetPermissionsFromContext(c),
 	}); err != nil {
 		global.Logger.WithFields(logger.Fields{
 			"req":    req,
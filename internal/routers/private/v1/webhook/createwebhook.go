@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/webhook"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateWebhookRequest struct {
+	AppID  string   `json:"app_id" binding:"required"`
+	URL    string   `json:"url" binding:"required,max=512"`
+	Events []string `json:"events" binding:"required"`
+}
+
+// CreateWebhook 为应用创建 webhook 订阅，签名密钥只在这里返回一次
+func (handler *Handler) CreateWebhook(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	sub, secret, err := handler.WebhookService.CreateSubscription(webhook.CreateSubscriptionArgs{
+		AppID:  req.AppID,
+		URL:    req.URL,
+		Events: req.Events,
+		Actor:  app.GetActorFromContext(c),
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("create webhook failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(map[string]any{
+		"webhook": sub,
+		"secret":  secret,
+	})
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteWebhook 删除订阅，尚未投递的事件会进入死信列表
+func (handler *Handler) DeleteWebhook(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	id := c.Param("id")
+	if id == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", id))
+		return
+	}
+
+	if err := handler.WebhookService.DeleteSubscription(id, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":     id,
+			"userId": userInfo.UserId,
+		}).Error("delete webhook failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
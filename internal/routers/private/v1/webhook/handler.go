@@ -0,0 +1,15 @@
+package webhook
+
+import (
+	"configuration-management/internal/biz/webhook"
+)
+
+type Handler struct {
+	WebhookService webhook.Service
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		WebhookService: webhook.NewService(),
+	}
+}
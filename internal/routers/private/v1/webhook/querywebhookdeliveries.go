@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/webhook"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryWebhookDeliveriesRequest struct {
+	SubscriptionID string `form:"subscription_id"`
+	EventType      string `form:"event_type"`
+	Status         string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+	Page           int    `form:"page"`
+	Limit          int    `form:"limit" binding:"max=100"`
+}
+
+// QueryWebhookDeliveries 分页查询投递记录，status=dead 为死信列表
+func (handler *Handler) QueryWebhookDeliveries(c *gin.Context) {
+	var req QueryWebhookDeliveriesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.WebhookService.QueryDeliveries(webhook.QueryDeliveriesArgs{
+		SubscriptionID: req.SubscriptionID,
+		EventType:      req.EventType,
+		Status:         req.Status,
+		Page:           req.Page,
+		Limit:          req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query webhook deliveries failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
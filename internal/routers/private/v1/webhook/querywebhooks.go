@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"configuration-management/global"
+	"configuration-management/internal/biz/webhook"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueryWebhooksRequest struct {
+	AppID string `form:"app_id"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit" binding:"max=100"`
+}
+
+// QueryWebhooks 分页查询 webhook 订阅，不返回签名密钥
+func (handler *Handler) QueryWebhooks(c *gin.Context) {
+	var req QueryWebhooksRequest
+	if err := c.ShouldBind(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	result, err := handler.WebhookService.QuerySubscriptions(webhook.QuerySubscriptionsArgs{
+		AppID: req.AppID,
+		Page:  req.Page,
+		Limit: req.Limit,
+	})
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req": req,
+		}).Error("query webhooks failed", err)
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ToResponseList(result.List, result.Total)
+}
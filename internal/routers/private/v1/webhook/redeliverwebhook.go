@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedeliverWebhook 手动重新投递，重新计算尝试次数
+func (handler *Handler) RedeliverWebhook(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", c.Param("id")))
+		return
+	}
+
+	if err := handler.WebhookService.Redeliver(id, app.GetActorFromContext(c)); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":     id,
+			"userId": userInfo.UserId,
+		}).Error("redeliver webhook failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RotateWebhookSecret 重新生成签名密钥，旧密钥立即失效，新密钥只在这里返回一次
+func (handler *Handler) RotateWebhookSecret(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	id := c.Param("id")
+	if id == "" {
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails("id", id))
+		return
+	}
+
+	secret, err := handler.WebhookService.RotateSecret(id, app.GetActorFromContext(c))
+	if err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"id":     id,
+			"userId": userInfo.UserId,
+		}).Error("rotate webhook secret failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK(map[string]any{
+		"secret": secret,
+	})
+}
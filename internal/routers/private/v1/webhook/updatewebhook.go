@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"errors"
+
+	"configuration-management/global"
+	"configuration-management/internal/biz/webhook"
+	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateWebhookRequest struct {
+	ID      string   `json:"id" binding:"required"`
+	URL     string   `json:"url" binding:"required,max=512"`
+	Events  []string `json:"events" binding:"required"`
+	Enabled bool     `json:"enabled"`
+}
+
+// UpdateWebhook 修改订阅的接收地址、事件类型，或者启用、停用订阅
+func (handler *Handler) UpdateWebhook(c *gin.Context) {
+	userInfo := app.GetUserInfoFromContext(c)
+
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":      req,
+			"userInfo": userInfo,
+		}).Error("invalid params", err)
+		app.NewResponse(c).ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
+
+	if err := handler.WebhookService.UpdateSubscription(webhook.UpdateSubscriptionArgs{
+		ID:      req.ID,
+		URL:     req.URL,
+		Events:  req.Events,
+		Enabled: req.Enabled,
+		Actor:   app.GetActorFromContext(c),
+	}); err != nil {
+		global.Logger.WithFields(logger.Fields{
+			"req":    req,
+			"userId": userInfo.UserId,
+		}).Error("update webhook failed", err)
+		var e *errcode.Error
+		if errors.As(err, &e) {
+			app.NewResponse(c).ToErrorResponse(e)
+			return
+		}
+		app.NewResponse(c).ToErrorResponse(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+
+	app.NewResponse(c).ResponseOK()
+}
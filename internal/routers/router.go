@@ -6,11 +6,30 @@ import (
 	"configuration-management/internal/routers/private/v1/apps"
 
 	"configuration-management/global"
+	abusebiz "configuration-management/internal/biz/abuse"
+	apikeybiz "configuration-management/internal/biz/apikey"
+	"configuration-management/internal/biz/permissions"
+	sessionbiz "configuration-management/internal/biz/session"
+	userbiz "configuration-management/internal/biz/user"
+	"configuration-management/internal/routers/private/v1/abuse"
+	"configuration-management/internal/routers/private/v1/activationattempt"
+	"configuration-management/internal/routers/private/v1/analytics"
+	"configuration-management/internal/routers/private/v1/apikey"
+	"configuration-management/internal/routers/private/v1/appversion"
+	"configuration-management/internal/routers/private/v1/audit"
 	"configuration-management/internal/routers/private/v1/card"
 	"configuration-management/internal/routers/private/v1/configuration"
+	"configuration-management/internal/routers/private/v1/event"
+	"configuration-management/internal/routers/private/v1/redeem"
+	"configuration-management/internal/routers/private/v1/role"
+	"configuration-management/internal/routers/private/v1/sso"
 	"configuration-management/internal/routers/private/v1/user"
+	"configuration-management/internal/routers/private/v1/webhook"
 	"configuration-management/pkg/app"
+	"configuration-management/pkg/errcode"
 	"configuration-management/pkg/logger"
+	"configuration-management/pkg/ratelimit"
+	"configuration-management/utils"
 
 	"github.com/gin-gonic/gin"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -19,26 +38,48 @@ import (
 
 func NewRouter() *gin.Engine {
 	r := gin.New()
+	// 只信任配置的反向代理，避免客户端伪造 X-Forwarded-For
+	if err := r.SetTrustedProxies(global.ServerSetting.TrustedProxies); err != nil {
+		global.Logger.Error("设置信任的代理失败", err)
+	}
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(corsMiddleware())
+	r.Use(requestIDMiddleware())
 
 	// Swagger Docs
 	r.GET("/TIPCRFNFJJ/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Private router
 	privateGroup := r.Group("/private/v1")
-	privateGroup.Use(jwtAuthMiddleware())
+	privateGroup.Use(authMiddleware(apikeybiz.NewService(), sessionbiz.NewService()))
+	privateGroup.Use(permissionMiddleware(userbiz.NewService()))
 
 	// Public router
 	publicGroup := r.Group("/public/v1")
 	publicGroup.Use(corsMiddleware())
+	publicGroup.Use(rateLimitMiddleware(global.RateLimiter))
 
 	{
 		// Configuration
 		configHandler := configuration.NewHandler()
 		privateGroup.GET("/configuration", configHandler.GetConfiguration)
 		privateGroup.POST("/configuration", configHandler.CreateConfiguration)
+
+		// remote config
+		privateGroup.GET("/remote-config/keys", configHandler.QueryRemoteKeys)
+		privateGroup.POST("/remote-config/key", configHandler.CreateRemoteKey)
+		privateGroup.PUT("/remote-config/key", configHandler.UpdateRemoteKey)
+		privateGroup.DELETE("/remote-config/key/:id", configHandler.DeleteRemoteKey)
+		privateGroup.GET("/remote-config/values", configHandler.QueryRemoteValues)
+		privateGroup.PUT("/remote-config/value", configHandler.SaveRemoteValue)
+		privateGroup.DELETE("/remote-config/value/:id", configHandler.DeleteRemoteValue)
+		privateGroup.GET("/remote-config/value/:id/history", configHandler.GetRemoteValueHistory)
+		privateGroup.POST("/remote-config/value/:id/rollback", configHandler.RollbackRemoteValue)
+
+		remoteConfigPublicGroup := publicGroup.Group("")
+		remoteConfigPublicGroup.Use(ipBlockMiddleware(abusebiz.NewService()))
+		remoteConfigPublicGroup.GET("/remote-config", configHandler.FetchRemoteConfig)
 	}
 
 	{
@@ -47,18 +88,28 @@ func NewRouter() *gin.Engine {
 
 		// public
 		cardPublicGroup := publicGroup.Group("")
+		cardPublicGroup.Use(ipBlockMiddleware(abusebiz.NewService()))
 		cardPublicGroup.POST("/identity", cardHandler.Identity)
 		cardPublicGroup.POST("/activate", cardHandler.Activate)
 		cardPublicGroup.POST("/check-availability", cardHandler.CheckAvailability)
 
 		// private
 		privateGroup.GET("/card/:value", cardHandler.GetCardByValue)
+		privateGroup.GET("/card/:value/history", cardHandler.GetCardHistory)
+		privateGroup.GET("/card/:value/qrcode", cardHandler.GetCardQRCode)
 		privateGroup.GET("/cards", cardHandler.GetCards)
 		privateGroup.GET("/export-cards", cardHandler.Export)
+		privateGroup.POST("/card-export-jobs", cardHandler.CreateExportJob)
+		privateGroup.GET("/card-export-jobs", cardHandler.QueryExportJobs)
+		privateGroup.GET("/card-export-job/:id", cardHandler.GetExportJob)
+		privateGroup.GET("/card-export-job/:id/download", cardHandler.DownloadExportJob)
+		privateGroup.GET("/card-sheet", cardHandler.RenderCardSheet)
+		privateGroup.GET("/card-sheet-layouts", cardHandler.GetSheetLayouts)
 		privateGroup.POST("/card", cardHandler.CreateCard)
 		privateGroup.PUT("/card", cardHandler.UpdateCard)
 		privateGroup.DELETE("/card/:value", cardHandler.DeleteCard)
 		privateGroup.POST("/cards", cardHandler.CreateCards)
+		privateGroup.POST("/cards/import", cardHandler.ImportCards)
 		privateGroup.DELETE("/cards", cardHandler.DeleteCardsByValues)
 		privateGroup.PUT("/batch-update-card-status", cardHandler.BatchUpdateStatus)
 		privateGroup.GET("/batch-query", cardHandler.BatchQuery)
@@ -66,15 +117,36 @@ func NewRouter() *gin.Engine {
 		privateGroup.PUT("/set-expired-at", cardHandler.SetCardExpiredAt)
 	}
 
+	{
+		// Redeem
+		redeemHandler := redeem.NewHandler()
+		redeemGroup := publicGroup.Group("")
+		redeemGroup.Use(ipBlockMiddleware(abusebiz.NewService()))
+		redeemGroup.GET("/redeem", redeemHandler.RedeemPage)
+		redeemGroup.POST("/redeem", redeemHandler.Redeem)
+	}
+
 	{
 		// App
 		appsHandler := apps.NewHandler()
 		privateGroup.GET("/apps", appsHandler.QueryAppList)
 		privateGroup.POST("/app", appsHandler.CreateApp)
 		privateGroup.PUT("/app", appsHandler.UpdateApp)
+		privateGroup.PUT("/app/:id/settings", appsHandler.UpdateAppSettings)
+		privateGroup.PUT("/app/:id/archive", appsHandler.ArchiveApp)
+		privateGroup.DELETE("/app/:id", appsHandler.DeleteApp)
 		privateGroup.GET("/app-options", appsHandler.QueryAppOptions)
 	}
 
+	{
+		// App Version
+		appVersionHandler := appversion.NewHandler()
+		privateGroup.GET("/app-versions", appVersionHandler.QueryAppVersions)
+		privateGroup.POST("/app-version", appVersionHandler.CreateAppVersion)
+		privateGroup.DELETE("/app-version/:id", appVersionHandler.DeleteAppVersion)
+		privateGroup.GET("/app-versions/devices", appVersionHandler.DeviceBreakdown)
+	}
+
 	{
 		// User
 		userHandler := user.NewHandler()
@@ -84,12 +156,104 @@ func NewRouter() *gin.Engine {
 
 		// private
 		privateGroup.GET("/get-user-info", userHandler.GetUserInfo)
-		userPrivateGroup := privateGroup.Group("")
-		userPrivateGroup.Use(userManageAuthMiddleware())
-		userPrivateGroup.GET("/users", userHandler.QueryUserList)
-		userPrivateGroup.POST("/user", userHandler.CreateUser)
-		userPrivateGroup.PUT("/user", userHandler.UpdateUser)
-		userPrivateGroup.POST("/reset-password", userHandler.ResetPassword)
+		privateGroup.GET("/me", userHandler.GetMe)
+		privateGroup.PUT("/me", userHandler.UpdateMe)
+		privateGroup.PUT("/me/password", userHandler.ChangePassword)
+		privateGroup.GET("/me/sessions", userHandler.GetMySessions)
+		privateGroup.DELETE("/me/sessions/:id", userHandler.RevokeMySession)
+		privateGroup.GET("/users", userHandler.QueryUserList)
+		privateGroup.POST("/user", userHandler.CreateUser)
+		privateGroup.PUT("/user", userHandler.UpdateUser)
+		privateGroup.DELETE("/user/:id", userHandler.DeleteUser)
+		privateGroup.POST("/disable-users", userHandler.DisableUsers)
+		privateGroup.POST("/reset-password", userHandler.ResetPassword)
+		privateGroup.PUT("/user-roles", userHandler.SetUserRoles)
+		privateGroup.POST("/user-quota", userHandler.GrantQuota)
+		privateGroup.GET("/quota-ledger", userHandler.QueryQuotaLedger)
+		privateGroup.GET("/user-app-grants", userHandler.GetAppGrants)
+		privateGroup.PUT("/user-app-grant", userHandler.SetAppGrant)
+		privateGroup.DELETE("/user-app-grant", userHandler.DeleteAppGrant)
+		privateGroup.POST("/service-account", userHandler.CreateServiceAccount)
+	}
+
+	{
+		// SSO
+		ssoHandler := sso.NewHandler()
+		publicGroup.GET("/oidc/login", ssoHandler.OIDCLogin)
+		publicGroup.GET("/oidc/callback", ssoHandler.OIDCCallback)
+	}
+
+	{
+		// Role
+		roleHandler := role.NewHandler()
+		privateGroup.GET("/roles", roleHandler.QueryRoleList)
+		privateGroup.POST("/role", roleHandler.CreateRole)
+		privateGroup.PUT("/role", roleHandler.UpdateRole)
+		privateGroup.DELETE("/role/:id", roleHandler.DeleteRole)
+	}
+
+	{
+		// API Key
+		apiKeyHandler := apikey.NewHandler()
+		privateGroup.GET("/api-keys", apiKeyHandler.QueryAPIKeys)
+		privateGroup.POST("/api-key", apiKeyHandler.CreateAPIKey)
+		privateGroup.DELETE("/api-key/:id", apiKeyHandler.RevokeAPIKey)
+	}
+
+	{
+		// Audit
+		auditHandler := audit.NewHandler()
+		privateGroup.GET("/audit-logs", auditHandler.QueryAuditLogs)
+		privateGroup.GET("/audit-logs/export", auditHandler.ExportAuditLogs)
+	}
+
+	{
+		// Activation Attempt
+		activationAttemptHandler := activationattempt.NewHandler()
+		privateGroup.GET("/activation-attempts", activationAttemptHandler.QueryActivationAttempts)
+	}
+
+	{
+		// Analytics
+		analyticsHandler := analytics.NewHandler()
+		privateGroup.GET("/analytics/activations", analyticsHandler.ActivationSeries)
+		privateGroup.GET("/analytics/conversion", analyticsHandler.Conversion)
+		privateGroup.GET("/analytics/churn", analyticsHandler.Churn)
+	}
+
+	{
+		// Abuse
+		abuseHandler := abuse.NewHandler()
+		privateGroup.GET("/abuse-flags", abuseHandler.QueryAbuseFlags)
+		privateGroup.PUT("/abuse-flag", abuseHandler.ReviewAbuseFlag)
+		privateGroup.GET("/ip-blocks", abuseHandler.GetIPBlocks)
+		privateGroup.DELETE("/ip-block/:ip", abuseHandler.UnblockIP)
+	}
+
+	{
+		// Webhook
+		webhookHandler := webhook.NewHandler()
+		privateGroup.GET("/webhooks", webhookHandler.QueryWebhooks)
+		privateGroup.POST("/webhook", webhookHandler.CreateWebhook)
+		privateGroup.PUT("/webhook", webhookHandler.UpdateWebhook)
+		privateGroup.DELETE("/webhook/:id", webhookHandler.DeleteWebhook)
+		privateGroup.POST("/webhook/:id/rotate-secret", webhookHandler.RotateWebhookSecret)
+		privateGroup.GET("/webhook-deliveries", webhookHandler.QueryWebhookDeliveries)
+		privateGroup.POST("/webhook-delivery/:id/redeliver", webhookHandler.RedeliverWebhook)
+	}
+
+	{
+		// Notification
+		eventHandler := event.NewHandler()
+		privateGroup.GET("/notifications", eventHandler.QueryNotifications)
+		privateGroup.GET("/notifications/unread-count", eventHandler.CountUnreadNotifications)
+		privateGroup.PUT("/notifications/read", eventHandler.MarkNotificationsRead)
+		privateGroup.GET("/notifications/stream", eventHandler.StreamNotifications)
+		privateGroup.GET("/notifications/preferences", eventHandler.GetNotificationPreferences)
+		privateGroup.PUT("/notifications/preferences", eventHandler.SaveNotificationPreferences)
+		privateGroup.GET("/notification-templates", eventHandler.QueryNotificationTemplates)
+		privateGroup.PUT("/notification-template", eventHandler.SaveNotificationTemplate)
+		privateGroup.DELETE("/notification-template/:type/:locale", eventHandler.DeleteNotificationTemplate)
 	}
 
 	return r
@@ -99,9 +263,9 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, V-Token")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, V-Token, X-API-Key, X-Request-ID, Last-Event-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Type, Content-Length, Authorization, V-Token")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Type, Content-Length, Authorization, V-Token, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -113,8 +277,85 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-func jwtAuthMiddleware() gin.HandlerFunc {
+// 请求ID中间件，沿用客户端传入的 X-Request-ID，没有时生成一个，并写入响应头
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" || len(requestID) > 64 {
+			requestID = utils.GenerateUUID()
+		}
+		c.Set(app.RequestIDKey, requestID)
+		c.Writer.Header().Set("X-Request-ID", requestID)
+		c.Next()
+	}
+}
+
+// 限流中间件，按客户端IP限流，只对配置了规则的路由生效
+// 按激活码限流需要先解析请求，由各个接口自己处理
+func rateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, retryAfter, err := limiter.Allow(c.FullPath(), ratelimit.KeyIP, c.ClientIP())
+		if err != nil {
+			// 限流存储不可用时放行
+			global.Logger.WithFields(logger.Fields{
+				"ip":   c.ClientIP(),
+				"path": c.FullPath(),
+			}).Error("限流检查失败", err)
+			return
+		}
+		if !ok {
+			app.NewResponse(c).ToTooManyRequestsResponse(retryAfter)
+			c.Abort()
+			return
+		}
+	}
+}
+
+// IP封禁中间件，被滥用检测封禁的IP不能访问公开的激活码接口
+func ipBlockMiddleware(abuseService abusebiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		blocked, err := abuseService.IsIPBlocked(c.ClientIP())
+		if err != nil {
+			// 查询失败时放行，避免影响正常用户
+			global.Logger.WithFields(logger.Fields{
+				"ip": c.ClientIP(),
+			}).Error("查询IP封禁状态失败", err)
+			return
+		}
+		if blocked {
+			app.NewResponse(c).ToErrorResponse(errcode.IPBlocked)
+			c.Abort()
+			return
+		}
+	}
+}
+
+// 鉴权中间件，支持登录获得的 V-Token 和 X-API-Key 两种方式
+func authMiddleware(apiKeyService apikeybiz.Service, sessionService sessionbiz.Service) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		if key := context.GetHeader("X-API-Key"); key != "" {
+			apiKey, owner, err := apiKeyService.Authenticate(key)
+			if err != nil {
+				global.Logger.WithFields(logger.Fields{
+					"path": context.FullPath(),
+				}).Error("api key authenticate failed", err)
+				context.AbortWithStatusJSON(http.StatusUnauthorized, app.ResponseContent{
+					StatusCode: http.StatusUnauthorized,
+				})
+				return
+			}
+
+			// 不带 root 角色，避免 root 用户的 Key 绕过 Key 的权限列表
+			context.Set(app.UserInfoKey, app.UserInfo{
+				UserId:   owner.ID,
+				Username: owner.Username,
+				MaxCnt:   owner.MaxCnt,
+				Roles:    apikeybiz.KeyRoles(owner),
+			})
+			context.Set(app.APIKeyPermissionsKey, apiKey.Permissions)
+			return
+		}
+
 		token := context.GetHeader("V-Token")
 		if token == "" {
 			context.AbortWithStatusJSON(http.StatusUnauthorized, app.ResponseContent{
@@ -146,13 +387,24 @@ func jwtAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查登录会话是否已被吊销
+		if err := sessionService.Validate(userInfo.SessionID); err != nil {
+			global.Logger.WithFields(logger.Fields{
+				"user_info": userInfo,
+			}).Error("session is invalid", err)
+			context.AbortWithStatusJSON(http.StatusUnauthorized, app.ResponseContent{
+				StatusCode: http.StatusUnauthorized,
+			})
+			return
+		}
+
 		// 将 userInfo 信息写入上下文
 		context.Set(app.UserInfoKey, userInfo)
 	}
 }
 
-// 用户管理权限校验中间件
-func userManageAuthMiddleware() gin.HandlerFunc {
+// 权限校验中间件，根据 routePermissions 检查用户是否拥有访问路由所需的权限
+func permissionMiddleware(userService userbiz.Service) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		userInfo := app.GetUserInfoFromContext(context)
 		if userInfo.UserId == "" {
@@ -162,15 +414,44 @@ func userManageAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 检查是否有权限管理用户
-		if !userInfo.IsRoot() {
+		userPermissions, err := userService.GetUserPermissions(userInfo.UserId)
+		if err != nil {
 			global.Logger.WithFields(logger.Fields{
 				"user_info": userInfo,
-			}).Error("没有权限管理用户")
+			}).Error("获取用户权限失败", err)
 			context.AbortWithStatusJSON(http.StatusUnauthorized, app.ResponseContent{
 				StatusCode: http.StatusUnauthorized,
 			})
 			return
 		}
+		// 使用 API Key 时，权限为用户权限和 Key 权限的交集
+		if keyPermissions, ok := context.Get(app.APIKeyPermissionsKey); ok {
+			userPermissions = permissions.Intersect(userPermissions, keyPermissions.([]string))
+		}
+		context.Set(app.PermissionsKey, []string(userPermissions))
+
+		// 检查路由所需的权限，没有声明权限的路由不允许访问
+		required, ok := routePermissions[context.Request.Method+" "+context.FullPath()]
+		if !ok {
+			global.Logger.WithFields(logger.Fields{
+				"method": context.Request.Method,
+				"path":   context.FullPath(),
+			}).Error("路由没有声明权限")
+			app.NewResponse(context).ToErrorResponse(errcode.NoPermission)
+			context.Abort()
+			return
+		}
+		for _, permission := range required {
+			if !app.HasPermission(context, permission) {
+				global.Logger.WithFields(logger.Fields{
+					"user_info":  userInfo,
+					"permission": permission,
+					"path":       context.FullPath(),
+				}).Error("没有权限")
+				app.NewResponse(context).ToErrorResponse(errcode.NoPermission)
+				context.Abort()
+				return
+			}
+		}
 	}
 }
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
 	"time"
 
 	"configuration-management/global"
+	"configuration-management/internal/biz/abuse"
+	"configuration-management/internal/biz/card"
+	"configuration-management/internal/biz/cardexport"
+	"configuration-management/internal/biz/cardstat"
+	"configuration-management/internal/biz/event"
+	"configuration-management/internal/biz/webhook"
 	"configuration-management/internal/routers"
 	"configuration-management/pkg/errcode"
+	"configuration-management/pkg/geoip"
 	"configuration-management/pkg/logger"
+	"configuration-management/pkg/ratelimit"
 	"configuration-management/pkg/setting"
 	"configuration-management/utils/security"
 
@@ -23,6 +33,21 @@ import (
 // @version 1.0
 // @description 统一管理用户的配置信息
 func main() {
+	rebuildStats := flag.Bool("rebuild-stats", false, "根据 card 表重建激活码统计后退出")
+	flag.Parse()
+	if *rebuildStats {
+		rows, err := cardstat.NewService().Rebuild()
+		if err != nil {
+			log.Fatalf("rebuild card stats err: %v", err)
+		}
+		log.Printf("rebuild card stats done, %d rows", rows)
+		return
+	}
+
+	startWebhookDispatcher()
+	startNotificationScanner()
+	startExportJanitor()
+
 	gin.SetMode(global.ServerSetting.RunMode)
 	router := routers.NewRouter()
 
@@ -61,6 +86,13 @@ func init() {
 	if err != nil {
 		log.Fatalf("init.setupRSAKey err: %v", err)
 	}
+
+	err = setupGeoIP()
+	if err != nil {
+		log.Fatalf("init.setupGeoIP err: %v", err)
+	}
+
+	setupRateLimiter()
 }
 
 func setupSetting() error {
@@ -80,6 +112,42 @@ func setupSetting() error {
 	if err != nil {
 		return err
 	}
+	err = setting.ReadSection("OIDC", &global.OIDCSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("GeoIP", &global.GeoIPSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("Abuse", &global.AbuseSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("RateLimit", &global.RateLimitSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("Webhook", &global.WebhookSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("Notification", &global.NotificationSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("Export", &global.ExportSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("CardSheet", &global.CardSheetSetting)
+	if err != nil {
+		return err
+	}
+	err = setting.ReadSection("Redeem", &global.RedeemSetting)
+	if err != nil {
+		return err
+	}
 
 	global.ServerSetting.ReadTimeout *= time.Second
 	global.ServerSetting.WriteTimeout *= time.Second
@@ -121,3 +189,79 @@ func setupRSAKey() error {
 
 	return nil
 }
+
+func setupGeoIP() error {
+	if global.GeoIPSetting == nil || global.GeoIPSetting.DatabasePath == "" {
+		global.GeoLocator = geoip.NopLocator{}
+		return nil
+	}
+	reader, err := geoip.Open(global.GeoIPSetting.DatabasePath)
+	if err != nil {
+		return err
+	}
+	global.GeoLocator = reader
+	return nil
+}
+
+func setupRateLimiter() {
+	if global.RateLimitSetting == nil || !global.RateLimitSetting.Enabled {
+		return
+	}
+	routes := make(map[string][]ratelimit.Rule)
+	for _, route := range global.RateLimitSetting.Routes {
+		for _, rule := range route.Rules {
+			routes[route.Path] = append(routes[route.Path], ratelimit.Rule{
+				Key: rule.Key,
+				Limit: ratelimit.Limit{
+					Rate:  ratelimit.Every(rule.Limit, rule.Per),
+					Burst: rule.Burst,
+				},
+			})
+		}
+	}
+	global.RateLimiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore(), routes)
+}
+
+// startWebhookDispatcher 在后台投递 webhook 发件箱中的事件
+func startWebhookDispatcher() {
+	if global.WebhookSetting == nil || !global.WebhookSetting.Enabled {
+		return
+	}
+	dispatcher := webhook.NewDispatcher(webhook.NewRepository(global.DBEngine), webhook.Options{
+		PollInterval:   global.WebhookSetting.PollInterval,
+		BatchSize:      global.WebhookSetting.BatchSize,
+		MaxAttempts:    global.WebhookSetting.MaxAttempts,
+		InitialBackoff: global.WebhookSetting.InitialBackoff,
+		MaxBackoff:     global.WebhookSetting.MaxBackoff,
+		Timeout:        global.WebhookSetting.Timeout,
+	}, card.NewExpiryProducer(card.NewService(), global.WebhookSetting.ExpiryScanWindow))
+	go dispatcher.Run(context.Background())
+}
+
+// startNotificationScanner 滥用检测的通知改为发到站内通知，并在后台定期扫描库存、额度和即将到期的批次
+func startNotificationScanner() {
+	service := event.NewService()
+	abuse.SetNotifier(service)
+	if global.NotificationSetting == nil || !global.NotificationSetting.Enabled {
+		return
+	}
+	go service.Run(context.Background())
+}
+
+// startExportJanitor 把上次中断的导出任务标记为失败，并定期删除到期的导出文件
+func startExportJanitor() {
+	service := cardexport.NewService()
+	if err := service.FailUnfinishedJobs(); err != nil {
+		global.Logger.Error("标记中断的导出任务失败", err)
+	}
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if _, err := service.Cleanup(time.Now()); err != nil {
+				global.Logger.Error("清理导出文件失败", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
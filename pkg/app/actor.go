@@ -0,0 +1,25 @@
+package app
+
+import "github.com/gin-gonic/gin"
+
+// Actor 发起修改操作的人，用于写入审计日志
+type Actor struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	IP        string `json:"ip"`
+	RequestID string `json:"request_id"`
+}
+
+// GetActorFromContext 从已经鉴权的请求中获取操作人信息
+func GetActorFromContext(c *gin.Context) Actor {
+	actor := Actor{
+		IP:        c.ClientIP(),
+		RequestID: c.GetString(RequestIDKey),
+	}
+	if value, ok := c.Get(UserInfoKey); ok {
+		userInfo := value.(UserInfo)
+		actor.ID = userInfo.UserId
+		actor.Name = userInfo.Username
+	}
+	return actor
+}
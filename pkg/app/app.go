@@ -1,7 +1,10 @@
 package app
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"configuration-management/pkg/errcode"
 
@@ -9,7 +12,12 @@ import (
 )
 
 const (
-	UserInfoKey = "userInfo"
+	UserInfoKey    = "userInfo"
+	PermissionsKey = "permissions"
+	// APIKeyPermissionsKey 使用 API Key 访问时，Key 自身拥有的权限
+	APIKeyPermissionsKey = "apiKeyPermissions"
+	// RequestIDKey 请求ID，来自 X-Request-ID 请求头或由服务端生成
+	RequestIDKey = "requestId"
 )
 
 var (
@@ -65,6 +73,16 @@ func (r *Response) ToResponseList(list interface{}, total int) {
 	r.Ctx.JSON(http.StatusOK, content)
 }
 
+// ToTooManyRequestsResponse 返回限流错误，并通过 Retry-After 告诉客户端需要等待的秒数
+func (r *Response) ToTooManyRequestsResponse(retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	r.Ctx.Header("Retry-After", strconv.Itoa(seconds))
+	r.ToErrorResponse(errcode.TooManyRequests)
+}
+
 func (r *Response) ToErrorResponse(err *errcode.Error) {
 	response := gin.H{"code": err.Code(), "msg": err.Msg()}
 	details := err.Details()
@@ -78,3 +96,27 @@ func (r *Response) ToErrorResponse(err *errcode.Error) {
 func GetUserInfoFromContext(c *gin.Context) UserInfo {
 	return c.MustGet(UserInfoKey).(UserInfo)
 }
+
+// GetPermissionsFromContext 获取权限中间件写入上下文的用户有效权限
+func GetPermissionsFromContext(c *gin.Context) []string {
+	if permissions, ok := c.Get(PermissionsKey); ok {
+		return permissions.([]string)
+	}
+	return []string{}
+}
+
+// IsAPIKeyRequest 当前请求是否通过 X-API-Key 鉴权
+func IsAPIKeyRequest(c *gin.Context) bool {
+	_, ok := c.Get(APIKeyPermissionsKey)
+	return ok
+}
+
+// HasPermission 检查当前请求的用户是否拥有某个权限
+func HasPermission(c *gin.Context, permission string) bool {
+	for _, p := range GetPermissionsFromContext(c) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -23,6 +23,8 @@ type UserInfo struct {
 	Username string   `json:"username"`
 	MaxCnt   int      `json:"maxCnt"`
 	Roles    []string `json:"roles"`
+	// SessionID 登录会话ID，通过 API Key 访问时为空
+	SessionID string `json:"sessionId"`
 }
 
 func (u *UserInfo) IsRoot() bool {
@@ -41,14 +43,17 @@ type CreateTokenArgs struct {
 }
 
 func CreateToken(info UserInfo) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
-		jwt.MapClaims{
-			"userId":   info.UserId,
-			"username": info.Username,
-			"maxCnt":   info.MaxCnt,
-			"roles":    info.Roles,
-			"exp":      time.Now().Add(TokenExp).Unix(),
-		})
+	claims := jwt.MapClaims{
+		"userId":   info.UserId,
+		"username": info.Username,
+		"maxCnt":   info.MaxCnt,
+		"roles":    info.Roles,
+		"exp":      time.Now().Add(TokenExp).Unix(),
+	}
+	if info.SessionID != "" {
+		claims["sid"] = info.SessionID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString(secretKey)
 	if err != nil {
@@ -105,6 +110,15 @@ func GetUserInfoFromToken(tokenString string) (UserInfo, error) {
 		userInfo.UserId = claims["userId"].(string)
 		userInfo.Username = claims["username"].(string)
 		userInfo.MaxCnt = int(claims["maxCnt"].(float64))
+		// 旧版本签发的 token 没有 sid，无法吊销，要求重新登录
+		sid, ok := claims["sid"].(string)
+		if !ok || sid == "" {
+			global.Logger.WithFields(logger.Fields{
+				"tokenString": tokenString,
+			}).Error("token without session id")
+			return UserInfo{}, fmt.Errorf("token without session id")
+		}
+		userInfo.SessionID = sid
 		if roles, ok := claims["roles"].([]interface{}); ok {
 			for _, role := range roles {
 				if r, ok := role.(string); ok {
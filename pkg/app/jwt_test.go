@@ -1,9 +1,13 @@
 package app
 
 import (
+	"io"
 	"testing"
 	"time"
 
+	"configuration-management/global"
+	"configuration-management/pkg/logger"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,3 +25,28 @@ func TestToken(t *testing.T) {
 	err = VerifyToken(token)
 	assert.Error(t, err)
 }
+
+func TestTokenWithoutSessionID(t *testing.T) {
+	global.Logger = logger.NewLogger(io.Discard, "", 0)
+
+	token, err := CreateToken(UserInfo{
+		UserId:    "testId",
+		Username:  "testName",
+		Roles:     []string{"testRole"},
+		SessionID: "testSession",
+	})
+	assert.NoError(t, err)
+	info, err := GetUserInfoFromToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "testSession", info.SessionID)
+
+	// 旧版本签发的 token 没有 sid，无法吊销，必须拒绝
+	legacy, err := CreateToken(UserInfo{
+		UserId:   "testId",
+		Username: "testName",
+		Roles:    []string{"testRole"},
+	})
+	assert.NoError(t, err)
+	_, err = GetUserInfoFromToken(legacy)
+	assert.Error(t, err)
+}
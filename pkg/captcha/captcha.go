@@ -0,0 +1,80 @@
+package captcha
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// 支持的验证码服务
+const (
+	ProviderStub      = "stub"
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderReCaptcha = "recaptcha"
+	ProviderTurnstile = "turnstile"
+)
+
+// Challenge 渲染验证码需要的信息
+// 本地题目使用 Question 和 Token，第三方服务使用 ScriptURL、WidgetClass 和 SiteKey 由前端脚本渲染
+type Challenge struct {
+	Provider    string
+	Question    string // 本地题目
+	Token       string // 本地题目的签名，随表单一起提交
+	ScriptURL   string // 第三方服务的前端脚本
+	WidgetClass string // 第三方服务的组件样式名
+	SiteKey     string
+}
+
+// Provider 验证码服务，Verify 从提交的表单中读取用户的回答
+type Provider interface {
+	Challenge() (Challenge, error)
+	Verify(ctx context.Context, form url.Values, remoteIP string) (bool, error)
+}
+
+// 本地题目提交时的表单字段
+const (
+	FieldToken  = "captcha_token"
+	FieldAnswer = "captcha_answer"
+)
+
+type Config struct {
+	Provider  string
+	SiteKey   string
+	Secret    string        // 第三方服务的密钥，stub 用来签名题目
+	VerifyURL string        // 为空时使用服务商的默认地址
+	Timeout   time.Duration // 请求第三方服务的超时时间
+	TTL       time.Duration // stub 题目的有效期
+}
+
+// New 根据配置创建验证码服务
+func New(cfg Config) (Provider, error) {
+	if cfg.Provider == "" || cfg.Provider == ProviderStub {
+		secret := []byte(cfg.Secret)
+		// 没有配置密钥时每次启动随机生成，多个实例部署时需要配置相同的密钥
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		return NewStub(secret, cfg.TTL), nil
+	}
+	p, ok := presets[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("不支持的验证码服务: %s", cfg.Provider)
+	}
+	if cfg.SiteKey == "" || cfg.Secret == "" {
+		return nil, fmt.Errorf("验证码服务 %s 需要 SiteKey 和 Secret", cfg.Provider)
+	}
+	if cfg.VerifyURL != "" {
+		p.verifyURL = cfg.VerifyURL
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return newSiteVerify(cfg.Provider, p, cfg.SiteKey, cfg.Secret, &http.Client{Timeout: timeout}), nil
+}
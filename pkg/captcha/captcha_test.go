@@ -0,0 +1,89 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStub(t *testing.T) {
+	p := NewStub([]byte("secret"), time.Minute).(*stub)
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	var x, y int
+	newChallenge := func() url.Values {
+		challenge, err := p.Challenge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fmt.Sscanf(challenge.Question, "%d + %d = ?", &x, &y); err != nil {
+			t.Fatal(err)
+		}
+		return url.Values{FieldToken: {challenge.Token}, FieldAnswer: {strconv.Itoa(x + y)}}
+	}
+
+	// 答错后题目作废，正确答案也不能再通过
+	answer := newChallenge()
+	if ok, _ := p.Verify(context.Background(), url.Values{FieldToken: answer[FieldToken], FieldAnswer: {"100"}}, ""); ok {
+		t.Fatal("wrong answer should fail")
+	}
+	if ok, _ := p.Verify(context.Background(), answer, ""); ok {
+		t.Fatal("challenge should be consumed by a failed attempt")
+	}
+
+	answer = newChallenge()
+	if ok, _ := p.Verify(context.Background(), answer, ""); !ok {
+		t.Fatal("right answer should pass")
+	}
+	// 同一道题不能使用两次
+	if ok, _ := p.Verify(context.Background(), answer, ""); ok {
+		t.Fatal("replayed answer should fail")
+	}
+
+	// 过期的题目
+	answer = newChallenge()
+	now = now.Add(2 * time.Minute)
+	if ok, _ := p.Verify(context.Background(), answer, ""); ok {
+		t.Fatal("expired challenge should fail")
+	}
+}
+
+func TestSiteVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("secret") != "s" || r.PostForm.Get("remoteip") != "1.2.3.4" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": ` + strconv.FormatBool(r.PostForm.Get("response") == "good") + `}`))
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Provider: ProviderTurnstile, SiteKey: "k", Secret: "s", VerifyURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, _ := p.Challenge()
+	if challenge.WidgetClass != "cf-turnstile" || challenge.SiteKey != "k" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+	if ok, err := p.Verify(context.Background(), url.Values{"cf-turnstile-response": {"good"}}, "1.2.3.4"); err != nil || !ok {
+		t.Fatalf("expected success, got %v %v", ok, err)
+	}
+	if ok, _ := p.Verify(context.Background(), url.Values{"cf-turnstile-response": {"bad"}}, "1.2.3.4"); ok {
+		t.Fatal("expected failure")
+	}
+	if ok, _ := p.Verify(context.Background(), url.Values{}, "1.2.3.4"); ok {
+		t.Fatal("missing response should fail")
+	}
+
+	if _, err := New(Config{Provider: "unknown"}); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
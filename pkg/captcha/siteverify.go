@@ -0,0 +1,92 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// preset 兼容 siteverify 接口的第三方服务
+type preset struct {
+	verifyURL     string
+	scriptURL     string
+	widgetClass   string
+	responseField string // 前端脚本写入表单的字段
+}
+
+var presets = map[string]preset{
+	ProviderHCaptcha: {
+		verifyURL:     "https://api.hcaptcha.com/siteverify",
+		scriptURL:     "https://js.hcaptcha.com/1/api.js",
+		widgetClass:   "h-captcha",
+		responseField: "h-captcha-response",
+	},
+	ProviderReCaptcha: {
+		verifyURL:     "https://www.google.com/recaptcha/api/siteverify",
+		scriptURL:     "https://www.google.com/recaptcha/api.js",
+		widgetClass:   "g-recaptcha",
+		responseField: "g-recaptcha-response",
+	},
+	ProviderTurnstile: {
+		verifyURL:     "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+		scriptURL:     "https://challenges.cloudflare.com/turnstile/v0/api.js",
+		widgetClass:   "cf-turnstile",
+		responseField: "cf-turnstile-response",
+	},
+}
+
+type siteVerify struct {
+	name    string
+	preset  preset
+	siteKey string
+	secret  string
+	client  *http.Client
+}
+
+func newSiteVerify(name string, p preset, siteKey string, secret string, client *http.Client) Provider {
+	return &siteVerify{name: name, preset: p, siteKey: siteKey, secret: secret, client: client}
+}
+
+func (s *siteVerify) Challenge() (Challenge, error) {
+	return Challenge{
+		Provider:    s.name,
+		ScriptURL:   s.preset.scriptURL,
+		WidgetClass: s.preset.widgetClass,
+		SiteKey:     s.siteKey,
+	}, nil
+}
+
+func (s *siteVerify) Verify(ctx context.Context, form url.Values, remoteIP string) (bool, error) {
+	response := form.Get(s.preset.responseField)
+	if response == "" {
+		return false, nil
+	}
+	body := url.Values{"secret": {s.secret}, "response": {response}}
+	if remoteIP != "" {
+		body.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.preset.verifyURL, strings.NewReader(body.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("验证码服务返回 %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
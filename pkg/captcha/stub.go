@@ -0,0 +1,86 @@
+package captcha
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// stub 不依赖外部服务的本地验证码：一道简单的加法题
+// 答案不保存在服务端，而是和过期时间一起签名放在 Token 中，每道题只能验证一次，不论是否通过
+type stub struct {
+	secret []byte
+	ttl    time.Duration
+	used   *cache.Cache
+	now    func() time.Time
+}
+
+// NewStub 创建本地验证码，ttl 为题目的有效期
+func NewStub(secret []byte, ttl time.Duration) Provider {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	return &stub{
+		secret: secret,
+		ttl:    ttl,
+		used:   cache.New(ttl, ttl),
+		now:    time.Now,
+	}
+}
+
+func (s *stub) Challenge() (Challenge, error) {
+	a, err := rand.Int(rand.Reader, big.NewInt(9))
+	if err != nil {
+		return Challenge{}, err
+	}
+	b, err := rand.Int(rand.Reader, big.NewInt(9))
+	if err != nil {
+		return Challenge{}, err
+	}
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return Challenge{}, err
+	}
+	x, y := a.Int64()+1, b.Int64()+1
+	payload := strconv.FormatInt(s.now().Add(s.ttl).Unix(), 10) + "." + hex.EncodeToString(nonce)
+	return Challenge{
+		Provider: ProviderStub,
+		Question: fmt.Sprintf("%d + %d = ?", x, y),
+		Token:    payload + "." + s.sign(payload, strconv.FormatInt(x+y, 10)),
+	}, nil
+}
+
+func (s *stub) Verify(_ context.Context, form url.Values, _ string) (bool, error) {
+	parts := strings.Split(form.Get(FieldToken), ".")
+	if len(parts) != 3 {
+		return false, nil
+	}
+	expiresAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || s.now().Unix() > expiresAt {
+		return false, nil
+	}
+	// 校验答案前先作废题目，答错后不能用同一道题继续猜
+	if err := s.used.Add(parts[1], true, s.ttl); err != nil {
+		return false, nil
+	}
+	payload := parts[0] + "." + parts[1]
+	answer := strings.TrimSpace(form.Get(FieldAnswer))
+	return hmac.Equal([]byte(s.sign(payload, answer)), []byte(parts[2])), nil
+}
+
+func (s *stub) sign(payload string, answer string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload + "|" + answer))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -12,9 +12,14 @@ var (
 	TooManyRequests           = NewError(10000007, "请求过多")
 	DuplicateKey              = NewError(10000008, "数据已存在")
 	NoPermission              = NewError(10000009, "没有权限")
+	IPBlocked                 = NewError(10000010, "IP已被封禁")
 
 	CardNotFound       = NewError(20010000, "激活码找不到")
 	CardNotAvailable   = NewError(20010001, "激活码不可用")
 	DeviceNotAvailable = NewError(20010002, "设备不可用")
 	CardExpired        = NewError(20010003, "激活码已过期")
+
+	AppDisabled     = NewError(20020000, "应用已停用")
+	AppArchived     = NewError(20020001, "应用已归档")
+	AppHasLiveCards = NewError(20020002, "应用还有未使用或未过期的激活码")
 )
@@ -69,6 +69,10 @@ func (e *Error) StatusCode() int {
 		return http.StatusUnauthorized
 	case TooManyRequests.Code():
 		return http.StatusTooManyRequests
+	case IPBlocked.Code():
+		fallthrough
+	case AppDisabled.Code():
+		return http.StatusForbidden
 	}
 
 	return http.StatusInternalServerError
@@ -0,0 +1,22 @@
+// Package geoip 根据IP查询地理位置，数据来自本地的 MaxMind DB(.mmdb) 文件，例如 GeoLite2-City
+package geoip
+
+import "net"
+
+// Location IP对应的地理位置，查不到的字段为空
+type Location struct {
+	Country string `json:"country"` // ISO 3166-1 国家代码，例如 CN
+	City    string `json:"city"`    // 城市英文名
+}
+
+// Locator 地理位置查询，可以替换为其他实现
+type Locator interface {
+	Lookup(ip net.IP) (Location, error)
+}
+
+// NopLocator 没有配置数据库时使用，总是返回空的位置
+type NopLocator struct{}
+
+func (NopLocator) Lookup(net.IP) (Location, error) {
+	return Location{}, nil
+}
@@ -0,0 +1,57 @@
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record 查询时只解码需要的字段
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Reader 基于 MaxMind DB 文件查询地理位置，可以并发使用
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// Open 打开 MaxMind DB 文件
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db}, nil
+}
+
+// FromBytes 从内存中的 MaxMind DB 数据创建 Reader
+func FromBytes(buf []byte) (*Reader, error) {
+	db, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db}, nil
+}
+
+// Lookup 查询IP所在的国家和城市，数据库中没有的IP返回空的位置
+func (r *Reader) Lookup(ip net.IP) (Location, error) {
+	var rec record
+	if err := r.db.Lookup(ip, &rec); err != nil {
+		return Location{}, err
+	}
+	return Location{
+		Country: rec.Country.ISOCode,
+		City:    rec.City.Names["en"],
+	}, nil
+}
+
+// Close 释放数据库文件
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
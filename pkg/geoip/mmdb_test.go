@@ -0,0 +1,111 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metadataMarker 元数据前的标记，位于文件末尾
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator 搜索树和数据区之间的 16 个字节
+const dataSectionSeparator = 16
+
+// 测试用到的数据区类型
+const (
+	typePointer = 1
+	typeString  = 2
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+)
+
+// 以下函数按 MaxMind DB 格式编码测试数据，只支持测试用到的类型
+func encString(s string) []byte {
+	return append([]byte{byte(typeString<<5 | len(s))}, s...)
+}
+
+func encUint16(v uint16) []byte {
+	return []byte{typeUint16<<5 | 2, byte(v >> 8), byte(v)}
+}
+
+func encUint32(v uint32) []byte {
+	return []byte{typeUint32<<5 | 4, byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func encPointer(p int) []byte {
+	return []byte{byte(typePointer<<5 | (p>>8)&0x7), byte(p)}
+}
+
+func encMap(pairs ...[]byte) []byte {
+	buf := []byte{byte(typeMap<<5 | len(pairs)/2)}
+	for _, p := range pairs {
+		buf = append(buf, p...)
+	}
+	return buf
+}
+
+// buildTestDB 生成只包含 1.0.0.0/8 的 IPv4 数据库，record_size 为 24
+func buildTestDB() []byte {
+	const nodeCount = 8
+	// 数据区：先写城市名，记录中通过指针引用
+	data := encString("Beijing")
+	recordOffset := len(data)
+	data = append(data, encMap(
+		encString("country"), encMap(encString("iso_code"), encString("CN")),
+		encString("city"), encMap(encString("names"), encMap(encString("en"), encPointer(0))),
+	)...)
+
+	record := func(v int) []byte { return []byte{byte(v >> 16), byte(v >> 8), byte(v)} }
+	var tree []byte
+	for i := 0; i < nodeCount; i++ {
+		if i < nodeCount-1 {
+			// 0 向下走，1 表示没有数据
+			tree = append(tree, record(i+1)...)
+			tree = append(tree, record(nodeCount)...)
+			continue
+		}
+		// 最后一位为 1 时指向记录
+		tree = append(tree, record(nodeCount)...)
+		tree = append(tree, record(nodeCount+dataSectionSeparator+recordOffset)...)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(tree)
+	buf.Write(make([]byte, dataSectionSeparator))
+	buf.Write(data)
+	buf.Write(metadataMarker)
+	buf.Write(encMap(
+		encString("node_count"), encUint32(nodeCount),
+		encString("record_size"), encUint16(24),
+		encString("ip_version"), encUint16(4),
+		encString("binary_format_major_version"), encUint16(2),
+		encString("binary_format_minor_version"), encUint16(0),
+		encString("database_type"), encString("Test"),
+	))
+	return buf.Bytes()
+}
+
+func TestReaderLookup(t *testing.T) {
+	r, err := FromBytes(buildTestDB())
+	assert.NoError(t, err)
+
+	loc, err := r.Lookup(net.ParseIP("1.2.3.4"))
+	assert.NoError(t, err)
+	assert.Equal(t, Location{Country: "CN", City: "Beijing"}, loc)
+
+	loc, err = r.Lookup(net.ParseIP("2.2.3.4"))
+	assert.NoError(t, err)
+	assert.Equal(t, Location{}, loc)
+
+	_, err = r.Lookup(net.ParseIP("2001:db8::1"))
+	assert.Error(t, err)
+}
+
+func TestFromBytesInvalid(t *testing.T) {
+	_, err := FromBytes([]byte("not a database"))
+	assert.Error(t, err)
+}
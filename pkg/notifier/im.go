@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// IM 群机器人的类型，请求体的格式不同
+const (
+	IMDingTalk = "dingtalk" // 钉钉自定义机器人
+	IMWeCom    = "wecom"    // 企业微信群机器人
+	IMSlack    = "slack"    // Slack incoming webhook，兼容该格式的服务也可以使用
+)
+
+// IsIMKind 是否为支持的群机器人类型
+func IsIMKind(kind string) bool {
+	switch kind {
+	case IMDingTalk, IMWeCom, IMSlack:
+		return true
+	}
+	return false
+}
+
+// errNonPublicAddress webhook 地址解析到了内网、回环或链路本地地址
+var errNonPublicAddress = errors.New("webhook address is not a public IP")
+
+// NewIMClient 发送群机器人消息的 HTTP 客户端，只能连接公网地址
+// 在建立连接时检查解析后的IP，重定向和 DNS 重绑定也无法访问内网；不使用环境变量中的代理，避免绕过检查
+func NewIMClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: publicOnly,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost: 2,
+		},
+	}
+}
+
+// publicOnly 拒绝连接回环、私有、链路本地、未指定和组播地址
+func publicOnly(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("%w: %s", errNonPublicAddress, host)
+	}
+	return nil
+}
+
+type imNotifier struct {
+	kind   string
+	client *http.Client
+}
+
+// NewIMWebhook 通过群机器人的 webhook 发送 markdown 消息，Message.To 为 webhook 地址
+func NewIMWebhook(kind string, client *http.Client) Notifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &imNotifier{kind: kind, client: client}
+}
+
+func (n *imNotifier) Send(ctx context.Context, msg Message) error {
+	if u, err := url.Parse(msg.To); err != nil || u.Scheme != "https" {
+		return fmt.Errorf("%s webhook must use https: %s", n.kind, msg.To)
+	}
+	payload, err := json.Marshal(imPayload(n.kind, msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.To, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s webhook returned %d: %s", n.kind, resp.StatusCode, body)
+	}
+
+	// 钉钉和企业微信出错时仍然返回 200，错误码在响应体中
+	if n.kind == IMDingTalk || n.kind == IMWeCom {
+		var result struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err := json.Unmarshal(body, &result); err == nil && result.ErrCode != 0 {
+			return fmt.Errorf("%s webhook returned errcode %d: %s", n.kind, result.ErrCode, result.ErrMsg)
+		}
+	}
+	return nil
+}
+
+func imPayload(kind string, msg Message) any {
+	switch kind {
+	case IMDingTalk:
+		return map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": msg.Subject,
+				"text":  "### " + msg.Subject + "\n\n" + msg.Body,
+			},
+		}
+	case IMWeCom:
+		return map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"content": "**" + msg.Subject + "**\n" + msg.Body,
+			},
+		}
+	default:
+		return map[string]string{
+			"text": "*" + msg.Subject + "*\n" + msg.Body,
+		}
+	}
+}
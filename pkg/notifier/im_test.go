@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIMWebhookPayloads(t *testing.T) {
+	cases := []struct {
+		kind  string
+		check func(body map[string]any) bool
+	}{
+		{IMDingTalk, func(body map[string]any) bool {
+			markdown, _ := body["markdown"].(map[string]any)
+			return body["msgtype"] == "markdown" && markdown["title"] == "标题" &&
+				strings.Contains(markdown["text"].(string), "正文")
+		}},
+		{IMWeCom, func(body map[string]any) bool {
+			markdown, _ := body["markdown"].(map[string]any)
+			return body["msgtype"] == "markdown" && strings.Contains(markdown["content"].(string), "正文")
+		}},
+		{IMSlack, func(body map[string]any) bool {
+			return body["text"] == "*标题*\n正文"
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.kind, func(t *testing.T) {
+			var received map[string]any
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("unexpected content type: %s", r.Header.Get("Content-Type"))
+				}
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+			}))
+			defer server.Close()
+
+			n := NewIMWebhook(c.kind, server.Client())
+			if err := n.Send(context.Background(), Message{To: server.URL, Subject: "标题", Body: "正文"}); err != nil {
+				t.Fatal(err)
+			}
+			if !c.check(received) {
+				t.Fatalf("unexpected payload: %v", received)
+			}
+		})
+	}
+}
+
+func TestIMWebhookErrors(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":310000,"errmsg":"keywords not in content"}`))
+	}))
+	defer server.Close()
+
+	// 钉钉返回 200，但是响应体中有错误码
+	n := NewIMWebhook(IMDingTalk, server.Client())
+	if err := n.Send(context.Background(), Message{To: server.URL, Subject: "x"}); err == nil || !strings.Contains(err.Error(), "310000") {
+		t.Fatalf("expected errcode error, got %v", err)
+	}
+	n = NewIMWebhook(IMSlack, server.Client())
+	if err := n.Send(context.Background(), Message{To: server.URL + "/fail", Subject: "x"}); err == nil {
+		t.Fatal("expected error for non-2xx status")
+	}
+}
+
+func TestIMWebhookRequiresHTTPS(t *testing.T) {
+	n := NewIMWebhook(IMSlack, nil)
+	if err := n.Send(context.Background(), Message{To: "http://hooks.example.com/x", Subject: "x"}); err == nil {
+		t.Fatal("expected error for http webhook")
+	}
+}
+
+func TestIMClientRejectsNonPublicAddresses(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request to loopback address should not be sent")
+	}))
+	defer server.Close()
+
+	n := NewIMWebhook(IMSlack, NewIMClient(time.Second))
+	err := n.Send(context.Background(), Message{To: server.URL, Subject: "x"})
+	if !errors.Is(err, errNonPublicAddress) {
+		t.Fatalf("expected non-public address error, got %v", err)
+	}
+}
+
+func TestPublicOnly(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1:443":       false,
+		"10.1.2.3:443":        false,
+		"172.16.0.1:443":      false,
+		"192.168.1.1:443":     false,
+		"169.254.169.254:80":  false,
+		"0.0.0.0:443":         false,
+		"[::1]:443":           false,
+		"[fe80::1]:443":       false,
+		"[fd00::1]:443":       false,
+		"[::ffff:10.0.0.1]:1": false,
+		"203.0.113.10:443":    true,
+		"[2001:db8::1]:443":   true,
+	}
+	for address, allowed := range cases {
+		err := publicOnly("tcp", address, nil)
+		if (err == nil) != allowed {
+			t.Errorf("publicOnly(%s) error = %v, allowed = %v", address, err, allowed)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Package notifier 把通知发送到站外渠道，目前支持邮件和 IM 群机器人
+package notifier
+
+import "context"
+
+// Message 一条待发送的通知
+type Message struct {
+	To      string // 接收方，邮件为收件地址，IM 为机器人的 webhook 地址
+	Subject string // 标题
+	Body    string // 正文，纯文本，IM 渠道按 markdown 展示
+}
+
+// Notifier 站外通知渠道
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
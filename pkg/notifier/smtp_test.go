@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP 只实现发送一封邮件需要的命令，记录收到的信封和内容
+type fakeSMTP struct {
+	listener net.Listener
+	from     string
+	to       string
+	data     chan string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{listener: listener, data: make(chan string, 1)}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeSMTP) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-localhost")
+			reply("250 HELP")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = strings.Trim(line[len("RCPT TO:"):], "<> ")
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data <- data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	server := newFakeSMTP(t)
+	port := server.listener.Addr().(*net.TCPAddr).Port
+	n := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: port, From: "noreply@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := n.Send(ctx, Message{To: "reseller@example.com", Subject: "激活码库存不足", Body: "应用 A 只剩 3 个未使用的激活码"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := <-server.data
+	if server.from != "noreply@example.com" || server.to != "reseller@example.com" {
+		t.Fatalf("unexpected envelope: %s -> %s", server.from, server.to)
+	}
+	if !strings.Contains(data, "Subject: =?utf-8?q?") {
+		t.Fatalf("subject should be encoded: %s", data)
+	}
+	parts := strings.SplitN(data, "\r\n\r\n", 2)
+	if len(parts) != 2 {
+		t.Fatalf("unexpected mail: %s", data)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(parts[1], "\r\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "应用 A 只剩 3 个未使用的激活码" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestSMTPSendEmptyRecipient(t *testing.T) {
+	n := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: 1})
+	if err := n.Send(context.Background(), Message{Subject: "x"}); err == nil {
+		t.Fatal("expected error for empty recipient")
+	}
+}
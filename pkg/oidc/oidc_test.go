@@ -0,0 +1,108 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"configuration-management/pkg/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURL = "http://localhost/public/v1/oidc/callback"
+
+// authorize 模拟浏览器访问授权端点，返回回调地址中的 code 和 state
+func authorize(t *testing.T, authURL string) (string, string) {
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newTestProvider(t *testing.T) (*oidctest.Provider, *Provider) {
+	mock := oidctest.NewProvider("config-management")
+	t.Cleanup(mock.Close)
+
+	provider, err := NewProvider(context.Background(), Config{
+		Issuer:      mock.Issuer(),
+		ClientID:    "config-management",
+		RedirectURL: redirectURL,
+		Scopes:      []string{"openid", "profile", "groups"},
+	}, nil)
+	require.NoError(t, err)
+	return mock, provider
+}
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	mock, provider := newTestProvider(t)
+	mock.Claims = map[string]any{
+		"sub":                "u-1",
+		"preferred_username": "alice",
+		"groups":             []string{"cm-admins", "cm-app-a"},
+	}
+
+	verifier, challenge, err := GeneratePKCE()
+	require.NoError(t, err)
+	code, state := authorize(t, provider.AuthCodeURL("state-1", "nonce-1", challenge))
+	assert.Equal(t, "state-1", state)
+
+	token, err := provider.Exchange(context.Background(), code, verifier)
+	require.NoError(t, err)
+
+	claims, err := provider.VerifyIDToken(context.Background(), token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", claims.String("sub"))
+	assert.Equal(t, "alice", claims.String("preferred_username"))
+	assert.Equal(t, []string{"cm-admins", "cm-app-a"}, claims.Strings("groups"))
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	_, challenge, err := GeneratePKCE()
+	require.NoError(t, err)
+	code, _ := authorize(t, provider.AuthCodeURL("state", "nonce", challenge))
+
+	_, err = provider.Exchange(context.Background(), code, "wrong-verifier")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDTokenRejectsWrongNonce(t *testing.T) {
+	mock, provider := newTestProvider(t)
+
+	idToken, err := mock.SignIDToken("nonce-1")
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(context.Background(), idToken, "nonce-2")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDTokenRejectsOtherAudience(t *testing.T) {
+	mock, provider := newTestProvider(t)
+	mock.Claims = map[string]any{"sub": "u-1", "aud": "other-client"}
+
+	idToken, err := mock.SignIDToken("nonce")
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(context.Background(), idToken, "nonce")
+	assert.Error(t, err)
+}
+
+func TestNewProviderRejectsIssuerMismatch(t *testing.T) {
+	mock := oidctest.NewProvider("config-management")
+	defer mock.Close()
+
+	_, err := NewProvider(context.Background(), Config{
+		Issuer:   mock.Issuer() + "/other",
+		ClientID: "config-management",
+	}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// MM 一毫米对应的点数，PDF 的长度单位为点 (1/72 英寸)
+const MM = 72 / 25.4
+
+// Document 只支持文字、矩形和线条的 PDF 文档，用于打印激活码卡片
+// 坐标以页面左上角为原点，单位为点
+// ASCII 文字使用等宽的 Courier，其他文字使用阅读器自带的 STSong-Light，两者都不嵌入字体文件
+type Document struct {
+	width  float64
+	height float64
+	pages  []*bytes.Buffer
+}
+
+// New 创建指定页面大小的文档
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// AddPage 添加一页，之后的绘制都在这一页上
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount 返回页数
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// TextWidth 估算文字的宽度：Courier 每个字符 0.6 个字号，中文每个字符 1 个字号
+func TextWidth(s string, size float64) float64 {
+	if isASCII(s) {
+		return float64(len(s)) * 0.6 * size
+	}
+	width := 0.0
+	for _, r := range s {
+		if r < 0x80 {
+			width += 0.5 * size
+		} else {
+			width += size
+		}
+	}
+	return width
+}
+
+// Text 在 (x, y) 处输出一行文字，y 为文字基线的位置
+func (d *Document) Text(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	font, encoded := "F1", encodeLatin(s)
+	if !isASCII(s) {
+		font, encoded = "F2", encodeUCS2(s)
+	}
+	fmt.Fprintf(d.page(), "BT /%s %s Tf %s %s Td %s Tj ET\n", font, num(size), num(x), num(d.height-y), encoded)
+}
+
+// FillRect 填充黑色矩形，(x, y) 为左上角
+func (d *Document) FillRect(x, y, w, h float64) {
+	fmt.Fprintf(d.page(), "%s %s %s %s re f\n", num(x), num(d.height-y-h), num(w), num(h))
+}
+
+// StrokeRect 画矩形边框，dashed 时为虚线，用作裁切线
+func (d *Document) StrokeRect(x, y, w, h float64, dashed bool) {
+	p := d.page()
+	if dashed {
+		p.WriteString("q [3 3] 0 d 0.5 G 0.3 w\n")
+	} else {
+		p.WriteString("q 0.5 w\n")
+	}
+	fmt.Fprintf(p, "%s %s %s %s re S Q\n", num(x), num(d.height-y-h), num(w), num(h))
+}
+
+// WriteTo 输出完整的 PDF 文件
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	out := &countingWriter{w: bufio.NewWriter(w)}
+	offsets := make([]int64, 0)
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// 1 目录，2 页面树，3-6 字体，之后每页一个页面对象和一个内容流
+	const firstPage = 7
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [5 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 6 0 R /DW 1000 >>")
+	object("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(d.width), num(d.height), firstPage+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if out.err != nil {
+		return out.n, out.err
+	}
+	return out.n, out.w.(*bufio.Writer).Flush()
+}
+
+// countingWriter 记录已经写入的字节数，用于生成交叉引用表，并保存第一个错误
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+func (c *countingWriter) WriteString(s string) {
+	_, _ = c.Write([]byte(s))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeLatin 转义字符串中的括号和反斜杠
+func encodeLatin(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", " ", "\n", " ")
+	return "(" + r.Replace(s) + ")"
+}
+
+// encodeUCS2 按 UniGB-UCS2-H 编码为十六进制字符串，基本平面以外的字符替换为问号
+func encodeUCS2(s string) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		if r > 0xFFFF || utf16.IsSurrogate(r) {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// num 格式化坐标，保留两位小数并去掉多余的零
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
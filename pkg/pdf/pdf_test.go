@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New(210*MM, 297*MM)
+	doc.AddPage()
+	doc.Text(10, 20, 12, "AB(12)")
+	doc.Text(10, 40, 12, "应用A")
+	doc.FillRect(10, 50, 5, 5)
+	doc.AddPage()
+	doc.StrokeRect(0, 0, 100, 50, true)
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+	if int(n) != len(data) || !strings.HasPrefix(data, "%PDF-1.4") || !strings.HasSuffix(data, "%%EOF\n") {
+		t.Fatalf("unexpected document: %d bytes", n)
+	}
+	if c := strings.Count(data, "/Type /Page "); c != 2 {
+		t.Fatalf("expected 2 pages, got %d", c)
+	}
+	if !strings.Contains(data, `(AB\(12\)) Tj`) || !strings.Contains(data, "<5E9475280041> Tj") {
+		t.Fatal("unexpected text encoding")
+	}
+	// 左上角为原点，y 需要翻转
+	if !strings.Contains(data, "10 786.89 5 5 re f") {
+		t.Fatal("unexpected rectangle position")
+	}
+
+	// 交叉引用表中的偏移量都指向对应的对象
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(data)
+	if match == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(match[1])
+	lines := strings.Split(data[xref:], "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		offset, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if !strings.HasPrefix(data[offset:], fmt.Sprintf("%d 0 obj", i)) {
+			t.Fatalf("xref entry %d points to %q", i, data[offset:offset+10])
+		}
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if w := TextWidth("ABCD", 10); w != 24 {
+		t.Fatalf("unexpected ascii width: %v", w)
+	}
+	if w := TextWidth("应用A", 10); w != 25 {
+		t.Fatalf("unexpected cjk width: %v", w)
+	}
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Rate: Every(1, time.Second), Burst: 2}
+	now := time.Now()
+
+	ok, _, _ := store.Take("k", limit, now)
+	assert.True(t, ok)
+	ok, _, _ = store.Take("k", limit, now)
+	assert.True(t, ok)
+
+	ok, retryAfter, err := store.Take("k", limit, now)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// 其他 key 不受影响
+	ok, _, _ = store.Take("other", limit, now)
+	assert.True(t, ok)
+
+	// 半秒后仍然不足一个令牌
+	ok, retryAfter, _ = store.Take("k", limit, now.Add(500*time.Millisecond))
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _, _ = store.Take("k", limit, now.Add(time.Second))
+	assert.True(t, ok)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Rate: Every(10, time.Second), Burst: 1}
+	now := time.Now()
+
+	store.Take("k", limit, now)
+	assert.Len(t, store.buckets, 1)
+
+	// 桶已经回满，清理后重新创建
+	store.Take("other", limit, now.Add(2*sweepInterval))
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "other")
+}
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), map[string][]Rule{
+		"/activate": {
+			{Key: KeyIP, Limit: Limit{Rate: Every(1, time.Minute), Burst: 1}},
+			{Key: KeyCard, Limit: Limit{Rate: Every(1, time.Minute), Burst: 2}},
+		},
+	})
+
+	ok, _, _ := limiter.Allow("/activate", KeyIP, "1.1.1.1")
+	assert.True(t, ok)
+	ok, retryAfter, _ := limiter.Allow("/activate", KeyIP, "1.1.1.1")
+	assert.False(t, ok)
+	assert.InDelta(t, time.Minute.Seconds(), retryAfter.Seconds(), 1)
+
+	// 按激活码限流和按IP限流互不影响
+	ok, _, _ = limiter.Allow("/activate", KeyCard, "1.1.1.1")
+	assert.True(t, ok)
+
+	// 没有配置的路由不限流
+	ok, _, _ = limiter.Allow("/identity", KeyIP, "1.1.1.1")
+	assert.True(t, ok)
+
+	var disabled *Limiter
+	ok, _, _ = disabled.Allow("/activate", KeyIP, "1.1.1.1")
+	assert.True(t, ok)
+}
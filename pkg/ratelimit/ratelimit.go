@@ -0,0 +1,67 @@
+// Package ratelimit 令牌桶限流，桶的状态保存在可替换的 Store 中
+package ratelimit
+
+import (
+	"time"
+)
+
+// 限流的维度
+const (
+	KeyIP   = "ip"
+	KeyCard = "card"
+)
+
+// Limit 令牌桶参数
+type Limit struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 桶的容量，即允许的突发请求数
+}
+
+// Every 每 per 时间允许 n 次请求
+func Every(n int, per time.Duration) float64 {
+	if n <= 0 || per <= 0 {
+		return 0
+	}
+	return float64(n) / per.Seconds()
+}
+
+// Store 保存令牌桶的状态，单实例使用内存，多实例部署时可以替换为共享存储
+type Store interface {
+	// Take 从 key 对应的桶中取一个令牌，令牌不足时返回需要等待的时间
+	Take(key string, limit Limit, now time.Time) (ok bool, retryAfter time.Duration, err error)
+}
+
+// Rule 一个路由上按某个维度的限流规则
+type Rule struct {
+	Key   string // ip, card
+	Limit Limit
+}
+
+// Limiter 按路由和维度限流，nil 表示不限流
+type Limiter struct {
+	store  Store
+	routes map[string][]Rule
+}
+
+// NewLimiter routes 的 key 为路由模板，例如 /public/v1/activate
+func NewLimiter(store Store, routes map[string][]Rule) *Limiter {
+	return &Limiter{store: store, routes: routes}
+}
+
+// Allow 检查 route 上维度为 keyType、值为 key 的请求是否放行，被拒绝时返回需要等待的时间
+func (l *Limiter) Allow(route string, keyType string, key string) (bool, time.Duration, error) {
+	if l == nil || key == "" {
+		return true, 0, nil
+	}
+	now := time.Now()
+	for _, rule := range l.routes[route] {
+		if rule.Key != keyType {
+			continue
+		}
+		ok, retryAfter, err := l.store.Take(route+"|"+keyType+"|"+key, rule.Limit, now)
+		if err != nil || !ok {
+			return ok, retryAfter, err
+		}
+	}
+	return true, 0, nil
+}
@@ -11,6 +11,8 @@ type ServerSettingS struct {
 	HttpPort     string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// TrustedProxies 信任的反向代理IP或网段，只有来自这些地址的请求才使用 X-Forwarded-For 中的客户端IP
+	TrustedProxies []string
 }
 
 type AppSettingS struct {
@@ -36,6 +38,152 @@ type DatabaseSettingS struct {
 	MaxOpenConns int
 }
 
+type OIDCSettingS struct {
+	Enabled             bool
+	Issuer              string
+	ClientID            string
+	ClientSecret        string
+	RedirectURL         string
+	Scopes              []string
+	UsernameClaim       string // 作为用户名的声明，默认为 preferred_username
+	GroupsClaim         string // 用于映射角色和应用的声明，默认为 groups
+	GroupMappings       []OIDCGroupMapping
+	FrontendRedirectURL string // 登录成功后跳转的前端地址，token 放在 fragment 中；为空时直接返回 JSON
+}
+
+type GeoIPSettingS struct {
+	DatabasePath string // MaxMind DB(.mmdb) 文件路径，为空时不查询地理位置
+}
+
+type AbuseSettingS struct {
+	Enabled bool
+	Rules   []AbuseRule // 为空时使用默认规则
+}
+
+// AbuseRule 滥用检测规则，统计窗口内的指标达到阈值时触发动作
+type AbuseRule struct {
+	Name          string
+	Signal        string        // 统计的指标，例如 seids_per_card
+	Window        time.Duration // 统计窗口，例如 1h
+	Threshold     int           // 达到该值时触发
+	Score         int           // 触发后为对象增加的分数
+	Actions       []string      // lock, block_ip, notify, flag
+	BlockDuration time.Duration // block_ip 的封禁时长
+}
+
+type RateLimitSettingS struct {
+	Enabled bool
+	Routes  []RateLimitRoute
+}
+
+// RateLimitRoute 一个公开路由的限流规则
+type RateLimitRoute struct {
+	Path  string // 路由模板，例如 /public/v1/activate
+	Rules []RateLimitRule
+}
+
+// RateLimitRule 令牌桶规则，每 Per 时间补充 Limit 个令牌，最多累积 Burst 个
+type RateLimitRule struct {
+	Key   string // ip 或 card
+	Limit int
+	Per   time.Duration
+	Burst int
+}
+
+type WebhookSettingS struct {
+	Enabled          bool
+	PollInterval     time.Duration // 轮询发件箱的间隔
+	BatchSize        int           // 每轮最多投递的数量
+	MaxAttempts      int           // 最多尝试次数，超过后进入死信列表
+	InitialBackoff   time.Duration // 第一次失败后的等待时间，之后每次翻倍
+	MaxBackoff       time.Duration // 最长的等待时间
+	Timeout          time.Duration // 单次请求的超时时间
+	ExpiryScanWindow time.Duration // 启动时补发多久以内的到期事件
+}
+
+type NotificationSettingS struct {
+	Enabled           bool          // 是否定期扫描并生成通知
+	ScanInterval      time.Duration // 扫描间隔
+	LowStockThreshold int           // 某个应用未使用的激活码少于该值时提醒
+	LowQuotaThreshold int           // 剩余额度少于该值时提醒
+	ExpiringWithin    time.Duration // 提醒多久以内到期的批次
+	Cooldown          time.Duration // 同一个提醒在冷却期内只发送一次
+	DailySummaryHour  int           // 每天几点之后发送前一天的日报
+	SendTimeout       time.Duration // 发送一条邮件或群消息的超时时间
+	SMTP              SMTPSettingS  // 邮件服务器，Host 为空时不发送邮件
+}
+
+type SMTPSettingS struct {
+	Host     string
+	Port     int
+	Username string // 为空时不认证
+	Password string
+	From     string // 发件地址
+}
+
+type ExportSettingS struct {
+	Dir               string        // 后台导出任务的文件保存目录
+	Retention         time.Duration // 文件保留的时间，到期后删除
+	MaxConcurrentJobs int           // 同时执行的导出任务数量
+}
+
+type CardSheetSettingS struct {
+	RedeemURL string            // 二维码中的兑换链接模板，例如 https://example.com/redeem?code={{.Value}}
+	MaxCards  int               // 一次最多打印的激活码数量
+	Layouts   []CardSheetLayout // 和默认版式同名时覆盖默认版式
+}
+
+// CardSheetLayout 打印激活码卡片的版式，长度单位为毫米
+type CardSheetLayout struct {
+	Name       string
+	PageWidth  float64
+	PageHeight float64
+	Columns    int // 每页的列数
+	Rows       int // 每页的行数
+	CardWidth  float64
+	CardHeight float64
+	MarginTop  float64
+	MarginLeft float64
+	GapX       float64 // 卡片之间的水平间距
+	GapY       float64 // 卡片之间的垂直间距
+	QRSize     float64 // 二维码的边长，放不下时自动缩小
+	FontSize   float64 // 激活码的字号，单位为点，放不下时自动缩小
+	Title      string  // 卡片标题的模板，为空时使用应用名称
+	Footer     string  // 卡片底部文字的模板
+	CutLines   bool    // 是否画出裁切线
+}
+
+type RedeemSettingS struct {
+	Enabled bool
+	Title   string          // 页面标题
+	Apps    []RedeemApp     // 各个应用的深度链接、兑换说明和权益
+	Captcha CaptchaSettingS // 查询激活码前的人机验证
+}
+
+// RedeemApp 兑换页面上展示的应用信息
+type RedeemApp struct {
+	AppID        string
+	DeepLink     string   // 打开应用并填入激活码的链接模板，例如 myapp://redeem?code={{.Value}}
+	Instructions string   // 兑换说明
+	Entitlements []string // 激活码包含的权益
+}
+
+type CaptchaSettingS struct {
+	Provider  string        // stub、hcaptcha、recaptcha 或 turnstile，默认为 stub
+	SiteKey   string        // 第三方服务的站点密钥
+	Secret    string        // 第三方服务的密钥，stub 用来签名题目，为空时每次启动随机生成
+	VerifyURL string        // 为空时使用服务商的默认地址
+	Timeout   time.Duration // 请求第三方服务的超时时间
+	TTL       time.Duration // stub 题目的有效期
+}
+
+// OIDCGroupMapping 身份提供方的分组对应的角色和应用
+type OIDCGroupMapping struct {
+	Group string
+	Roles []string
+	Apps  []string
+}
+
 func (s *Setting) ReadSection(k string, v interface{}) error {
 	err := s.vp.UnmarshalKey(k, v)
 	if err != nil {
@@ -0,0 +1,48 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+// NewCSVWriter 写入带 BOM 的 UTF-8 CSV，Excel 打开时中文不会乱码
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(row []string) error {
+	if !c.started {
+		c.started = true
+		if _, err := io.WriteString(c.w, "\xEF\xBB\xBF"); err != nil {
+			return err
+		}
+	}
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		escaped[i] = escapeFormula(cell)
+	}
+	return c.csv.Write(escaped)
+}
+
+func (c *csvWriter) Close() error {
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+// escapeFormula 以公式字符开头的内容前加单引号，避免用表格软件打开时被当作公式执行
+func escapeFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,41 @@
+// Package spreadsheet 逐行写入 CSV 和 XLSX 文件，内存占用和行数无关
+package spreadsheet
+
+import (
+	"fmt"
+	"io"
+)
+
+// 支持的文件格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer 逐行写入表格，Close 写入文件结尾，但不会关闭底层的 io.Writer
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+// New 按格式创建 Writer
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, "Sheet1")
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// ContentType 文件格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,128 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	rows := [][]string{{"值", "备注"}, {"ABC", "=HYPERLINK(\"x\")"}, {"DEF", "a,b\nc"}}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\xEF\xBB\xBF")) {
+		t.Fatal("missing BOM")
+	}
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes()[3:])).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0][0] != "值" || records[2][1] != "a,b\nc" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	// 公式前加单引号
+	if records[1][1] != "'=HYPERLINK(\"x\")" {
+		t.Fatalf("formula should be escaped: %q", records[1][1])
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"ABC":          "ABC",
+		"=1+1":         "'=1+1",
+		"+1":           "'+1",
+		"-1":           "'-1",
+		"@SUM(A1)":     "'@SUM(A1)",
+		"\t=1":         "'\t=1",
+		"\r=1":         "'\r=1",
+		"a=1":          "a=1",
+		"中文=HYPERLINK": "中文=HYPERLINK",
+	}
+	for cell, want := range cases {
+		if got := escapeFormula(cell); got != want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(FormatXLSX, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]string, 28)
+	for i := range header {
+		header[i] = "h"
+	}
+	if err := w.Write(header); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]string{"<a & b>", "中文"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if files[name] == nil {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+
+	r, err := files["xl/worksheets/sheet1.xml"].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref  string `xml:"r,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		t.Fatalf("invalid sheet xml: %v\n%s", err, data)
+	}
+	if len(sheet.Rows) != 2 || sheet.Rows[1].R != 2 {
+		t.Fatalf("unexpected rows: %+v", sheet.Rows)
+	}
+	if sheet.Rows[0].Cells[27].Ref != "AB1" {
+		t.Fatalf("unexpected cell reference: %s", sheet.Rows[0].Cells[27].Ref)
+	}
+	if sheet.Rows[1].Cells[0].Text != "<a & b>" || sheet.Rows[1].Cells[1].Text != "中文" {
+		t.Fatalf("unexpected cells: %+v", sheet.Rows[1].Cells)
+	}
+}
+
+func TestNewUnsupportedFormat(t *testing.T) {
+	if _, err := New("pdf", io.Discard); err == nil || !strings.Contains(err.Error(), "pdf") {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"strings"
 )
 
 const (
@@ -33,3 +34,23 @@ func GenerateActivationKeyByApp(prefix string, length int) string {
 	randomString, _ := generateRandomString(length)
 	return prefix + randomString
 }
+
+// IsActivationKeyOfApp 检查激活码是否符合应用的格式：前缀相同，前缀后是指定长度的大写字母或数字
+func IsActivationKeyOfApp(value string, prefix string, length int) bool {
+	if !strings.HasPrefix(value, prefix) {
+		return false
+	}
+	body := value[len(prefix):]
+	if length > 0 && len(body) != length {
+		return false
+	}
+	if len(body) == 0 {
+		return false
+	}
+	for i := 0; i < len(body); i++ {
+		if strings.IndexByte(charset, body[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}